@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,27 +19,42 @@ import (
 	"github.com/aura-webinar/backend/config"
 	"github.com/aura-webinar/backend/internal/ads"
 	"github.com/aura-webinar/backend/internal/analytics"
+	"github.com/aura-webinar/backend/internal/audit"
 	"github.com/aura-webinar/backend/internal/auth"
+	"github.com/aura-webinar/backend/internal/calendar"
+	"github.com/aura-webinar/backend/internal/campaigns"
 	"github.com/aura-webinar/backend/internal/certificates"
 	"github.com/aura-webinar/backend/internal/emaillogs"
+	"github.com/aura-webinar/backend/internal/emailtemplates"
 	"github.com/aura-webinar/backend/internal/feedback"
+	"github.com/aura-webinar/backend/internal/imports"
+	"github.com/aura-webinar/backend/internal/jobs"
+	"github.com/aura-webinar/backend/internal/lifecycle"
 	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/organizations"
+	"github.com/aura-webinar/backend/internal/payments"
 	"github.com/aura-webinar/backend/internal/polls"
 	"github.com/aura-webinar/backend/internal/questions"
 	"github.com/aura-webinar/backend/internal/realtime"
 	"github.com/aura-webinar/backend/internal/recorder"
 	"github.com/aura-webinar/backend/internal/recordings"
 	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/internal/reminders"
+	"github.com/aura-webinar/backend/internal/series"
 	"github.com/aura-webinar/backend/internal/sessionlog"
 	"github.com/aura-webinar/backend/internal/speakerinvites"
+	"github.com/aura-webinar/backend/internal/sso"
 	"github.com/aura-webinar/backend/internal/streams"
+	"github.com/aura-webinar/backend/internal/suppressions"
 	"github.com/aura-webinar/backend/internal/waitlist"
 	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/internal/webinartemplates"
 	"github.com/aura-webinar/backend/internal/worker"
 	"github.com/aura-webinar/backend/internal/zego"
 	"github.com/aura-webinar/backend/pkg/database"
 	"github.com/aura-webinar/backend/pkg/queue"
+	"github.com/aura-webinar/backend/pkg/ratelimit"
 	"github.com/aura-webinar/backend/pkg/redis"
 	"github.com/aura-webinar/backend/pkg/response"
 	"github.com/aura-webinar/backend/pkg/storage"
@@ -91,6 +107,7 @@ func main() {
 	jwtService := auth.NewJWTService(cfg.JWT.Secret, cfg.JWT.ExpireHours)
 	redisPubSub := realtime.NewRedisPubSub(rdb.Client, logger)
 	hub := realtime.NewHub(logger, redisPubSub, redisPubSub)
+	hub.SetSessionTracker(realtime.NewRedisSessions(rdb.Client))
 
 	iceServers := make([]webrtc.ICEServer, 0, len(cfg.WebRTC.ICEUrls))
 	for _, u := range cfg.WebRTC.ICEUrls {
@@ -104,6 +121,7 @@ func main() {
 	authRepo := auth.NewRepository(pool)
 	authHandler := auth.NewHandler(authRepo, jwtService, logger)
 	authHandler.SetEmailQueue(jobQueue, cfg.Email.FrontendURL)
+	authHandler.SetLockout(auth.NewLockout(rdb.Client, cfg.RateLimit.LockoutThreshold, cfg.RateLimit.LockoutWindow, cfg.RateLimit.LockoutDuration))
 
 	// Webinars
 	webinarRepo := webinars.NewRepository(pool)
@@ -119,6 +137,10 @@ func main() {
 	orgRepo := organizations.NewRepository(pool)
 	orgHandler := organizations.NewHandler(orgRepo)
 
+	// Single sign-on (OIDC providers from config)
+	ssoRepo := sso.NewRepository(pool)
+	ssoHandler := sso.NewHandler(cfg.SSO, ssoRepo, authRepo, orgRepo, jwtService, rdb.Client, logger)
+
 	// Registrations (Phase 2)
 	registrationRepo := registrations.NewRepository(pool)
 	waitlistRepo := waitlist.NewRepository(pool)
@@ -126,6 +148,9 @@ func main() {
 	registrationHandler.SetAuth(authRepo, jwtService)
 	registrationHandler.SetEmailQueue(jobQueue, cfg.Email.FrontendURL)
 	registrationHandler.SetWaitlist(waitlistRepo)
+	// Join links are reusable; the hub limits each attendee's concurrent live sessions per the webinar's join policy
+	registrationHandler.SetJoinPolicy(hub, cfg.Join.DeviceLimit, cfg.Join.OnLimit)
+	hub.SetSessionLimit(registrationHandler.SessionLimit)
 	if s3Client != nil {
 		registrationHandler.SetS3(s3Client)
 	}
@@ -151,20 +176,37 @@ func main() {
 	recordingRepo := recordings.NewRepository(pool)
 	recordingHandler := recordings.NewHandler(recordingRepo, webinarRepo, s3Client, logger)
 	recordingWebhook := recordings.NewWebhookHandler(recordingRepo, jobQueue, logger)
-	recordingProcessor := worker.NewRecordingProcessor(recordingRepo, s3Client, jobQueue, logger)
+	recordingWebhook.SetSignature(cfg.Recording.WebhookSecret, time.Duration(cfg.Recording.WebhookTolerance)*time.Second)
+	recordingWebhook.SetAllowedHosts(cfg.Recording.AllowedHosts)
+	recordingWebhook.SetDeliveryStore(rdb.Client)
+	if cfg.Recording.WebhookSecret == "" || len(cfg.Recording.AllowedHosts) == 0 {
+		logger.Warn("recording webhook disabled until RECORDING_WEBHOOK_SECRET and RECORDING_ALLOWED_HOSTS are set")
+	}
 
 	// In-app recording (speaker view via SFU + ffmpeg)
 	recorderSvc := recorder.NewService(sfu, cfg.Recording.OutputDir, logger)
 	recordingHandler.SetRecordingService(recorderSvc)
 
-	// Stream metadata (peak viewers)
+	// Stream metadata (peak viewers). Sessions open and close with the webinar lifecycle (go-live, end, which
+	// also queues the analytics snapshot), not with this replica's audience: viewers refreshing or connected to
+	// other replicas would split one broadcast into many sessions. A live webinar without an open session
+	// (e.g. live before the lifecycle existed) gets one from its first viewer.
 	streamRepo := streams.NewRepository(pool)
 	hub.SetAudienceChangeHandler(func(webinarID uuid.UUID, count int) {
-		session, err := streamRepo.GetOrCreateActive(ctx, webinarID)
+		session, err := streamRepo.GetActiveByWebinar(ctx, webinarID)
 		if err != nil {
 			return
 		}
-		if session != nil && count > session.PeakViewers {
+		if session == nil {
+			w, err := webinarRepo.GetByID(ctx, webinarID)
+			if err != nil || w == nil || w.Status != models.WebinarStatusLive {
+				return
+			}
+			if session, err = streamRepo.GetOrCreateActive(ctx, webinarID); err != nil {
+				return
+			}
+		}
+		if count > session.PeakViewers {
 			_ = streamRepo.UpdatePeakViewers(ctx, session.ID, count)
 		}
 	})
@@ -203,6 +245,65 @@ func main() {
 	emailLogsRepo := emaillogs.NewRepository(pool)
 	emailLogsHandler := emaillogs.NewHandler(emailLogsRepo)
 
+	// Audit log (every successful mutating API call; audience interactions excluded)
+	auditRepo := audit.NewRepository(pool)
+	auditHandler := audit.NewHandler(auditRepo, orgRepo)
+	emailTemplatesRepo := emailtemplates.NewRepository(pool)
+	emailRenderer := emailtemplates.NewRenderer(emailTemplatesRepo)
+	emailTemplatesHandler := emailtemplates.NewHandler(emailTemplatesRepo, emailRenderer, orgRepo)
+	// Organizer email campaigns (fanned out by the worker's campaign task)
+	campaignHandler := campaigns.NewHandler(campaigns.NewRepository(pool), webinarRepo, campaigns.NewAudience(registrationRepo, sessionLogRepo), emailLogsRepo, emailRenderer)
+	if cfg.Email.UnsubscribeSecret == "" {
+		logger.Warn("unsubscribe links disabled until EMAIL_UNSUBSCRIBE_SECRET is set")
+	}
+	suppressionsHandler := suppressions.NewHandler(suppressions.NewRepository(pool), emailLogsRepo, orgRepo, suppressions.NewSigner(cfg.Email.UnsubscribeSecret), logger)
+	suppressionsHandler.SetSendGridKey(cfg.Email.SendGridWebhookKey)
+	suppressionsHandler.SetSESTopics(cfg.Email.SESTopicARNs)
+	// Bulk registration imports (registered by the worker's import processor)
+	importsHandler := imports.NewHandler(imports.NewRepository(pool), webinarRepo, jobQueue, logger)
+	// Reminder schedules (planned as delayed jobs when a webinar is created or rescheduled)
+	reminderRepo := reminders.NewRepository(pool)
+	reminderPlanner := reminders.NewPlanner(reminderRepo, jobQueue)
+	webinarHandler.SetReminderPlanner(reminderPlanner)
+	// Webinar lifecycle (stream session, attendee notices, analytics, recording, cancellation emails and refunds)
+	paymentRepo := payments.NewRepository(pool)
+	paymentHandler := payments.NewHandler(paymentRepo, webinarRepo, logger)
+	lifecycleEffects := lifecycle.NewEffects(streamRepo, registrationRepo, paymentRepo, hub, jobQueue, logger)
+	lifecycleEffects.SetRecordings(recordingHandler)
+	webinarHandler.SetLifecycle(lifecycleEffects)
+	reminderHandler := reminders.NewHandler(reminderRepo, reminderPlanner, webinarRepo, orgRepo, logger)
+	// Recurring series (occurrences generated up to series.Horizon; the worker extends them)
+	seriesRepo := series.NewRepository(pool)
+	seriesGen := series.NewGenerator(seriesRepo, webinarRepo, registrationRepo, waitlistRepo, logger)
+	seriesGen.SetReminderPlanner(reminderPlanner)
+	seriesGen.SetLifecycle(lifecycleEffects)
+	seriesGen.SetEmailQueue(jobQueue, cfg.Email.FrontendURL)
+	seriesHandler := series.NewHandler(seriesRepo, seriesGen, webinarRepo, advertisementRepo, orgRepo, logger)
+	// Calendar (attendee and organization feeds; reschedules email registrants an updated invite)
+	calendarHandler := calendar.NewHandler(calendar.NewRepository(pool), orgRepo, registrationRepo,
+		calendar.NewBuilder(cfg.Email.FromName, cfg.Email.FromAddress), cfg.Email.PublicAPIURL, cfg.Email.FrontendURL, logger)
+	calendarNotifier := calendar.NewNotifier(registrationRepo, jobQueue, cfg.Email.FrontendURL)
+	webinarHandler.SetScheduleNotifier(calendarNotifier)
+	seriesGen.SetScheduleNotifier(calendarNotifier)
+	// Webinar cloning and organization templates (ads get their own S3 copies; speakers are invited again)
+	templateRepo := webinartemplates.NewRepository(pool)
+	templateCloner := webinartemplates.NewCloner(templateRepo, s3Client, logger)
+	templateCloner.SetSpeakerInviter(speakerInviteHandler)
+	templateCloner.SetReminderPlanner(reminderPlanner)
+	templateHandler := webinartemplates.NewHandler(templateRepo, templateCloner, webinarRepo, orgRepo, logger)
+	jobsHandler := jobs.NewHandler(jobQueue)
+	auditRecorder := audit.NewRecorder(auditRepo, func(ctx context.Context, webinarID uuid.UUID) (*uuid.UUID, error) {
+		w, err := webinarRepo.GetByID(ctx, webinarID)
+		if err != nil || w == nil {
+			return nil, err
+		}
+		return w.OrganizationID, nil
+	}, []string{
+		"POST /webinars/:id/questions",
+		"POST /questions/:id/upvote",
+		"POST /polls/:id/answer",
+	}, logger)
+
 	jwtValidate := func(token string) (userID, role string, err error) {
 		claims, err := jwtService.Validate(token)
 		if err != nil {
@@ -211,7 +312,17 @@ func main() {
 		return claims.UserID.String(), claims.Role, nil
 	}
 
+	// Rate limiting (Redis sliding window; rules from RATE_LIMIT_* env)
+	limiter := ratelimit.NewLimiter(rdb.Client)
+	rateLimit := func(name string, rule ratelimit.Rule, key middleware.KeyFunc) gin.HandlerFunc {
+		return middleware.RateLimit(limiter, name, rule, key, logger)
+	}
+
 	router := gin.New()
+	// Client IPs (rate limit keys, audit log) come from X-Forwarded-For only behind the configured proxies
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Fatal("trusted proxies", zap.Error(err))
+	}
 	router.Use(gin.Recovery())
 	router.Use(middleware.CORS(cfg.Server.CORSAllowedOrigins))
 	router.Use(middleware.Logger(logger))
@@ -221,46 +332,167 @@ func main() {
 
 	// Public: webinar details (for registration page), registration, token validation
 	router.GET("/webinars/list", webinarHandler.ListPublic)
-	router.GET("/webinars/:id", webinarHandler.GetByID)
-	router.POST("/webinars/:id/register", registrationHandler.Register)
+	router.GET("/webinars/catalog", webinarHandler.Catalog)
+	router.GET("/webinars/:id", middleware.OptionalJWT(jwtService), webinarHandler.GetByID)
+	router.POST("/webinars/:id/register",
+		rateLimit("webinar_register_ip", cfg.RateLimit.WebinarRegister, middleware.KeyByIP),
+		rateLimit("webinar_register", cfg.RateLimit.WebinarRegisterPerWebinar, middleware.KeyByParam("id")),
+		registrationHandler.Register)
 	router.POST("/webinars/:id/register/upload", registrationHandler.UploadFile)
 	router.POST("/webinars/:id/feedback", middleware.OptionalJWT(jwtService), feedbackHandler.Submit)
 	router.GET("/webinars/:id/certificate/validate", certificateHandler.ValidateCertificate)
 	router.GET("/webinars/:id/certificate", certificateHandler.CertificateHTML)
 	router.GET("/registrations/:token/validate", registrationHandler.ValidateToken)
+	router.GET("/series/:id", seriesHandler.Get)
+	router.POST("/series/:id/register",
+		rateLimit("webinar_register_ip", cfg.RateLimit.WebinarRegister, middleware.KeyByIP),
+		rateLimit("series_register", cfg.RateLimit.WebinarRegisterPerWebinar, middleware.KeyByParam("id")),
+		seriesHandler.Register)
+	router.GET("/registrations/:token/calendar", calendarHandler.RegistrationFeedURL)
+	router.GET("/calendar/attendees/:token", calendarHandler.AttendeeFeed)
+	router.GET("/calendar/registrations/:token", calendarHandler.RegistrationFeed)
+	router.GET("/calendar/organizations/:slug", calendarHandler.OrganizationFeed)
 
 	// Auth (public)
 	authGroup := router.Group("/auth")
 	{
-		authGroup.POST("/login", authHandler.Login)
-		authGroup.POST("/register", authHandler.Register)
+		authGroup.POST("/login",
+			rateLimit("login_ip", cfg.RateLimit.Login, middleware.KeyByIP),
+			rateLimit("login_email", cfg.RateLimit.LoginPerEmail, middleware.KeyByJSONField("email")),
+			authHandler.Login)
+		authGroup.POST("/register", rateLimit("register_ip", cfg.RateLimit.Register, middleware.KeyByIP), authHandler.Register)
 		authGroup.GET("/verify-email", authHandler.VerifyEmail)
 		authGroup.POST("/exchange-token", registrationHandler.ExchangeToken)
 		router.GET("/auth/speaker-invite/validate", speakerInviteHandler.GetInviteByToken)
 		router.POST("/auth/speaker-invite/accept", speakerInviteHandler.AcceptInvite)
+		authGroup.GET("/sso/providers", ssoHandler.ListProviders)
+		authGroup.GET("/sso/:provider/authorize", ssoHandler.Authorize)
+		authGroup.POST("/sso/:provider/callback", ssoHandler.Callback)
+	}
+
+	// Routes organization API keys may call, with the scope each requires; all other routes reject keys.
+	apiKeyScopes := map[string]string{
+		"GET /webinars":                                                   models.ScopeWebinarsRead,
+		"POST /webinars":                                                  models.ScopeWebinarsWrite,
+		"PATCH /webinars/:id":                                             models.ScopeWebinarsWrite,
+		"PUT /webinars/:id/registration-form":                             models.ScopeWebinarsWrite,
+		"POST /webinars/:id/publish":                                      models.ScopeWebinarsWrite,
+		"POST /webinars/:id/go-live":                                      models.ScopeWebinarsWrite,
+		"POST /webinars/:id/end":                                          models.ScopeWebinarsWrite,
+		"POST /webinars/:id/archive":                                      models.ScopeWebinarsWrite,
+		"POST /webinars/:id/cancel":                                       models.ScopeWebinarsWrite,
+		"POST /webinars/:id/clone":                                        models.ScopeWebinarsWrite,
+		"GET /webinars/:id/reminders":                                     models.ScopeWebinarsRead,
+		"PUT /webinars/:id/reminders":                                     models.ScopeWebinarsWrite,
+		"GET /webinars/:id/join-policy":                                   models.ScopeWebinarsRead,
+		"PUT /webinars/:id/join-policy":                                   models.ScopeWebinarsWrite,
+		"GET /webinars/:id/registrations":                                 models.ScopeRegistrationsRead,
+		"GET /webinars/:id/registrations/export":                          models.ScopeRegistrationsRead,
+		"PATCH /webinars/:id/registrations/:registrationId":               models.ScopeRegistrationsWrite,
+		"POST /webinars/:id/registrations/:registrationId/resend":         models.ScopeRegistrationsWrite,
+		"POST /webinars/:id/registrations/:registrationId/revoke":         models.ScopeRegistrationsWrite,
+		"GET /webinars/:id/registrations/:registrationId/sessions":        models.ScopeRegistrationsRead,
+		"POST /webinars/:id/registrations/:registrationId/sessions/reset": models.ScopeRegistrationsWrite,
+		"GET /webinars/:id/registrations/imports":                         models.ScopeRegistrationsRead,
+		"POST /webinars/:id/registrations/imports":                        models.ScopeRegistrationsWrite,
+		"GET /webinars/:id/registrations/imports/:importId":               models.ScopeRegistrationsRead,
+		"GET /webinars/:id/registrations/imports/:importId/report":        models.ScopeRegistrationsRead,
+		"GET /webinars/:id/analytics":                                     models.ScopeAnalyticsRead,
+		"GET /webinars/:id/analytics/sessions":                            models.ScopeAnalyticsRead,
+		"GET /webinars/:id/analytics/sessions/:sessionId":                 models.ScopeAnalyticsRead,
+		"GET /series":                              models.ScopeWebinarsRead,
+		"POST /series":                             models.ScopeWebinarsWrite,
+		"PATCH /series/:id":                        models.ScopeWebinarsWrite,
+		"GET /series/:id/occurrences":              models.ScopeWebinarsRead,
+		"PATCH /series/:id/occurrences/:webinarId": models.ScopeWebinarsWrite,
+		"PUT /series/:id/ads":                      models.ScopeWebinarsWrite,
 	}
 
-	// Protected API (JWT required)
+	// Protected API (JWT or organization API key required)
 	api := router.Group("")
-	api.Use(middleware.JWT(jwtService))
+	api.Use(middleware.JWTOrAPIKey(jwtService, orgRepo, organizations.APIKeyPrefix, apiKeyScopes, logger))
+	api.Use(auditRecorder.Middleware())
 	{
 		// Users (admin only; for speaker assignment etc.)
 		api.GET("/users", middleware.RequireRole("admin"), authHandler.List)
 
+		// SSO: link an identity provider account to the signed-in user
+		api.GET("/auth/sso/:provider/link", ssoHandler.Link)
+
 		// Organizations (create, join, list my orgs; list members for org access)
 		api.GET("/organizations", orgHandler.ListMyOrganizations)
 		api.POST("/organizations", orgHandler.CreateOrganization)
 		api.POST("/organizations/join", orgHandler.JoinOrganization)
 		api.GET("/organizations/:id/members", orgHandler.ListMembers)
+		api.GET("/organizations/:id/domains", orgHandler.ListDomains)
+		api.POST("/organizations/:id/domains", orgHandler.CreateDomain)
+		api.POST("/organizations/:id/domains/:domainId/verify", orgHandler.VerifyDomain)
+		api.DELETE("/organizations/:id/domains/:domainId", orgHandler.DeleteDomain)
+		api.GET("/organizations/:id/api-keys", orgHandler.ListAPIKeys)
+		api.POST("/organizations/:id/api-keys", orgHandler.CreateAPIKey)
+		api.DELETE("/organizations/:id/api-keys/:keyId", orgHandler.RevokeAPIKey)
+		api.GET("/organizations/:id/audit-log", auditHandler.List)
+		api.GET("/organizations/:id/email-branding", emailTemplatesHandler.GetBranding)
+		api.PUT("/organizations/:id/email-branding", emailTemplatesHandler.UpdateBranding)
+		api.GET("/organizations/:id/email-templates", emailTemplatesHandler.List)
+		api.POST("/organizations/:id/email-templates/preview", emailTemplatesHandler.Preview)
+		api.GET("/organizations/:id/email-templates/:type/:locale", emailTemplatesHandler.Get)
+		api.PUT("/organizations/:id/email-templates/:type/:locale", emailTemplatesHandler.Upsert)
+		api.DELETE("/organizations/:id/email-templates/:type/:locale", emailTemplatesHandler.Delete)
+		api.GET("/organizations/:id/email-suppressions", suppressionsHandler.List)
+		api.POST("/organizations/:id/email-suppressions", suppressionsHandler.Create)
+		api.DELETE("/organizations/:id/email-suppressions/:suppressionId", suppressionsHandler.Delete)
+		api.GET("/organizations/:id/reminders", reminderHandler.GetOrganization)
+		api.PUT("/organizations/:id/reminders", reminderHandler.UpdateOrganization)
+		api.GET("/organizations/:id/webinar-templates", templateHandler.List)
+		api.POST("/organizations/:id/webinar-templates", templateHandler.Create)
+		api.GET("/organizations/:id/webinar-templates/:templateId", templateHandler.Get)
+		api.PATCH("/organizations/:id/webinar-templates/:templateId", templateHandler.Update)
+		api.DELETE("/organizations/:id/webinar-templates/:templateId", templateHandler.Delete)
+		api.POST("/organizations/:id/webinar-templates/:templateId/webinars", middleware.RequireRole("admin"), templateHandler.CreateWebinar)
 
 		// Webinars (GET /webinars/:id is public, for registration page)
 		api.GET("/webinars", webinarHandler.List)
 		api.POST("/webinars", middleware.RequireRole("admin"), webinarHandler.Create)
 		api.GET("/webinars/:id/analytics", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), analyticsHandler.GetByWebinar)
+		api.GET("/webinars/:id/analytics/sessions", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), analyticsHandler.ListSessions)
+		api.GET("/webinars/:id/analytics/sessions/:sessionId", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), analyticsHandler.GetSession)
+		api.GET("/webinars/:id/registrations", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.ListByWebinar)
+		api.GET("/webinars/:id/registrations/export", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.Export)
+		api.PATCH("/webinars/:id/registrations/:registrationId", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.Update)
+		api.POST("/webinars/:id/registrations/:registrationId/resend", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.ResendLink)
+		api.POST("/webinars/:id/registrations/:registrationId/revoke", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.Revoke)
+		api.GET("/webinars/:id/registrations/:registrationId/sessions", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.Sessions)
+		api.POST("/webinars/:id/registrations/:registrationId/sessions/reset", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.ResetSessions)
+		api.GET("/webinars/:id/registrations/imports", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), importsHandler.List)
+		api.POST("/webinars/:id/registrations/imports", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), importsHandler.Create)
+		api.GET("/webinars/:id/registrations/imports/:importId", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), importsHandler.Get)
+		api.GET("/webinars/:id/registrations/imports/:importId/report", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), importsHandler.Report)
 		api.GET("/webinars/:id/emails", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), emailLogsHandler.ListByWebinar)
+		api.GET("/webinars/:id/refunds", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), paymentHandler.ListRefunds)
+		api.POST("/webinars/:id/refunds/:paymentId/refunded", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), paymentHandler.MarkRefunded)
 		api.POST("/webinars/:id/emails/resend", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), emailLogsHandler.Resend)
+		api.GET("/webinars/:id/campaigns", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), campaignHandler.List)
+		api.POST("/webinars/:id/campaigns", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), campaignHandler.Create)
+		api.POST("/webinars/:id/campaigns/audience", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), campaignHandler.Audience)
+		api.GET("/webinars/:id/campaigns/:campaignId", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), campaignHandler.Get)
+		api.PATCH("/webinars/:id/campaigns/:campaignId", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), campaignHandler.Update)
+		api.DELETE("/webinars/:id/campaigns/:campaignId", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), campaignHandler.Delete)
+		api.POST("/webinars/:id/campaigns/:campaignId/schedule", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), campaignHandler.Schedule)
+		api.POST("/webinars/:id/campaigns/:campaignId/cancel", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), campaignHandler.Cancel)
 		api.PATCH("/webinars/:id", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), webinarHandler.Update)
 		api.PUT("/webinars/:id/registration-form", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), webinarHandler.UpdateRegistrationForm)
+		api.POST("/webinars/:id/publish", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), webinarHandler.Transition(models.WebinarActionPublish))
+		api.POST("/webinars/:id/archive", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), webinarHandler.Transition(models.WebinarActionArchive))
+		api.POST("/webinars/:id/cancel", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), webinarHandler.Transition(models.WebinarActionCancel))
+		api.POST("/webinars/:id/clone", middleware.RequireRole("admin"), webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), templateHandler.Clone)
+		// Speakers may take a webinar live and end it, so these check access in the handler.
+		api.POST("/webinars/:id/go-live", webinarHandler.Transition(models.WebinarActionGoLive))
+		api.POST("/webinars/:id/end", webinarHandler.Transition(models.WebinarActionEnd))
+		api.GET("/webinars/:id/reminders", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), reminderHandler.GetWebinar)
+		api.PUT("/webinars/:id/reminders", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), reminderHandler.UpdateWebinar)
+		api.GET("/webinars/:id/join-policy", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.GetJoinPolicy)
+		api.PUT("/webinars/:id/join-policy", webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), registrationHandler.UpdateJoinPolicy)
 		api.DELETE("/webinars/:id", webinarHandler.Delete)
 		api.POST("/webinars/:id/speakers", middleware.RequireRole("admin", "speaker"), webinarHandler.AddSpeaker)
 		api.POST("/webinars/:id/speakers/invite", middleware.RequireRole("admin", "speaker"), speakerInviteHandler.Invite)
@@ -269,12 +501,24 @@ func main() {
 		api.GET("/webinars/:id/feedback", middleware.RequireRole("admin", "speaker"), webinars.RequireWebinarOrgAccess(webinarRepo, orgRepo), feedbackHandler.List)
 		api.GET("/webinars/:id/zego-token", zegoHandler.GetToken)
 
+		// Recurring series (GET /series/:id and registration are public)
+		api.GET("/series", seriesHandler.List)
+		api.POST("/series", middleware.RequireRole("admin"), seriesHandler.Create)
+		api.PATCH("/series/:id", seriesHandler.Update)
+		api.GET("/series/:id/occurrences", seriesHandler.Occurrences)
+		api.PATCH("/series/:id/occurrences/:webinarId", seriesHandler.UpdateOccurrence)
+		api.PUT("/series/:id/ads", seriesHandler.UpdateAds)
+
+		// Calendar feed link of the signed-in user (the feeds themselves are public)
+		api.GET("/calendar/feed", calendarHandler.FeedURL)
+		api.POST("/calendar/feed/reset", calendarHandler.ResetFeed)
+
 		// Questions
 		api.POST("/webinars/:id/questions", questionHandler.Create)
 		api.GET("/webinars/:id/questions", middleware.RequireRole("admin", "speaker"), questionHandler.ListByWebinar)
 		api.PATCH("/questions/:id/approve", middleware.RequireRole("admin", "speaker"), questionHandler.Approve)
 		api.PATCH("/questions/:id/answer", middleware.RequireRole("admin", "speaker"), questionHandler.Answer)
-		api.POST("/questions/:id/upvote", questionHandler.Upvote)
+		api.POST("/questions/:id/upvote", rateLimit("upvote", cfg.RateLimit.Upvote, middleware.KeyByUserID), questionHandler.Upvote)
 
 		// Polls
 		api.POST("/webinars/:id/polls", middleware.RequireRole("admin", "speaker"), pollHandler.Create)
@@ -302,13 +546,31 @@ func main() {
 		api.GET("/recordings/:id/download-url", recordingHandler.GenerateDownloadURL)
 		api.POST("/webinars/:id/recording/start", recordingHandler.StartRecording)
 		api.POST("/webinars/:id/recording/stop", recordingHandler.StopRecording)
+
+		// Job queue administration (platform operators only): dead-letter inspection, replay, purge; expvar gauges
+		admin := api.Group("/admin", middleware.RequireOperator(cfg.Server.Operators))
+		admin.GET("/jobs/dlq", jobsHandler.ListDLQ)
+		admin.GET("/jobs/dlq/stats", jobsHandler.DLQStats)
+		admin.POST("/jobs/dlq/replay", jobsHandler.ReplayAllDLQ)
+		admin.DELETE("/jobs/dlq", jobsHandler.PurgeDLQ)
+		admin.GET("/jobs/dlq/:jobId", jobsHandler.GetDLQ)
+		admin.POST("/jobs/dlq/:jobId/replay", jobsHandler.ReplayDLQ)
+		admin.DELETE("/jobs/dlq/:jobId", jobsHandler.DeleteDLQ)
+		admin.GET("/metrics", gin.WrapH(expvar.Handler()))
 	}
 
-	// Webhooks (no JWT; validate webhook signature in handler when configured)
+	// Webhooks (no JWT; HMAC signature verified in handler)
 	router.POST("/webhooks/recording-ready", recordingWebhook.RecordingReady)
+	// Email provider bounce/complaint events (SendGrid ECDSA signature, SES via signed SNS messages)
+	router.POST("/webhooks/email/sendgrid", suppressionsHandler.SendGridEvents)
+	router.POST("/webhooks/email/ses", suppressionsHandler.SESEvents)
+
+	// Unsubscribe links in emails (signed token in query; POST also serves RFC 8058 one-click)
+	router.GET("/email/unsubscribe", suppressionsHandler.UnsubscribePage)
+	router.POST("/email/unsubscribe", suppressionsHandler.Unsubscribe)
 
 	// WebSocket (token in query; no Authorization header required)
-	router.GET("/ws", func(c *gin.Context) {
+	router.GET("/ws", rateLimit("ws_ip", cfg.RateLimit.WebSocket, middleware.KeyByIP), func(c *gin.Context) {
 		realtime.ServeWs(hub, logger, jwtValidate, sfu)(c)
 	})
 
@@ -319,20 +581,19 @@ func main() {
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 	}
 
-	// Background workers (recording upload to S3, email sending)
+	// Background processors run in-process unless a dedicated worker (cmd/worker) handles them
 	workerCtx, workerCancel := context.WithCancel(context.Background())
 	defer workerCancel()
-	if s3Client != nil {
-		go recordingProcessor.Run(workerCtx)
-		logger.Info("recording worker started")
+	workerDone := make(chan struct{})
+	if cfg.Worker.Embedded {
+		go func() {
+			worker.NewRunner(cfg, pool, rdb.Client, jobQueue, s3Client, logger).Run(workerCtx)
+			close(workerDone)
+		}()
+	} else {
+		close(workerDone)
+		logger.Info("embedded background processors disabled (WORKER_EMBEDDED=false)")
 	}
-	emailProcessor := worker.NewEmailProcessor(emailLogsRepo, jobQueue, cfg.Email, logger)
-	go emailProcessor.Run(workerCtx)
-	logger.Info("email worker started")
-
-	reminderScheduler := worker.NewReminderScheduler(webinarRepo, registrationRepo, emailLogsRepo, jobQueue, cfg.Email.FrontendURL, logger)
-	go reminderScheduler.Run(workerCtx)
-	logger.Info("reminder scheduler started")
 
 	go func() {
 		logger.Info("server listening", zap.String("port", cfg.Server.Port))
@@ -351,6 +612,7 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server shutdown", zap.Error(err))
 	}
+	<-workerDone
 	logger.Info("server stopped")
 }
 
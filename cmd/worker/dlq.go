@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/config"
+	"github.com/aura-webinar/backend/pkg/queue"
+	"github.com/aura-webinar/backend/pkg/redis"
+)
+
+const dlqUsage = `usage: worker dlq <command> [flags]
+
+commands:
+  stats                               DLQ depth, total and per job type
+  list   [-type T] [-webinar ID] [-offset N] [-limit N] [-json]
+  show   <job-id>                     job envelope with last error and failure history
+  replay <job-id>                     re-queue one job with fresh attempts
+  replay -all [-type T] [-webinar ID] re-queue every matching job
+  purge  <job-id>                     delete one job
+  purge  -all [-type T] [-webinar ID] delete every matching job
+`
+
+// runDLQ implements the "dlq" subcommand. Returns the process exit code.
+func runDLQ(args []string, logger *zap.Logger) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, dlqUsage)
+		return 2
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("load config", zap.Error(err))
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	rdb, err := redis.NewClient(ctx, cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB, logger)
+	if err != nil {
+		logger.Error("redis", zap.Error(err))
+		return 1
+	}
+	defer rdb.Close()
+
+	q := queue.NewQueue(rdb.Client, logger)
+	if err := dlqCommand(ctx, q, args[0], args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "dlq:", err)
+		return 1
+	}
+	return 0
+}
+
+func dlqCommand(ctx context.Context, q *queue.Queue, cmd string, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("dlq "+cmd, flag.ContinueOnError)
+	jobType := fs.String("type", "", "only jobs of this type")
+	webinar := fs.String("webinar", "", "only jobs for this webinar ID")
+	all := fs.Bool("all", false, "apply to every matching job")
+	offset := fs.Int("offset", 0, "skip this many matching jobs")
+	limit := fs.Int("limit", 50, "list at most this many jobs (0 = all)")
+	asJSON := fs.Bool("json", false, "print jobs as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	filter := queue.DLQFilter{Type: queue.JobType(*jobType)}
+	if *webinar != "" {
+		id, err := uuid.Parse(*webinar)
+		if err != nil {
+			return fmt.Errorf("invalid -webinar: %w", err)
+		}
+		filter.WebinarID = id
+	}
+
+	switch cmd {
+	case "stats":
+		stats, err := q.DLQStats(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "depth\t%d\n", stats.Depth)
+		for t, n := range stats.ByType {
+			name := string(t)
+			if name == "" {
+				name = "(invalid)"
+			}
+			fmt.Fprintf(out, "%s\t%d\n", name, n)
+		}
+		return nil
+
+	case "list":
+		jobs, total, err := q.ListDLQ(ctx, filter, *offset, *limit)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return json.NewEncoder(out).Encode(map[string]interface{}{"items": jobs, "total": total})
+		}
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTYPE\tWEBINAR\tATTEMPTS\tCREATED\tLAST ERROR")
+		for _, job := range jobs {
+			webinarID := ""
+			if id := queue.WebinarIDOf(job); id != uuid.Nil {
+				webinarID = id.String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", job.ID, job.Type, webinarID, job.Attempt,
+				job.CreatedAt.Format(time.RFC3339), truncate(job.LastError, 80))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%d of %d matching job(s)\n", len(jobs), total)
+		return nil
+
+	case "show":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("show takes exactly one job ID")
+		}
+		job, err := q.GetDLQ(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return fmt.Errorf("job %s is not in the dead-letter queue", fs.Arg(0))
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(job)
+
+	case "replay":
+		if *all {
+			n, err := q.ReplayAllDLQ(ctx, filter)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "replayed %d job(s)\n", n)
+			return nil
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("replay takes one job ID, or -all")
+		}
+		ok, err := q.ReplayDLQ(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("job %s is not in the dead-letter queue", fs.Arg(0))
+		}
+		fmt.Fprintf(out, "replayed %s\n", fs.Arg(0))
+		return nil
+
+	case "purge":
+		if *all {
+			n, err := q.PurgeDLQ(ctx, filter)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "purged %d job(s)\n", n)
+			return nil
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("purge takes one job ID, or -all")
+		}
+		ok, err := q.DeleteDLQ(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("job %s is not in the dead-letter queue", fs.Arg(0))
+		}
+		fmt.Fprintf(out, "purged %s\n", fs.Arg(0))
+		return nil
+	}
+	return fmt.Errorf("unknown command %q\n\n%s", cmd, dlqUsage)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -1,8 +1,11 @@
-// Package main runs the background job worker (recording upload to S3, etc.).
+// Package main runs the background job worker: every queue processor (recordings, emails, analytics)
+// and periodic task (reminders, audit retention), with graceful drain and health probes.
+// "worker dlq ..." inspects, replays and purges dead-lettered jobs instead.
 package main
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,7 +15,6 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/aura-webinar/backend/config"
-	"github.com/aura-webinar/backend/internal/recordings"
 	"github.com/aura-webinar/backend/internal/worker"
 	"github.com/aura-webinar/backend/pkg/database"
 	"github.com/aura-webinar/backend/pkg/queue"
@@ -24,6 +26,12 @@ func main() {
 	logger := newLogger()
 	defer logger.Sync()
 
+	if len(os.Args) > 1 && os.Args[1] == "dlq" {
+		code := runDLQ(os.Args[2:], logger)
+		logger.Sync()
+		os.Exit(code)
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		logger.Fatal("load config", zap.Error(err))
@@ -42,35 +50,68 @@ func main() {
 	}
 	defer rdb.Close()
 
-	s3Cfg := storage.S3Config{
-		Region:               cfg.AWS.Region,
-		AccessKeyID:          cfg.AWS.AccessKeyID,
-		SecretAccessKey:      cfg.AWS.SecretAccessKey,
-		AdsBucket:            cfg.AWS.AdsBucket,
-		RecordingsBucket:     cfg.AWS.RecordingsBucket,
-		PresignExpireMinutes: cfg.AWS.PresignExpireMinutes,
-	}
-	s3Client, err := storage.NewS3(ctx, s3Cfg, logger)
-	if err != nil {
-		logger.Fatal("s3", zap.Error(err))
+	var s3Client *storage.S3
+	if cfg.AWS.Region != "" {
+		s3Cfg := storage.S3Config{
+			Region:               cfg.AWS.Region,
+			AccessKeyID:          cfg.AWS.AccessKeyID,
+			SecretAccessKey:      cfg.AWS.SecretAccessKey,
+			AdsBucket:            cfg.AWS.AdsBucket,
+			RecordingsBucket:     cfg.AWS.RecordingsBucket,
+			PresignExpireMinutes: cfg.AWS.PresignExpireMinutes,
+		}
+		s3Client, err = storage.NewS3(ctx, s3Cfg, logger)
+		if err != nil {
+			logger.Warn("s3 disabled", zap.Error(err))
+		}
 	}
 
-	recRepo := recordings.NewRepository(pool)
 	jobQueue := queue.NewQueue(rdb.Client, logger)
-	processor := worker.NewRecordingProcessor(recRepo, s3Client, jobQueue, logger)
+	runner := worker.NewRunner(cfg, pool, rdb.Client, jobQueue, s3Client, logger)
+
+	// Liveness/readiness probes; readiness drops to 503 as soon as draining starts.
+	var healthSrv *http.Server
+	if cfg.Worker.HealthPort != "" {
+		healthSrv = &http.Server{
+			Addr: ":" + cfg.Worker.HealthPort,
+			Handler: worker.HealthHandler(runner.Consumer(), map[string]worker.HealthCheck{
+				"database": pool.Ping,
+				"redis":    func(ctx context.Context) error { return rdb.Ping(ctx).Err() },
+			}),
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Info("worker health endpoint listening", zap.String("port", cfg.Worker.HealthPort))
+			if err := healthSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("health endpoint", zap.Error(err))
+			}
+		}()
+	}
 
 	workerCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go processor.Run(workerCtx)
+	done := make(chan struct{})
+	go func() {
+		runner.Run(workerCtx)
+		close(done)
+	}()
 	logger.Info("worker started")
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	// Stop taking jobs; the consumer finishes in-flight work within the drain timeout and re-queues the rest.
+	logger.Info("worker draining", zap.Duration("timeout", cfg.Worker.DrainTimeout))
 	cancel()
-	time.Sleep(2 * time.Second)
+	<-done
+	if healthSrv != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelShutdown()
+		_ = healthSrv.Shutdown(shutdownCtx)
+	}
 	logger.Info("worker stopped")
 }
 
@@ -5,8 +5,11 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/aura-webinar/backend/pkg/ratelimit"
 )
 
 // Config holds application configuration loaded from environment.
@@ -16,12 +19,70 @@ type Config struct {
 	Redis     RedisConfig
 	JWT       JWTConfig
 	WebRTC    WebRTCConfig
+	Join      JoinConfig
 	AWS       AWSConfig
 	Recording RecordingConfig
 	Zego      ZegoConfig
 	Stripe    StripeConfig
 	Razorpay  RazorpayConfig
 	Email     EmailConfig
+	SSO       SSOConfig
+	RateLimit RateLimitConfig
+	Audit     AuditConfig
+	Worker    WorkerConfig
+}
+
+// WorkerConfig holds background job processing settings (cmd/worker, or the server when Embedded).
+type WorkerConfig struct {
+	// Embedded runs the job processors and schedulers inside the API server too (single-binary deploys).
+	Embedded bool
+	// Concurrency is the number of parallel workers per job type (e.g. "email" -> 4); unlisted types use 1.
+	Concurrency       map[string]int
+	DrainTimeout      time.Duration // how long in-flight jobs may finish after SIGTERM before being re-queued
+	VisibilityTimeout time.Duration // how long a job stays leased without a heartbeat
+	HealthPort        string        // cmd/worker serves /healthz and /readyz here; empty disables
+	LeaderLeaseTTL    time.Duration // scheduled tasks run on the replica holding this Redis lease
+}
+
+// AuditConfig holds audit log retention.
+type AuditConfig struct {
+	RetentionDays int // entries older than this are deleted daily; 0 = keep forever
+}
+
+// RateLimitConfig holds per-route request budgets (env format "N/duration", e.g. "10/1m") and login lockout.
+type RateLimitConfig struct {
+	Login                     ratelimit.Rule // POST /auth/login per IP
+	LoginPerEmail             ratelimit.Rule // POST /auth/login per email
+	Register                  ratelimit.Rule // POST /auth/register per IP
+	WebinarRegister           ratelimit.Rule // POST /webinars/:id/register per IP
+	WebinarRegisterPerWebinar ratelimit.Rule // POST /webinars/:id/register per webinar
+	Upvote                    ratelimit.Rule // POST /questions/:id/upvote per user
+	WebSocket                 ratelimit.Rule // GET /ws connections per IP
+	LockoutThreshold          int            // failed logins within LockoutWindow before the account locks; 0 = off
+	LockoutWindow             time.Duration
+	LockoutDuration           time.Duration
+}
+
+// SSOConfig holds OpenID Connect single sign-on providers for the organizer console.
+type SSOConfig struct {
+	Providers []OIDCProviderConfig
+	StateTTL  int // minutes an authorization request (state, nonce, PKCE verifier) stays valid
+}
+
+// OIDCProviderConfig is one OpenID Connect identity provider (Google, Microsoft, Okta, ...).
+type OIDCProviderConfig struct {
+	ID           string // URL-safe key used in routes, e.g. "google"
+	Name         string // display name for the login button
+	Issuer       string // issuer URL; discovery document is read from <issuer>/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // frontend callback page registered with the IdP
+	Scopes       []string // defaults to openid, email, profile
+	// TrustEmail treats the email claim as verified when the IdP omits email_verified (e.g. Microsoft Entra ID work accounts).
+	// It only applies to a tenant-specific issuer or with AllowedTenants; a multi-tenant issuer accepts any tenant's emails.
+	TrustEmail bool
+	// AllowedTenants restricts a multi-tenant issuer (Microsoft /organizations, /common) to these tenant IDs (tid claim).
+	AllowedTenants []string
 }
 
 // ZegoConfig holds ZEGOCLOUD (live streaming / video) credentials for token generation.
@@ -53,11 +114,26 @@ type EmailConfig struct {
 	SMTPPass    string
 	APIKey      string // optional e.g. SendGrid
 	FrontendURL string // base URL for join links in emails
+	// Transport is smtp, sendgrid, ses or file; empty picks sendgrid when APIKey is set, else smtp.
+	Transport         string
+	SMTPPoolSize      int
+	SESRegion         string
+	SinkDir           string // file transport writes .eml files here
+	UnsubscribeMailto string // optional mailto: target added to List-Unsubscribe
+	// PublicAPIURL is this API's public base URL; unsubscribe links in emails point at it.
+	PublicAPIURL       string
+	UnsubscribeSecret  string   // HMAC key for unsubscribe tokens; unset disables unsubscribe links
+	SendGridWebhookKey string   // signed event webhook verification key; empty rejects SendGrid events
+	SESTopicARNs       []string // SNS topics accepted for SES bounces and complaints; empty rejects SES events
+	CampaignRate       int      // campaign emails enqueued per minute, across all campaigns
 }
 
 // RecordingConfig holds in-app recording (speaker view) settings.
 type RecordingConfig struct {
-	OutputDir string // directory for temp recording files; empty = os.TempDir()
+	OutputDir        string   // directory for temp recording files; empty = os.TempDir()
+	WebhookSecret    string   // HMAC-SHA256 secret for /webhooks/recording-ready; empty rejects all deliveries
+	WebhookTolerance int      // seconds a signed timestamp stays valid
+	AllowedHosts     []string // provider hosts the worker may download recordings from; ".example.com" matches subdomains
 }
 
 // WebRTCConfig holds STUN/TURN ICE server URLs for WebRTC.
@@ -65,12 +141,24 @@ type WebRTCConfig struct {
 	ICEUrls []string // e.g. stun:stun.l.google.com:19302 (comma-separated in env)
 }
 
+// JoinConfig holds the default join link policy; organizers override it per webinar.
+type JoinConfig struct {
+	DeviceLimit int    // concurrent live sessions per attendee; 0 = unlimited
+	OnLimit     string // "newest_wins" disconnects the oldest session, "reject" refuses the new one
+}
+
 // ServerConfig holds HTTP server settings.
 type ServerConfig struct {
 	Port               string
 	ReadTimeout        int
 	WriteTimeout       int
 	CORSAllowedOrigins string // comma-separated, or "*" for all (e.g. http://localhost:3000,http://localhost:3001)
+	// TrustedProxies are the load balancer IPs/CIDRs whose X-Forwarded-For gives the client IP (rate limits,
+	// audit log). Empty trusts none: the client IP is the connection's remote address.
+	TrustedProxies []string
+	// Operators are the user IDs of platform operators, the only users allowed on /admin (dead-letter
+	// queue, metrics). The "admin" user role is an organizer role anyone can sign up with. Empty disables /admin.
+	Operators []string
 }
 
 // DatabaseConfig holds PostgreSQL connection settings.
@@ -135,6 +223,8 @@ func Load() (*Config, error) {
 			ReadTimeout:        readTimeout,
 			WriteTimeout:       writeTimeout,
 			CORSAllowedOrigins: getCORSAllowedOrigins(),
+			TrustedProxies:     splitTrim(getEnv("TRUSTED_PROXIES", ""), ","),
+			Operators:          splitTrim(getEnv("PLATFORM_OPERATORS", ""), ","),
 		},
 		Database: DatabaseConfig{
 			URL:      getEnv("DATABASE_URL", "postgres://localhost:5432/webinar?sslmode=disable"),
@@ -157,6 +247,10 @@ func Load() (*Config, error) {
 		WebRTC: WebRTCConfig{
 			ICEUrls: splitTrim(getEnv("WEBRTC_ICE_URLS", "stun:stun.l.google.com:19302"), ","),
 		},
+		Join: JoinConfig{
+			DeviceLimit: getEnvInt("JOIN_DEVICE_LIMIT", 2),
+			OnLimit:     getEnv("JOIN_LIMIT_POLICY", "newest_wins"),
+		},
 		AWS: AWSConfig{
 			Region:               getEnv("AWS_REGION", "us-east-1"),
 			AccessKeyID:          getEnv("AWS_ACCESS_KEY_ID", ""),
@@ -166,7 +260,10 @@ func Load() (*Config, error) {
 			PresignExpireMinutes: getEnvInt("AWS_PRESIGN_EXPIRE_MINUTES", 15),
 		},
 		Recording: RecordingConfig{
-			OutputDir: getEnv("RECORDING_OUTPUT_DIR", ""),
+			OutputDir:        getEnv("RECORDING_OUTPUT_DIR", ""),
+			WebhookSecret:    getEnv("RECORDING_WEBHOOK_SECRET", ""),
+			WebhookTolerance: getEnvInt("RECORDING_WEBHOOK_TOLERANCE_SECONDS", 300),
+			AllowedHosts:     splitTrim(getEnv("RECORDING_ALLOWED_HOSTS", ""), ","),
 		},
 		Zego: ZegoConfig{
 			AppID:        uint32(getEnvInt("ZEGO_APP_ID", 0)),
@@ -182,19 +279,120 @@ func Load() (*Config, error) {
 			WebhookSecret: getEnv("RAZORPAY_WEBHOOK_SECRET", ""),
 		},
 		Email: EmailConfig{
-			FromAddress: getEnv("FROM_EMAIL", getEnv("EMAIL_FROM_ADDRESS", "noreply@example.com")),
-			FromName:    getEnv("FROM_NAME", getEnv("EMAIL_FROM_NAME", "Aura Webinar")),
-			SMTPHost:    getEnv("SMTP_HOST", ""),
-			SMTPPort:    getEnvInt("SMTP_PORT", 587),
-			SMTPUser:    getEnv("SMTP_USER", ""),
-			SMTPPass:    getEnv("SMTP_PASSWORD", getEnv("SMTP_PASS", "")),
-			APIKey:      getEnv("EMAIL_API_KEY", ""),
-			FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+			FromAddress:        getEnv("FROM_EMAIL", getEnv("EMAIL_FROM_ADDRESS", "noreply@example.com")),
+			FromName:           getEnv("FROM_NAME", getEnv("EMAIL_FROM_NAME", "Aura Webinar")),
+			SMTPHost:           getEnv("SMTP_HOST", ""),
+			SMTPPort:           getEnvInt("SMTP_PORT", 587),
+			SMTPUser:           getEnv("SMTP_USER", ""),
+			SMTPPass:           getEnv("SMTP_PASSWORD", getEnv("SMTP_PASS", "")),
+			APIKey:             getEnv("EMAIL_API_KEY", ""),
+			Transport:          getEnv("EMAIL_TRANSPORT", ""),
+			SMTPPoolSize:       getEnvInt("SMTP_POOL_SIZE", 4),
+			SESRegion:          getEnv("EMAIL_SES_REGION", getEnv("AWS_REGION", "us-east-1")),
+			SinkDir:            getEnv("EMAIL_SINK_DIR", "tmp/mail"),
+			UnsubscribeMailto:  getEnv("EMAIL_UNSUBSCRIBE_MAILTO", ""),
+			PublicAPIURL:       strings.TrimSuffix(getEnv("API_PUBLIC_URL", "http://localhost:8080"), "/"),
+			UnsubscribeSecret:  getEnv("EMAIL_UNSUBSCRIBE_SECRET", ""),
+			SendGridWebhookKey: getEnv("EMAIL_SENDGRID_WEBHOOK_KEY", ""),
+			SESTopicARNs:       splitTrim(getEnv("EMAIL_SES_TOPIC_ARNS", ""), ","),
+			CampaignRate:       getEnvInt("EMAIL_CAMPAIGN_RATE_PER_MINUTE", 600),
+			FrontendURL:        getEnv("FRONTEND_URL", "http://localhost:3000"),
+		},
+		SSO: SSOConfig{
+			Providers: loadOIDCProviders(),
+			StateTTL:  getEnvInt("OIDC_STATE_TTL_MINUTES", 10),
 		},
+		Audit: AuditConfig{
+			RetentionDays: getEnvInt("AUDIT_RETENTION_DAYS", 365),
+		},
+		Worker: WorkerConfig{
+			Embedded:          getEnv("WORKER_EMBEDDED", "true") != "false",
+			DrainTimeout:      time.Duration(getEnvInt("WORKER_DRAIN_TIMEOUT_SECONDS", 30)) * time.Second,
+			VisibilityTimeout: time.Duration(getEnvInt("WORKER_VISIBILITY_TIMEOUT_SECONDS", 300)) * time.Second,
+			HealthPort:        getEnv("WORKER_HEALTH_PORT", "8081"),
+			LeaderLeaseTTL:    time.Duration(getEnvInt("WORKER_LEADER_TTL_SECONDS", 30)) * time.Second,
+		},
+	}
+	concurrency, err := parseConcurrency(getEnv("WORKER_CONCURRENCY", "recording_upload=2,email=4,analytics=1,reminder=1,registration_import=1"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Worker.Concurrency = concurrency
+	if cfg.Join.DeviceLimit < 0 || (cfg.Join.OnLimit != "newest_wins" && cfg.Join.OnLimit != "reject") {
+		return nil, fmt.Errorf("JOIN_DEVICE_LIMIT must be 0 or more and JOIN_LIMIT_POLICY newest_wins or reject")
 	}
+	rl, err := loadRateLimits()
+	if err != nil {
+		return nil, err
+	}
+	cfg.RateLimit = rl
 	return cfg, nil
 }
 
+// loadRateLimits reads RATE_LIMIT_* rules. RATE_LIMIT_ENABLED=false turns every rule off (lockout stays on).
+func loadRateLimits() (RateLimitConfig, error) {
+	rl := RateLimitConfig{
+		LockoutThreshold: getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 5),
+		LockoutWindow:    time.Duration(getEnvInt("LOGIN_LOCKOUT_WINDOW_MINUTES", 15)) * time.Minute,
+		LockoutDuration:  time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+	}
+	if getEnv("RATE_LIMIT_ENABLED", "true") == "false" {
+		return rl, nil
+	}
+	rules := []struct {
+		dst      *ratelimit.Rule
+		env, def string
+	}{
+		{&rl.Login, "RATE_LIMIT_LOGIN", "20/1m"},
+		{&rl.LoginPerEmail, "RATE_LIMIT_LOGIN_EMAIL", "10/15m"},
+		{&rl.Register, "RATE_LIMIT_REGISTER", "10/1h"},
+		{&rl.WebinarRegister, "RATE_LIMIT_WEBINAR_REGISTER", "30/1h"},
+		{&rl.WebinarRegisterPerWebinar, "RATE_LIMIT_WEBINAR_REGISTER_PER_WEBINAR", "600/1m"},
+		{&rl.Upvote, "RATE_LIMIT_UPVOTE", "60/1m"},
+		{&rl.WebSocket, "RATE_LIMIT_WS", "30/1m"},
+	}
+	for _, r := range rules {
+		rule, err := ratelimit.ParseRule(getEnv(r.env, r.def))
+		if err != nil {
+			return rl, fmt.Errorf("%s: %w", r.env, err)
+		}
+		*r.dst = rule
+	}
+	return rl, nil
+}
+
+// defaultOIDCIssuers are used when OIDC_<ID>_ISSUER is not set for a well-known provider.
+var defaultOIDCIssuers = map[string]string{
+	"google":    "https://accounts.google.com",
+	"microsoft": "https://login.microsoftonline.com/organizations/v2.0",
+}
+
+// loadOIDCProviders reads OIDC_PROVIDERS (comma-separated IDs) and OIDC_<ID>_* settings for each.
+// Providers without a client ID or issuer are skipped.
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, id := range splitTrim(getEnv("OIDC_PROVIDERS", ""), ",") {
+		id = strings.ToLower(id)
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_")) + "_"
+		p := OIDCProviderConfig{
+			ID:             id,
+			Name:           getEnv(prefix+"NAME", strings.ToUpper(id[:1])+id[1:]),
+			Issuer:         strings.TrimSuffix(getEnv(prefix+"ISSUER", defaultOIDCIssuers[id]), "/"),
+			ClientID:       getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret:   getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:    getEnv(prefix+"REDIRECT_URL", getEnv("FRONTEND_URL", "http://localhost:3000")+"/auth/sso/callback"),
+			Scopes:         splitTrim(getEnv(prefix+"SCOPES", "openid,email,profile"), ","),
+			TrustEmail:     getEnv(prefix+"TRUST_EMAIL", "false") == "true",
+			AllowedTenants: splitTrim(getEnv(prefix+"ALLOWED_TENANTS", ""), ","),
+		}
+		if p.ClientID == "" || p.Issuer == "" {
+			continue
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
+
 func getEnvInt(key string, fallback int) int {
 	if v := os.Getenv(key); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
@@ -204,6 +402,20 @@ func getEnvInt(key string, fallback int) int {
 	return fallback
 }
 
+// parseConcurrency parses "type=n,type=n" (e.g. "email=4,recording_upload=2").
+func parseConcurrency(s string) (map[string]int, error) {
+	out := make(map[string]int)
+	for _, pair := range splitTrim(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if !ok || err != nil || n < 1 || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("WORKER_CONCURRENCY %q: want type=n with n >= 1", pair)
+		}
+		out[strings.TrimSpace(k)] = n
+	}
+	return out, nil
+}
+
 func splitTrim(s, sep string) []string {
 	if s == "" {
 		return nil
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/aura-webinar/backend/internal/audit"
 	"github.com/aura-webinar/backend/internal/middleware"
 	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/webinars"
@@ -419,6 +420,10 @@ func (h *AdvertisementHandler) ToggleAdvertisement(c *gin.Context) {
 			"ad_id": adID, "file_url": a.FileURL, "type": a.FileType, "active": active,
 		})
 	}
+	audit.Annotate(c, audit.Change{
+		Action: "ad.toggle", WebinarID: &a.WebinarID,
+		Before: gin.H{"is_active": a.IsActive}, After: gin.H{"is_active": active},
+	})
 	response.OK(c, gin.H{"id": adID, "active": active})
 }
 
@@ -442,11 +447,14 @@ func (h *AdvertisementHandler) DeleteAdvertisement(c *gin.Context) {
 	}
 
 	if a.S3Key != "" && h.s3 != nil {
-		_ = h.s3.DeleteAd(c.Request.Context(), a.S3Key)
+		if shared, err := h.adRepo.S3KeyShared(c.Request.Context(), a.S3Key, a.ID); err == nil && !shared {
+			_ = h.s3.DeleteAd(c.Request.Context(), a.S3Key)
+		}
 	}
 	if err := h.adRepo.DeleteAdvertisement(c.Request.Context(), adID); err != nil {
 		response.Internal(c, "failed to delete ad")
 		return
 	}
+	audit.Annotate(c, audit.Change{Action: "ad.delete", WebinarID: &a.WebinarID, Before: a})
 	response.NoContent(c)
 }
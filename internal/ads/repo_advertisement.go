@@ -100,6 +100,17 @@ func (r *AdvertisementRepository) DeleteAdvertisement(ctx context.Context, id uu
 	return err
 }
 
+// S3KeyShared reports whether an advertisement other than id, a series ad or a template ad uses the S3
+// object (series occurrences share their creatives).
+func (r *AdvertisementRepository) S3KeyShared(ctx context.Context, s3Key string, id uuid.UUID) (bool, error) {
+	const q = `SELECT EXISTS (SELECT 1 FROM advertisements WHERE s3_key = $1 AND id <> $2)
+		OR EXISTS (SELECT 1 FROM webinar_series_ads WHERE s3_key = $1)
+		OR EXISTS (SELECT 1 FROM webinar_template_ads WHERE s3_key = $1)`
+	var shared bool
+	err := r.pool.QueryRow(ctx, q, s3Key, id).Scan(&shared)
+	return shared, err
+}
+
 // GetOrCreatePlaylist returns the playlist for a webinar, creating one if missing.
 func (r *AdvertisementRepository) GetOrCreatePlaylist(ctx context.Context, webinarID uuid.UUID, rotationInterval int) (*models.AdPlaylist, error) {
 	const getQ = `SELECT id, webinar_id, rotation_interval, is_running, created_at, updated_at FROM ad_playlists WHERE webinar_id = $1`
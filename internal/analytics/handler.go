@@ -5,6 +5,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/questions"
 	"github.com/aura-webinar/backend/internal/registrations"
 	"github.com/aura-webinar/backend/internal/sessionlog"
@@ -21,6 +22,7 @@ type Handler struct {
 	streamRepo       *streams.Repository
 	webinarRepo      *webinars.Repository
 	sessionLogRepo   *sessionlog.Repository
+	snapshots        *Repository
 }
 
 // NewHandler creates an analytics handler.
@@ -39,6 +41,7 @@ func NewHandler(
 		streamRepo:       streamRepo,
 		webinarRepo:      webinarRepo,
 		sessionLogRepo:   sessionLogRepo,
+		snapshots:        NewRepository(pool),
 	}
 }
 
@@ -136,3 +139,65 @@ func (h *Handler) GetByWebinar(c *gin.Context) {
 
 	response.OK(c, out)
 }
+
+// SessionAnalytics is one stream session with its engagement snapshot (nil until the analytics job has run).
+type SessionAnalytics struct {
+	Session models.StreamSession      `json:"session"`
+	Metrics *models.EngagementMetrics `json:"metrics"`
+}
+
+// ListSessions handles GET /webinars/:id/analytics/sessions. Returns each stream session (newest first)
+// with its stored engagement snapshot. Access is enforced by route middleware.
+func (h *Handler) ListSessions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid webinar id")
+		return
+	}
+	ctx := c.Request.Context()
+	sessions, err := h.streamRepo.ListByWebinar(ctx, id)
+	if err != nil {
+		response.Internal(c, "failed to load stream sessions")
+		return
+	}
+	snapshots, err := h.snapshots.ListSnapshotsByWebinar(ctx, id)
+	if err != nil {
+		response.Internal(c, "failed to load engagement snapshots")
+		return
+	}
+	bySession := make(map[uuid.UUID]*models.EngagementMetrics, len(snapshots))
+	for _, m := range snapshots {
+		if m.StreamSessionID != nil {
+			bySession[*m.StreamSessionID] = m
+		}
+	}
+	out := make([]SessionAnalytics, 0, len(sessions))
+	for _, s := range sessions {
+		out = append(out, SessionAnalytics{Session: s, Metrics: bySession[s.ID]})
+	}
+	response.OK(c, out)
+}
+
+// GetSession handles GET /webinars/:id/analytics/sessions/:sessionId. Returns the session's engagement snapshot.
+func (h *Handler) GetSession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid webinar id")
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		response.BadRequest(c, "invalid session id")
+		return
+	}
+	m, err := h.snapshots.GetSnapshotBySession(c.Request.Context(), id, sessionID)
+	if err != nil {
+		response.Internal(c, "failed to load engagement snapshot")
+		return
+	}
+	if m == nil {
+		response.NotFound(c, "no snapshot for this session yet")
+		return
+	}
+	response.OK(c, m)
+}
@@ -0,0 +1,120 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository computes and stores engagement_metrics snapshots.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates an analytics repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// SessionTotals are the raw counts behind a stream session snapshot.
+type SessionTotals struct {
+	Registrations     int
+	Attended          int
+	TotalWatchSeconds int64
+	PollParticipants  int
+	Questions         int
+}
+
+// ComputeSessionTotals counts activity during a stream session's window (started_at to ended_at, or now
+// while it is still live): attendees and watch time from session logs, poll answers and questions asked.
+// Registrations are those made before the window closed.
+func (r *Repository) ComputeSessionTotals(ctx context.Context, s *models.StreamSession) (*SessionTotals, error) {
+	end := time.Now()
+	if s.EndedAt != nil {
+		end = *s.EndedAt
+	}
+	const q = `SELECT
+		(SELECT COUNT(*) FROM registrations WHERE webinar_id = $1 AND created_at <= $3),
+		(SELECT COUNT(DISTINCT user_id) FROM user_session_logs WHERE webinar_id = $1 AND joined_at BETWEEN $2 AND $3),
+		(SELECT COALESCE(SUM(watch_seconds), 0) FROM user_session_logs
+			WHERE webinar_id = $1 AND joined_at BETWEEN $2 AND $3 AND left_at IS NOT NULL),
+		(SELECT COUNT(DISTINCT pa.user_id) FROM poll_answers pa INNER JOIN polls p ON p.id = pa.poll_id
+			WHERE p.webinar_id = $1 AND pa.answered_at BETWEEN $2 AND $3),
+		(SELECT COUNT(*) FROM questions WHERE webinar_id = $1 AND created_at BETWEEN $2 AND $3)`
+	var t SessionTotals
+	err := r.pool.QueryRow(ctx, q, s.WebinarID, s.StartedAt, end).Scan(
+		&t.Registrations, &t.Attended, &t.TotalWatchSeconds, &t.PollParticipants, &t.Questions)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SaveSnapshot writes the snapshot for m.StreamSessionID, replacing any earlier one for that session.
+func (r *Repository) SaveSnapshot(ctx context.Context, m *models.EngagementMetrics) error {
+	const q = `INSERT INTO engagement_metrics (webinar_id, stream_session_id, total_registrations, total_attended, total_no_show,
+			peak_live_viewers, avg_watch_seconds, poll_participation_count, poll_participation_percent, questions_count, recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		ON CONFLICT (stream_session_id) WHERE stream_session_id IS NOT NULL DO UPDATE SET
+			total_registrations = EXCLUDED.total_registrations,
+			total_attended = EXCLUDED.total_attended,
+			total_no_show = EXCLUDED.total_no_show,
+			peak_live_viewers = EXCLUDED.peak_live_viewers,
+			avg_watch_seconds = EXCLUDED.avg_watch_seconds,
+			poll_participation_count = EXCLUDED.poll_participation_count,
+			poll_participation_percent = EXCLUDED.poll_participation_percent,
+			questions_count = EXCLUDED.questions_count,
+			recorded_at = NOW()
+		RETURNING id, recorded_at, created_at`
+	return r.pool.QueryRow(ctx, q, m.WebinarID, m.StreamSessionID, m.TotalRegistrations, m.TotalAttended, m.TotalNoShow,
+		m.PeakLiveViewers, m.AvgWatchSeconds, m.PollParticipationCount, m.PollParticipationPercent, m.QuestionsCount,
+	).Scan(&m.ID, &m.RecordedAt, &m.CreatedAt)
+}
+
+const snapshotColumns = `id, webinar_id, stream_session_id, total_registrations, total_attended, total_no_show, peak_live_viewers,
+	avg_watch_seconds, poll_participation_count, poll_participation_percent::float8, questions_count, recorded_at, created_at`
+
+func scanSnapshot(row pgx.Row) (*models.EngagementMetrics, error) {
+	var m models.EngagementMetrics
+	err := row.Scan(&m.ID, &m.WebinarID, &m.StreamSessionID, &m.TotalRegistrations, &m.TotalAttended, &m.TotalNoShow,
+		&m.PeakLiveViewers, &m.AvgWatchSeconds, &m.PollParticipationCount, &m.PollParticipationPercent, &m.QuestionsCount,
+		&m.RecordedAt, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListSnapshotsByWebinar returns a webinar's snapshots, newest session first.
+func (r *Repository) ListSnapshotsByWebinar(ctx context.Context, webinarID uuid.UUID) ([]*models.EngagementMetrics, error) {
+	q := `SELECT ` + snapshotColumns + ` FROM engagement_metrics WHERE webinar_id = $1 ORDER BY recorded_at DESC`
+	rows, err := r.pool.Query(ctx, q, webinarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []*models.EngagementMetrics{}
+	for rows.Next() {
+		m, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// GetSnapshotBySession returns the snapshot of a stream session of the webinar (nil if not computed yet).
+func (r *Repository) GetSnapshotBySession(ctx context.Context, webinarID, sessionID uuid.UUID) (*models.EngagementMetrics, error) {
+	q := `SELECT ` + snapshotColumns + ` FROM engagement_metrics WHERE webinar_id = $1 AND stream_session_id = $2`
+	m, err := scanSnapshot(r.pool.QueryRow(ctx, q, webinarID, sessionID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return m, err
+}
@@ -0,0 +1,257 @@
+// Package audit keeps an append-only log of administrative actions (who changed what, from where).
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// contextChangeKey holds the *Change a handler attached to the request.
+const contextChangeKey = "audit_change"
+
+// Change describes what a handler did, for the audit entry written after it returns.
+// Any field left empty is filled from the route (action "METHOD /path", target from :id).
+type Change struct {
+	Action         string
+	TargetType     string
+	TargetID       string
+	OrganizationID *uuid.UUID
+	// WebinarID lets the recorder resolve the organization when the handler does not know it.
+	WebinarID *uuid.UUID
+	// Before and After are snapshots of the target (any JSON-marshalable value); nil for create/delete.
+	Before interface{}
+	After  interface{}
+}
+
+// Annotate attaches change details to the current request. Later calls override non-empty fields.
+func Annotate(c *gin.Context, ch Change) {
+	cur, _ := c.Get(contextChangeKey)
+	prev, _ := cur.(*Change)
+	if prev == nil {
+		c.Set(contextChangeKey, &ch)
+		return
+	}
+	if ch.Action != "" {
+		prev.Action = ch.Action
+	}
+	if ch.TargetType != "" {
+		prev.TargetType = ch.TargetType
+	}
+	if ch.TargetID != "" {
+		prev.TargetID = ch.TargetID
+	}
+	if ch.OrganizationID != nil {
+		prev.OrganizationID = ch.OrganizationID
+	}
+	if ch.WebinarID != nil {
+		prev.WebinarID = ch.WebinarID
+	}
+	if ch.Before != nil {
+		prev.Before = ch.Before
+	}
+	if ch.After != nil {
+		prev.After = ch.After
+	}
+}
+
+// WebinarOrgResolver returns the organization of a webinar (nil if it has none).
+type WebinarOrgResolver func(ctx context.Context, webinarID uuid.UUID) (*uuid.UUID, error)
+
+// Recorder writes an audit entry for every successful mutating request it wraps.
+type Recorder struct {
+	repo       *Repository
+	resolveOrg WebinarOrgResolver
+	skip       map[string]bool
+	logger     *zap.Logger
+}
+
+// NewRecorder creates a recorder. skipRoutes ("METHOD /route/:pattern") are not audited (e.g. audience interactions).
+func NewRecorder(repo *Repository, resolveOrg WebinarOrgResolver, skipRoutes []string, logger *zap.Logger) *Recorder {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	skip := make(map[string]bool, len(skipRoutes))
+	for _, r := range skipRoutes {
+		skip[r] = true
+	}
+	return &Recorder{repo: repo, resolveOrg: resolveOrg, skip: skip, logger: logger}
+}
+
+// Middleware records POST/PUT/PATCH/DELETE requests that succeed (2xx). Call after JWT.
+func (rec *Recorder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return
+		}
+		route := c.Request.Method + " " + c.FullPath()
+		if c.FullPath() == "" || rec.skip[route] {
+			return
+		}
+		if status := c.Writer.Status(); status < 200 || status >= 300 {
+			return
+		}
+
+		var ch Change
+		if v, ok := c.Get(contextChangeKey); ok {
+			if p, ok := v.(*Change); ok {
+				ch = *p
+			}
+		}
+		e := &models.AuditLog{
+			Action:     ch.Action,
+			TargetType: ch.TargetType,
+			TargetID:   ch.TargetID,
+			IPAddress:  c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+		}
+		if e.Action == "" {
+			e.Action = route
+		}
+		if e.TargetType == "" {
+			e.TargetType = routeTargetType(c.FullPath())
+		}
+		if e.TargetID == "" {
+			e.TargetID = c.Param("id")
+		}
+		if v, ok := c.Get(middleware.ContextAPIKeyID); ok {
+			if id, ok := v.(uuid.UUID); ok {
+				e.ActorAPIKeyID = &id
+			}
+		}
+		if v, ok := c.Get(middleware.ContextUserID); ok {
+			if id, ok := v.(uuid.UUID); ok {
+				e.ActorUserID = &id
+			}
+		}
+		if diff, err := Diff(ch.Before, ch.After); err == nil {
+			e.Diff = diff
+		}
+
+		// The response is already written; don't tie the insert to the client connection.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		e.OrganizationID = rec.organizationID(ctx, c, &ch)
+		if err := rec.repo.Insert(ctx, e); err != nil {
+			rec.logger.Error("write audit log failed", zap.String("action", e.Action), zap.Error(err))
+		}
+	}
+}
+
+// organizationID picks the organization for the entry: handler annotation, request context
+// (webinar org access or API key), then the webinar being acted on.
+func (rec *Recorder) organizationID(ctx context.Context, c *gin.Context, ch *Change) *uuid.UUID {
+	if ch.OrganizationID != nil {
+		return ch.OrganizationID
+	}
+	if v, ok := c.Get(middleware.ContextOrganizationID); ok {
+		if id, ok := v.(uuid.UUID); ok {
+			return &id
+		}
+	}
+	if id, ok := middleware.APIKeyOrganizationID(c); ok {
+		return &id
+	}
+	if strings.HasPrefix(c.FullPath(), "/organizations/:id") {
+		if id, err := uuid.Parse(c.Param("id")); err == nil {
+			return &id
+		}
+	}
+	webinarID := ch.WebinarID
+	if webinarID == nil && strings.HasPrefix(c.FullPath(), "/webinars/:id") {
+		if id, err := uuid.Parse(c.Param("id")); err == nil {
+			webinarID = &id
+		}
+	}
+	if webinarID == nil || rec.resolveOrg == nil {
+		return nil
+	}
+	orgID, err := rec.resolveOrg(ctx, *webinarID)
+	if err != nil {
+		rec.logger.Warn("resolve audit organization failed", zap.Error(err))
+		return nil
+	}
+	return orgID
+}
+
+// routeTargetType derives the target type from the route: the resource owning :id, else the first segment
+// ("/webinars/:id/ads" -> "webinar", "/ads/:id/toggle" -> "ad", "/webinars" -> "webinar").
+func routeTargetType(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] == ":id" {
+			return strings.TrimSuffix(parts[i-1], "s")
+		}
+	}
+	return strings.TrimSuffix(parts[0], "s")
+}
+
+// Diff returns the top-level fields that differ between before and after as
+// {"field": {"before": ..., "after": ...}}. A nil side records every field of the other (create/delete).
+func Diff(before, after interface{}) (json.RawMessage, error) {
+	if before == nil && after == nil {
+		return nil, nil
+	}
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+	// A side is omitted when the field does not exist there (created or removed), not when it is zero.
+	out := make(map[string]map[string]interface{})
+	for k, bv := range b {
+		av, ok := a[k]
+		if !ok {
+			out[k] = map[string]interface{}{"before": bv}
+		} else if !reflect.DeepEqual(av, bv) {
+			out[k] = map[string]interface{}{"before": bv, "after": av}
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok {
+			out[k] = map[string]interface{}{"after": av}
+		}
+	}
+	delete(out, "updated_at")
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(out)
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return m, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		// Not an object: record it under "value"
+		var scalar interface{}
+		if err := json.Unmarshal(raw, &scalar); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": scalar}, nil
+	}
+	return m, nil
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// OrgRoleLookup returns a user's role in an organization (organizations.Repository).
+type OrgRoleLookup interface {
+	GetUserRole(ctx context.Context, orgID, userID uuid.UUID) (string, error)
+}
+
+// Handler serves the audit log.
+type Handler struct {
+	repo    *Repository
+	orgRepo OrgRoleLookup
+}
+
+// NewHandler creates an audit log handler.
+func NewHandler(repo *Repository, orgRepo OrgRoleLookup) *Handler {
+	return &Handler{repo: repo, orgRepo: orgRepo}
+}
+
+// List handles GET /organizations/:id/audit-log (owner or event manager).
+// Query: actor_id, action, target_type, target_id, from, to (RFC3339), limit (max 200), cursor (from next_cursor).
+func (h *Handler) List(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+
+	f := Filter{
+		Action:     strings.TrimSpace(c.Query("action")),
+		TargetType: strings.TrimSpace(c.Query("target_type")),
+		TargetID:   strings.TrimSpace(c.Query("target_id")),
+		Limit:      defaultPageSize,
+	}
+	if v := c.Query("actor_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			response.BadRequest(c, "invalid actor_id")
+			return
+		}
+		f.ActorUserID = &id
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		if v := c.Query(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				response.BadRequest(c, "invalid "+p.name+" (want RFC3339)")
+				return
+			}
+			*p.dst = &t
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			response.BadRequest(c, "limit must be 1–200")
+			return
+		}
+		f.Limit = n
+	}
+	if v := c.Query("cursor"); v != "" {
+		cur, err := decodeCursor(v)
+		if err != nil {
+			response.BadRequest(c, "invalid cursor")
+			return
+		}
+		f.Cursor = cur
+	}
+
+	list, err := h.repo.ListByOrganization(c.Request.Context(), orgID, f)
+	if err != nil {
+		response.Internal(c, "failed to load audit log")
+		return
+	}
+	if list == nil {
+		list = []models.AuditLog{}
+	}
+	var next string
+	if len(list) == f.Limit {
+		last := list[len(list)-1]
+		next = encodeCursor(Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	response.OK(c, gin.H{"items": list, "next_cursor": next})
+}
+
+func encodeCursor(cur Cursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cur.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cur.ID.String()))
+}
+
+func decodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	ts, id, _ := strings.Cut(string(raw), "|")
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, err
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{CreatedAt: t, ID: uid}, nil
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Filter narrows an audit log listing. Zero values are ignored.
+type Filter struct {
+	ActorUserID *uuid.UUID
+	Action      string
+	TargetType  string
+	TargetID    string
+	From        *time.Time
+	To          *time.Time
+	// Cursor continues after the last entry of the previous page (newest first).
+	Cursor *Cursor
+	Limit  int
+}
+
+// Cursor is the keyset position of an entry (created_at, id).
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Repository handles audit_logs persistence. Entries are never updated.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates an audit log repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Insert appends an entry.
+func (r *Repository) Insert(ctx context.Context, e *models.AuditLog) error {
+	const q = `INSERT INTO audit_logs (id, organization_id, actor_user_id, actor_api_key_id, action, target_type, target_id, diff, ip_address, user_agent)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at`
+	var diff interface{}
+	if len(e.Diff) > 0 {
+		diff = e.Diff
+	}
+	return r.pool.QueryRow(ctx, q, e.OrganizationID, e.ActorUserID, e.ActorAPIKeyID, e.Action, e.TargetType, e.TargetID, diff, e.IPAddress, e.UserAgent).
+		Scan(&e.ID, &e.CreatedAt)
+}
+
+// ListByOrganization returns an organization's entries, newest first.
+func (r *Repository) ListByOrganization(ctx context.Context, orgID uuid.UUID, f Filter) ([]models.AuditLog, error) {
+	conds := []string{"organization_id = $1"}
+	args := []interface{}{orgID}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ActorUserID != nil {
+		add("actor_user_id = $%d", *f.ActorUserID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.TargetType != "" {
+		add("target_type = $%d", f.TargetType)
+	}
+	if f.TargetID != "" {
+		add("target_id = $%d", f.TargetID)
+	}
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", *f.To)
+	}
+	if f.Cursor != nil {
+		args = append(args, f.Cursor.CreatedAt, f.Cursor.ID)
+		conds = append(conds, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, f.Limit)
+	q := `SELECT id, organization_id, actor_user_id, actor_api_key_id, action, target_type, target_id, diff, ip_address, user_agent, created_at
+		FROM audit_logs WHERE ` + strings.Join(conds, " AND ") + fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args))
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.AuditLog
+	for rows.Next() {
+		var e models.AuditLog
+		if err := rows.Scan(&e.ID, &e.OrganizationID, &e.ActorUserID, &e.ActorAPIKeyID, &e.Action, &e.TargetType, &e.TargetID, &e.Diff, &e.IPAddress, &e.UserAgent, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// DeleteBefore removes entries older than cutoff (retention). Returns the number of rows removed.
+func (r *Repository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM audit_logs WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
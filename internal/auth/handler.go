@@ -3,7 +3,9 @@ package auth
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/email"
 	"github.com/aura-webinar/backend/pkg/queue"
 	"github.com/aura-webinar/backend/pkg/response"
 	"github.com/aura-webinar/backend/pkg/utils"
@@ -47,6 +50,7 @@ type Handler struct {
 	jwt          *JWTService
 	jobQueue     *queue.Queue
 	frontendURL  string
+	lockout      *Lockout
 	logger       *zap.Logger
 }
 
@@ -61,6 +65,11 @@ func (h *Handler) SetEmailQueue(q *queue.Queue, frontendURL string) {
 	h.frontendURL = frontendURL
 }
 
+// SetLockout enables account lockout after repeated failed logins.
+func (h *Handler) SetLockout(l *Lockout) {
+	h.lockout = l
+}
+
 // Register handles POST /auth/register.
 func (h *Handler) Register(c *gin.Context) {
 	var req RegisterRequest
@@ -143,7 +152,7 @@ func (h *Handler) Register(c *gin.Context) {
 			RecipientEmail: req.Email,
 			RecipientName:  req.FullName,
 			VerifyURL:      verifyURL,
-			Subject:        "Verify your email address",
+			Locale:         email.LocaleFromAcceptLanguage(c.GetHeader("Accept-Language")),
 		}
 		if err := h.jobQueue.EnqueueEmail(c.Request.Context(), payload); err != nil {
 			h.logger.Warn("enqueue verification email failed", zap.Error(err))
@@ -164,16 +173,29 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.repo.GetByEmail(c.Request.Context(), req.Email)
-	if err != nil {
-		response.Unauthorized(c, "invalid email or password")
-		return
+	if h.lockout != nil {
+		lockedFor, err := h.lockout.LockedFor(c.Request.Context(), req.Email)
+		if err != nil {
+			h.logger.Warn("login lockout check failed", zap.Error(err))
+		}
+		if lockedFor > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedFor.Seconds()))))
+			response.TooManyRequests(c, "too many failed login attempts, try again later")
+			return
+		}
 	}
 
-	if !utils.CheckPassword(req.Password, user.Password) {
+	user, err := h.repo.GetByEmail(c.Request.Context(), req.Email)
+	if err != nil || !utils.CheckPassword(req.Password, user.Password) {
+		h.recordLoginFailure(c, req.Email)
 		response.Unauthorized(c, "invalid email or password")
 		return
 	}
+	if h.lockout != nil {
+		if err := h.lockout.Reset(c.Request.Context(), req.Email); err != nil {
+			h.logger.Warn("reset login lockout failed", zap.Error(err))
+		}
+	}
 	if !user.EmailVerified {
 		response.Unauthorized(c, "please verify your email before logging in")
 		return
@@ -217,6 +239,21 @@ func (h *Handler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Body{Success: true, Data: list})
 }
 
+// recordLoginFailure counts a failed login toward the account lockout (unknown emails count too, so lockout does not reveal which accounts exist).
+func (h *Handler) recordLoginFailure(c *gin.Context, email string) {
+	if h.lockout == nil {
+		return
+	}
+	lockedFor, err := h.lockout.RecordFailure(c.Request.Context(), email)
+	if err != nil {
+		h.logger.Warn("record login failure failed", zap.Error(err))
+		return
+	}
+	if lockedFor > 0 {
+		h.logger.Warn("account locked after failed logins", zap.String("email", email), zap.Duration("duration", lockedFor))
+	}
+}
+
 func generateVerificationToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -6,17 +6,21 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/models"
 )
 
 var (
 	ErrInvalidToken = errors.New("invalid token")
 )
 
-// Claims holds JWT claims including user ID and role.
+// Claims holds JWT claims including user ID and role. RegistrationID is set on guest sessions exchanged
+// from a join link; they carry no email.
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Role   string    `json:"role"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	RegistrationID *uuid.UUID `json:"registration_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -36,15 +40,19 @@ func NewJWTService(secret string, expireHours int) *JWTService {
 
 // Generate creates a new JWT for the user.
 func (s *JWTService) Generate(userID uuid.UUID, email, role string) (string, error) {
-	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(s.expireHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ID:        uuid.New().String(),
-		},
+	return s.sign(Claims{UserID: userID, Email: email, Role: role})
+}
+
+// GenerateGuest creates an audience-only JWT for a registration's guest user (see registrations.ExchangeToken).
+func (s *JWTService) GenerateGuest(userID, registrationID uuid.UUID) (string, error) {
+	return s.sign(Claims{UserID: userID, Role: string(models.RoleAudience), RegistrationID: &registrationID})
+}
+
+func (s *JWTService) sign(claims Claims) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(s.expireHours) * time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        uuid.New().String(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(s.secret)
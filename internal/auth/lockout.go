@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	lockoutFailKeyPrefix = "auth:login_failures:"
+	lockoutLockKeyPrefix = "auth:lockout:"
+)
+
+// Lockout locks an account (by email) after repeated failed logins, independent of the client IP.
+type Lockout struct {
+	client    *redis.Client
+	threshold int
+	window    time.Duration
+	duration  time.Duration
+}
+
+// NewLockout creates a lockout tracker: threshold failures within window lock the account for duration.
+func NewLockout(client *redis.Client, threshold int, window, duration time.Duration) *Lockout {
+	return &Lockout{client: client, threshold: threshold, window: window, duration: duration}
+}
+
+// LockedFor returns how long the account stays locked (0 if not locked).
+func (l *Lockout) LockedFor(ctx context.Context, email string) (time.Duration, error) {
+	ttl, err := l.client.PTTL(ctx, lockoutLockKeyPrefix+normalizeEmail(email)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// RecordFailure counts a failed login and locks the account once the threshold is reached.
+// Returns the lock duration if this failure triggered a lock.
+func (l *Lockout) RecordFailure(ctx context.Context, email string) (time.Duration, error) {
+	if l.threshold <= 0 {
+		return 0, nil
+	}
+	email = normalizeEmail(email)
+	failKey := lockoutFailKeyPrefix + email
+	n, err := l.client.Incr(ctx, failKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		if err := l.client.Expire(ctx, failKey, l.window).Err(); err != nil {
+			return 0, err
+		}
+	}
+	if n < int64(l.threshold) {
+		return 0, nil
+	}
+	pipe := l.client.TxPipeline()
+	pipe.Set(ctx, lockoutLockKeyPrefix+email, "1", l.duration)
+	pipe.Del(ctx, failKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return l.duration, nil
+}
+
+// Reset clears failure count and lock (after a successful login or an admin unlock).
+func (l *Lockout) Reset(ctx context.Context, email string) error {
+	email = normalizeEmail(email)
+	return l.client.Del(ctx, lockoutFailKeyPrefix+email, lockoutLockKeyPrefix+email).Err()
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
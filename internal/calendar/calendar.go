@@ -0,0 +1,94 @@
+// Package calendar puts webinars into attendees' calendars: iCalendar invites attached to registration,
+// reschedule and cancellation emails, and subscribable feeds per attendee and per organization.
+package calendar
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/ical"
+)
+
+const (
+	// ProdID identifies this product in the calendar data it writes.
+	ProdID = "-//Aura Webinar//Webinars//EN"
+	// InviteFilename names the .ics attachment of webinar emails.
+	InviteFilename = "invite.ics"
+	// feedWindow keeps webinars that ended or were cancelled this recently in feeds, so subscribers see
+	// the change before they drop out.
+	feedWindow = 30 * 24 * time.Hour
+	// feedRefresh is how often subscribers are asked to re-fetch a feed.
+	feedRefresh = time.Hour
+)
+
+// Builder turns webinars into iCalendar events. The organizer is the email sender; UIDs are in its domain.
+type Builder struct {
+	organizer ical.Person
+	domain    string
+}
+
+// NewBuilder creates an event builder for the email sender fromName <fromAddress>.
+func NewBuilder(fromName, fromAddress string) *Builder {
+	domain := "localhost"
+	if i := strings.LastIndex(fromAddress, "@"); i >= 0 && i < len(fromAddress)-1 {
+		domain = fromAddress[i+1:]
+	}
+	return &Builder{organizer: ical.Person{Name: fromName, Email: fromAddress}, domain: domain}
+}
+
+// UID returns the event UID of a registrant's copy of a webinar, or of the webinar itself (in organization
+// feeds) when registrationID is uuid.Nil. Invites, their updates and the attendee feed share it.
+func (b *Builder) UID(webinarID, registrationID uuid.UUID) string {
+	if registrationID == uuid.Nil {
+		return "webinar-" + webinarID.String() + "@" + b.domain
+	}
+	return "webinar-" + webinarID.String() + "-" + registrationID.String() + "@" + b.domain
+}
+
+// Event returns w as an event; joinURL (a personal join link, or the public page) is its URL and location.
+func (b *Builder) Event(w *models.Webinar, registrationID uuid.UUID, joinURL string) ical.Event {
+	e := ical.Event{
+		UID:      b.UID(w.ID, registrationID),
+		Sequence: w.CalendarSequence,
+		Start:    w.StartsAt,
+		Summary:  w.Title,
+		Location: joinURL,
+		URL:      joinURL,
+		Status:   ical.StatusConfirmed,
+		Modified: w.UpdatedAt,
+	}
+	if w.EndsAt != nil {
+		e.End = *w.EndsAt
+	}
+	e.Description = w.Description
+	if joinURL != "" {
+		if e.Description != "" {
+			e.Description += "\n\n"
+		}
+		e.Description += "Join: " + joinURL
+	}
+	if w.Status == models.WebinarStatusCancelled {
+		e.Status = ical.StatusCancelled
+	}
+	return e
+}
+
+// Invite returns the calendar attached to an email to attendee: method ical.MethodRequest for a new or
+// updated invitation, ical.MethodCancel when the webinar was called off. A cancelled webinar always gets
+// a cancellation, whatever method was asked for.
+func (b *Builder) Invite(w *models.Webinar, registrationID uuid.UUID, joinURL string, attendee ical.Person, method string) *ical.Calendar {
+	e := b.Event(w, registrationID, joinURL)
+	if w.Status == models.WebinarStatusCancelled {
+		method = ical.MethodCancel
+	}
+	if method == ical.MethodCancel {
+		e.Status = ical.StatusCancelled
+	}
+	organizer := b.organizer
+	e.Organizer = &organizer
+	e.Attendee = &attendee
+	return &ical.Calendar{ProdID: ProdID, Method: method, Events: []ical.Event{e}}
+}
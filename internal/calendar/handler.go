@@ -0,0 +1,195 @@
+package calendar
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/organizations"
+	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/ical"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// Handler serves calendar feeds and the links to subscribe to them.
+type Handler struct {
+	repo         *Repository
+	orgRepo      *organizations.Repository
+	regRepo      *registrations.Repository
+	builder      *Builder
+	publicAPIURL string
+	frontendURL  string
+	logger       *zap.Logger
+}
+
+// NewHandler creates a calendar handler. Feed links point at publicAPIURL; event links at frontendURL.
+func NewHandler(repo *Repository, orgRepo *organizations.Repository, regRepo *registrations.Repository, builder *Builder, publicAPIURL, frontendURL string, logger *zap.Logger) *Handler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Handler{repo: repo, orgRepo: orgRepo, regRepo: regRepo, builder: builder,
+		publicAPIURL: strings.TrimSuffix(publicAPIURL, "/"), frontendURL: frontendURL, logger: logger}
+}
+
+// AttendeeFeed handles GET /calendar/attendees/:token (no auth; the token is from a feed link): every webinar
+// the attendee's email address is registered for, each with their personal join link. A reset feed link
+// is not found.
+func (h *Handler) AttendeeFeed(c *gin.Context) {
+	addr, err := h.repo.EmailByFeedToken(c.Request.Context(), strings.TrimSuffix(c.Param("token"), ".ics"))
+	if err != nil {
+		h.logger.Error("load calendar feed token failed", zap.Error(err))
+		response.Internal(c, "failed to load calendar")
+		return
+	}
+	if addr == "" {
+		response.NotFound(c, "calendar feed not found")
+		return
+	}
+	list, err := h.repo.ListByEmail(c.Request.Context(), addr, time.Now().Add(-feedWindow))
+	if err != nil {
+		h.logger.Error("list attendee calendar failed", zap.Error(err))
+		response.Internal(c, "failed to load calendar")
+		return
+	}
+	h.writeAttendee(c, list)
+}
+
+// RegistrationFeed handles GET /calendar/registrations/:token (no auth; the token is a join token): the one
+// webinar of that registration. A join token proves nothing about the registered address, so it never
+// opens the address's other registrations.
+func (h *Handler) RegistrationFeed(c *gin.Context) {
+	reg, ok := h.registration(c, strings.TrimSuffix(c.Param("token"), ".ics"))
+	if !ok {
+		return
+	}
+	list, err := h.repo.ListByRegistration(c.Request.Context(), reg.ID, time.Now().Add(-feedWindow))
+	if err != nil {
+		h.logger.Error("list registration calendar failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		response.Internal(c, "failed to load calendar")
+		return
+	}
+	h.writeAttendee(c, list)
+}
+
+func (h *Handler) writeAttendee(c *gin.Context, list []AttendeeEvent) {
+	cal := &ical.Calendar{ProdID: ProdID, Method: ical.MethodPublish, Name: "My webinars", Refresh: feedRefresh}
+	for i := range list {
+		e := &list[i]
+		joinURL := h.publicPage(e.Webinar.ID.String())
+		if e.JoinToken != "" {
+			joinURL = email.BuildJoinURL(h.frontendURL, e.Webinar.ID.String(), e.JoinToken)
+		}
+		cal.Events = append(cal.Events, h.builder.Event(&e.Webinar, e.RegistrationID, joinURL))
+	}
+	h.write(c, cal)
+}
+
+// OrganizationFeed handles GET /calendar/organizations/:slug (no auth): the organization's public webinars.
+func (h *Handler) OrganizationFeed(c *gin.Context) {
+	org, err := h.orgRepo.GetBySlug(c.Request.Context(), strings.TrimSuffix(c.Param("slug"), ".ics"))
+	if err != nil || org == nil {
+		response.NotFound(c, "organization not found")
+		return
+	}
+	list, err := h.repo.ListByOrganization(c.Request.Context(), org.ID, time.Now().Add(-feedWindow))
+	if err != nil {
+		h.logger.Error("list organization calendar failed", zap.Error(err), zap.String("organization_id", org.ID.String()))
+		response.Internal(c, "failed to load calendar")
+		return
+	}
+	cal := &ical.Calendar{ProdID: ProdID, Method: ical.MethodPublish, Name: org.Name, Refresh: feedRefresh}
+	for i := range list {
+		w := &list[i]
+		cal.Events = append(cal.Events, h.builder.Event(w, uuid.Nil, h.publicPage(w.ID.String())))
+	}
+	h.write(c, cal)
+}
+
+// FeedURL handles GET /calendar/feed: the signed-in user's feed link (webinars registered with their
+// account's email address).
+func (h *Handler) FeedURL(c *gin.Context) {
+	if addr, ok := accountEmail(c); ok {
+		h.feedLinks(c, addr, false)
+	}
+}
+
+// ResetFeed handles POST /calendar/feed/reset: replaces the signed-in user's feed link; calendars subscribed
+// to the old one stop updating.
+func (h *Handler) ResetFeed(c *gin.Context) {
+	if addr, ok := accountEmail(c); ok {
+		h.feedLinks(c, addr, true)
+	}
+}
+
+// RegistrationFeedURL handles GET /registrations/:token/calendar (no auth): the feed link of the join
+// token's registration, for attendees without an account. It stops working when the join link is revoked.
+func (h *Handler) RegistrationFeedURL(c *gin.Context) {
+	if _, ok := h.registration(c, c.Param("token")); ok {
+		h.respondLinks(c, "/calendar/registrations/"+c.Param("token")+".ics")
+	}
+}
+
+// accountEmail returns the signed-in user's email address; API keys have none.
+func accountEmail(c *gin.Context) (string, bool) {
+	addr, _ := c.Get(middleware.ContextUserEmail)
+	s, _ := addr.(string)
+	if s == "" {
+		response.BadRequest(c, "calendar feeds belong to user accounts")
+		return "", false
+	}
+	return s, true
+}
+
+// registration returns the registration a valid join token belongs to. Writes the error response on failure.
+func (h *Handler) registration(c *gin.Context, token string) (*models.Registration, bool) {
+	tok, err := h.regRepo.GetTokenByToken(c.Request.Context(), token)
+	if err != nil || tok == nil || time.Now().After(tok.ExpiresAt) {
+		response.Unauthorized(c, "invalid or expired token")
+		return nil, false
+	}
+	reg, err := h.regRepo.GetRegistrationByID(c.Request.Context(), tok.RegistrationID)
+	if err != nil || reg == nil {
+		response.NotFound(c, "registration not found")
+		return nil, false
+	}
+	return reg, true
+}
+
+// feedLinks responds with the https and webcal links of an attendee's feed, under a new token if reset.
+func (h *Handler) feedLinks(c *gin.Context, addr string, reset bool) {
+	get := h.repo.FeedToken
+	if reset {
+		get = h.repo.ResetFeedToken
+	}
+	token, err := get(c.Request.Context(), addr)
+	if err != nil {
+		h.logger.Error("calendar feed token failed", zap.Error(err))
+		response.Internal(c, "failed to create calendar feed link")
+		return
+	}
+	h.respondLinks(c, "/calendar/attendees/"+token+".ics")
+}
+
+// respondLinks responds with the https and webcal links of the feed at path.
+func (h *Handler) respondLinks(c *gin.Context, path string) {
+	u := h.publicAPIURL + path
+	webcal := "webcal://" + strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+	response.OK(c, gin.H{"url": u, "webcal_url": webcal})
+}
+
+// publicPage is a webinar's page for people without a join link.
+func (h *Handler) publicPage(webinarID string) string {
+	return strings.TrimSuffix(h.frontendURL, "/") + "/audience?webinar_id=" + webinarID
+}
+
+func (h *Handler) write(c *gin.Context, cal *ical.Calendar) {
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, ical.ContentType(""), cal.Bytes())
+}
@@ -0,0 +1,67 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/ical"
+	"github.com/aura-webinar/backend/pkg/queue"
+)
+
+// Notifier implements webinars.ScheduleNotifier: registrants of a rescheduled webinar get an email with
+// an updated invite.
+type Notifier struct {
+	regRepo     *registrations.Repository
+	jobQueue    *queue.Queue
+	frontendURL string
+}
+
+// NewNotifier creates a reschedule notifier; join links in its emails point at frontendURL.
+func NewNotifier(regRepo *registrations.Repository, q *queue.Queue, frontendURL string) *Notifier {
+	return &Notifier{regRepo: regRepo, jobQueue: q, frontendURL: frontendURL}
+}
+
+// Rescheduled emails every registrant of w the new time with an updated invite (same UID, higher
+// SEQUENCE). Each registrant is emailed once per start time. Returns how many emails were queued.
+func (n *Notifier) Rescheduled(ctx context.Context, w *models.Webinar) (int, error) {
+	regs, err := n.regRepo.ListByWebinar(ctx, w.ID)
+	if err != nil {
+		return 0, fmt.Errorf("list registrations: %w", err)
+	}
+	notified := 0
+	for _, reg := range regs {
+		tok, err := n.regRepo.GetLatestTokenForRegistration(ctx, reg.ID)
+		if err != nil || tok == nil {
+			if tok, err = n.regRepo.IssueToken(ctx, reg.ID); err != nil {
+				return notified, fmt.Errorf("issue join link: %w", err)
+			}
+		}
+		payload := queue.EmailPayload{
+			EmailType:       models.EmailTypeWebinarRescheduled,
+			WebinarID:       w.ID,
+			RegistrationID:  reg.ID,
+			RecipientEmail:  reg.Email,
+			RecipientName:   reg.FullName,
+			WebinarTitle:    w.Title,
+			WebinarStartsAt: w.StartsAt.Format(time.RFC3339),
+			JoinURL:         email.BuildJoinURL(n.frontendURL, w.ID.String(), tok.Token),
+			Locale:          reg.Locale,
+			CalendarMethod:  ical.MethodRequest,
+		}
+		_, err = n.jobQueue.Enqueue(ctx, queue.JobTypeEmail, payload, queue.Options{
+			DedupKey: "rescheduled:" + reg.ID.String() + ":" + strconv.FormatInt(w.StartsAt.Unix(), 10),
+			DedupTTL: 24 * time.Hour,
+		})
+		if err != nil && !errors.Is(err, queue.ErrDuplicate) {
+			return notified, fmt.Errorf("enqueue reschedule email: %w", err)
+		}
+		notified++
+	}
+	return notified, nil
+}
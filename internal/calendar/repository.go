@@ -0,0 +1,137 @@
+package calendar
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// feedStatuses are the webinar statuses feeds list; cancelled ones are kept so subscribers drop them.
+var feedStatuses = []string{
+	models.WebinarStatusScheduled,
+	models.WebinarStatusLive,
+	models.WebinarStatusEnded,
+	models.WebinarStatusArchived,
+	models.WebinarStatusCancelled,
+}
+
+// AttendeeEvent is one registered webinar in an attendee's feed.
+type AttendeeEvent struct {
+	Webinar        models.Webinar
+	RegistrationID uuid.UUID
+	JoinToken      string // the latest valid join token; empty when all have expired or were revoked
+}
+
+// Repository reads the webinars calendar feeds list and stores attendee feed tokens.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a calendar feed repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// ListByEmail returns the webinars the address is registered for that start after since, soonest first.
+func (r *Repository) ListByEmail(ctx context.Context, email string, since time.Time) ([]AttendeeEvent, error) {
+	return r.listAttendee(ctx, `LOWER(r.email) = LOWER($1)`, email, since)
+}
+
+// ListByRegistration returns the registration's webinar if it starts after since.
+func (r *Repository) ListByRegistration(ctx context.Context, registrationID uuid.UUID, since time.Time) ([]AttendeeEvent, error) {
+	return r.listAttendee(ctx, `r.id = $1`, registrationID, since)
+}
+
+// listAttendee lists the registrations matching where ($1 is arg) with their webinars and latest join tokens.
+func (r *Repository) listAttendee(ctx context.Context, where string, arg interface{}, since time.Time) ([]AttendeeEvent, error) {
+	q := `SELECT w.id, w.title, w.description, w.starts_at, w.ends_at, w.status, w.calendar_sequence, w.updated_at, r.id, COALESCE(t.token, '')
+		FROM registrations r
+		JOIN webinars w ON w.id = r.webinar_id
+		LEFT JOIN LATERAL (
+			SELECT token FROM registration_tokens
+			WHERE registration_id = r.id AND expires_at > NOW() AND revoked_at IS NULL
+			ORDER BY created_at DESC LIMIT 1
+		) t ON TRUE
+		WHERE ` + where + ` AND w.status = ANY($2) AND w.starts_at >= $3
+		ORDER BY w.starts_at`
+	rows, err := r.pool.Query(ctx, q, arg, feedStatuses, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []AttendeeEvent
+	for rows.Next() {
+		var e AttendeeEvent
+		w := &e.Webinar
+		if err := rows.Scan(&w.ID, &w.Title, &w.Description, &w.StartsAt, &w.EndsAt, &w.Status, &w.CalendarSequence, &w.UpdatedAt, &e.RegistrationID, &e.JoinToken); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// ListByOrganization returns an organization's public webinars that start after since, soonest first.
+func (r *Repository) ListByOrganization(ctx context.Context, orgID uuid.UUID, since time.Time) ([]models.Webinar, error) {
+	const q = `SELECT id, title, description, starts_at, ends_at, status, calendar_sequence, updated_at
+		FROM webinars
+		WHERE organization_id = $1 AND visibility = 'public' AND status = ANY($2) AND starts_at >= $3
+		ORDER BY starts_at`
+	rows, err := r.pool.Query(ctx, q, orgID, feedStatuses, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Webinar
+	for rows.Next() {
+		var w models.Webinar
+		if err := rows.Scan(&w.ID, &w.Title, &w.Description, &w.StartsAt, &w.EndsAt, &w.Status, &w.CalendarSequence, &w.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+	return list, rows.Err()
+}
+
+// FeedToken returns the address's feed token, creating one on first use.
+func (r *Repository) FeedToken(ctx context.Context, email string) (string, error) {
+	token, err := newFeedToken()
+	if err != nil {
+		return "", err
+	}
+	const q = `INSERT INTO calendar_feed_tokens (email, token) VALUES ($1, $2)
+		ON CONFLICT (email) DO UPDATE SET email = EXCLUDED.email
+		RETURNING token`
+	err = r.pool.QueryRow(ctx, q, strings.ToLower(strings.TrimSpace(email)), token).Scan(&token)
+	return token, err
+}
+
+// ResetFeedToken replaces the address's feed token; the previous feed link stops working.
+func (r *Repository) ResetFeedToken(ctx context.Context, email string) (string, error) {
+	token, err := newFeedToken()
+	if err != nil {
+		return "", err
+	}
+	const q = `INSERT INTO calendar_feed_tokens (email, token) VALUES ($1, $2)
+		ON CONFLICT (email) DO UPDATE SET token = EXCLUDED.token, created_at = NOW()
+		RETURNING token`
+	err = r.pool.QueryRow(ctx, q, strings.ToLower(strings.TrimSpace(email)), token).Scan(&token)
+	return token, err
+}
+
+// EmailByFeedToken returns the address a feed token belongs to, or "" if the token is unknown or was reset.
+func (r *Repository) EmailByFeedToken(ctx context.Context, token string) (string, error) {
+	const q = `SELECT email FROM calendar_feed_tokens WHERE token = $1`
+	var email string
+	err := r.pool.QueryRow(ctx, q, token).Scan(&email)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	return email, err
+}
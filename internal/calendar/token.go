@@ -0,0 +1,17 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// newFeedToken returns a random attendee feed token. Tokens are stored per email address (calendar_feed_tokens)
+// and do not expire, since calendar apps keep polling a subscription for as long as it exists; resetting the
+// feed link replaces the token.
+func newFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,304 @@
+package campaigns
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/emaillogs"
+	"github.com/aura-webinar/backend/internal/emailtemplates"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+const (
+	maxNameLength    = 255
+	maxSubjectLength = 500
+	maxBodyLength    = 64 * 1024
+	// audienceSampleSize is how many matching recipients the audience preview lists.
+	audienceSampleSize = 20
+)
+
+// Handler serves webinar email campaigns. Routes run after webinars.RequireWebinarOrgAccess and are
+// limited to callers who may manage the webinar (webinars.CanManage).
+type Handler struct {
+	repo        *Repository
+	webinarRepo webinars.Lookup
+	audience    *Audience
+	emailLogs   *emaillogs.Repository
+	renderer    *emailtemplates.Renderer
+}
+
+// NewHandler creates a campaigns handler.
+func NewHandler(repo *Repository, webinarRepo webinars.Lookup, audience *Audience, emailLogs *emaillogs.Repository, renderer *emailtemplates.Renderer) *Handler {
+	return &Handler{repo: repo, webinarRepo: webinarRepo, audience: audience, emailLogs: emailLogs, renderer: renderer}
+}
+
+// CampaignRequest is the body for POST /webinars/:id/campaigns and PATCH /webinars/:id/campaigns/:campaignId.
+// Subject and body_html are templates with the same data as email templates ({{.RecipientName}}, {{.JoinURL}}, ...).
+type CampaignRequest struct {
+	Name     string                 `json:"name" binding:"required"`
+	Subject  string                 `json:"subject" binding:"required"`
+	BodyHTML string                 `json:"body_html" binding:"required"`
+	Segment  models.CampaignSegment `json:"segment"`
+}
+
+// ScheduleRequest is the body for POST /webinars/:id/campaigns/:campaignId/schedule (send_at empty = now).
+type ScheduleRequest struct {
+	SendAt *time.Time `json:"send_at"`
+}
+
+// AudienceRequest is the body for POST /webinars/:id/campaigns/audience.
+type AudienceRequest struct {
+	Segment models.CampaignSegment `json:"segment"`
+}
+
+// AudienceRecipient is one entry of the audience preview sample.
+type AudienceRecipient struct {
+	RegistrationID uuid.UUID `json:"registration_id"`
+	Email          string    `json:"email"`
+	FullName       string    `json:"full_name"`
+}
+
+// List handles GET /webinars/:id/campaigns.
+func (h *Handler) List(c *gin.Context) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return
+	}
+	list, err := h.repo.ListByWebinar(c.Request.Context(), w.ID)
+	if err != nil {
+		response.Internal(c, "failed to load campaigns")
+		return
+	}
+	if list == nil {
+		list = []*models.EmailCampaign{}
+	}
+	response.OK(c, list)
+}
+
+// Create handles POST /webinars/:id/campaigns: saves a draft.
+func (h *Handler) Create(c *gin.Context) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return
+	}
+	campaign, ok := h.bindCampaign(c)
+	if !ok {
+		return
+	}
+	userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
+	campaign.WebinarID = w.ID
+	campaign.CreatedBy = &userID
+	if err := h.repo.Create(c.Request.Context(), campaign); err != nil {
+		response.Internal(c, "failed to create campaign")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "email_campaign.create", TargetType: "email_campaign", TargetID: campaign.ID.String(), After: campaign})
+	response.Created(c, campaign)
+}
+
+// Get handles GET /webinars/:id/campaigns/:campaignId: the campaign with delivery stats from email_logs.
+func (h *Handler) Get(c *gin.Context) {
+	campaign, ok := h.load(c)
+	if !ok {
+		return
+	}
+	stats := models.CampaignStats{}
+	var err error
+	if stats.Recipients, stats.Enqueued, err = h.repo.CountRecipients(c.Request.Context(), campaign.ID); err != nil {
+		response.Internal(c, "failed to load campaign stats")
+		return
+	}
+	if stats.ByStatus, err = h.emailLogs.CountByCampaign(c.Request.Context(), campaign.ID); err != nil {
+		response.Internal(c, "failed to load campaign stats")
+		return
+	}
+	response.OK(c, gin.H{"campaign": campaign, "stats": stats})
+}
+
+// Update handles PATCH /webinars/:id/campaigns/:campaignId (draft or scheduled campaigns only).
+func (h *Handler) Update(c *gin.Context) {
+	before, ok := h.load(c)
+	if !ok {
+		return
+	}
+	campaign, ok := h.bindCampaign(c)
+	if !ok {
+		return
+	}
+	campaign.ID = before.ID
+	updated, err := h.repo.Update(c.Request.Context(), campaign)
+	if err != nil {
+		response.Internal(c, "failed to update campaign")
+		return
+	}
+	if !updated {
+		response.Conflict(c, "only draft or scheduled campaigns can be edited")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "email_campaign.update", TargetType: "email_campaign", TargetID: campaign.ID.String(), Before: before, After: campaign})
+	response.OK(c, campaign)
+}
+
+// Schedule handles POST /webinars/:id/campaigns/:campaignId/schedule. The worker starts the fan-out at
+// send_at (within a minute); the audience is resolved then, not now.
+func (h *Handler) Schedule(c *gin.Context) {
+	before, ok := h.load(c)
+	if !ok {
+		return
+	}
+	var body ScheduleRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			response.BadRequest(c, "invalid body")
+			return
+		}
+	}
+	sendAt := time.Now()
+	if body.SendAt != nil {
+		if body.SendAt.Before(time.Now().Add(-time.Minute)) {
+			response.BadRequest(c, "send_at is in the past")
+			return
+		}
+		sendAt = *body.SendAt
+	}
+	campaign, err := h.repo.Schedule(c.Request.Context(), before.ID, sendAt)
+	if err != nil {
+		response.Internal(c, "failed to schedule campaign")
+		return
+	}
+	if campaign == nil {
+		response.Conflict(c, "campaign is "+before.Status)
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "email_campaign.schedule", TargetType: "email_campaign", TargetID: campaign.ID.String(), Before: before, After: campaign})
+	response.OK(c, campaign)
+}
+
+// Cancel handles POST /webinars/:id/campaigns/:campaignId/cancel: stops a scheduled or sending campaign.
+func (h *Handler) Cancel(c *gin.Context) {
+	before, ok := h.load(c)
+	if !ok {
+		return
+	}
+	campaign, err := h.repo.Cancel(c.Request.Context(), before.ID)
+	if err != nil {
+		response.Internal(c, "failed to cancel campaign")
+		return
+	}
+	if campaign == nil {
+		response.Conflict(c, "campaign is "+before.Status)
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "email_campaign.cancel", TargetType: "email_campaign", TargetID: campaign.ID.String(), Before: before, After: campaign})
+	response.OK(c, campaign)
+}
+
+// Delete handles DELETE /webinars/:id/campaigns/:campaignId (not while sending; cancel first).
+func (h *Handler) Delete(c *gin.Context) {
+	campaign, ok := h.load(c)
+	if !ok {
+		return
+	}
+	deleted, err := h.repo.Delete(c.Request.Context(), campaign.ID)
+	if err != nil {
+		response.Internal(c, "failed to delete campaign")
+		return
+	}
+	if !deleted {
+		response.Conflict(c, "cancel the campaign before deleting it")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "email_campaign.delete", TargetType: "email_campaign", TargetID: campaign.ID.String(), Before: campaign})
+	response.NoContent(c)
+}
+
+// Audience handles POST /webinars/:id/campaigns/audience: counts a segment's recipients and lists a sample.
+func (h *Handler) Audience(c *gin.Context) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return
+	}
+	var body AudienceRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "invalid body")
+		return
+	}
+	if err := ValidateSegment(&body.Segment); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	regs, err := h.audience.Resolve(c.Request.Context(), w.ID, body.Segment)
+	if err != nil {
+		response.Internal(c, "failed to resolve audience")
+		return
+	}
+	sample := []AudienceRecipient{}
+	for i := 0; i < len(regs) && i < audienceSampleSize; i++ {
+		sample = append(sample, AudienceRecipient{RegistrationID: regs[i].ID, Email: regs[i].Email, FullName: regs[i].FullName})
+	}
+	response.OK(c, gin.H{"count": len(regs), "sample": sample})
+}
+
+// bindCampaign parses and validates a CampaignRequest; the content must render against sample data.
+// Writes the error response on failure.
+func (h *Handler) bindCampaign(c *gin.Context) (*models.EmailCampaign, bool) {
+	var body CampaignRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "name, subject and body_html required")
+		return nil, false
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" || len(body.Name) > maxNameLength {
+		response.BadRequest(c, "name is required (max 255 characters)")
+		return nil, false
+	}
+	if len(body.Subject) > maxSubjectLength || len(body.BodyHTML) > maxBodyLength {
+		response.BadRequest(c, "subject or body_html is too long")
+		return nil, false
+	}
+	if err := ValidateSegment(&body.Segment); err != nil {
+		response.BadRequest(c, err.Error())
+		return nil, false
+	}
+	var orgID *uuid.UUID
+	if v, ok := c.Get(middleware.ContextOrganizationID); ok {
+		id := v.(uuid.UUID)
+		orgID = &id
+	}
+	if _, err := h.renderer.RenderDraft(c.Request.Context(), orgID, models.EmailTypeCampaign, emailtemplates.DefaultLocale, body.Subject, body.BodyHTML, emailtemplates.SampleData(emailtemplates.DefaultLocale)); err != nil {
+		response.BadRequest(c, "campaign does not render: "+err.Error())
+		return nil, false
+	}
+	return &models.EmailCampaign{Name: body.Name, Subject: body.Subject, BodyHTML: body.BodyHTML, Segment: body.Segment}, true
+}
+
+// load returns the :campaignId campaign of the :id webinar, which the caller must manage. Writes the error
+// response on failure.
+func (h *Handler) load(c *gin.Context) (*models.EmailCampaign, bool) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return nil, false
+	}
+	id, err := uuid.Parse(c.Param("campaignId"))
+	if err != nil {
+		response.BadRequest(c, "invalid campaign id")
+		return nil, false
+	}
+	campaign, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		response.Internal(c, "failed to load campaign")
+		return nil, false
+	}
+	if campaign == nil || campaign.WebinarID != w.ID {
+		response.NotFound(c, "Campaign not found")
+		return nil, false
+	}
+	return campaign, true
+}
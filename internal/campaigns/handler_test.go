@@ -0,0 +1,133 @@
+package campaigns
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// fakeWebinars serves webinars from memory.
+type fakeWebinars map[uuid.UUID]*models.Webinar
+
+func (f fakeWebinars) GetByID(ctx context.Context, id uuid.UUID) (*models.Webinar, error) {
+	return f[id], nil
+}
+
+// newTestRouter registers the campaign routes for userID, with orgID and orgRole set as if
+// RequireWebinarOrgAccess had granted organization access. Only the webinar lookup is wired: a request that
+// gets past the access check and reaches a repository would panic.
+func newTestRouter(webinars fakeWebinars, userID uuid.UUID, orgID *uuid.UUID, orgRole string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(nil, webinars, nil, nil, nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserID, userID)
+		c.Set(middleware.ContextUserRole, string(models.RoleAdmin))
+		if orgID != nil {
+			c.Set(middleware.ContextOrganizationID, *orgID)
+			c.Set(middleware.ContextOrganizationRole, orgRole)
+		}
+	})
+	r.GET("/webinars/:id/campaigns", h.List)
+	r.POST("/webinars/:id/campaigns", h.Create)
+	r.POST("/webinars/:id/campaigns/audience", h.Audience)
+	r.GET("/webinars/:id/campaigns/:campaignId", h.Get)
+	r.PATCH("/webinars/:id/campaigns/:campaignId", h.Update)
+	r.DELETE("/webinars/:id/campaigns/:campaignId", h.Delete)
+	r.POST("/webinars/:id/campaigns/:campaignId/schedule", h.Schedule)
+	r.POST("/webinars/:id/campaigns/:campaignId/cancel", h.Cancel)
+	return r
+}
+
+func campaignRequests(webinarID uuid.UUID) [][2]string {
+	base := "/webinars/" + webinarID.String() + "/campaigns"
+	item := base + "/" + uuid.NewString()
+	return [][2]string{
+		{http.MethodGet, base},
+		{http.MethodPost, base},
+		{http.MethodPost, base + "/audience"},
+		{http.MethodGet, item},
+		{http.MethodPatch, item},
+		{http.MethodDelete, item},
+		{http.MethodPost, item + "/schedule"},
+		{http.MethodPost, item + "/cancel"},
+	}
+}
+
+func serve(r *gin.Engine, method, path string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestCampaignRoutesRefuseNonCreator(t *testing.T) {
+	creator := uuid.New()
+	w := &models.Webinar{ID: uuid.New(), CreatedBy: creator}
+	// The role claim alone (an organizer-level admin of another tenant) must not grant access.
+	r := newTestRouter(fakeWebinars{w.ID: w}, uuid.New(), nil, "")
+	for _, req := range campaignRequests(w.ID) {
+		if code := serve(r, req[0], req[1]); code != http.StatusForbidden {
+			t.Errorf("%s %s: status %d, want 403", req[0], req[1], code)
+		}
+	}
+}
+
+func TestCampaignRoutesRefuseOtherOrganization(t *testing.T) {
+	orgID, otherOrgID := uuid.New(), uuid.New()
+	w := &models.Webinar{ID: uuid.New(), CreatedBy: uuid.New(), OrganizationID: &orgID}
+	r := newTestRouter(fakeWebinars{w.ID: w}, uuid.New(), &otherOrgID, models.OrgRoleOwner)
+	for _, req := range campaignRequests(w.ID) {
+		if code := serve(r, req[0], req[1]); code != http.StatusForbidden {
+			t.Errorf("%s %s: status %d, want 403", req[0], req[1], code)
+		}
+	}
+}
+
+func TestCampaignRoutesRefuseOrganizationModerator(t *testing.T) {
+	orgID := uuid.New()
+	w := &models.Webinar{ID: uuid.New(), CreatedBy: uuid.New(), OrganizationID: &orgID}
+	r := newTestRouter(fakeWebinars{w.ID: w}, uuid.New(), &orgID, models.OrgRoleModerator)
+	for _, req := range campaignRequests(w.ID) {
+		if code := serve(r, req[0], req[1]); code != http.StatusForbidden {
+			t.Errorf("%s %s: status %d, want 403", req[0], req[1], code)
+		}
+	}
+}
+
+func TestCampaignRoutesUnknownWebinar(t *testing.T) {
+	r := newTestRouter(fakeWebinars{}, uuid.New(), nil, "")
+	for _, req := range campaignRequests(uuid.New()) {
+		if code := serve(r, req[0], req[1]); code != http.StatusNotFound {
+			t.Errorf("%s %s: status %d, want 404", req[0], req[1], code)
+		}
+	}
+}
+
+func TestCampaignCreateAllowsCreator(t *testing.T) {
+	creator := uuid.New()
+	w := &models.Webinar{ID: uuid.New(), CreatedBy: creator}
+	r := newTestRouter(fakeWebinars{w.ID: w}, creator, nil, "")
+	// Past the access check, the empty body fails validation before any repository is used.
+	if code := serve(r, http.MethodPost, "/webinars/"+w.ID.String()+"/campaigns"); code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", code)
+	}
+}
+
+func TestCampaignCreateAllowsOrganizationManager(t *testing.T) {
+	orgID := uuid.New()
+	w := &models.Webinar{ID: uuid.New(), CreatedBy: uuid.New(), OrganizationID: &orgID}
+	r := newTestRouter(fakeWebinars{w.ID: w}, uuid.New(), &orgID, models.OrgRoleEventManager)
+	if code := serve(r, http.MethodPost, "/webinars/"+w.ID.String()+"/campaigns"); code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", code)
+	}
+}
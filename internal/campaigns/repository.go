@@ -0,0 +1,233 @@
+package campaigns
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository handles email_campaigns and email_campaign_recipients persistence.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a campaigns repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Recipient is a snapshotted campaign recipient waiting to be enqueued.
+type Recipient struct {
+	RegistrationID uuid.UUID
+	Email          string
+	FullName       string
+	Locale         string
+}
+
+const columns = `id, webinar_id, name, subject, body_html, segment, status, scheduled_at, started_at, completed_at,
+	recipient_count, created_by, created_at, updated_at`
+
+func scanCampaign(row pgx.Row) (*models.EmailCampaign, error) {
+	var c models.EmailCampaign
+	var segment []byte
+	if err := row.Scan(&c.ID, &c.WebinarID, &c.Name, &c.Subject, &c.BodyHTML, &segment, &c.Status, &c.ScheduledAt, &c.StartedAt,
+		&c.CompletedAt, &c.RecipientCount, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(segment, &c.Segment); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *Repository) list(ctx context.Context, q string, args ...interface{}) ([]*models.EmailCampaign, error) {
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*models.EmailCampaign
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// Create inserts a draft campaign.
+func (r *Repository) Create(ctx context.Context, c *models.EmailCampaign) error {
+	segment, err := json.Marshal(c.Segment)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO email_campaigns (webinar_id, name, subject, body_html, segment, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + columns
+	saved, err := scanCampaign(r.pool.QueryRow(ctx, q, c.WebinarID, c.Name, c.Subject, c.BodyHTML, segment, c.CreatedBy))
+	if err != nil {
+		return err
+	}
+	*c = *saved
+	return nil
+}
+
+// GetByID returns a campaign, or nil, nil.
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*models.EmailCampaign, error) {
+	c, err := scanCampaign(r.pool.QueryRow(ctx, `SELECT `+columns+` FROM email_campaigns WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// ListByWebinar returns the webinar's campaigns, newest first.
+func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]*models.EmailCampaign, error) {
+	return r.list(ctx, `SELECT `+columns+` FROM email_campaigns WHERE webinar_id = $1 ORDER BY created_at DESC`, webinarID)
+}
+
+// Update saves name, content and segment of a draft or scheduled campaign. Returns false if the campaign
+// has started sending (or was cancelled) meanwhile.
+func (r *Repository) Update(ctx context.Context, c *models.EmailCampaign) (bool, error) {
+	segment, err := json.Marshal(c.Segment)
+	if err != nil {
+		return false, err
+	}
+	q := `UPDATE email_campaigns SET name = $2, subject = $3, body_html = $4, segment = $5, updated_at = NOW()
+		WHERE id = $1 AND status IN ('draft', 'scheduled')
+		RETURNING ` + columns
+	saved, err := scanCampaign(r.pool.QueryRow(ctx, q, c.ID, c.Name, c.Subject, c.BodyHTML, segment))
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	*c = *saved
+	return true, nil
+}
+
+// Schedule moves a draft (or reschedules a scheduled) campaign to send at sendAt. Returns nil, nil if
+// the campaign is in another status.
+func (r *Repository) Schedule(ctx context.Context, id uuid.UUID, sendAt time.Time) (*models.EmailCampaign, error) {
+	q := `UPDATE email_campaigns SET status = 'scheduled', scheduled_at = $2, updated_at = NOW()
+		WHERE id = $1 AND status IN ('draft', 'scheduled')
+		RETURNING ` + columns
+	c, err := scanCampaign(r.pool.QueryRow(ctx, q, id, sendAt))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// Cancel stops a scheduled or sending campaign; emails already enqueued are skipped by the worker.
+// Returns nil, nil if the campaign is in another status.
+func (r *Repository) Cancel(ctx context.Context, id uuid.UUID) (*models.EmailCampaign, error) {
+	q := `UPDATE email_campaigns SET status = 'cancelled', completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status IN ('scheduled', 'sending')
+		RETURNING ` + columns
+	c, err := scanCampaign(r.pool.QueryRow(ctx, q, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// Delete removes a campaign that is not sending. Returns false if there is none or it is sending.
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM email_campaigns WHERE id = $1 AND status <> 'sending'`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListDue returns scheduled campaigns whose send time has come.
+func (r *Repository) ListDue(ctx context.Context, now time.Time) ([]*models.EmailCampaign, error) {
+	return r.list(ctx, `SELECT `+columns+` FROM email_campaigns
+		WHERE status = 'scheduled' AND scheduled_at <= $1 ORDER BY scheduled_at`, now)
+}
+
+// ListSending returns campaigns being fanned out, oldest first.
+func (r *Repository) ListSending(ctx context.Context) ([]*models.EmailCampaign, error) {
+	return r.list(ctx, `SELECT `+columns+` FROM email_campaigns WHERE status = 'sending' ORDER BY started_at, id`)
+}
+
+// Start snapshots the recipients and moves a scheduled campaign to sending, atomically. Returns false if
+// the campaign is no longer scheduled (cancelled or rescheduled meanwhile).
+func (r *Repository) Start(ctx context.Context, id uuid.UUID, registrationIDs []uuid.UUID) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `UPDATE email_campaigns SET status = 'sending', started_at = NOW(), recipient_count = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'scheduled' AND scheduled_at <= NOW()`, id, len(registrationIDs))
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	ids := make([]string, len(registrationIDs))
+	for i, rid := range registrationIDs {
+		ids[i] = rid.String()
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO email_campaign_recipients (campaign_id, registration_id)
+		SELECT $1, unnest($2::text[])::uuid ON CONFLICT DO NOTHING`, id, ids); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// PendingRecipients returns up to limit recipients not yet enqueued.
+func (r *Repository) PendingRecipients(ctx context.Context, campaignID uuid.UUID, limit int) ([]Recipient, error) {
+	rows, err := r.pool.Query(ctx, `SELECT reg.id, reg.email, reg.full_name, COALESCE(reg.locale, '')
+		FROM email_campaign_recipients cr
+		JOIN registrations reg ON reg.id = cr.registration_id
+		WHERE cr.campaign_id = $1 AND cr.enqueued_at IS NULL
+		ORDER BY cr.registration_id
+		LIMIT $2`, campaignID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Recipient
+	for rows.Next() {
+		var rc Recipient
+		if err := rows.Scan(&rc.RegistrationID, &rc.Email, &rc.FullName, &rc.Locale); err != nil {
+			return nil, err
+		}
+		list = append(list, rc)
+	}
+	return list, rows.Err()
+}
+
+// MarkEnqueued records that a recipient's email was handed to the queue.
+func (r *Repository) MarkEnqueued(ctx context.Context, campaignID, registrationID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE email_campaign_recipients SET enqueued_at = NOW()
+		WHERE campaign_id = $1 AND registration_id = $2`, campaignID, registrationID)
+	return err
+}
+
+// Complete marks a sending campaign as sent once every recipient is enqueued.
+func (r *Repository) Complete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE email_campaigns SET status = 'sent', completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'sending'`, id)
+	return err
+}
+
+// CountRecipients returns the campaign's snapshotted and enqueued recipient counts.
+func (r *Repository) CountRecipients(ctx context.Context, campaignID uuid.UUID) (total, enqueued int, err error) {
+	err = r.pool.QueryRow(ctx, `SELECT COUNT(*), COUNT(enqueued_at) FROM email_campaign_recipients WHERE campaign_id = $1`, campaignID).
+		Scan(&total, &enqueued)
+	return total, enqueued, err
+}
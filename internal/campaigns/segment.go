@@ -0,0 +1,155 @@
+package campaigns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/internal/sessionlog"
+)
+
+const (
+	maxFieldFilters = 20
+	maxFilterValues = 100
+)
+
+// Audience resolves segments to registrations.
+type Audience struct {
+	regRepo     *registrations.Repository
+	sessionRepo *sessionlog.Repository
+}
+
+// NewAudience creates an audience resolver.
+func NewAudience(regRepo *registrations.Repository, sessionRepo *sessionlog.Repository) *Audience {
+	return &Audience{regRepo: regRepo, sessionRepo: sessionRepo}
+}
+
+// Resolve returns the webinar's registrations matching seg.
+func (a *Audience) Resolve(ctx context.Context, webinarID uuid.UUID, seg models.CampaignSegment) ([]models.Registration, error) {
+	regs, err := a.regRepo.ListByWebinar(ctx, webinarID)
+	if err != nil {
+		return nil, fmt.Errorf("list registrations: %w", err)
+	}
+	var watched map[string]int64
+	if seg.MinWatchMinutes > 0 {
+		if watched, err = a.sessionRepo.WatchSecondsByEmail(ctx, webinarID); err != nil {
+			return nil, fmt.Errorf("load watch time: %w", err)
+		}
+	}
+	var out []models.Registration
+	for _, reg := range regs {
+		if Matches(seg, reg, watched) {
+			out = append(out, reg)
+		}
+	}
+	return out, nil
+}
+
+// Matches reports whether reg is in the segment. watched maps lowercased emails to watch seconds
+// (only consulted when the segment has MinWatchMinutes).
+func Matches(seg models.CampaignSegment, reg models.Registration, watched map[string]int64) bool {
+	switch seg.Audience {
+	case models.CampaignAudienceAttended:
+		if reg.AttendedAt == nil {
+			return false
+		}
+	case models.CampaignAudienceNoShow:
+		if reg.AttendedAt != nil {
+			return false
+		}
+	}
+	if seg.MinWatchMinutes > 0 && watched[strings.ToLower(reg.Email)] < int64(seg.MinWatchMinutes)*60 {
+		return false
+	}
+	if len(seg.Fields) == 0 {
+		return true
+	}
+	responses := formResponses(reg.ExtraData)
+	for _, f := range seg.Fields {
+		if !fieldMatches(f, responses) {
+			return false
+		}
+	}
+	return true
+}
+
+// formResponses flattens extra_data to strings (non-string answers such as checkboxes are formatted).
+func formResponses(extra json.RawMessage) map[string]string {
+	out := map[string]string{}
+	var raw map[string]interface{}
+	if len(extra) == 0 || json.Unmarshal(extra, &raw) != nil {
+		return out
+	}
+	for k, v := range raw {
+		switch t := v.(type) {
+		case string:
+			out[k] = t
+		case nil:
+		default:
+			out[k] = fmt.Sprint(t)
+		}
+	}
+	return out
+}
+
+func fieldMatches(f models.CampaignFieldFilter, responses map[string]string) bool {
+	v, ok := responses[f.Field]
+	v = strings.TrimSpace(v)
+	present := ok && v != ""
+	switch f.Op {
+	case "present":
+		return present
+	case "absent":
+		return !present
+	case "eq":
+		return strings.EqualFold(v, f.Value)
+	case "neq":
+		return !strings.EqualFold(v, f.Value)
+	case "contains":
+		return present && strings.Contains(strings.ToLower(v), strings.ToLower(f.Value))
+	case "in":
+		for _, want := range f.Values {
+			if strings.EqualFold(v, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateSegment checks a segment before it is saved. An empty audience means registered.
+func ValidateSegment(seg *models.CampaignSegment) error {
+	switch seg.Audience {
+	case "":
+		seg.Audience = models.CampaignAudienceRegistered
+	case models.CampaignAudienceRegistered, models.CampaignAudienceAttended, models.CampaignAudienceNoShow:
+	default:
+		return fmt.Errorf("audience must be registered, attended or no_show")
+	}
+	if seg.MinWatchMinutes < 0 {
+		return fmt.Errorf("min_watch_minutes must not be negative")
+	}
+	if len(seg.Fields) > maxFieldFilters {
+		return fmt.Errorf("at most %d field filters", maxFieldFilters)
+	}
+	for _, f := range seg.Fields {
+		if strings.TrimSpace(f.Field) == "" {
+			return fmt.Errorf("field filter without field")
+		}
+		switch f.Op {
+		case "eq", "neq", "contains", "present", "absent":
+		case "in":
+			if len(f.Values) == 0 || len(f.Values) > maxFilterValues {
+				return fmt.Errorf("field %q: in needs 1–%d values", f.Field, maxFilterValues)
+			}
+		default:
+			return fmt.Errorf("field %q: op must be eq, neq, contains, in, present or absent", f.Field)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/aura-webinar/backend/internal/audit"
 	"github.com/aura-webinar/backend/pkg/response"
 )
 
@@ -54,5 +55,9 @@ func (h *Handler) Resend(c *gin.Context) {
 		return
 	}
 	// TODO: enqueue email to worker (QueueEmails) or send via SMTP when email worker is implemented
+	audit.Annotate(c, audit.Change{
+		Action: "email.resend", TargetType: "registration", TargetID: body.RegistrationID,
+		After: gin.H{"email_type": body.EmailType},
+	})
 	response.OK(c, gin.H{"message": "resend queued"})
 }
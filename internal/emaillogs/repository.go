@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/aura-webinar/backend/internal/models"
@@ -19,28 +20,31 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 	return &Repository{pool: pool}
 }
 
-// Create inserts an email log (pending).
-func (r *Repository) Create(ctx context.Context, webinarID, registrationID *uuid.UUID, emailType, recipientEmail, subject string) (*models.EmailLog, error) {
-	const q = `INSERT INTO email_logs (id, webinar_id, registration_id, email_type, recipient_email, subject, status)
-		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, 'pending')
-		RETURNING id, webinar_id, registration_id, email_type, recipient_email, subject, status, sent_at, error_message, created_at`
+// Create inserts an email log (pending). campaignID is set for campaign emails.
+func (r *Repository) Create(ctx context.Context, webinarID, registrationID, campaignID *uuid.UUID, emailType, recipientEmail, subject string) (*models.EmailLog, error) {
+	const q = `INSERT INTO email_logs (id, webinar_id, registration_id, campaign_id, email_type, recipient_email, subject, status)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, 'pending')
+		RETURNING id, webinar_id, registration_id, email_type, recipient_email, subject, status, sent_at, error_message, message_id, campaign_id, created_at`
 	var el models.EmailLog
-	var errMsg *string
-	err := r.pool.QueryRow(ctx, q, webinarID, registrationID, emailType, recipientEmail, subject).
-		Scan(&el.ID, &el.WebinarID, &el.RegistrationID, &el.EmailType, &el.RecipientEmail, &el.Subject, &el.Status, &el.SentAt, &errMsg, &el.CreatedAt)
+	var errMsg, messageID *string
+	err := r.pool.QueryRow(ctx, q, webinarID, registrationID, campaignID, emailType, recipientEmail, subject).
+		Scan(&el.ID, &el.WebinarID, &el.RegistrationID, &el.EmailType, &el.RecipientEmail, &el.Subject, &el.Status, &el.SentAt, &errMsg, &messageID, &el.CampaignID, &el.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	if errMsg != nil {
 		el.ErrorMessage = *errMsg
 	}
+	if messageID != nil {
+		el.MessageID = *messageID
+	}
 	return &el, nil
 }
 
-// MarkSent updates log to sent.
-func (r *Repository) MarkSent(ctx context.Context, id uuid.UUID) error {
-	const q = `UPDATE email_logs SET status = 'sent', sent_at = NOW() WHERE id = $1`
-	_, err := r.pool.Exec(ctx, q, id)
+// MarkSent updates log to sent with the transport's message ID.
+func (r *Repository) MarkSent(ctx context.Context, id uuid.UUID, messageID string) error {
+	const q = `UPDATE email_logs SET status = 'sent', sent_at = NOW(), message_id = NULLIF($2, '') WHERE id = $1`
+	_, err := r.pool.Exec(ctx, q, id, messageID)
 	return err
 }
 
@@ -51,6 +55,34 @@ func (r *Repository) MarkFailed(ctx context.Context, id uuid.UUID, errMsg string
 	return err
 }
 
+// MarkSuppressed updates log to suppressed (the recipient is on the suppression list; nothing was sent).
+func (r *Repository) MarkSuppressed(ctx context.Context, id uuid.UUID, reason string) error {
+	const q = `UPDATE email_logs SET status = 'suppressed', error_message = $2 WHERE id = $1`
+	_, err := r.pool.Exec(ctx, q, id, reason)
+	return err
+}
+
+// MarkDelivery records a provider outcome (bounced, complained, failed) on the log with messageID and
+// returns the organization of its webinar (nil for account emails). An empty status leaves the log as is.
+// found is false when no log matches.
+func (r *Repository) MarkDelivery(ctx context.Context, messageID, status, detail string) (orgID *uuid.UUID, found bool, err error) {
+	const q = `WITH updated AS (
+			UPDATE email_logs SET status = COALESCE(NULLIF($2, ''), status),
+				error_message = CASE WHEN $2 = '' THEN error_message ELSE NULLIF($3, '') END
+			WHERE message_id = $1
+			RETURNING webinar_id
+		)
+		SELECT w.organization_id FROM updated u LEFT JOIN webinars w ON w.id = u.webinar_id LIMIT 1`
+	err = r.pool.QueryRow(ctx, q, messageID, status, detail).Scan(&orgID)
+	if err == pgx.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return orgID, true, nil
+}
+
 // AlreadySent checks if an email of the given type was already sent to this registration.
 func (r *Repository) AlreadySent(ctx context.Context, registrationID uuid.UUID, emailType string) (bool, error) {
 	const q = `SELECT EXISTS(
@@ -64,7 +96,7 @@ func (r *Repository) AlreadySent(ctx context.Context, registrationID uuid.UUID,
 
 // ListByWebinar returns email logs for a webinar, newest first.
 func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]*models.EmailLog, error) {
-	const q = `SELECT id, webinar_id, registration_id, email_type, recipient_email, subject, status, sent_at, error_message, created_at
+	const q = `SELECT id, webinar_id, registration_id, email_type, recipient_email, subject, status, sent_at, error_message, message_id, campaign_id, created_at
 		FROM email_logs
 		WHERE webinar_id = $1
 		ORDER BY created_at DESC`
@@ -76,8 +108,8 @@ func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]
 	var list []*models.EmailLog
 	for rows.Next() {
 		var el models.EmailLog
-		var subject, errMsg *string
-		if err := rows.Scan(&el.ID, &el.WebinarID, &el.RegistrationID, &el.EmailType, &el.RecipientEmail, &subject, &el.Status, &el.SentAt, &errMsg, &el.CreatedAt); err != nil {
+		var subject, errMsg, messageID *string
+		if err := rows.Scan(&el.ID, &el.WebinarID, &el.RegistrationID, &el.EmailType, &el.RecipientEmail, &subject, &el.Status, &el.SentAt, &errMsg, &messageID, &el.CampaignID, &el.CreatedAt); err != nil {
 			return nil, err
 		}
 		if subject != nil {
@@ -86,7 +118,29 @@ func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]
 		if errMsg != nil {
 			el.ErrorMessage = *errMsg
 		}
+		if messageID != nil {
+			el.MessageID = *messageID
+		}
 		list = append(list, &el)
 	}
 	return list, rows.Err()
 }
+
+// CountByCampaign returns the number of the campaign's email logs per status.
+func (r *Repository) CountByCampaign(ctx context.Context, campaignID uuid.UUID) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx, `SELECT status, COUNT(*) FROM email_logs WHERE campaign_id = $1 GROUP BY status`, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
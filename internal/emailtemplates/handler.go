@@ -0,0 +1,308 @@
+package emailtemplates
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+const (
+	maxSubjectLength = 500
+	maxBodyLength    = 64 * 1024
+	maxFooterLength  = 1000
+)
+
+// OrgRoleLookup returns a user's role in an organization (organizations.Repository).
+type OrgRoleLookup interface {
+	GetUserRole(ctx context.Context, orgID, userID uuid.UUID) (string, error)
+}
+
+// Handler serves organization email branding and template overrides.
+type Handler struct {
+	repo     *Repository
+	renderer *Renderer
+	orgRepo  OrgRoleLookup
+}
+
+// NewHandler creates an email templates handler.
+func NewHandler(repo *Repository, renderer *Renderer, orgRepo OrgRoleLookup) *Handler {
+	return &Handler{repo: repo, renderer: renderer, orgRepo: orgRepo}
+}
+
+// BrandingRequest is the body for PUT /organizations/:id/email-branding.
+type BrandingRequest struct {
+	LogoURL       string `json:"logo_url"`
+	PrimaryColor  string `json:"primary_color"`
+	FooterText    string `json:"footer_text"`
+	DefaultLocale string `json:"default_locale"`
+}
+
+// TemplateRequest is the body for PUT /organizations/:id/email-templates/:type/:locale.
+type TemplateRequest struct {
+	Subject  string `json:"subject" binding:"required"`
+	BodyHTML string `json:"body_html" binding:"required"`
+}
+
+// PreviewRequest is the body for POST /organizations/:id/email-templates/preview. Without subject and
+// body_html the effective template (override or default) is rendered.
+type PreviewRequest struct {
+	EmailType string `json:"email_type" binding:"required"`
+	Locale    string `json:"locale"`
+	Subject   string `json:"subject"`
+	BodyHTML  string `json:"body_html"`
+}
+
+// GetBranding handles GET /organizations/:id/email-branding (owner or event manager).
+func (h *Handler) GetBranding(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	b, err := h.repo.GetBranding(c.Request.Context(), orgID)
+	if err != nil {
+		response.Internal(c, "failed to load email branding")
+		return
+	}
+	if b == nil {
+		response.NotFound(c, "Organization not found")
+		return
+	}
+	response.OK(c, b)
+}
+
+// UpdateBranding handles PUT /organizations/:id/email-branding (owner or event manager).
+func (h *Handler) UpdateBranding(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	var body BrandingRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "invalid body")
+		return
+	}
+	b := &models.EmailBranding{
+		OrganizationID: orgID,
+		LogoURL:        strings.TrimSpace(body.LogoURL),
+		PrimaryColor:   strings.TrimSpace(body.PrimaryColor),
+		FooterText:     strings.TrimSpace(body.FooterText),
+		DefaultLocale:  DefaultLocale,
+	}
+	if b.LogoURL != "" {
+		if u, err := url.Parse(b.LogoURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			response.BadRequest(c, "logo_url must be an https URL")
+			return
+		}
+	}
+	if b.PrimaryColor != "" && !colorRegex.MatchString(b.PrimaryColor) {
+		response.BadRequest(c, "primary_color must be a hex color like #0ea5e9")
+		return
+	}
+	if len(b.FooterText) > maxFooterLength {
+		response.BadRequest(c, "footer_text is too long")
+		return
+	}
+	if body.DefaultLocale != "" {
+		if b.DefaultLocale = email.NormalizeLocale(body.DefaultLocale); b.DefaultLocale == "" {
+			response.BadRequest(c, "default_locale must be a language tag like en or pt-br")
+			return
+		}
+	}
+	before, _ := h.repo.GetBranding(c.Request.Context(), orgID)
+	if before == nil {
+		response.NotFound(c, "Organization not found")
+		return
+	}
+	if err := h.repo.UpsertBranding(c.Request.Context(), b); err != nil {
+		response.Internal(c, "failed to save email branding")
+		return
+	}
+	b.OrganizationName = before.OrganizationName
+	audit.Annotate(c, audit.Change{Action: "email_branding.update", TargetType: "organization", TargetID: orgID.String(), OrganizationID: &orgID, Before: before, After: b})
+	response.OK(c, b)
+}
+
+// List handles GET /organizations/:id/email-templates: the organization's overrides plus the email types
+// and locales that have defaults.
+func (h *Handler) List(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	list, err := h.repo.ListTemplates(c.Request.Context(), orgID)
+	if err != nil {
+		response.Internal(c, "failed to load email templates")
+		return
+	}
+	if list == nil {
+		list = []*models.EmailTemplate{}
+	}
+	response.OK(c, gin.H{"templates": list, "email_types": Types, "locales": h.renderer.Locales()})
+}
+
+// Get handles GET /organizations/:id/email-templates/:type/:locale: the override, or the default source
+// (source "default") to start editing from.
+func (h *Handler) Get(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	emailType, locale, ok := templateParams(c)
+	if !ok {
+		return
+	}
+	t, err := h.repo.GetTemplate(c.Request.Context(), orgID, emailType, locale)
+	if err != nil {
+		response.Internal(c, "failed to load email template")
+		return
+	}
+	if t != nil {
+		response.OK(c, gin.H{"template": t, "source": "organization"})
+		return
+	}
+	def := h.renderer.Default(emailType, locale)
+	if def == nil {
+		def = h.renderer.Default(emailType, DefaultLocale)
+	}
+	if def == nil {
+		def = h.renderer.Default(fallbackType, DefaultLocale)
+		def.EmailType = emailType
+	}
+	def.Locale = locale
+	response.OK(c, gin.H{"template": def, "source": "default"})
+}
+
+// Upsert handles PUT /organizations/:id/email-templates/:type/:locale. The template must render against
+// sample data before it is saved.
+func (h *Handler) Upsert(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	emailType, locale, ok := templateParams(c)
+	if !ok {
+		return
+	}
+	var body TemplateRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "subject and body_html required")
+		return
+	}
+	if len(body.Subject) > maxSubjectLength || len(body.BodyHTML) > maxBodyLength {
+		response.BadRequest(c, "subject or body_html is too long")
+		return
+	}
+	if _, err := h.renderer.RenderDraft(c.Request.Context(), &orgID, emailType, locale, body.Subject, body.BodyHTML, SampleData(locale)); err != nil {
+		response.BadRequest(c, "template does not render: "+err.Error())
+		return
+	}
+	userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
+	before, _ := h.repo.GetTemplate(c.Request.Context(), orgID, emailType, locale)
+	t := &models.EmailTemplate{OrganizationID: orgID, EmailType: emailType, Locale: locale, Subject: body.Subject, BodyHTML: body.BodyHTML, UpdatedBy: &userID}
+	if err := h.repo.UpsertTemplate(c.Request.Context(), t); err != nil {
+		response.Internal(c, "failed to save email template")
+		return
+	}
+	change := audit.Change{Action: "email_template.update", TargetType: "email_template", TargetID: t.ID.String(), OrganizationID: &orgID, After: t}
+	if before != nil {
+		change.Before = before
+	}
+	audit.Annotate(c, change)
+	response.OK(c, t)
+}
+
+// Delete handles DELETE /organizations/:id/email-templates/:type/:locale (reverts to the default).
+func (h *Handler) Delete(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	emailType, locale, ok := templateParams(c)
+	if !ok {
+		return
+	}
+	deleted, err := h.repo.DeleteTemplate(c.Request.Context(), orgID, emailType, locale)
+	if err != nil {
+		response.Internal(c, "failed to delete email template")
+		return
+	}
+	if !deleted {
+		response.NotFound(c, "Email template not found")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "email_template.delete", TargetType: "email_template", TargetID: emailType + "/" + locale, OrganizationID: &orgID})
+	response.NoContent(c)
+}
+
+// Preview handles POST /organizations/:id/email-templates/preview: renders a draft or the effective
+// template with the organization's branding against sample data.
+func (h *Handler) Preview(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	var body PreviewRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "email_type required")
+		return
+	}
+	if !validType(body.EmailType) {
+		response.BadRequest(c, "unknown email_type")
+		return
+	}
+	locale := DefaultLocale
+	if body.Locale != "" {
+		if locale = email.NormalizeLocale(body.Locale); locale == "" {
+			response.BadRequest(c, "invalid locale")
+			return
+		}
+	}
+	data := SampleData(locale)
+	var out *Rendered
+	var err error
+	if body.Subject != "" || body.BodyHTML != "" {
+		out, err = h.renderer.RenderDraft(c.Request.Context(), &orgID, body.EmailType, locale, body.Subject, body.BodyHTML, data)
+	} else {
+		out, err = h.renderer.Render(c.Request.Context(), &orgID, body.EmailType, locale, data)
+	}
+	if err != nil {
+		response.BadRequest(c, "template does not render: "+err.Error())
+		return
+	}
+	response.OK(c, out)
+}
+
+func templateParams(c *gin.Context) (string, string, bool) {
+	emailType := c.Param("type")
+	if !validType(emailType) {
+		response.BadRequest(c, "unknown email type")
+		return "", "", false
+	}
+	locale := email.NormalizeLocale(c.Param("locale"))
+	if locale == "" {
+		response.BadRequest(c, "invalid locale")
+		return "", "", false
+	}
+	return emailType, locale, true
+}
+
+func validType(t string) bool {
+	if _, ok := models.ReminderOffset(t); ok {
+		return true
+	}
+	for _, v := range Types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
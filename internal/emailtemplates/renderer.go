@@ -0,0 +1,369 @@
+package emailtemplates
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/email"
+)
+
+//go:embed defaults/*.html
+var defaultsFS embed.FS
+
+const (
+	// DefaultLocale is used when neither the recipient nor the organization has a matching variant.
+	DefaultLocale = "en"
+	// DefaultPrimaryColor is the button color when the organization has no branding.
+	DefaultPrimaryColor = "#0ea5e9"
+	// fallbackType names the generic template for email types without their own.
+	fallbackType = "default"
+	// reminderFallbackType names the generic template for reminder offsets without their own.
+	reminderFallbackType = "reminder"
+)
+
+// Types are the email types an organization can override (all but thank_you and replay_access have their own
+// default template).
+// Reminders for other offsets (models.ReminderEmailType) can be overridden too.
+var Types = []string{
+	models.EmailTypeEmailVerification,
+	models.EmailTypeSpeakerInvitation,
+	models.EmailTypeRegistrationConfirmation,
+	models.EmailTypeReminder1w,
+	models.EmailTypeReminder24h,
+	models.EmailTypeReminder1h,
+	models.EmailTypeReminder10m,
+	models.EmailTypeWebinarCancelled,
+	models.EmailTypeWebinarRescheduled,
+	models.EmailTypeThankYou,
+	models.EmailTypeReplayAccess,
+}
+
+// layoutText holds the layout's own strings per language.
+var layoutText = map[string]LayoutText{
+	"en": {Unsubscribe: "Unsubscribe from these emails"},
+	"es": {Unsubscribe: "Dejar de recibir estos correos"},
+}
+
+// durationUnits are singular/plural unit names per language, for Data.StartsIn.
+var durationUnits = map[string][4][2]string{
+	"en": {{"week", "weeks"}, {"day", "days"}, {"hour", "hours"}, {"minute", "minutes"}},
+	"es": {{"semana", "semanas"}, {"día", "días"}, {"hora", "horas"}, {"minuto", "minutos"}},
+}
+
+var (
+	colorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// Brand is the organization look applied by the layout.
+type Brand struct {
+	Name         string
+	LogoURL      string
+	PrimaryColor string
+	FooterText   string
+}
+
+// LayoutText is localized copy used by the shared layout.
+type LayoutText struct {
+	Unsubscribe string
+}
+
+// Data is what templates render. All string fields are escaped by html/template in bodies;
+// attendee-provided values (RecipientName, WebinarTitle) are never trusted as HTML.
+type Data struct {
+	EmailType      string
+	Locale         string
+	RecipientName  string
+	WebinarTitle   string
+	StartsAt       string // formatted for Locale (see FormatTime)
+	StartsIn       string // reminders: the offset before start for Locale, e.g. "1 week"
+	JoinURL        string
+	VerifyURL      string
+	InviteURL      string
+	UnsubscribeURL string
+	Brand          Brand
+	Text           LayoutText
+}
+
+// Rendered is a rendered email.
+type Rendered struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Locale  string `json:"locale"`
+	Source  string `json:"source"` // "organization" or "default"
+}
+
+// Store loads organization branding and overrides (Repository). Both return nil, nil when unset.
+type Store interface {
+	GetBranding(ctx context.Context, orgID uuid.UUID) (*models.EmailBranding, error)
+	GetTemplate(ctx context.Context, orgID uuid.UUID, emailType, locale string) (*models.EmailTemplate, error)
+}
+
+type compiled struct {
+	subject *texttemplate.Template
+	body    *htmltemplate.Template
+	// sources for editing (GET of a default template)
+	subjectSrc, bodySrc string
+}
+
+// Renderer renders emails from organization overrides or the embedded defaults.
+type Renderer struct {
+	store    Store
+	layout   *htmltemplate.Template
+	defaults map[string]*compiled // "<type>.<locale>"
+	locales  []string
+}
+
+// NewRenderer parses the embedded templates (panics if they are invalid). store may be nil to render
+// defaults only.
+func NewRenderer(store Store) *Renderer {
+	layoutSrc, err := defaultsFS.ReadFile("defaults/layout.html")
+	if err != nil {
+		panic(err)
+	}
+	r := &Renderer{
+		store:    store,
+		layout:   htmltemplate.Must(htmltemplate.New("layout").Parse(string(layoutSrc))),
+		defaults: make(map[string]*compiled),
+	}
+	files, err := fs.Glob(defaultsFS, "defaults/*.*.html")
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range files {
+		key := strings.TrimSuffix(strings.TrimPrefix(name, "defaults/"), ".html")
+		src, err := defaultsFS.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		text := texttemplate.Must(texttemplate.New(key).Parse(string(src)))
+		subject, body := text.Lookup("subject"), text.Lookup("body")
+		if subject == nil || body == nil {
+			panic(fmt.Sprintf("email template %s: missing subject or body", name))
+		}
+		c, err := r.compile(subject.Tree.Root.String(), body.Tree.Root.String())
+		if err != nil {
+			panic(fmt.Sprintf("email template %s: %v", name, err))
+		}
+		r.defaults[key] = c
+		if strings.HasPrefix(key, fallbackType+".") {
+			r.locales = append(r.locales, strings.TrimPrefix(key, fallbackType+"."))
+		}
+	}
+	return r
+}
+
+// compile parses a subject (text/template) and a body (html/template inside the layout).
+func (r *Renderer) compile(subjectSrc, bodySrc string) (*compiled, error) {
+	subject, err := texttemplate.New("subject").Parse(subjectSrc)
+	if err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	layout, err := r.layout.Clone()
+	if err != nil {
+		return nil, err
+	}
+	body, err := layout.New("body").Parse(bodySrc)
+	if err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+	return &compiled{subject: subject, body: body, subjectSrc: subjectSrc, bodySrc: bodySrc}, nil
+}
+
+// Render renders emailType for the organization (nil for account emails) in the best locale available:
+// the recipient's locale, its base language, the organization default, then DefaultLocale. At each step an
+// organization override wins over the embedded default. Types without a template use a generic one
+// (reminders the generic reminder first).
+func (r *Renderer) Render(ctx context.Context, orgID *uuid.UUID, emailType, locale string, data Data) (*Rendered, error) {
+	branding, err := r.branding(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	orgDefault := ""
+	if branding != nil {
+		orgDefault = branding.DefaultLocale
+	}
+	types := []string{emailType, fallbackType}
+	if _, ok := models.ReminderOffset(emailType); ok {
+		types = []string{emailType, reminderFallbackType, fallbackType}
+	}
+	for _, t := range types {
+		for _, loc := range localeChain(locale, orgDefault) {
+			c, source, err := r.lookup(ctx, orgID, t, loc)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				return r.execute(c, emailType, loc, source, branding, data)
+			}
+		}
+	}
+	return nil, fmt.Errorf("no email template for %q", emailType)
+}
+
+// RenderDraft renders an unsaved override (preview and validation before save).
+func (r *Renderer) RenderDraft(ctx context.Context, orgID *uuid.UUID, emailType, locale, subject, bodyHTML string, data Data) (*Rendered, error) {
+	c, err := r.compile(subject, bodyHTML)
+	if err != nil {
+		return nil, err
+	}
+	branding, err := r.branding(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return r.execute(c, emailType, locale, "draft", branding, data)
+}
+
+// Default returns the embedded template source for emailType and locale (nil if there is none).
+func (r *Renderer) Default(emailType, locale string) *models.EmailTemplate {
+	c := r.defaults[emailType+"."+locale]
+	if c == nil {
+		return nil
+	}
+	return &models.EmailTemplate{EmailType: emailType, Locale: locale, Subject: c.subjectSrc, BodyHTML: c.bodySrc}
+}
+
+// Locales returns the locales with embedded defaults.
+func (r *Renderer) Locales() []string {
+	return r.locales
+}
+
+func (r *Renderer) lookup(ctx context.Context, orgID *uuid.UUID, emailType, locale string) (*compiled, string, error) {
+	if orgID != nil && r.store != nil && emailType != fallbackType {
+		t, err := r.store.GetTemplate(ctx, *orgID, emailType, locale)
+		if err != nil {
+			return nil, "", fmt.Errorf("load email template: %w", err)
+		}
+		if t != nil {
+			c, err := r.compile(t.Subject, t.BodyHTML)
+			if err != nil {
+				return nil, "", fmt.Errorf("organization template %s/%s: %w", emailType, locale, err)
+			}
+			return c, "organization", nil
+		}
+	}
+	return r.defaults[emailType+"."+locale], "default", nil
+}
+
+func (r *Renderer) branding(ctx context.Context, orgID *uuid.UUID) (*models.EmailBranding, error) {
+	if orgID == nil || r.store == nil {
+		return nil, nil
+	}
+	b, err := r.store.GetBranding(ctx, *orgID)
+	if err != nil {
+		return nil, fmt.Errorf("load email branding: %w", err)
+	}
+	return b, nil
+}
+
+func (r *Renderer) execute(c *compiled, emailType, locale, source string, branding *models.EmailBranding, data Data) (*Rendered, error) {
+	data.EmailType = emailType
+	data.Locale = locale
+	if branding != nil {
+		data.Brand.Name = branding.OrganizationName
+		data.Brand.LogoURL = branding.LogoURL
+		data.Brand.FooterText = branding.FooterText
+		if branding.PrimaryColor != "" {
+			data.Brand.PrimaryColor = branding.PrimaryColor
+		}
+	}
+	if !colorRegex.MatchString(data.Brand.PrimaryColor) {
+		data.Brand.PrimaryColor = DefaultPrimaryColor
+	}
+	text, ok := layoutText[baseLanguage(locale)]
+	if !ok {
+		text = layoutText[DefaultLocale]
+	}
+	data.Text = text
+	if minutes, ok := models.ReminderOffset(emailType); ok {
+		data.StartsIn = FormatOffset(minutes, locale)
+	}
+
+	var subject, body bytes.Buffer
+	if err := c.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	if err := c.body.ExecuteTemplate(&body, "layout", data); err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+	return &Rendered{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    body.String(),
+		Locale:  locale,
+		Source:  source,
+	}, nil
+}
+
+// localeChain lists the locales to try, most specific first, without duplicates.
+func localeChain(locale, orgDefault string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	for _, l := range []string{email.NormalizeLocale(locale), baseLanguage(email.NormalizeLocale(locale)), email.NormalizeLocale(orgDefault), DefaultLocale} {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			chain = append(chain, l)
+		}
+	}
+	return chain
+}
+
+func baseLanguage(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
+
+// FormatTime formats a webinar start for the locale (UTC; webinars carry no time zone).
+func FormatTime(t time.Time, locale string) string {
+	t = t.UTC()
+	switch baseLanguage(email.NormalizeLocale(locale)) {
+	case "es":
+		return t.Format("02/01/2006 15:04") + " UTC"
+	default:
+		return t.Format("Monday, January 2, 2006 at 3:04 PM") + " UTC"
+	}
+}
+
+// FormatOffset spells a reminder offset for the locale in its largest whole unit ("1 week", "90 minutes").
+func FormatOffset(minutes int, locale string) string {
+	units, ok := durationUnits[baseLanguage(email.NormalizeLocale(locale))]
+	if !ok {
+		units = durationUnits[DefaultLocale]
+	}
+	n, unit := minutes, units[3]
+	switch {
+	case minutes%(7*24*60) == 0:
+		n, unit = minutes/(7*24*60), units[0]
+	case minutes%(24*60) == 0:
+		n, unit = minutes/(24*60), units[1]
+	case minutes%60 == 0:
+		n, unit = minutes/60, units[2]
+	}
+	if n == 1 {
+		return "1 " + unit[0]
+	}
+	return strconv.Itoa(n) + " " + unit[1]
+}
+
+// SampleData is the data previews render against.
+func SampleData(locale string) Data {
+	return Data{
+		RecipientName:  "Alex Example",
+		WebinarTitle:   "Quarterly Product Update",
+		StartsAt:       FormatTime(time.Now().Add(24*time.Hour).Truncate(time.Hour), locale),
+		JoinURL:        "https://example.com/audience?webinar_id=00000000-0000-0000-0000-000000000000&token=sample",
+		VerifyURL:      "https://example.com/auth/verify?token=sample",
+		InviteURL:      "https://example.com/auth/speaker-invite?token=sample",
+		UnsubscribeURL: "https://example.com/unsubscribe?token=sample",
+	}
+}
@@ -0,0 +1,114 @@
+package emailtemplates
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository handles organization_email_branding and email_templates persistence.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates an email templates repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// GetBranding returns the organization's branding (defaults when it has none). Returns nil, nil if the
+// organization does not exist.
+func (r *Repository) GetBranding(ctx context.Context, orgID uuid.UUID) (*models.EmailBranding, error) {
+	const q = `SELECT o.id, o.name, COALESCE(b.logo_url, ''), COALESCE(b.primary_color, ''), COALESCE(b.footer_text, ''),
+			COALESCE(b.default_locale, 'en'), COALESCE(b.updated_at, o.updated_at)
+		FROM organizations o
+		LEFT JOIN organization_email_branding b ON b.organization_id = o.id
+		WHERE o.id = $1`
+	var b models.EmailBranding
+	err := r.pool.QueryRow(ctx, q, orgID).Scan(&b.OrganizationID, &b.OrganizationName, &b.LogoURL, &b.PrimaryColor, &b.FooterText, &b.DefaultLocale, &b.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// UpsertBranding saves the organization's branding.
+func (r *Repository) UpsertBranding(ctx context.Context, b *models.EmailBranding) error {
+	const q = `INSERT INTO organization_email_branding (organization_id, logo_url, primary_color, footer_text, default_locale)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5)
+		ON CONFLICT (organization_id) DO UPDATE SET logo_url = EXCLUDED.logo_url, primary_color = EXCLUDED.primary_color,
+			footer_text = EXCLUDED.footer_text, default_locale = EXCLUDED.default_locale, updated_at = NOW()
+		RETURNING updated_at`
+	return r.pool.QueryRow(ctx, q, b.OrganizationID, b.LogoURL, b.PrimaryColor, b.FooterText, b.DefaultLocale).Scan(&b.UpdatedAt)
+}
+
+const templateColumns = `id, organization_id, email_type, locale, subject, body_html, updated_by, created_at, updated_at`
+
+func scanTemplate(row pgx.Row) (*models.EmailTemplate, error) {
+	var t models.EmailTemplate
+	if err := row.Scan(&t.ID, &t.OrganizationID, &t.EmailType, &t.Locale, &t.Subject, &t.BodyHTML, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTemplate returns the organization's override for emailType and locale, or nil, nil.
+func (r *Repository) GetTemplate(ctx context.Context, orgID uuid.UUID, emailType, locale string) (*models.EmailTemplate, error) {
+	t, err := scanTemplate(r.pool.QueryRow(ctx, `SELECT `+templateColumns+` FROM email_templates
+		WHERE organization_id = $1 AND email_type = $2 AND locale = $3`, orgID, emailType, locale))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+// ListTemplates returns the organization's overrides.
+func (r *Repository) ListTemplates(ctx context.Context, orgID uuid.UUID) ([]*models.EmailTemplate, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+templateColumns+` FROM email_templates
+		WHERE organization_id = $1 ORDER BY email_type, locale`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*models.EmailTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// UpsertTemplate creates or replaces an override (one per organization, type and locale).
+func (r *Repository) UpsertTemplate(ctx context.Context, t *models.EmailTemplate) error {
+	q := `INSERT INTO email_templates (organization_id, email_type, locale, subject, body_html, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id, email_type, locale) DO UPDATE SET subject = EXCLUDED.subject,
+			body_html = EXCLUDED.body_html, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING ` + templateColumns
+	saved, err := scanTemplate(r.pool.QueryRow(ctx, q, t.OrganizationID, t.EmailType, t.Locale, t.Subject, t.BodyHTML, t.UpdatedBy))
+	if err != nil {
+		return err
+	}
+	*t = *saved
+	return nil
+}
+
+// DeleteTemplate removes an override (the default applies again). Returns false if there was none.
+func (r *Repository) DeleteTemplate(ctx context.Context, orgID uuid.UUID, emailType, locale string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM email_templates WHERE organization_id = $1 AND email_type = $2 AND locale = $3`,
+		orgID, emailType, locale)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
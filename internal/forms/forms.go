@@ -0,0 +1,313 @@
+// Package forms validates webinar registration forms: the organizer's field configuration
+// (models.FormFieldConfig) and attendee responses against it.
+package forms
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Field types.
+const (
+	TypeText     = "text"
+	TypeEmail    = "email"
+	TypeNumber   = "number"
+	TypeTextarea = "textarea"
+	TypeDropdown = "dropdown"
+	TypeCheckbox = "checkbox"
+	TypeRadio    = "radio"
+	TypeDate     = "date"
+	TypeFile     = "file"
+)
+
+// Field error codes.
+const (
+	CodeRequired    = "required"
+	CodeInvalid     = "invalid"
+	CodeTooShort    = "too_short"
+	CodeTooLong     = "too_long"
+	CodePattern     = "pattern"
+	CodeOutOfRange  = "out_of_range"
+	CodeNotAnOption = "not_an_option"
+)
+
+const (
+	// DefaultMaxLength caps answers of fields without max_length.
+	DefaultMaxLength = 5000
+	// MaxFields bounds the fields of one form.
+	MaxFields = 100
+	// maxPatternLength bounds organizer regular expressions.
+	maxPatternLength = 500
+	// dateLayout is the answer format of date fields.
+	dateLayout = "2006-01-02"
+)
+
+var (
+	fieldIDRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+	fieldTypes   = map[string]bool{
+		TypeText: true, TypeEmail: true, TypeNumber: true, TypeTextarea: true, TypeDropdown: true,
+		TypeCheckbox: true, TypeRadio: true, TypeDate: true, TypeFile: true,
+	}
+)
+
+// FieldError is a problem with one answer.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Options are the per-webinar inputs of response validation.
+type Options struct {
+	// FileURLPrefix is the URL prefix of files uploaded for this webinar (POST /webinars/:id/register/upload).
+	// Empty means uploads are not available and file answers are rejected.
+	FileURLPrefix string
+}
+
+// ParseConfig decodes a webinar's audience_form_config (empty or null = no fields).
+func ParseConfig(raw json.RawMessage) ([]models.FormFieldConfig, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var fields []models.FormFieldConfig
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// ValidateConfig checks an organizer's form configuration: unique IDs, known types, options for choice fields,
+// consistent rules, compilable patterns, and conditions that refer to an earlier field.
+func ValidateConfig(fields []models.FormFieldConfig) error {
+	if len(fields) > MaxFields {
+		return fmt.Errorf("at most %d form fields", MaxFields)
+	}
+	seen := make(map[string]bool, len(fields))
+	for i, f := range fields {
+		if !fieldIDRegex.MatchString(f.ID) {
+			return fmt.Errorf("field %d: id must be 1-64 letters, digits, '_', '.' or '-'", i+1)
+		}
+		if seen[f.ID] {
+			return fmt.Errorf("field %q: duplicate id", f.ID)
+		}
+		if !fieldTypes[f.Type] {
+			return fmt.Errorf("field %q: unknown type %q", f.ID, f.Type)
+		}
+		if (f.Type == TypeDropdown || f.Type == TypeRadio) && len(f.Options) == 0 {
+			return fmt.Errorf("field %q: %s needs options", f.ID, f.Type)
+		}
+		if f.MinLength != nil && *f.MinLength < 0 || f.MaxLength != nil && *f.MaxLength < 1 {
+			return fmt.Errorf("field %q: invalid length limits", f.ID)
+		}
+		if f.MinLength != nil && f.MaxLength != nil && *f.MinLength > *f.MaxLength {
+			return fmt.Errorf("field %q: min_length is greater than max_length", f.ID)
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("field %q: min is greater than max", f.ID)
+		}
+		if f.Pattern != "" {
+			if len(f.Pattern) > maxPatternLength {
+				return fmt.Errorf("field %q: pattern is too long", f.ID)
+			}
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				return fmt.Errorf("field %q: invalid pattern: %v", f.ID, err)
+			}
+		}
+		if f.ShowIf != nil {
+			if !seen[f.ShowIf.Field] {
+				return fmt.Errorf("field %q: show_if must refer to an earlier field", f.ID)
+			}
+			if f.ShowIf.Equals == "" && len(f.ShowIf.In) == 0 {
+				return fmt.Errorf("field %q: show_if needs equals or in", f.ID)
+			}
+		}
+		seen[f.ID] = true
+	}
+	return nil
+}
+
+// ValidateResponses checks answers against the form and returns the answers to store: trimmed, for visible
+// fields only (answers to unknown or hidden fields are dropped). A form without fields accepts any answers.
+func ValidateResponses(fields []models.FormFieldConfig, responses map[string]string, opts Options) (map[string]string, []FieldError) {
+	if len(fields) == 0 {
+		return responses, nil
+	}
+	clean := make(map[string]string, len(fields))
+	var errs []FieldError
+	for _, f := range fields {
+		if f.ShowIf != nil && !conditionHolds(f.ShowIf, clean) {
+			continue
+		}
+		v := strings.TrimSpace(responses[f.ID])
+		if v == "" || f.Type == TypeCheckbox && v == "false" && len(f.Options) == 0 {
+			if f.Required {
+				errs = append(errs, FieldError{Field: f.ID, Code: CodeRequired, Message: label(f) + " is required"})
+			}
+			continue
+		}
+		normalized, fe := validateAnswer(f, v, opts)
+		if fe != nil {
+			errs = append(errs, *fe)
+			continue
+		}
+		clean[f.ID] = normalized
+	}
+	return clean, errs
+}
+
+// validateAnswer checks a non-empty answer and returns its stored form.
+func validateAnswer(f models.FormFieldConfig, v string, opts Options) (string, *FieldError) {
+	fail := func(code, msg string) (string, *FieldError) {
+		return "", &FieldError{Field: f.ID, Code: code, Message: label(f) + " " + msg}
+	}
+	maxLength := DefaultMaxLength
+	if f.MaxLength != nil && *f.MaxLength < maxLength {
+		maxLength = *f.MaxLength
+	}
+	n := utf8.RuneCountInString(v)
+	if n > maxLength {
+		return fail(CodeTooLong, fmt.Sprintf("must be at most %d characters", maxLength))
+	}
+	if f.MinLength != nil && n < *f.MinLength {
+		return fail(CodeTooShort, fmt.Sprintf("must be at least %d characters", *f.MinLength))
+	}
+
+	switch f.Type {
+	case TypeEmail:
+		addr, err := mail.ParseAddress(v)
+		if err != nil || addr.Name != "" || addr.Address != v {
+			return fail(CodeInvalid, "must be an email address")
+		}
+	case TypeNumber:
+		x, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+			return fail(CodeInvalid, "must be a number")
+		}
+		if f.Min != nil && x < *f.Min || f.Max != nil && x > *f.Max {
+			return fail(CodeOutOfRange, "must be "+rangeText(f.Min, f.Max))
+		}
+	case TypeDate:
+		if _, err := time.Parse(dateLayout, v); err != nil {
+			return fail(CodeInvalid, "must be a date (YYYY-MM-DD)")
+		}
+	case TypeDropdown, TypeRadio:
+		if len(f.Options) > 0 && !contains(f.Options, v) {
+			return fail(CodeNotAnOption, "must be one of the listed options")
+		}
+	case TypeCheckbox:
+		if len(f.Options) == 0 {
+			if v != "true" {
+				return fail(CodeInvalid, "must be true or false")
+			}
+			return v, nil
+		}
+		// Multiple choice: a JSON array of option labels, or a single label.
+		var choices []string
+		if strings.HasPrefix(v, "[") {
+			if err := json.Unmarshal([]byte(v), &choices); err != nil {
+				return fail(CodeInvalid, "must be a list of options")
+			}
+		} else {
+			choices = []string{v}
+		}
+		if len(choices) == 0 && f.Required {
+			return "", &FieldError{Field: f.ID, Code: CodeRequired, Message: label(f) + " is required"}
+		}
+		for _, c := range choices {
+			if !contains(f.Options, c) {
+				return fail(CodeNotAnOption, "must only contain the listed options")
+			}
+		}
+		out, _ := json.Marshal(choices)
+		return string(out), nil
+	case TypeFile:
+		if !uploadedFile(v, opts.FileURLPrefix) {
+			return fail(CodeInvalid, "must be a file uploaded for this webinar")
+		}
+	}
+
+	if f.Pattern != "" && f.Type != TypeFile {
+		re, err := compilePattern(f.Pattern)
+		if err != nil || !re.MatchString(v) {
+			return fail(CodePattern, "has an invalid format")
+		}
+	}
+	return v, nil
+}
+
+// conditionHolds reports whether a show_if condition matches the (already validated) answers so far.
+// A condition on a hidden or unanswered field does not hold.
+func conditionHolds(cond *models.FormCondition, answers map[string]string) bool {
+	v, ok := answers[cond.Field]
+	if !ok {
+		return false
+	}
+	if cond.Equals != "" && v == cond.Equals {
+		return true
+	}
+	if contains(cond.In, v) {
+		return true
+	}
+	// Multiple-choice checkbox answers match when any choice does.
+	var choices []string
+	if strings.HasPrefix(v, "[") && json.Unmarshal([]byte(v), &choices) == nil {
+		for _, c := range choices {
+			if c == cond.Equals || contains(cond.In, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// uploadedFile reports whether u is an object under the webinar's upload prefix.
+func uploadedFile(u, prefix string) bool {
+	if prefix == "" || !strings.HasPrefix(u, prefix) || len(u) == len(prefix) {
+		return false
+	}
+	parsed, err := url.Parse(u)
+	return err == nil && parsed.Scheme == "https" && parsed.RawQuery == "" && parsed.Fragment == "" &&
+		!strings.Contains(parsed.Path, "..")
+}
+
+func compilePattern(p string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + p + `)$`)
+}
+
+func rangeText(min, max *float64) string {
+	format := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	switch {
+	case min != nil && max != nil:
+		return "between " + format(*min) + " and " + format(*max)
+	case min != nil:
+		return "at least " + format(*min)
+	default:
+		return "at most " + format(*max)
+	}
+}
+
+func label(f models.FormFieldConfig) string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return f.ID
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
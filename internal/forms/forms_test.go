@@ -0,0 +1,167 @@
+package forms
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// codes returns the error code of each field with an error.
+func codes(errs []FieldError) map[string]string {
+	out := make(map[string]string, len(errs))
+	for _, e := range errs {
+		out[e.Field] = e.Code
+	}
+	return out
+}
+
+func TestValidateResponsesRequired(t *testing.T) {
+	fields := []models.FormFieldConfig{
+		{ID: "company", Label: "Company", Type: TypeText, Required: true},
+		{ID: "terms", Type: TypeCheckbox, Required: true},
+		{ID: "topics", Type: TypeCheckbox, Options: []string{"go", "sql"}, Required: true},
+		{ID: "phone", Type: TypeText},
+	}
+	tests := []struct {
+		name      string
+		responses map[string]string
+		want      map[string]string // field -> error code
+		clean     map[string]string
+	}{
+		{
+			name:      "all answered",
+			responses: map[string]string{"company": " Acme ", "terms": "true", "topics": `["go"]`},
+			want:      map[string]string{},
+			clean:     map[string]string{"company": "Acme", "terms": "true", "topics": `["go"]`},
+		},
+		{
+			name:      "missing and blank",
+			responses: map[string]string{"company": "   "},
+			want:      map[string]string{"company": CodeRequired, "terms": CodeRequired, "topics": CodeRequired},
+			clean:     map[string]string{},
+		},
+		{
+			name:      "unchecked consent and empty choice list",
+			responses: map[string]string{"company": "Acme", "terms": "false", "topics": "[]"},
+			want:      map[string]string{"terms": CodeRequired, "topics": CodeRequired},
+			clean:     map[string]string{"company": "Acme"},
+		},
+		{
+			name:      "unknown answers dropped",
+			responses: map[string]string{"company": "Acme", "terms": "true", "topics": "sql", "extra": "x"},
+			want:      map[string]string{},
+			clean:     map[string]string{"company": "Acme", "terms": "true", "topics": `["sql"]`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clean, errs := ValidateResponses(fields, tt.responses, Options{})
+			if got := codes(errs); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("errors %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(clean, tt.clean) {
+				t.Fatalf("clean %v, want %v", clean, tt.clean)
+			}
+		})
+	}
+}
+
+func TestValidateResponsesPattern(t *testing.T) {
+	fields := []models.FormFieldConfig{
+		// The pattern must match the whole answer, even without anchors.
+		{ID: "ticket", Type: TypeText, Pattern: `[A-Z]{3}-\d{4}`},
+		{ID: "zip", Type: TypeText, Pattern: `\d{5}|\d{5}-\d{4}`},
+		{ID: "email", Type: TypeEmail, Pattern: `.+@example\.com`},
+	}
+	tests := []struct {
+		name     string
+		field    string
+		answer   string
+		wantCode string
+	}{
+		{name: "match", field: "ticket", answer: "ABC-1234"},
+		{name: "prefix only", field: "ticket", answer: "ABC-12345", wantCode: CodePattern},
+		{name: "embedded", field: "ticket", answer: "x ABC-1234", wantCode: CodePattern},
+		{name: "alternation anchored", field: "zip", answer: "12345-6789"},
+		{name: "alternation partial", field: "zip", answer: "123456", wantCode: CodePattern},
+		{name: "type checked first", field: "email", answer: "not-an-email", wantCode: CodeInvalid},
+		{name: "type and pattern", field: "email", answer: "ada@example.com"},
+		{name: "pattern after type", field: "email", answer: "ada@example.org", wantCode: CodePattern},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := ValidateResponses(fields, map[string]string{tt.field: tt.answer}, Options{})
+			if got := codes(errs)[tt.field]; got != tt.wantCode {
+				t.Fatalf("code %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestValidateResponsesShowIf(t *testing.T) {
+	fields := []models.FormFieldConfig{
+		{ID: "in_person", Type: TypeRadio, Options: []string{"yes", "no"}, Required: true},
+		{ID: "diet", Type: TypeDropdown, Options: []string{"none", "vegan"}, Required: true,
+			ShowIf: &models.FormCondition{Field: "in_person", Equals: "yes"}},
+		{ID: "allergies", Type: TypeText, Required: true,
+			ShowIf: &models.FormCondition{Field: "diet", In: []string{"vegan"}}},
+		{ID: "interests", Type: TypeCheckbox, Options: []string{"talks", "workshops"}},
+		{ID: "workshop", Type: TypeText, Required: true,
+			ShowIf: &models.FormCondition{Field: "interests", Equals: "workshops"}},
+	}
+	tests := []struct {
+		name      string
+		responses map[string]string
+		want      map[string]string
+		clean     map[string]string
+	}{
+		{
+			name:      "condition false: hidden fields skipped and dropped",
+			responses: map[string]string{"in_person": "no", "diet": "vegan", "allergies": "nuts"},
+			want:      map[string]string{},
+			clean:     map[string]string{"in_person": "no"},
+		},
+		{
+			name:      "condition true: shown field required",
+			responses: map[string]string{"in_person": "yes"},
+			want:      map[string]string{"diet": CodeRequired},
+			clean:     map[string]string{"in_person": "yes"},
+		},
+		{
+			name:      "chained conditions",
+			responses: map[string]string{"in_person": "yes", "diet": "vegan"},
+			want:      map[string]string{"allergies": CodeRequired},
+			clean:     map[string]string{"in_person": "yes", "diet": "vegan"},
+		},
+		{
+			name:      "invalid answer hides dependents",
+			responses: map[string]string{"in_person": "yes", "diet": "keto", "allergies": "nuts"},
+			want:      map[string]string{"diet": CodeNotAnOption},
+			clean:     map[string]string{"in_person": "yes"},
+		},
+		{
+			name:      "unanswered controlling field",
+			responses: map[string]string{"diet": "none"},
+			want:      map[string]string{"in_person": CodeRequired},
+			clean:     map[string]string{},
+		},
+		{
+			name:      "any checkbox choice matches",
+			responses: map[string]string{"in_person": "no", "interests": `["talks","workshops"]`},
+			want:      map[string]string{"workshop": CodeRequired},
+			clean:     map[string]string{"in_person": "no", "interests": `["talks","workshops"]`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clean, errs := ValidateResponses(fields, tt.responses, Options{})
+			if got := codes(errs); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("errors %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(clean, tt.clean) {
+				t.Fatalf("clean %v, want %v", clean, tt.clean)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Package imports registers attendees in bulk from CSV files: the organizer uploads a file and a column
+// mapping, the worker registers each row like POST /webinars/:id/register would, and every row's outcome
+// is kept for a downloadable report.
+package imports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aura-webinar/backend/internal/forms"
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+const (
+	// MaxFileSize bounds an uploaded CSV.
+	MaxFileSize = 5 << 20
+	// MaxRows bounds the data rows of one import.
+	MaxRows = 10000
+)
+
+// Header aliases recognized when the mapping leaves a column unset (compared case-insensitively).
+var (
+	emailAliases    = []string{"email", "e-mail", "email address", "mail"}
+	fullNameAliases = []string{"full_name", "full name", "name", "fullname"}
+	localeAliases   = []string{"locale", "language"}
+)
+
+// Columns are the indexes of mapped CSV columns (-1 = not present).
+type Columns struct {
+	Email    int
+	FullName int
+	Locale   int
+	Fields   map[string]int // form field id -> column
+}
+
+// Row is one data row of an import.
+type Row struct {
+	Line     int
+	Email    string
+	FullName string
+	Locale   string
+	Answers  map[string]string
+}
+
+// NewReader returns a CSV reader for an uploaded file: a UTF-8 BOM is skipped, and files whose header uses
+// semicolons but no commas (spreadsheet exports in many locales) are read as semicolon-separated.
+func NewReader(data []byte) *csv.Reader {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	if bytes.IndexByte(header, ';') >= 0 && bytes.IndexByte(header, ',') < 0 {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	return r
+}
+
+// Scan checks an uploaded file: a header that resolves the mapping, well-formed records, and at most
+// MaxRows data rows. Returns the number of data rows.
+func Scan(data []byte, fields []models.FormFieldConfig, m models.ImportMapping) (int, error) {
+	r := NewReader(data)
+	header, err := r.Read()
+	if err == io.EOF {
+		return 0, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if _, err := ResolveColumns(header, fields, m); err != nil {
+		return 0, err
+	}
+	n := 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid CSV: %v", err)
+		}
+		if blank(record) {
+			continue
+		}
+		if n++; n > MaxRows {
+			return 0, fmt.Errorf("at most %d rows per import", MaxRows)
+		}
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("the file has no rows")
+	}
+	return n, nil
+}
+
+// ResolveColumns finds the mapped columns in the header. Unmapped email and full name columns, and the
+// optional locale column, are found by common header names; without a fields mapping, form fields are
+// matched by id or label. File fields cannot be imported.
+func ResolveColumns(header []string, fields []models.FormFieldConfig, m models.ImportMapping) (*Columns, error) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if _, dup := index[key]; !dup && key != "" {
+			index[key] = i
+		}
+	}
+	find := func(name string, aliases []string) int {
+		if name != "" {
+			aliases = []string{name}
+		}
+		for _, a := range aliases {
+			if i, ok := index[strings.ToLower(strings.TrimSpace(a))]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	cols := &Columns{
+		Email:    find(m.Email, emailAliases),
+		FullName: find(m.FullName, fullNameAliases),
+		Locale:   find("", localeAliases),
+		Fields:   make(map[string]int),
+	}
+	if cols.Email < 0 {
+		return nil, fmt.Errorf("no email column (map it with mapping.email)")
+	}
+	if cols.FullName < 0 {
+		return nil, fmt.Errorf("no full name column (map it with mapping.full_name)")
+	}
+
+	byID := make(map[string]models.FormFieldConfig, len(fields))
+	for _, f := range fields {
+		byID[f.ID] = f
+	}
+	if m.Fields != nil {
+		for id, column := range m.Fields {
+			f, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("mapping.fields: %q is not a registration form field", id)
+			}
+			if f.Type == forms.TypeFile {
+				return nil, fmt.Errorf("mapping.fields: file field %q cannot be imported", id)
+			}
+			i := find(column, nil)
+			if i < 0 {
+				return nil, fmt.Errorf("mapping.fields: no column %q", column)
+			}
+			cols.Fields[id] = i
+		}
+		return cols, nil
+	}
+	for _, f := range fields {
+		if f.Type == forms.TypeFile {
+			continue
+		}
+		if i := find("", []string{f.ID, f.Label}); i >= 0 {
+			cols.Fields[f.ID] = i
+		}
+	}
+	return cols, nil
+}
+
+// Each reads the data rows of an uploaded file and calls fn for each, skipping blank lines.
+func Each(data []byte, cols *Columns, fn func(*Row) error) error {
+	r := NewReader(data)
+	if _, err := r.Read(); err != nil {
+		return err
+	}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if blank(record) {
+			continue
+		}
+		line, _ := r.FieldPos(0)
+		row := &Row{
+			Line:     line,
+			Email:    cell(record, cols.Email),
+			FullName: cell(record, cols.FullName),
+			Locale:   cell(record, cols.Locale),
+			Answers:  make(map[string]string, len(cols.Fields)),
+		}
+		for id, i := range cols.Fields {
+			if v := cell(record, i); v != "" {
+				row.Answers[id] = v
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// ImportableFields returns the form as validated for imports: file fields are never required, since
+// uploads cannot come from a CSV.
+func ImportableFields(fields []models.FormFieldConfig) []models.FormFieldConfig {
+	out := make([]models.FormFieldConfig, len(fields))
+	for i, f := range fields {
+		if f.Type == forms.TypeFile {
+			f.Required = false
+		}
+		out[i] = f
+	}
+	return out
+}
+
+func cell(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,227 @@
+package imports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/forms"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/queue"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// Handler serves bulk registration imports. Routes run after webinars.RequireWebinarOrgAccess and are
+// limited to callers who may manage the webinar (webinars.CanManage).
+type Handler struct {
+	repo        *Repository
+	webinarRepo webinars.Lookup
+	jobQueue    *queue.Queue
+	logger      *zap.Logger
+}
+
+// NewHandler creates an imports handler.
+func NewHandler(repo *Repository, webinarRepo webinars.Lookup, q *queue.Queue, logger *zap.Logger) *Handler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Handler{repo: repo, webinarRepo: webinarRepo, jobQueue: q, logger: logger}
+}
+
+// Create handles POST /webinars/:id/registrations/imports (multipart): file (CSV), optional mapping (JSON
+// models.ImportMapping) and send_confirmations (true to email join links). The file is checked here and
+// registered by the worker; poll GET .../imports/:importId for progress.
+func (h *Handler) Create(c *gin.Context) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return
+	}
+	webinarID := w.ID
+	if !acceptsImports(w) {
+		response.Conflict(c, "cannot import registrations for a "+w.Status+" webinar")
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "missing file (form field: file)")
+		return
+	}
+	if file.Size > MaxFileSize {
+		response.BadRequest(c, "file size exceeds 5MB limit")
+		return
+	}
+	var mapping models.ImportMapping
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			response.BadRequest(c, "mapping must be a JSON object with email, full_name and fields")
+			return
+		}
+	}
+	sendConfirmations, _ := strconv.ParseBool(c.PostForm("send_confirmations"))
+
+	rc, err := file.Open()
+	if err != nil {
+		response.Internal(c, "failed to read file")
+		return
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, MaxFileSize+1))
+	if err != nil {
+		response.Internal(c, "failed to read file")
+		return
+	}
+	if len(data) > MaxFileSize {
+		response.BadRequest(c, "file size exceeds 5MB limit")
+		return
+	}
+	fields, err := forms.ParseConfig(w.AudienceFormConfig)
+	if err != nil {
+		h.logger.Error("parse registration form failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
+		response.Internal(c, "failed to import registrations")
+		return
+	}
+	total, err := Scan(data, fields, mapping)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
+	imp := &models.RegistrationImport{
+		WebinarID:         webinarID,
+		Filename:          truncate(filepath.Base(file.Filename), 255),
+		Mapping:           mapping,
+		SendConfirmations: sendConfirmations,
+		TotalRows:         total,
+		CreatedBy:         &userID,
+	}
+	if err := h.repo.Create(c.Request.Context(), imp, data); err != nil {
+		h.logger.Error("create registration import failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
+		response.Internal(c, "failed to import registrations")
+		return
+	}
+	if _, err := h.jobQueue.Enqueue(c.Request.Context(), queue.JobTypeImport, queue.ImportPayload{ImportID: imp.ID, WebinarID: webinarID}, queue.Options{
+		DedupKey: imp.ID.String(),
+	}); err != nil {
+		h.logger.Error("enqueue registration import failed", zap.Error(err), zap.String("import_id", imp.ID.String()))
+		_ = h.repo.Fail(c.Request.Context(), imp.ID, "could not be queued")
+		response.Internal(c, "failed to queue import")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "registration_import.create", TargetType: "registration_import", TargetID: imp.ID.String(),
+		After: gin.H{"webinar_id": webinarID, "filename": imp.Filename, "rows": total, "send_confirmations": sendConfirmations}})
+	response.Created(c, imp)
+}
+
+// List handles GET /webinars/:id/registrations/imports.
+func (h *Handler) List(c *gin.Context) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return
+	}
+	list, err := h.repo.ListByWebinar(c.Request.Context(), w.ID)
+	if err != nil {
+		response.Internal(c, "failed to load imports")
+		return
+	}
+	if list == nil {
+		list = []*models.RegistrationImport{}
+	}
+	response.OK(c, list)
+}
+
+// Get handles GET /webinars/:id/registrations/imports/:importId: status and progress counters.
+func (h *Handler) Get(c *gin.Context) {
+	imp, ok := h.load(c)
+	if !ok {
+		return
+	}
+	response.OK(c, imp)
+}
+
+// Report handles GET /webinars/:id/registrations/imports/:importId/report: one CSV line per processed row
+// with its outcome (created, waitlisted, duplicate, invalid) and the reason for rejected rows.
+func (h *Handler) Report(c *gin.Context) {
+	imp, ok := h.load(c)
+	if !ok {
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="import-`+imp.ID.String()+`-report.csv"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	err := w.Write([]string{"Row", "Email", "Status", "Registration ID", "Message"})
+	if err == nil {
+		err = h.repo.StreamRows(c.Request.Context(), imp.ID, func(res *models.ImportRowResult) error {
+			regID := ""
+			if res.RegistrationID != nil {
+				regID = res.RegistrationID.String()
+			}
+			return w.Write([]string{strconv.Itoa(res.Row), csvSafe(res.Email), res.Status, regID, csvSafe(res.Message)})
+		})
+	}
+	w.Flush()
+	if err == nil {
+		err = w.Error()
+	}
+	if err != nil {
+		// Headers are sent; the client gets a truncated report.
+		h.logger.Error("registration import report failed", zap.Error(err), zap.String("import_id", imp.ID.String()))
+	}
+}
+
+// load returns the :importId import of the :id webinar, which the caller must manage. Writes the error
+// response on failure.
+func (h *Handler) load(c *gin.Context) (*models.RegistrationImport, bool) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return nil, false
+	}
+	importID, err := uuid.Parse(c.Param("importId"))
+	if err != nil {
+		response.BadRequest(c, "invalid import id")
+		return nil, false
+	}
+	imp, err := h.repo.GetByID(c.Request.Context(), importID)
+	if err != nil {
+		response.Internal(c, "failed to load import")
+		return nil, false
+	}
+	if imp == nil || imp.WebinarID != w.ID {
+		response.NotFound(c, "import not found")
+		return nil, false
+	}
+	return imp, true
+}
+
+// acceptsImports reports whether attendees can be pre-registered: drafts can be, unlike public registration.
+func acceptsImports(w *models.Webinar) bool {
+	return w.Status == models.WebinarStatusDraft || w.Published()
+}
+
+// csvSafe neutralizes values a spreadsheet would evaluate as a formula (rows come from an uploaded file).
+func csvSafe(v string) string {
+	if v != "" && (v[0] == '=' || v[0] == '+' || v[0] == '-' || v[0] == '@' || v[0] == '\t' || v[0] == '\r') {
+		return "'" + v
+	}
+	return v
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
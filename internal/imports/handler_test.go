@@ -0,0 +1,52 @@
+package imports
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// fakeWebinars serves webinars from memory.
+type fakeWebinars map[uuid.UUID]*models.Webinar
+
+func (f fakeWebinars) GetByID(ctx context.Context, id uuid.UUID) (*models.Webinar, error) {
+	return f[id], nil
+}
+
+func TestImportRoutesRefuseNonManager(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := &models.Webinar{ID: uuid.New(), CreatedBy: uuid.New(), Status: models.WebinarStatusDraft}
+	// Only the webinar lookup is wired: a request that reaches the imports repository would panic.
+	h := NewHandler(nil, fakeWebinars{w.ID: w}, nil, nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserID, uuid.New())
+		c.Set(middleware.ContextUserRole, string(models.RoleAdmin))
+	})
+	r.POST("/webinars/:id/registrations/imports", h.Create)
+	r.GET("/webinars/:id/registrations/imports", h.List)
+	r.GET("/webinars/:id/registrations/imports/:importId", h.Get)
+	r.GET("/webinars/:id/registrations/imports/:importId/report", h.Report)
+
+	base := "/webinars/" + w.ID.String() + "/registrations/imports"
+	item := base + "/" + uuid.NewString()
+	for _, req := range [][2]string{
+		{http.MethodPost, base},
+		{http.MethodGet, base},
+		{http.MethodGet, item},
+		{http.MethodGet, item + "/report"},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(req[0], req[1], nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s: status %d, want 403", req[0], req[1], rec.Code)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository handles registration_imports and registration_import_rows persistence.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates an imports repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const columns = `id, webinar_id, filename, mapping, send_confirmations, status, total_rows, processed_rows, created_count,
+	waitlisted_count, duplicate_count, invalid_count, COALESCE(error, ''), created_by, created_at, started_at, completed_at`
+
+func scanImport(row pgx.Row) (*models.RegistrationImport, error) {
+	var imp models.RegistrationImport
+	var mapping []byte
+	if err := row.Scan(&imp.ID, &imp.WebinarID, &imp.Filename, &mapping, &imp.SendConfirmations, &imp.Status, &imp.TotalRows,
+		&imp.ProcessedRows, &imp.Created, &imp.Waitlisted, &imp.Duplicates, &imp.Invalid, &imp.Error, &imp.CreatedBy,
+		&imp.CreatedAt, &imp.StartedAt, &imp.CompletedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mapping, &imp.Mapping); err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// Create stores a queued import with its CSV.
+func (r *Repository) Create(ctx context.Context, imp *models.RegistrationImport, source []byte) error {
+	mapping, err := json.Marshal(imp.Mapping)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO registration_imports (webinar_id, filename, source, mapping, send_confirmations, total_rows, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + columns
+	saved, err := scanImport(r.pool.QueryRow(ctx, q, imp.WebinarID, imp.Filename, source, mapping, imp.SendConfirmations, imp.TotalRows, imp.CreatedBy))
+	if err != nil {
+		return err
+	}
+	*imp = *saved
+	return nil
+}
+
+// GetByID returns an import, or nil, nil.
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*models.RegistrationImport, error) {
+	imp, err := scanImport(r.pool.QueryRow(ctx, `SELECT `+columns+` FROM registration_imports WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return imp, err
+}
+
+// ListByWebinar returns the webinar's imports, newest first.
+func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]*models.RegistrationImport, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+columns+` FROM registration_imports WHERE webinar_id = $1 ORDER BY created_at DESC`, webinarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*models.RegistrationImport
+	for rows.Next() {
+		imp, err := scanImport(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, imp)
+	}
+	return list, rows.Err()
+}
+
+// Source returns the uploaded CSV of an import.
+func (r *Repository) Source(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	var source []byte
+	err := r.pool.QueryRow(ctx, `SELECT source FROM registration_imports WHERE id = $1`, id).Scan(&source)
+	return source, err
+}
+
+// Start marks an import running (again, when a job is retried) and returns the last row already reported,
+// so processing resumes after it.
+func (r *Repository) Start(ctx context.Context, id uuid.UUID) (lastRow int, err error) {
+	const q = `UPDATE registration_imports SET status = 'running', error = NULL, completed_at = NULL, started_at = COALESCE(started_at, NOW())
+		WHERE id = $1
+		RETURNING (SELECT COALESCE(MAX(row_number), 0) FROM registration_import_rows WHERE import_id = $1)`
+	err = r.pool.QueryRow(ctx, q, id).Scan(&lastRow)
+	return lastRow, err
+}
+
+// RecordRow stores a row's outcome and advances the import's progress counters. A row reported
+// before (a retried job) is not counted twice.
+func (r *Repository) RecordRow(ctx context.Context, importID uuid.UUID, res *models.ImportRowResult) error {
+	const q = `WITH ins AS (
+			INSERT INTO registration_import_rows (import_id, row_number, email, status, registration_id, message)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (import_id, row_number) DO NOTHING
+			RETURNING status
+		)
+		UPDATE registration_imports SET
+			processed_rows = processed_rows + (SELECT COUNT(*) FROM ins),
+			created_count = created_count + (SELECT COUNT(*) FROM ins WHERE status = 'created'),
+			waitlisted_count = waitlisted_count + (SELECT COUNT(*) FROM ins WHERE status = 'waitlisted'),
+			duplicate_count = duplicate_count + (SELECT COUNT(*) FROM ins WHERE status = 'duplicate'),
+			invalid_count = invalid_count + (SELECT COUNT(*) FROM ins WHERE status = 'invalid')
+		WHERE id = $1`
+	_, err := r.pool.Exec(ctx, q, importID, res.Row, res.Email, res.Status, res.RegistrationID, res.Message)
+	return err
+}
+
+// Complete marks an import completed.
+func (r *Repository) Complete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE registration_imports SET status = 'completed', completed_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// Fail marks an import failed with a reason; rows processed so far stay registered and reported.
+func (r *Repository) Fail(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE registration_imports SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1`, id, reason)
+	return err
+}
+
+// StreamRows calls fn for each reported row of an import in file order.
+func (r *Repository) StreamRows(ctx context.Context, importID uuid.UUID, fn func(*models.ImportRowResult) error) error {
+	rows, err := r.pool.Query(ctx, `SELECT row_number, email, status, registration_id, message
+		FROM registration_import_rows WHERE import_id = $1 ORDER BY row_number`, importID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var res models.ImportRowResult
+		if err := rows.Scan(&res.Row, &res.Email, &res.Status, &res.RegistrationID, &res.Message); err != nil {
+			return err
+		}
+		if err := fn(&res); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
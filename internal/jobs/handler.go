@@ -0,0 +1,169 @@
+// Package jobs exposes admin endpoints for the background job queue (dead-letter inspection and replay).
+package jobs
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/pkg/queue"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// Handler handles job queue admin endpoints.
+type Handler struct {
+	q *queue.Queue
+}
+
+// NewHandler creates a jobs handler.
+func NewHandler(q *queue.Queue) *Handler {
+	return &Handler{q: q}
+}
+
+// parseFilter reads ?type= and ?webinar_id=.
+func parseFilter(c *gin.Context) (queue.DLQFilter, bool) {
+	f := queue.DLQFilter{Type: queue.JobType(c.Query("type"))}
+	if s := c.Query("webinar_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			response.BadRequest(c, "invalid webinar_id")
+			return f, false
+		}
+		f.WebinarID = id
+	}
+	return f, true
+}
+
+// ListDLQ handles GET /admin/jobs/dlq?type=&webinar_id=&offset=&limit=. Oldest first.
+func (h *Handler) ListDLQ(c *gin.Context) {
+	f, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	jobs, total, err := h.q.ListDLQ(c.Request.Context(), f, offset, limit)
+	if err != nil {
+		response.Internal(c, "failed to list dead-lettered jobs")
+		return
+	}
+	response.OK(c, gin.H{"items": jobs, "total": total, "offset": offset, "limit": limit})
+}
+
+// DLQStats handles GET /admin/jobs/dlq/stats. Returns DLQ depth, total and per job type.
+func (h *Handler) DLQStats(c *gin.Context) {
+	stats, err := h.q.DLQStats(c.Request.Context())
+	if err != nil {
+		response.Internal(c, "failed to load dlq stats")
+		return
+	}
+	response.OK(c, stats)
+}
+
+// GetDLQ handles GET /admin/jobs/dlq/:jobId. Returns the job with its last error and failure history.
+func (h *Handler) GetDLQ(c *gin.Context) {
+	job, err := h.q.GetDLQ(c.Request.Context(), c.Param("jobId"))
+	if err != nil {
+		response.Internal(c, "failed to load job")
+		return
+	}
+	if job == nil {
+		response.NotFound(c, "job not in dead-letter queue")
+		return
+	}
+	response.OK(c, job)
+}
+
+// ReplayDLQ handles POST /admin/jobs/dlq/:jobId/replay. Re-queues the job with fresh attempts.
+func (h *Handler) ReplayDLQ(c *gin.Context) {
+	jobID := c.Param("jobId")
+	ok, err := h.q.ReplayDLQ(c.Request.Context(), jobID)
+	if err != nil {
+		response.Internal(c, "failed to replay job")
+		return
+	}
+	if !ok {
+		response.NotFound(c, "job not in dead-letter queue")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "job.replay", TargetType: "job", TargetID: jobID})
+	response.OK(c, gin.H{"replayed": 1})
+}
+
+// ReplayAllDLQ handles POST /admin/jobs/dlq/replay?type=&webinar_id=. Re-queues every matching job.
+func (h *Handler) ReplayAllDLQ(c *gin.Context) {
+	f, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	n, err := h.q.ReplayAllDLQ(c.Request.Context(), f)
+	if err != nil {
+		response.Internal(c, "failed to replay jobs")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "job.replay_bulk", TargetType: "job", After: filterSummary(f, n)})
+	response.OK(c, gin.H{"replayed": n})
+}
+
+// DeleteDLQ handles DELETE /admin/jobs/dlq/:jobId.
+func (h *Handler) DeleteDLQ(c *gin.Context) {
+	jobID := c.Param("jobId")
+	ok, err := h.q.DeleteDLQ(c.Request.Context(), jobID)
+	if err != nil {
+		response.Internal(c, "failed to delete job")
+		return
+	}
+	if !ok {
+		response.NotFound(c, "job not in dead-letter queue")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "job.purge", TargetType: "job", TargetID: jobID})
+	response.NoContent(c)
+}
+
+// PurgeDLQ handles DELETE /admin/jobs/dlq?type=&webinar_id=. Without a filter the whole DLQ is purged,
+// so that case requires ?all=true.
+func (h *Handler) PurgeDLQ(c *gin.Context) {
+	f, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+	if f == (queue.DLQFilter{}) && c.Query("all") != "true" {
+		response.BadRequest(c, "pass type or webinar_id, or all=true to purge everything")
+		return
+	}
+	n, err := h.q.PurgeDLQ(c.Request.Context(), f)
+	if err != nil {
+		response.Internal(c, "failed to purge jobs")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "job.purge_bulk", TargetType: "job", After: filterSummary(f, n)})
+	response.OK(c, gin.H{"purged": n})
+}
+
+func filterSummary(f queue.DLQFilter, n int) gin.H {
+	out := gin.H{"count": n}
+	if f.Type != "" {
+		out["type"] = f.Type
+	}
+	if f.WebinarID != uuid.Nil {
+		out["webinar_id"] = f.WebinarID
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+// Package lifecycle carries out what a webinar's status change implies beyond the webinar row: stream
+// sessions, live notifications to connected attendees, analytics and recording follow-up when it ends, and
+// cancellation emails and refunds.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/payments"
+	"github.com/aura-webinar/backend/internal/realtime"
+	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/internal/streams"
+	"github.com/aura-webinar/backend/pkg/ical"
+	"github.com/aura-webinar/backend/pkg/queue"
+)
+
+// EventStatus is the WebSocket event sent to a webinar's clients when its status changes.
+const EventStatus = "webinar_status"
+
+// recordingTimeout bounds the upload of an in-app recording after a webinar ends.
+const recordingTimeout = 30 * time.Minute
+
+// RecordingFinisher stops a webinar's in-app recording and stores it (recordings.Handler).
+type RecordingFinisher interface {
+	FinishRecording(ctx context.Context, webinarID uuid.UUID) (*models.Recording, error)
+}
+
+// Effects implements webinars.LifecycleEffects.
+type Effects struct {
+	streamRepo  *streams.Repository
+	regRepo     *registrations.Repository
+	paymentRepo *payments.Repository
+	hub         *realtime.Hub
+	jobQueue    *queue.Queue
+	recordings  RecordingFinisher
+	logger      *zap.Logger
+}
+
+// NewEffects creates the webinar lifecycle effects.
+func NewEffects(
+	streamRepo *streams.Repository,
+	regRepo *registrations.Repository,
+	paymentRepo *payments.Repository,
+	hub *realtime.Hub,
+	q *queue.Queue,
+	logger *zap.Logger,
+) *Effects {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Effects{streamRepo: streamRepo, regRepo: regRepo, paymentRepo: paymentRepo, hub: hub, jobQueue: q, logger: logger}
+}
+
+// SetRecordings stops and uploads a running in-app recording when a webinar ends.
+func (e *Effects) SetRecordings(r RecordingFinisher) {
+	e.recordings = r
+}
+
+// WentLive opens the webinar's stream session and tells connected clients.
+func (e *Effects) WentLive(ctx context.Context, w *models.Webinar) error {
+	if _, err := e.streamRepo.GetOrCreateActive(ctx, w.ID); err != nil {
+		return fmt.Errorf("open stream session: %w", err)
+	}
+	e.notify(w)
+	return nil
+}
+
+// Ended closes the stream session and queues its analytics snapshot, tells connected clients, and stops and
+// uploads a running in-app recording in the background.
+func (e *Effects) Ended(ctx context.Context, w *models.Webinar) error {
+	e.notify(w)
+	if e.recordings != nil {
+		go func(webinarID uuid.UUID) {
+			ctx, cancel := context.WithTimeout(context.Background(), recordingTimeout)
+			defer cancel()
+			rec, err := e.recordings.FinishRecording(ctx, webinarID)
+			if err != nil {
+				e.logger.Error("finish recording failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
+				return
+			}
+			if rec != nil {
+				e.logger.Info("recording finished with webinar", zap.String("recording_id", rec.ID.String()))
+			}
+		}(w.ID)
+	}
+	session, err := e.streamRepo.EndActive(ctx, w.ID)
+	if err != nil {
+		return fmt.Errorf("close stream session: %w", err)
+	}
+	if session == nil {
+		return nil
+	}
+	if err := e.jobQueue.EnqueueAnalytics(ctx, queue.AnalyticsPayload{WebinarID: w.ID, StreamSessionID: session.ID}); err != nil {
+		return fmt.Errorf("enqueue analytics: %w", err)
+	}
+	return nil
+}
+
+// Cancelled emails every registrant, marks completed ticket payments for refund and tells connected
+// clients. Returns how many emails were queued and payments marked.
+func (e *Effects) Cancelled(ctx context.Context, w *models.Webinar) (notified int, refunds int64, err error) {
+	e.notify(w)
+	if refunds, err = e.paymentRepo.RequestRefunds(ctx, w.ID); err != nil {
+		return 0, 0, fmt.Errorf("request refunds: %w", err)
+	}
+	regs, err := e.regRepo.ListByWebinar(ctx, w.ID)
+	if err != nil {
+		return 0, refunds, fmt.Errorf("list registrations: %w", err)
+	}
+	for _, reg := range regs {
+		payload := queue.EmailPayload{
+			EmailType:       models.EmailTypeWebinarCancelled,
+			WebinarID:       w.ID,
+			RegistrationID:  reg.ID,
+			RecipientEmail:  reg.Email,
+			RecipientName:   reg.FullName,
+			WebinarTitle:    w.Title,
+			WebinarStartsAt: w.StartsAt.Format(time.RFC3339),
+			Locale:          reg.Locale,
+			CalendarMethod:  ical.MethodCancel,
+		}
+		_, err := e.jobQueue.Enqueue(ctx, queue.JobTypeEmail, payload, queue.Options{
+			DedupKey: "cancelled:" + reg.ID.String(),
+			DedupTTL: 24 * time.Hour,
+		})
+		if err != nil && !errors.Is(err, queue.ErrDuplicate) {
+			return notified, refunds, fmt.Errorf("enqueue cancellation email: %w", err)
+		}
+		notified++
+	}
+	return notified, refunds, nil
+}
+
+func (e *Effects) notify(w *models.Webinar) {
+	e.hub.BroadcastToWebinarAndPublish(w.ID, EventStatus, map[string]string{"webinar_id": w.ID.String(), "status": w.Status})
+}
@@ -24,6 +24,10 @@ func CORS(allowedOrigins string) gin.HandlerFunc {
 			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 			c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			c.Header("Access-Control-Max-Age", "86400")
+			if allowOrigin != "*" {
+				// Listed origins may send cookies (the SSO state cookie); bearer tokens remain the credential.
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
 		}
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent) // 204
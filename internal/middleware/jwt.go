@@ -15,6 +15,8 @@ const (
 	ContextUserRole = "user_role"
 	// ContextUserEmail is the key for user email in gin context.
 	ContextUserEmail = "user_email"
+	// ContextOrganizationID is the key for the organization resolved for the request (e.g. by webinar org access).
+	ContextOrganizationID = "organization_id"
 )
 
 // JWT returns a middleware that validates JWT and sets user claims in context.
@@ -21,12 +21,20 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 		statusCode = c.Writer.Status()
 		latency := time.Since(start)
 
-		logger.Info("request",
+		fields := []zap.Field{
 			zap.Int("status", statusCode),
 			zap.Duration("latency", latency),
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.String("client_ip", clientIP),
-		)
+		}
+		// Attribute integration traffic to the API key that made it
+		if keyID, ok := c.Get(ContextAPIKeyID); ok {
+			fields = append(fields, zap.Any("api_key_id", keyID))
+			if orgID, ok := c.Get(ContextAPIKeyOrganizationID); ok {
+				fields = append(fields, zap.Any("organization_id", orgID))
+			}
+		}
+		logger.Info("request", fields...)
 	}
 }
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// ContextOrganizationRole is the key for the caller's role in the ContextOrganizationID organization.
+const ContextOrganizationRole = "organization_role"
+
+// OrgRoleLookup returns a user's role in an organization, or "" if not a member (organizations.Repository).
+type OrgRoleLookup interface {
+	GetUserRole(ctx context.Context, orgID, userID uuid.UUID) (string, error)
+}
+
+// OrgRole returns the caller's role in orgID, or "" if none. An API key acts as its creator, and only in
+// its own organization.
+func OrgRole(c *gin.Context, orgRepo OrgRoleLookup, orgID uuid.UUID) (string, error) {
+	if keyOrgID, ok := APIKeyOrganizationID(c); ok && keyOrgID != orgID {
+		return "", nil
+	}
+	return orgRepo.GetUserRole(c.Request.Context(), orgID, c.MustGet(ContextUserID).(uuid.UUID))
+}
+
+// AuthorizeOrgRole requires the caller to hold one of roles (e.g. models.OrgManagerRoles) in orgID, and
+// sets ContextOrganizationID and ContextOrganizationRole. Writes the error response on failure.
+func AuthorizeOrgRole(c *gin.Context, orgRepo OrgRoleLookup, orgID uuid.UUID, roles ...string) bool {
+	role, err := OrgRole(c, orgRepo, orgID)
+	if err != nil {
+		response.Internal(c, "failed to check organization role")
+		return false
+	}
+	if !models.HasOrgRole(role, roles) {
+		response.Forbidden(c, "requires organization role "+strings.Join(roles, " or "))
+		return false
+	}
+	c.Set(ContextOrganizationID, orgID)
+	c.Set(ContextOrganizationRole, role)
+	return true
+}
+
+// RequireOrgRole parses the :id organization and requires AuthorizeOrgRole. Writes the error response on
+// failure.
+func RequireOrgRole(c *gin.Context, orgRepo OrgRoleLookup, roles ...string) (uuid.UUID, bool) {
+	orgID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid organization id")
+		return uuid.Nil, false
+	}
+	if !AuthorizeOrgRole(c, orgRepo, orgID, roles...) {
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
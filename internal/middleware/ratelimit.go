@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/pkg/ratelimit"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// maxKeyBodyBytes bounds how much of a request body KeyByJSONField will read.
+const maxKeyBodyBytes = 64 << 10
+
+// KeyFunc extracts the rate limit key from a request. An empty key skips limiting for that request.
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP keys by client IP.
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUserID keys by the authenticated user (call after JWT); falls back to client IP.
+func KeyByUserID(c *gin.Context) string {
+	if v, ok := c.Get(ContextUserID); ok {
+		if id, ok := v.(uuid.UUID); ok {
+			return "user:" + id.String()
+		}
+	}
+	return KeyByIP(c)
+}
+
+// KeyByParam keys by a route parameter (e.g. "id" for per-webinar limits).
+func KeyByParam(name string) KeyFunc {
+	return func(c *gin.Context) string {
+		if v := c.Param(name); v != "" {
+			return name + ":" + v
+		}
+		return ""
+	}
+}
+
+// KeyByJSONField keys by a string field of the JSON body (e.g. "email"), lowercased.
+// The body is restored so the handler can still bind it.
+func KeyByJSONField(field string) KeyFunc {
+	return func(c *gin.Context) string {
+		if c.Request.Body == nil {
+			return ""
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxKeyBodyBytes))
+		if err != nil {
+			return ""
+		}
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		var m map[string]interface{}
+		if json.Unmarshal(body, &m) != nil {
+			return ""
+		}
+		v, _ := m[field].(string)
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" {
+			return ""
+		}
+		return field + ":" + v
+	}
+}
+
+// RateLimit returns a middleware that allows at most rule.Limit requests per rule.Window for each key.
+// Sets RateLimit-Limit/Remaining/Reset headers and answers 429 with Retry-After when exceeded.
+// If Redis is unavailable the request is let through (fail open) and the error is logged.
+func RateLimit(limiter *ratelimit.Limiter, name string, rule ratelimit.Rule, key KeyFunc, logger *zap.Logger) gin.HandlerFunc {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return func(c *gin.Context) {
+		if limiter == nil || !rule.Enabled() {
+			c.Next()
+			return
+		}
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+		res, err := limiter.Allow(c.Request.Context(), name+":"+k, rule)
+		if err != nil {
+			logger.Warn("rate limit check failed", zap.String("rule", name), zap.Error(err))
+			c.Next()
+			return
+		}
+		resetSecs := int(math.Ceil(res.Reset.Seconds()))
+		setRateLimitHeaders(c, res, resetSecs)
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(resetSecs))
+			logger.Info("rate limited", zap.String("rule", name), zap.String("key", k), zap.String("path", c.FullPath()))
+			response.TooManyRequests(c, "too many requests, please retry later")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders writes RateLimit-* headers; with several limiters on one route the most restrictive wins.
+func setRateLimitHeaders(c *gin.Context, res ratelimit.Result, resetSecs int) {
+	if prev := c.Writer.Header().Get("RateLimit-Remaining"); prev != "" {
+		if n, err := strconv.Atoi(prev); err == nil && n <= res.Remaining {
+			return
+		}
+	}
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(resetSecs))
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
 	"github.com/aura-webinar/backend/pkg/response"
 )
 
@@ -27,3 +29,30 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireOperator returns a middleware that allows only the platform operators with the given user IDs,
+// signed in with a JWT (API keys are refused). With no operators every request is refused.
+func RequireOperator(userIDs []string) gin.HandlerFunc {
+	allowed := make(map[uuid.UUID]struct{}, len(userIDs))
+	for _, s := range userIDs {
+		if id, err := uuid.Parse(s); err == nil && id != uuid.Nil {
+			allowed[id] = struct{}{}
+		}
+	}
+	return func(c *gin.Context) {
+		v, ok := c.Get(ContextUserID)
+		if !ok {
+			response.Unauthorized(c, "missing user context")
+			c.Abort()
+			return
+		}
+		userID, _ := v.(uuid.UUID)
+		_, viaAPIKey := APIKeyOrganizationID(c)
+		if _, ok := allowed[userID]; !ok || viaAPIKey {
+			response.Forbidden(c, "insufficient permissions")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API key scopes. A key can only call routes mapped to one of its scopes.
+const (
+	ScopeWebinarsRead       = "webinars:read"
+	ScopeWebinarsWrite      = "webinars:write"
+	ScopeRegistrationsRead  = "registrations:read"
+	ScopeRegistrationsWrite = "registrations:write"
+	ScopeAnalyticsRead      = "analytics:read"
+)
+
+// APIKeyScopes lists every scope a key may be granted.
+var APIKeyScopes = []string{ScopeWebinarsRead, ScopeWebinarsWrite, ScopeRegistrationsRead, ScopeRegistrationsWrite, ScopeAnalyticsRead}
+
+// APIKey is an organization-scoped credential for server-to-server integrations.
+// The secret is shown once at creation; only its hash is stored.
+type APIKey struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	KeyHash        string     `json:"-"`
+	Scopes         []string   `json:"scopes"`
+	CreatedBy      uuid.UUID  `json:"created_by"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog is one recorded administrative action. Diff maps each changed field to {"before": ..., "after": ...}.
+type AuditLog struct {
+	ID             uuid.UUID       `json:"id"`
+	OrganizationID *uuid.UUID      `json:"organization_id,omitempty"`
+	ActorUserID    *uuid.UUID      `json:"actor_user_id,omitempty"`
+	ActorAPIKeyID  *uuid.UUID      `json:"actor_api_key_id,omitempty"`
+	Action         string          `json:"action"`
+	TargetType     string          `json:"target_type"`
+	TargetID       string          `json:"target_id"`
+	Diff           json.RawMessage `json:"diff,omitempty"`
+	IPAddress      string          `json:"ip_address"`
+	UserAgent      string          `json:"user_agent"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
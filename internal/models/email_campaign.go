@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CampaignStatus for an email campaign.
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusSending   = "sending"
+	CampaignStatusSent      = "sent"
+	CampaignStatusCancelled = "cancelled"
+)
+
+// Campaign audiences.
+const (
+	CampaignAudienceRegistered = "registered" // every registrant
+	CampaignAudienceAttended   = "attended"   // joined the live session
+	CampaignAudienceNoShow     = "no_show"    // registered but never joined
+)
+
+// EmailCampaign is an organizer email to a segment of a webinar's registrants.
+type EmailCampaign struct {
+	ID             uuid.UUID       `json:"id"`
+	WebinarID      uuid.UUID       `json:"webinar_id"`
+	Name           string          `json:"name"`
+	Subject        string          `json:"subject"`   // text/template, same data as email templates
+	BodyHTML       string          `json:"body_html"` // html/template inside the organization layout
+	Segment        CampaignSegment `json:"segment"`
+	Status         string          `json:"status"`
+	ScheduledAt    *time.Time      `json:"scheduled_at,omitempty"`
+	StartedAt      *time.Time      `json:"started_at,omitempty"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+	RecipientCount int             `json:"recipient_count"`
+	CreatedBy      *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// CampaignSegment selects recipients among a webinar's registrants. All conditions must match.
+type CampaignSegment struct {
+	Audience        string                `json:"audience"`                    // registered, attended or no_show
+	MinWatchMinutes int                   `json:"min_watch_minutes,omitempty"` // total live watch time
+	Fields          []CampaignFieldFilter `json:"fields,omitempty"`
+}
+
+// CampaignFieldFilter matches a registration form response (extra_data), case-insensitively.
+type CampaignFieldFilter struct {
+	Field  string   `json:"field"`            // form field id
+	Op     string   `json:"op"`               // eq, neq, contains, in, present, absent
+	Value  string   `json:"value,omitempty"`  // eq, neq, contains
+	Values []string `json:"values,omitempty"` // in
+}
+
+// CampaignStats counts a campaign's recipients and its email log statuses.
+type CampaignStats struct {
+	Recipients int            `json:"recipients"`
+	Enqueued   int            `json:"enqueued"`
+	ByStatus   map[string]int `json:"by_status"` // email_logs status -> count
+}
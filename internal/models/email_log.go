@@ -16,6 +16,9 @@ const (
 	EmailTypeReminder10m              = "reminder_10m"
 	EmailTypeThankYou                 = "thank_you"
 	EmailTypeReplayAccess             = "replay_access"
+	EmailTypeWebinarCancelled         = "webinar_cancelled"
+	EmailTypeWebinarRescheduled       = "webinar_rescheduled"
+	EmailTypeCampaign                 = "campaign"
 )
 
 // EmailLogStatus for delivery.
@@ -23,6 +26,11 @@ const (
 	EmailLogStatusPending = "pending"
 	EmailLogStatusSent    = "sent"
 	EmailLogStatusFailed  = "failed"
+	// EmailLogStatusSuppressed: not sent because the address is on the suppression list.
+	EmailLogStatusSuppressed = "suppressed"
+	// EmailLogStatusBounced and EmailLogStatusComplained are set from provider events after sending.
+	EmailLogStatusBounced    = "bounced"
+	EmailLogStatusComplained = "complained"
 )
 
 // EmailLog records sent automation emails.
@@ -36,5 +44,7 @@ type EmailLog struct {
 	Status         string     `json:"status"`
 	SentAt         *time.Time `json:"sent_at,omitempty"`
 	ErrorMessage   string     `json:"error_message,omitempty"`
+	MessageID      string     `json:"message_id,omitempty"` // provider message ID, set when sent
+	CampaignID     *uuid.UUID `json:"campaign_id,omitempty"`
 	CreatedAt      time.Time  `json:"created_at"`
 }
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SuppressionReason says why an address is suppressed.
+const (
+	SuppressionReasonBounce      = "bounce"
+	SuppressionReasonComplaint   = "complaint"
+	SuppressionReasonUnsubscribe = "unsubscribe"
+	SuppressionReasonManual      = "manual"
+)
+
+// EmailSuppression is an address that must not be emailed. OrganizationID nil applies to every organization
+// (hard bounces); otherwise only that organization's webinar emails are skipped.
+type EmailSuppression struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	Email          string     `json:"email"`
+	Reason         string     `json:"reason"`
+	Detail         string     `json:"detail,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
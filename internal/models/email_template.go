@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailBranding is an organization's look for all emails about its webinars.
+type EmailBranding struct {
+	OrganizationID   uuid.UUID `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	LogoURL          string    `json:"logo_url,omitempty"`
+	PrimaryColor     string    `json:"primary_color,omitempty"` // #rrggbb
+	FooterText       string    `json:"footer_text,omitempty"`
+	DefaultLocale    string    `json:"default_locale"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// EmailTemplate overrides the default template of one email type and locale for an organization.
+// Subject is a text/template; BodyHTML is an html/template rendered inside the branded layout.
+type EmailTemplate struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	EmailType      string     `json:"email_type"`
+	Locale         string     `json:"locale"`
+	Subject        string     `json:"subject"`
+	BodyHTML       string     `json:"body_html"`
+	UpdatedBy      *uuid.UUID `json:"updated_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -22,6 +22,26 @@ const (
 	OrgRoleModerator   = "moderator"
 )
 
+// Organization role sets, for middleware.RequireOrgRole.
+var (
+	// OrgOwnerRoles may manage the organization itself: domains and API keys.
+	OrgOwnerRoles = []string{OrgRoleOwner}
+	// OrgManagerRoles may manage the organization's webinars, series and settings.
+	OrgManagerRoles = []string{OrgRoleOwner, OrgRoleEventManager}
+	// OrgMemberRoles may see the organization and reach its webinars.
+	OrgMemberRoles = []string{OrgRoleOwner, OrgRoleEventManager, OrgRoleModerator}
+)
+
+// HasOrgRole reports whether role is one of roles.
+func HasOrgRole(role string, roles []string) bool {
+	for _, r := range roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
 // OrganizationUser links a user to an organization with a role.
 type OrganizationUser struct {
 	ID             uuid.UUID `json:"id"`
@@ -31,3 +51,14 @@ type OrganizationUser struct {
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
+
+// OrganizationDomain is an email domain claimed by an organization (used to match SSO users).
+type OrganizationDomain struct {
+	ID                uuid.UUID  `json:"id"`
+	OrganizationID    uuid.UUID  `json:"organization_id"`
+	Domain            string     `json:"domain"`
+	VerificationToken string     `json:"verification_token,omitempty"`
+	DefaultRole       string     `json:"default_role"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
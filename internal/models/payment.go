@@ -17,6 +17,7 @@ const (
 	PaymentStatusPending           = "pending"
 	PaymentStatusCompleted         = "completed"
 	PaymentStatusFailed            = "failed"
+	PaymentStatusRefundPending     = "refund_pending" // the webinar was cancelled; the provider refund is not issued yet
 	PaymentStatusRefunded          = "refunded"
 	PaymentStatusPartiallyRefunded = "partially_refunded"
 )
@@ -14,6 +14,7 @@ type Registration struct {
 	Email      string          `json:"email"`
 	FullName   string          `json:"full_name"`
 	ExtraData  json.RawMessage `json:"extra_data,omitempty"`
+	Locale     string          `json:"locale,omitempty"` // picks the email template variant
 	AttendedAt *time.Time      `json:"attended_at,omitempty"`
 	CreatedAt  time.Time       `json:"created_at"`
 	UpdatedAt  time.Time       `json:"updated_at"`
@@ -25,6 +26,32 @@ type RegistrationToken struct {
 	RegistrationID uuid.UUID  `json:"registration_id"`
 	Token          string     `json:"token"`
 	ExpiresAt      time.Time  `json:"expires_at"`
-	UsedAt         *time.Time `json:"used_at,omitempty"`
+	UsedAt         *time.Time `json:"used_at,omitempty"`    // first exchange; links stay reusable
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"` // revoked tokens are treated as unknown
 	CreatedAt      time.Time  `json:"created_at"`
 }
+
+// RegistrationTokenUse records one exchange of a join link for a session (which device used the link).
+type RegistrationTokenUse struct {
+	ID             uuid.UUID  `json:"id"`
+	TokenID        uuid.UUID  `json:"token_id"`
+	RegistrationID uuid.UUID  `json:"registration_id"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+	IPAddress      string     `json:"ip_address"`
+	UserAgent      string     `json:"user_agent"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// What happens when an attendee opens more live sessions than the join policy allows.
+const (
+	JoinLimitNewestWins = "newest_wins" // the oldest sessions are disconnected
+	JoinLimitReject     = "reject"      // the new connection is refused
+)
+
+// JoinPolicy limits an attendee's concurrent live sessions in a webinar. Nil fields use the server defaults.
+type JoinPolicy struct {
+	WebinarID   uuid.UUID `json:"webinar_id"`
+	DeviceLimit *int      `json:"device_limit"` // 0 = unlimited
+	OnLimit     *string   `json:"on_limit"`     // JoinLimitNewestWins or JoinLimitReject
+	UpdatedAt   time.Time `json:"updated_at"`
+}
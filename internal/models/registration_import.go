@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportStatus for a registration import.
+const (
+	ImportStatusQueued    = "queued"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// ImportRowStatus is the outcome of one imported row.
+const (
+	ImportRowCreated    = "created"    // registered (and confirmed by email when requested)
+	ImportRowWaitlisted = "waitlisted" // the webinar was full
+	ImportRowDuplicate  = "duplicate"  // already registered, or repeated earlier in the file
+	ImportRowInvalid    = "invalid"    // missing email or name, or form answers that fail validation
+)
+
+// RegistrationImport is a CSV of attendees registered in bulk by the worker.
+type RegistrationImport struct {
+	ID                uuid.UUID     `json:"id"`
+	WebinarID         uuid.UUID     `json:"webinar_id"`
+	Filename          string        `json:"filename"`
+	Mapping           ImportMapping `json:"mapping"`
+	SendConfirmations bool          `json:"send_confirmations"`
+	Status            string        `json:"status"`
+	TotalRows         int           `json:"total_rows"`
+	ProcessedRows     int           `json:"processed_rows"`
+	Created           int           `json:"created"`
+	Waitlisted        int           `json:"waitlisted"`
+	Duplicates        int           `json:"duplicates"`
+	Invalid           int           `json:"invalid"`
+	Error             string        `json:"error,omitempty"`
+	CreatedBy         *uuid.UUID    `json:"created_by,omitempty"`
+	CreatedAt         time.Time     `json:"created_at"`
+	StartedAt         *time.Time    `json:"started_at,omitempty"`
+	CompletedAt       *time.Time    `json:"completed_at,omitempty"`
+}
+
+// ImportMapping maps CSV column headers to registration data.
+type ImportMapping struct {
+	Email    string            `json:"email"`
+	FullName string            `json:"full_name"`
+	Fields   map[string]string `json:"fields,omitempty"` // form field id -> column header
+}
+
+// ImportRowResult is one line of an import's report.
+type ImportRowResult struct {
+	Row            int        `json:"row"` // line in the file; the header is line 1
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	RegistrationID *uuid.UUID `json:"registration_id,omitempty"`
+	Message        string     `json:"message,omitempty"`
+}
@@ -63,3 +63,14 @@ func (u *User) ToPublic() UserPublic {
 		CreatedAt:     u.CreatedAt,
 	}
 }
+
+// UserIdentity links a user to an external identity provider account (OIDC issuer + subject).
+type UserIdentity struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Provider    string    `json:"provider"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -13,7 +13,64 @@ type FormFieldConfig struct {
 	Label    string   `json:"label"`    // display label, e.g. "Company name"
 	Type     string   `json:"type"`     // "text", "email", "number", "textarea", "dropdown", "checkbox", "radio", "date", "file"
 	Required bool     `json:"required"`
-	Options  []string `json:"options,omitempty"` // for dropdown and radio: option labels
+	Options  []string `json:"options,omitempty"` // for dropdown and radio: option labels; checkbox: multiple choice
+
+	// Optional rules, enforced when a registration is submitted.
+	MinLength *int           `json:"min_length,omitempty"` // text, textarea, email: characters
+	MaxLength *int           `json:"max_length,omitempty"`
+	Pattern   string         `json:"pattern,omitempty"` // regular expression (RE2) the whole answer must match
+	Min       *float64       `json:"min,omitempty"`     // number: inclusive range
+	Max       *float64       `json:"max,omitempty"`
+	ShowIf    *FormCondition `json:"show_if,omitempty"` // field is shown (and validated) only when the condition holds
+}
+
+// FormCondition makes a form field conditional on the answer to an earlier field,
+// e.g. {"field": "attending_in_person", "equals": "yes"}.
+type FormCondition struct {
+	Field  string   `json:"field"`
+	Equals string   `json:"equals,omitempty"`
+	In     []string `json:"in,omitempty"` // any of these answers
+}
+
+// WebinarStatus is a webinar's lifecycle state.
+const (
+	WebinarStatusDraft     = "draft"     // being prepared; not listed, no registrations
+	WebinarStatusScheduled = "scheduled" // published: listed and open for registration
+	WebinarStatusLive      = "live"      // streaming; attendees can still register and join
+	WebinarStatusEnded     = "ended"
+	WebinarStatusArchived  = "archived"  // hidden from organizers' active lists
+	WebinarStatusCancelled = "cancelled" // registrants were notified and paid tickets marked refund_pending
+)
+
+// WebinarVisibility controls who can find a webinar.
+const (
+	WebinarVisibilityPublic   = "public"   // listed in the catalog and /webinars/list
+	WebinarVisibilityUnlisted = "unlisted" // not listed; anyone with the link can view and register
+	WebinarVisibilityPrivate  = "private"  // not listed or open for registration; organizers add attendees
+)
+
+// Webinar lifecycle actions (POST /webinars/:id/<action>).
+const (
+	WebinarActionPublish = "publish"
+	WebinarActionGoLive  = "go-live"
+	WebinarActionEnd     = "end"
+	WebinarActionArchive = "archive"
+	WebinarActionCancel  = "cancel"
+)
+
+// WebinarTransition is an allowed lifecycle step: the action moves a webinar in one of From to To.
+type WebinarTransition struct {
+	From []string
+	To   string
+}
+
+// WebinarTransitions are the allowed lifecycle steps by action.
+var WebinarTransitions = map[string]WebinarTransition{
+	WebinarActionPublish: {From: []string{WebinarStatusDraft}, To: WebinarStatusScheduled},
+	WebinarActionGoLive:  {From: []string{WebinarStatusScheduled}, To: WebinarStatusLive},
+	WebinarActionEnd:     {From: []string{WebinarStatusLive}, To: WebinarStatusEnded},
+	WebinarActionArchive: {From: []string{WebinarStatusEnded, WebinarStatusCancelled}, To: WebinarStatusArchived},
+	WebinarActionCancel:  {From: []string{WebinarStatusDraft, WebinarStatusScheduled}, To: WebinarStatusCancelled},
 }
 
 // Webinar represents a webinar session.
@@ -32,10 +89,22 @@ type Webinar struct {
 	Category           string          `json:"category,omitempty"`
 	BannerImageURL     string          `json:"banner_image_url,omitempty"`
 	AudienceFormConfig json.RawMessage `json:"audience_form_config,omitempty"`
+	Status             string          `json:"status"`     // WebinarStatus*
+	Visibility         string          `json:"visibility"` // WebinarVisibility*
+	StatusChangedAt    *time.Time      `json:"status_changed_at,omitempty"`
+	SeriesID           *uuid.UUID      `json:"series_id,omitempty"`     // set for occurrences of a WebinarSeries
+	OccurrenceAt       *time.Time      `json:"occurrence_at,omitempty"` // the occurrence's time in the series rule
+	CalendarSequence   int             `json:"-"`                       // iCalendar SEQUENCE; raised on time changes and cancellation
 	CreatedAt          time.Time       `json:"created_at"`
 	UpdatedAt          time.Time       `json:"updated_at"`
 }
 
+// Published reports whether the webinar is listed and open to attendees: registration and join links
+// work while it is scheduled or live.
+func (w *Webinar) Published() bool {
+	return w.Status == WebinarStatusScheduled || w.Status == WebinarStatusLive
+}
+
 // WebinarSpeaker links a user as speaker to a webinar.
 type WebinarSpeaker struct {
 	WebinarID uuid.UUID `json:"webinar_id"`
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderStatus for a planned webinar reminder.
+const (
+	ReminderStatusScheduled = "scheduled"
+	ReminderStatusSent      = "sent"
+	ReminderStatusSkipped   = "skipped"   // due after the webinar started, or the webinar moved before it fired
+	ReminderStatusCancelled = "cancelled" // replaced by a new plan
+)
+
+// Reminder offset sources, most specific first.
+const (
+	ReminderSourceWebinar      = "webinar"
+	ReminderSourceOrganization = "organization"
+	ReminderSourceDefault      = "default"
+)
+
+// EmailTypeReminder1w is the one-week reminder (other offsets use ReminderEmailType).
+const EmailTypeReminder1w = "reminder_1w"
+
+// DefaultReminderOffsets are the reminder offsets (minutes before start) when neither the webinar
+// nor its organization configures any: 24h, 1h and 10m.
+var DefaultReminderOffsets = []int{24 * 60, 60, 10}
+
+var reminderTypeRegex = regexp.MustCompile(`^reminder_([1-9][0-9]*)([mhw])$`)
+
+// ReminderEmailType names the email type (and template) of a reminder sent offsetMinutes before start:
+// whole weeks as "reminder_<n>w", whole hours as "reminder_<n>h" (so 24h stays reminder_24h), else minutes.
+func ReminderEmailType(offsetMinutes int) string {
+	switch {
+	case offsetMinutes%(7*24*60) == 0:
+		return "reminder_" + strconv.Itoa(offsetMinutes/(7*24*60)) + "w"
+	case offsetMinutes%60 == 0:
+		return "reminder_" + strconv.Itoa(offsetMinutes/60) + "h"
+	default:
+		return "reminder_" + strconv.Itoa(offsetMinutes) + "m"
+	}
+}
+
+// ReminderOffset parses a ReminderEmailType back to minutes; ok is false for other email types.
+func ReminderOffset(emailType string) (minutes int, ok bool) {
+	m := reminderTypeRegex.FindStringSubmatch(emailType)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	switch m[2] {
+	case "w":
+		n *= 7 * 24 * 60
+	case "h":
+		n *= 60
+	}
+	return n, true
+}
+
+// WebinarReminder is one reminder planned from a webinar's start time.
+type WebinarReminder struct {
+	ID             uuid.UUID  `json:"id"`
+	WebinarID      uuid.UUID  `json:"webinar_id"`
+	OffsetMinutes  int        `json:"offset_minutes"`
+	EmailType      string     `json:"email_type"`
+	SendAt         time.Time  `json:"send_at"`
+	Status         string     `json:"status"`
+	RecipientCount int        `json:"recipient_count"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ReminderSchedule is a webinar's or organization's reminder configuration. Offsets is the stored value
+// (nil = inherit); Effective and Source are what applies.
+type ReminderSchedule struct {
+	Offsets   []int              `json:"reminder_offsets"`
+	Effective []int              `json:"effective_offsets"`
+	Source    string             `json:"source"`
+	Reminders []*WebinarReminder `json:"reminders,omitempty"`
+}
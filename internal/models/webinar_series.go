@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebinarSeries is a recurring webinar: its RRULE (RFC 5545) in Timezone generates webinars, its
+// occurrences, which share the series' settings.
+type WebinarSeries struct {
+	ID                 uuid.UUID       `json:"id"`
+	Title              string          `json:"title"`
+	Description        string          `json:"description"`
+	RRule              string          `json:"rrule"`     // e.g. "FREQ=WEEKLY;BYDAY=TU"
+	Timezone           string          `json:"timezone"`  // IANA name, e.g. "America/New_York"
+	StartsAt           time.Time       `json:"starts_at"` // first occurrence (DTSTART); its local time repeats
+	DurationMinutes    int             `json:"duration_minutes"`
+	OccurrenceStatus   string          `json:"occurrence_status"` // WebinarStatusDraft or WebinarStatusScheduled
+	CreatedBy          uuid.UUID       `json:"created_by"`
+	OrganizationID     *uuid.UUID      `json:"organization_id,omitempty"`
+	IsPaid             bool            `json:"is_paid"`
+	TicketPriceCents   int             `json:"ticket_price_cents"`
+	TicketCurrency     string          `json:"ticket_currency"`
+	MaxAudience        *int            `json:"max_audience,omitempty"`
+	Category           string          `json:"category,omitempty"`
+	BannerImageURL     string          `json:"banner_image_url,omitempty"`
+	AudienceFormConfig json.RawMessage `json:"audience_form_config,omitempty"`
+	SpeakerIDs         []uuid.UUID     `json:"speaker_ids"`
+	GeneratedUntil     *time.Time      `json:"generated_until,omitempty"` // occurrences exist up to here
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// SeriesAd is an ad creative copied into each occurrence of a series.
+type SeriesAd struct {
+	ID        uuid.UUID `json:"id"`
+	SeriesID  uuid.UUID `json:"series_id"`
+	FileURL   string    `json:"file_url"`
+	FileType  string    `json:"file_type"`
+	FileSize  int64     `json:"file_size"`
+	Duration  int       `json:"duration"`
+	S3Key     string    `json:"s3_key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SeriesRegistration is an attendee registered for every occurrence of a series.
+type SeriesRegistration struct {
+	ID        uuid.UUID       `json:"id"`
+	SeriesID  uuid.UUID       `json:"series_id"`
+	Email     string          `json:"email"`
+	FullName  string          `json:"full_name"`
+	ExtraData json.RawMessage `json:"extra_data,omitempty"`
+	Locale    string          `json:"locale,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
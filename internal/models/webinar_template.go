@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebinarTemplate is a saved webinar setup new webinars are created from: an organization's template, or
+// the snapshot of a webinar being cloned (ID and OrganizationID are then unset).
+type WebinarTemplate struct {
+	ID                 uuid.UUID       `json:"id"`
+	OrganizationID     uuid.UUID       `json:"organization_id"`
+	Name               string          `json:"name"`
+	Title              string          `json:"title"`
+	Description        string          `json:"description"`
+	DurationMinutes    int             `json:"duration_minutes"` // 0: new webinars have no end time
+	IsPaid             bool            `json:"is_paid"`
+	TicketPriceCents   int             `json:"ticket_price_cents"`
+	TicketCurrency     string          `json:"ticket_currency"`
+	MaxAudience        *int            `json:"max_audience,omitempty"`
+	Category           string          `json:"category,omitempty"`
+	BannerImageURL     string          `json:"banner_image_url,omitempty"`
+	Visibility         string          `json:"visibility"`
+	AudienceFormConfig json.RawMessage `json:"audience_form_config,omitempty"`
+	SpeakerEmails      []string        `json:"speaker_emails"` // invited to each new webinar
+	Polls              []TemplatePoll  `json:"polls"`
+	ReminderOffsets    []int           `json:"reminder_offsets"` // nil inherits the organization default
+	AdRotationInterval int             `json:"ad_rotation_interval"`
+	Ads                []TemplateAd    `json:"ads"`
+	CreatedBy          *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// TemplatePoll is a prepared poll created, not launched, in each new webinar.
+type TemplatePoll struct {
+	Question string `json:"question"`
+	OptionA  string `json:"option_a"`
+	OptionB  string `json:"option_b"`
+	OptionC  string `json:"option_c"`
+	OptionD  string `json:"option_d"`
+}
+
+// TemplateAd is an ad creative whose S3 object is copied into each new webinar.
+type TemplateAd struct {
+	ID       uuid.UUID `json:"id"`
+	FileURL  string    `json:"file_url"`
+	FileType string    `json:"file_type"`
+	FileSize int64     `json:"file_size"`
+	Duration int       `json:"duration"`
+	S3Key    string    `json:"s3_key,omitempty"`
+	IsActive bool      `json:"is_active"`
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/aura-webinar/backend/internal/audit"
 	"github.com/aura-webinar/backend/internal/middleware"
 	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/pkg/response"
@@ -67,6 +68,7 @@ func (h *Handler) CreateOrganization(c *gin.Context) {
 		response.Internal(c, "failed to add you as owner")
 		return
 	}
+	audit.Annotate(c, audit.Change{Action: "organization.create", TargetType: "organization", TargetID: org.ID.String(), OrganizationID: &org.ID, After: org})
 	response.OK(c, org)
 }
 
@@ -92,6 +94,7 @@ func (h *Handler) JoinOrganization(c *gin.Context) {
 		response.Internal(c, "failed to join organization")
 		return
 	}
+	audit.Annotate(c, audit.Change{Action: "organization.join", TargetType: "organization", TargetID: org.ID.String(), OrganizationID: &org.ID})
 	response.OK(c, org)
 }
 
@@ -108,16 +111,8 @@ func (h *Handler) ListMyOrganizations(c *gin.Context) {
 
 // ListMembers handles GET /organizations/:id/members. Requires JWT and org access (owner/event_manager/moderator).
 func (h *Handler) ListMembers(c *gin.Context) {
-	idStr := c.Param("id")
-	orgID, err := uuid.Parse(idStr)
-	if err != nil {
-		response.BadRequest(c, "invalid organization id")
-		return
-	}
-	userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
-	ok, err := h.repo.UserHasOrgAccess(c.Request.Context(), orgID, userID)
-	if err != nil || !ok {
-		response.Forbidden(c, "not authorized for this organization")
+	orgID, ok := middleware.RequireOrgRole(c, h.repo, models.OrgMemberRoles...)
+	if !ok {
 		return
 	}
 	members, err := h.repo.ListMembers(c.Request.Context(), orgID)
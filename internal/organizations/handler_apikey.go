@@ -0,0 +1,109 @@
+package organizations
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// CreateAPIKeyRequest is the body for POST /organizations/:id/api-keys.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+// ListAPIKeys handles GET /organizations/:id/api-keys (owner). Secrets are never returned.
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.repo, models.OrgOwnerRoles...)
+	if !ok {
+		return
+	}
+	list, err := h.repo.ListAPIKeys(c.Request.Context(), orgID)
+	if err != nil {
+		response.Internal(c, "failed to load api keys")
+		return
+	}
+	response.OK(c, list)
+}
+
+// CreateAPIKey handles POST /organizations/:id/api-keys (owner). The secret is in the response only this once.
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.repo, models.OrgOwnerRoles...)
+	if !ok {
+		return
+	}
+	var body CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "name and scopes required")
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if len(body.Name) < 1 || len(body.Name) > 255 {
+		response.BadRequest(c, "name must be 1–255 characters")
+		return
+	}
+	scopes, ok := normalizeScopes(body.Scopes)
+	if !ok {
+		response.BadRequest(c, "scopes must be one or more of: "+strings.Join(models.APIKeyScopes, ", "))
+		return
+	}
+	userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
+	key, secret, err := h.repo.CreateAPIKey(c.Request.Context(), orgID, userID, body.Name, scopes)
+	if err != nil {
+		response.Internal(c, "failed to create api key")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "api_key.create", TargetType: "api_key", TargetID: key.ID.String(), After: key})
+	response.Created(c, gin.H{"api_key": key, "secret": secret})
+}
+
+// RevokeAPIKey handles DELETE /organizations/:id/api-keys/:keyId (owner). Takes effect on the next request.
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.repo, models.OrgOwnerRoles...)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(c.Param("keyId"))
+	if err != nil {
+		response.BadRequest(c, "invalid api key id")
+		return
+	}
+	revoked, err := h.repo.RevokeAPIKey(c.Request.Context(), orgID, keyID)
+	if err != nil {
+		response.Internal(c, "failed to revoke api key")
+		return
+	}
+	if !revoked {
+		response.NotFound(c, "api key not found")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "api_key.revoke", TargetType: "api_key", TargetID: keyID.String()})
+	response.NoContent(c)
+}
+
+// normalizeScopes validates and de-duplicates requested scopes.
+func normalizeScopes(in []string) ([]string, bool) {
+	valid := make(map[string]bool, len(models.APIKeyScopes))
+	for _, s := range models.APIKeyScopes {
+		valid[s] = true
+	}
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !valid[s] {
+			return nil, false
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out, len(out) > 0
+}
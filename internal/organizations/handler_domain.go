@@ -0,0 +1,158 @@
+package organizations
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// DomainTXTPrefix is the DNS label queried when verifying a domain claim (e.g. _aura-verification.example.com).
+const DomainTXTPrefix = "_aura-verification."
+
+var domainRegex = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// lookupTXT is net.DefaultResolver.LookupTXT; a variable so the resolver can be swapped.
+var lookupTXT = net.DefaultResolver.LookupTXT
+
+// CreateDomainRequest is the body for POST /organizations/:id/domains.
+type CreateDomainRequest struct {
+	Domain      string `json:"domain" binding:"required"`
+	DefaultRole string `json:"default_role"` // role for SSO users matched by this domain; defaults to moderator
+}
+
+// ListDomains handles GET /organizations/:id/domains. Any org member may list.
+func (h *Handler) ListDomains(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.repo, models.OrgMemberRoles...)
+	if !ok {
+		return
+	}
+	list, err := h.repo.ListDomains(c.Request.Context(), orgID)
+	if err != nil {
+		response.Internal(c, "failed to load domains")
+		return
+	}
+	response.OK(c, list)
+}
+
+// CreateDomain handles POST /organizations/:id/domains (owner). Returns the TXT record to publish.
+func (h *Handler) CreateDomain(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.repo, models.OrgOwnerRoles...)
+	if !ok {
+		return
+	}
+	var body CreateDomainRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "domain required")
+		return
+	}
+	domain := strings.ToLower(strings.TrimSpace(body.Domain))
+	if !domainRegex.MatchString(domain) {
+		response.BadRequest(c, "invalid domain")
+		return
+	}
+	role := body.DefaultRole
+	if role == "" {
+		role = models.OrgRoleModerator
+	}
+	if role != models.OrgRoleOwner && role != models.OrgRoleEventManager && role != models.OrgRoleModerator {
+		response.BadRequest(c, "default_role must be owner, event_manager or moderator")
+		return
+	}
+	owner, err := h.repo.GetVerifiedDomain(c.Request.Context(), domain)
+	if err != nil {
+		response.Internal(c, "failed to add domain")
+		return
+	}
+	if owner != nil {
+		response.Conflict(c, "this domain is already verified by an organization")
+		return
+	}
+	d, err := h.repo.CreateDomain(c.Request.Context(), orgID, domain, role)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique") {
+			response.Conflict(c, "this organization has already claimed this domain")
+			return
+		}
+		response.Internal(c, "failed to add domain")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "domain.create", TargetType: "domain", TargetID: d.ID.String(), After: d})
+	response.Created(c, gin.H{
+		"domain":     d,
+		"txt_record": gin.H{"name": DomainTXTPrefix + d.Domain, "value": "aura-verification=" + d.VerificationToken},
+	})
+}
+
+// VerifyDomain handles POST /organizations/:id/domains/:domainId/verify (owner). Checks the DNS TXT record.
+func (h *Handler) VerifyDomain(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.repo, models.OrgOwnerRoles...)
+	if !ok {
+		return
+	}
+	domainID, err := uuid.Parse(c.Param("domainId"))
+	if err != nil {
+		response.BadRequest(c, "invalid domain id")
+		return
+	}
+	d, err := h.repo.GetDomain(c.Request.Context(), domainID)
+	if err != nil || d == nil || d.OrganizationID != orgID {
+		response.NotFound(c, "domain not found")
+		return
+	}
+	if d.VerifiedAt == nil {
+		records, err := lookupTXT(c.Request.Context(), DomainTXTPrefix+d.Domain)
+		if err != nil {
+			response.BadRequest(c, "TXT record not found for "+DomainTXTPrefix+d.Domain)
+			return
+		}
+		found := false
+		for _, r := range records {
+			if strings.TrimSpace(r) == "aura-verification="+d.VerificationToken {
+				found = true
+				break
+			}
+		}
+		if !found {
+			response.BadRequest(c, "TXT record does not contain the verification token")
+			return
+		}
+		if err := h.repo.MarkDomainVerified(c.Request.Context(), d.ID); err != nil {
+			if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique") {
+				response.Conflict(c, "this domain is already verified by another organization")
+				return
+			}
+			response.Internal(c, "failed to verify domain")
+			return
+		}
+	}
+	updated, _ := h.repo.GetDomain(c.Request.Context(), d.ID)
+	audit.Annotate(c, audit.Change{Action: "domain.verify", TargetType: "domain", TargetID: d.ID.String(), Before: d, After: updated})
+	response.OK(c, updated)
+}
+
+// DeleteDomain handles DELETE /organizations/:id/domains/:domainId (owner).
+func (h *Handler) DeleteDomain(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.repo, models.OrgOwnerRoles...)
+	if !ok {
+		return
+	}
+	domainID, err := uuid.Parse(c.Param("domainId"))
+	if err != nil {
+		response.BadRequest(c, "invalid domain id")
+		return
+	}
+	if err := h.repo.DeleteDomain(c.Request.Context(), orgID, domainID); err != nil {
+		response.Internal(c, "failed to delete domain")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "domain.delete", TargetType: "domain", TargetID: domainID.String()})
+	response.NoContent(c)
+}
@@ -0,0 +1,115 @@
+package organizations
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// APIKeyPrefix starts every API key secret so it can be told apart from a JWT.
+const APIKeyPrefix = "aura_sk_"
+
+// apiKeyDisplayLen is how many characters of the secret (including APIKeyPrefix) are kept as the visible prefix.
+const apiKeyDisplayLen = len(APIKeyPrefix) + 8
+
+const apiKeyColumns = `id, organization_id, name, prefix, key_hash, scopes, created_by, last_used_at, revoked_at, created_at`
+
+// CreateAPIKey generates a key for the organization and returns it with the plaintext secret (never stored).
+func (r *Repository) CreateAPIKey(ctx context.Context, orgID, createdBy uuid.UUID, name string, scopes []string) (*models.APIKey, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	secret := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	const q = `INSERT INTO api_keys (id, organization_id, name, prefix, key_hash, scopes, created_by)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6)
+		RETURNING ` + apiKeyColumns
+	k, err := scanAPIKey(r.pool.QueryRow(ctx, q, orgID, name, secret[:apiKeyDisplayLen], hashAPIKey(secret), scopes, createdBy))
+	if err != nil {
+		return nil, "", err
+	}
+	return k, secret, nil
+}
+
+// ListAPIKeys returns the organization's keys, including revoked ones.
+func (r *Repository) ListAPIKeys(ctx context.Context, orgID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE organization_id = $1 ORDER BY created_at DESC`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *k)
+	}
+	return list, rows.Err()
+}
+
+// RevokeAPIKey revokes a key of the organization. Returns false if no active key matched.
+func (r *Repository) RevokeAPIKey(ctx context.Context, orgID, keyID uuid.UUID) (bool, error) {
+	const q = `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL`
+	tag, err := r.pool.Exec(ctx, q, keyID, orgID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// AuthenticateAPIKey resolves a presented secret to an active key and records its use.
+// Returns nil, nil for unknown or revoked keys, and for keys whose creator is no longer an owner or event
+// manager of the organization (a key acts as its creator).
+func (r *Repository) AuthenticateAPIKey(ctx context.Context, secret string) (*models.APIKey, error) {
+	if !strings.HasPrefix(secret, APIKeyPrefix) {
+		return nil, nil
+	}
+	k, err := scanAPIKey(r.pool.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hashAPIKey(secret)))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if k.RevokedAt != nil {
+		return nil, nil
+	}
+	role, err := r.GetUserRole(ctx, k.OrganizationID, k.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !models.HasOrgRole(role, models.OrgManagerRoles) {
+		return nil, nil
+	}
+	// Throttled so a busy integration does not write on every request.
+	const touch = `UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`
+	if _, err := r.pool.Exec(ctx, touch, k.ID); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var k models.APIKey
+	err := row.Scan(&k.ID, &k.OrganizationID, &k.Name, &k.Prefix, &k.KeyHash, &k.Scopes, &k.CreatedBy, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package organizations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// CreateDomain claims an email domain for an organization (unverified until the DNS TXT record is checked).
+func (r *Repository) CreateDomain(ctx context.Context, orgID uuid.UUID, domain, defaultRole string) (*models.OrganizationDomain, error) {
+	token, err := generateDomainToken()
+	if err != nil {
+		return nil, err
+	}
+	const q = `INSERT INTO organization_domains (id, organization_id, domain, verification_token, default_role)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4)
+		RETURNING id, organization_id, domain, verification_token, default_role, verified_at, created_at`
+	var d models.OrganizationDomain
+	err = r.pool.QueryRow(ctx, q, orgID, domain, token, defaultRole).
+		Scan(&d.ID, &d.OrganizationID, &d.Domain, &d.VerificationToken, &d.DefaultRole, &d.VerifiedAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// GetDomain returns a domain claim by ID.
+func (r *Repository) GetDomain(ctx context.Context, id uuid.UUID) (*models.OrganizationDomain, error) {
+	const q = `SELECT id, organization_id, domain, verification_token, default_role, verified_at, created_at
+		FROM organization_domains WHERE id = $1`
+	var d models.OrganizationDomain
+	err := r.pool.QueryRow(ctx, q, id).
+		Scan(&d.ID, &d.OrganizationID, &d.Domain, &d.VerificationToken, &d.DefaultRole, &d.VerifiedAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListDomains returns the domains claimed by an organization.
+func (r *Repository) ListDomains(ctx context.Context, orgID uuid.UUID) ([]models.OrganizationDomain, error) {
+	const q = `SELECT id, organization_id, domain, verification_token, default_role, verified_at, created_at
+		FROM organization_domains WHERE organization_id = $1 ORDER BY domain`
+	rows, err := r.pool.Query(ctx, q, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.OrganizationDomain
+	for rows.Next() {
+		var d models.OrganizationDomain
+		if err := rows.Scan(&d.ID, &d.OrganizationID, &d.Domain, &d.VerificationToken, &d.DefaultRole, &d.VerifiedAt, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// MarkDomainVerified sets verified_at for a domain claim. Fails with a unique violation when another
+// organization has verified the domain.
+func (r *Repository) MarkDomainVerified(ctx context.Context, id uuid.UUID) error {
+	const q = `UPDATE organization_domains SET verified_at = NOW() WHERE id = $1 AND verified_at IS NULL`
+	_, err := r.pool.Exec(ctx, q, id)
+	return err
+}
+
+// DeleteDomain removes a domain claim from an organization.
+func (r *Repository) DeleteDomain(ctx context.Context, orgID, id uuid.UUID) error {
+	const q = `DELETE FROM organization_domains WHERE id = $1 AND organization_id = $2`
+	_, err := r.pool.Exec(ctx, q, id, orgID)
+	return err
+}
+
+// GetVerifiedDomain returns the verified claim for an email domain, or nil if no organization owns it.
+func (r *Repository) GetVerifiedDomain(ctx context.Context, domain string) (*models.OrganizationDomain, error) {
+	const q = `SELECT id, organization_id, domain, verification_token, default_role, verified_at, created_at
+		FROM organization_domains WHERE domain = $1 AND verified_at IS NOT NULL`
+	var d models.OrganizationDomain
+	err := r.pool.QueryRow(ctx, q, domain).
+		Scan(&d.ID, &d.OrganizationID, &d.Domain, &d.VerificationToken, &d.DefaultRole, &d.VerifiedAt, &d.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+func generateDomainToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/aura-webinar/backend/internal/models"
@@ -65,21 +66,15 @@ func (r *Repository) GetUserRole(ctx context.Context, orgID, userID uuid.UUID) (
 	const q = `SELECT role FROM organization_users WHERE organization_id = $1 AND user_id = $2`
 	var role string
 	err := r.pool.QueryRow(ctx, q, orgID, userID).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
 	if err != nil {
 		return "", err
 	}
 	return role, nil
 }
 
-// UserHasOrgAccess returns true if user is owner, event_manager, or moderator in the org.
-func (r *Repository) UserHasOrgAccess(ctx context.Context, orgID, userID uuid.UUID) (bool, error) {
-	role, err := r.GetUserRole(ctx, orgID, userID)
-	if err != nil || role == "" {
-		return false, nil
-	}
-	return role == models.OrgRoleOwner || role == models.OrgRoleEventManager || role == models.OrgRoleModerator, nil
-}
-
 // ListOrganizationsForUser returns organizations the user is a member of (for GET /organizations).
 func (r *Repository) ListOrganizationsForUser(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error) {
 	const q = `SELECT o.id, o.name, o.slug, o.created_at, o.updated_at
@@ -152,4 +147,13 @@ func (r *Repository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]Member
 		list = append(list, m)
 	}
 	return list, rows.Err()
-}
\ No newline at end of file
+}
+
+// EnsureMember adds a user to an organization with a role unless they are already a member (never changes an existing role).
+func (r *Repository) EnsureMember(ctx context.Context, orgID, userID uuid.UUID, role string) error {
+	const q = `INSERT INTO organization_users (id, organization_id, user_id, role)
+		VALUES (gen_random_uuid(), $1, $2, $3)
+		ON CONFLICT (organization_id, user_id) DO NOTHING`
+	_, err := r.pool.Exec(ctx, q, orgID, userID, role)
+	return err
+}
@@ -0,0 +1,80 @@
+package payments
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// Handler shows organizers the refunds a cancelled webinar owes. Routes run after
+// webinars.RequireWebinarOrgAccess and are limited to callers who may manage the webinar.
+type Handler struct {
+	repo        *Repository
+	webinarRepo webinars.Lookup
+	logger      *zap.Logger
+}
+
+// NewHandler creates a payments handler.
+func NewHandler(repo *Repository, webinarRepo webinars.Lookup, logger *zap.Logger) *Handler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Handler{repo: repo, webinarRepo: webinarRepo, logger: logger}
+}
+
+// ListRefunds handles GET /webinars/:id/refunds: payments waiting for a refund (refund_pending) and those
+// already refunded.
+func (h *Handler) ListRefunds(c *gin.Context) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return
+	}
+	list, err := h.repo.ListRefunds(c.Request.Context(), w.ID)
+	if err != nil {
+		h.logger.Error("list refunds failed", zap.Error(err), zap.String("webinar_id", w.ID.String()))
+		response.Internal(c, "failed to load refunds")
+		return
+	}
+	if list == nil {
+		list = []*models.Payment{}
+	}
+	pending := 0
+	for _, p := range list {
+		if p.Status == models.PaymentStatusRefundPending {
+			pending++
+		}
+	}
+	response.OK(c, gin.H{"payments": list, "pending": pending})
+}
+
+// MarkRefunded handles POST /webinars/:id/refunds/:paymentId/refunded: records that the organizer refunded
+// the payment with the provider.
+func (h *Handler) MarkRefunded(c *gin.Context) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return
+	}
+	paymentID, err := uuid.Parse(c.Param("paymentId"))
+	if err != nil {
+		response.BadRequest(c, "invalid payment id")
+		return
+	}
+	marked, err := h.repo.MarkRefunded(c.Request.Context(), w.ID, paymentID)
+	if err != nil {
+		h.logger.Error("mark refunded failed", zap.Error(err), zap.String("payment_id", paymentID.String()))
+		response.Internal(c, "failed to update payment")
+		return
+	}
+	if !marked {
+		response.Conflict(c, "payment is not waiting for a refund")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "payment.refunded", TargetType: "payment", TargetID: paymentID.String(), OrganizationID: w.OrganizationID,
+		After: gin.H{"webinar_id": w.ID, "status": models.PaymentStatusRefunded}})
+	response.OK(c, gin.H{"payment_id": paymentID, "status": models.PaymentStatusRefunded})
+}
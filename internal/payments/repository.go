@@ -0,0 +1,67 @@
+// Package payments holds ticket payments for paid webinars.
+package payments
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository handles payment persistence.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a payments repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// RequestRefunds marks the webinar's completed payments refund_pending. No provider refund is issued:
+// organizers refund them with the provider and mark them refunded (MarkRefunded). Returns how many
+// payments were marked.
+func (r *Repository) RequestRefunds(ctx context.Context, webinarID uuid.UUID) (int64, error) {
+	const q = `UPDATE payments SET status = 'refund_pending', updated_at = NOW() WHERE webinar_id = $1 AND status = 'completed'`
+	tag, err := r.pool.Exec(ctx, q, webinarID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListRefunds returns the webinar's payments that are waiting for or have received a refund, oldest first.
+func (r *Repository) ListRefunds(ctx context.Context, webinarID uuid.UUID) ([]*models.Payment, error) {
+	const q = `SELECT id, webinar_id, registration_id, provider, COALESCE(provider_payment_id, ''), COALESCE(provider_order_id, ''),
+		amount_cents, currency, status, refunded_at, created_at, updated_at
+		FROM payments WHERE webinar_id = $1 AND status IN ('refund_pending', 'refunded') ORDER BY created_at`
+	rows, err := r.pool.Query(ctx, q, webinarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*models.Payment
+	for rows.Next() {
+		var p models.Payment
+		if err := rows.Scan(&p.ID, &p.WebinarID, &p.RegistrationID, &p.Provider, &p.ProviderPaymentID, &p.ProviderOrderID,
+			&p.AmountCents, &p.Currency, &p.Status, &p.RefundedAt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &p)
+	}
+	return list, rows.Err()
+}
+
+// MarkRefunded records that a refund_pending payment of the webinar was refunded with the provider.
+// Returns false if no such payment was pending.
+func (r *Repository) MarkRefunded(ctx context.Context, webinarID, paymentID uuid.UUID) (bool, error) {
+	const q = `UPDATE payments SET status = 'refunded', refunded_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND webinar_id = $2 AND status = 'refund_pending'`
+	tag, err := r.pool.Exec(ctx, q, paymentID, webinarID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
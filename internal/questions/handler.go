@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/aura-webinar/backend/internal/audit"
 	"github.com/aura-webinar/backend/internal/middleware"
 	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/realtime"
@@ -94,6 +95,10 @@ func (h *Handler) Approve(c *gin.Context) {
 	h.hub.PublishToWebinarOnly(q.WebinarID, "approve_question", map[string]interface{}{
 		"id": q.ID, "approved": true, "answered": q.Answered, "votes": q.Votes,
 	})
+	audit.Annotate(c, audit.Change{
+		Action: "question.approve", WebinarID: &q.WebinarID,
+		Before: gin.H{"approved": q.Approved}, After: gin.H{"approved": true},
+	})
 	response.OK(c, gin.H{"id": q.ID, "approved": true})
 }
 
@@ -118,6 +123,10 @@ func (h *Handler) Answer(c *gin.Context) {
 	h.hub.PublishToWebinarOnly(q.WebinarID, "question_answered", map[string]interface{}{
 		"id": q.ID, "answered": true,
 	})
+	audit.Annotate(c, audit.Change{
+		Action: "question.answer", WebinarID: &q.WebinarID,
+		Before: gin.H{"answered": q.Answered}, After: gin.H{"answered": true},
+	})
 	response.OK(c, gin.H{"id": q.ID, "answered": true})
 }
 
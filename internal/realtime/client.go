@@ -3,6 +3,8 @@ package realtime
 import (
 	"encoding/json"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -38,6 +40,11 @@ type Client struct {
 	conn      *websocket.Conn
 	send      chan WSMessage
 	logger    *zap.Logger
+
+	tracked      atomic.Bool   // counted by the hub's session tracker
+	revoked      chan struct{} // closed to disconnect the client (join policy or organizer reset)
+	revokeOnce   sync.Once
+	revokeReason string
 }
 
 // ServeWs handles the WebSocket upgrade and runs the client loop.
@@ -61,12 +68,6 @@ func ServeWs(hub *Hub, logger *zap.Logger, jwtValidate func(token string) (userI
 		}
 		userID, _ := uuid.Parse(userIDStr)
 
-		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-		if err != nil {
-			logger.Warn("websocket upgrade failed", zap.Error(err))
-			return
-		}
-
 		client := &Client{
 			ID:        uuid.New().String(),
 			WebinarID: webinarID,
@@ -75,10 +76,23 @@ func ServeWs(hub *Hub, logger *zap.Logger, jwtValidate func(token string) (userI
 			JoinedAt:  time.Now(),
 			hub:       hub,
 			sfu:       sfu,
-			conn:      conn,
 			send:      make(chan WSMessage, 256),
 			logger:    logger,
+			revoked:   make(chan struct{}),
+		}
+		if err := hub.Admit(c.Request.Context(), client); err != nil {
+			// ErrSessionLimit: the join policy refuses more devices for this attendee.
+			c.JSON(http.StatusConflict, gin.H{"error": "already joined on the maximum number of devices"})
+			return
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			hub.releaseSession(client)
+			logger.Warn("websocket upgrade failed", zap.Error(err))
+			return
 		}
+		client.conn = conn
 		hub.Register(client)
 		go client.writePump()
 		client.readPump()
@@ -193,11 +207,32 @@ func (c *Client) writePump() {
 			if err := c.conn.WriteJSON(msg); err != nil {
 				return
 			}
+		case <-c.revoked:
+			// Tell the client why before closing, so it does not reconnect in a loop.
+			data, _ := json.Marshal(map[string]string{"reason": c.revokeReason})
+			_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			_ = c.conn.WriteJSON(WSMessage{Event: eventSessionRevoked, Data: data})
+			_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, c.revokeReason))
+			return
 		case <-ticker.C:
 			_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
+			c.hub.touchSession(c)
 		}
 	}
 }
+
+// session is the client's entry in the hub's session tracker.
+func (c *Client) session() Session {
+	return Session{ClientID: c.ID, JoinedAt: c.JoinedAt}
+}
+
+// revoke disconnects the client; reason is sent with the session_revoked event.
+func (c *Client) revoke(reason string) {
+	c.revokeOnce.Do(func() {
+		c.revokeReason = reason
+		close(c.revoked)
+	})
+}
@@ -36,6 +36,8 @@ type Hub struct {
 	onAudience     AudienceChangeHandler
 	onSessionJoin  SessionLogJoin
 	onSessionLeave SessionLogLeave
+	sessions       SessionTracker
+	sessionLimit   SessionLimitFunc
 }
 
 // RedisPublisher is the interface for publishing to Redis (for cross-instance broadcast).
@@ -56,6 +58,7 @@ func NewHub(logger *zap.Logger, redisPub RedisPublisher, redisSub RedisSubscribe
 		logger:    logger,
 		redis:     redisPub,
 		redisSub:  redisSub,
+		sessions:  newLocalSessions(),
 		onAudience: nil,
 	}
 }
@@ -82,6 +85,13 @@ func (h *Hub) Register(c *Client) {
 		h.webinars[c.WebinarID] = make(map[string]*Client)
 		if h.redisSub != nil {
 			cancel, err := h.redisSub.SubscribeWebinar(c.WebinarID, func(event string, payload []byte) {
+				if event == eventSessionRevoked {
+					var p revokePayload
+					if json.Unmarshal(payload, &p) == nil {
+						h.revokeLocal(c.WebinarID, p)
+					}
+					return
+				}
 				h.BroadcastToWebinar(c.WebinarID, event, json.RawMessage(payload))
 			})
 			if err == nil {
@@ -124,6 +134,7 @@ func (h *Hub) Unregister(c *Client) {
 	}
 	onAudience := h.onAudience
 	h.mu.Unlock()
+	h.releaseSession(c)
 	if onAudience != nil && count > 0 {
 		onAudience(c.WebinarID, count)
 	}
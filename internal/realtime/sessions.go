@@ -0,0 +1,360 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// sessionStaleAfter drops tracked sessions whose instance stopped refreshing them (crash, lost network).
+	// Clients refresh on every ping.
+	sessionStaleAfter = 2 * PongWait * time.Second
+	sessionKeyPrefix  = "webinar:sessions:"
+
+	// eventSessionRevoked is published to a webinar's channel to disconnect sessions on whichever instance
+	// holds them; it is handled by the hub and never broadcast to clients.
+	eventSessionRevoked = "session_revoked"
+
+	// Reasons sent to a disconnected client with the session_revoked event.
+	RevokeReasonSessionLimit = "session_limit" // a newer session of the same attendee took the slot
+	RevokeReasonReset        = "reset"         // an organizer reset the attendee's sessions
+)
+
+// ErrSessionLimit is returned by Hub.Admit when the join policy refuses another session for the user.
+var ErrSessionLimit = errors.New("session limit reached")
+
+// SessionLimit is the join policy for an attendee's concurrent connections to a webinar.
+type SessionLimit struct {
+	Max        int  // 0 = unlimited
+	NewestWins bool // disconnect the oldest sessions; false refuses the new connection
+}
+
+// SessionLimitFunc returns the join policy of a webinar.
+type SessionLimitFunc func(ctx context.Context, webinarID uuid.UUID) SessionLimit
+
+// Session is one tracked connection of a user to a webinar.
+type Session struct {
+	ClientID string
+	JoinedAt time.Time
+}
+
+// SessionTracker records each user's live connections to a webinar. The hub's default tracker only sees
+// this instance; NewRedisSessions shares sessions across instances.
+type SessionTracker interface {
+	// Add records s and returns the user's sessions in the webinar, oldest first (including s).
+	Add(ctx context.Context, webinarID, userID uuid.UUID, s Session) ([]Session, error)
+	// Touch marks s as still connected.
+	Touch(ctx context.Context, webinarID, userID uuid.UUID, s Session) error
+	Remove(ctx context.Context, webinarID, userID uuid.UUID, sessions ...Session) error
+	// List returns the user's sessions in the webinar, oldest first.
+	List(ctx context.Context, webinarID, userID uuid.UUID) ([]Session, error)
+}
+
+// revokePayload is the body of eventSessionRevoked.
+type revokePayload struct {
+	ClientIDs []string `json:"client_ids"`
+	Reason    string   `json:"reason"`
+}
+
+// localSessions tracks sessions in memory (single instance).
+type localSessions struct {
+	mu       sync.Mutex
+	sessions map[string][]Session // webinar/user -> sessions, oldest first
+}
+
+func newLocalSessions() *localSessions {
+	return &localSessions{sessions: make(map[string][]Session)}
+}
+
+func (l *localSessions) Add(_ context.Context, webinarID, userID uuid.UUID, s Session) ([]Session, error) {
+	key := sessionKey(webinarID, userID)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	list := append(l.sessions[key], s)
+	sortSessions(list)
+	l.sessions[key] = list
+	return append([]Session(nil), list...), nil
+}
+
+func (l *localSessions) Touch(context.Context, uuid.UUID, uuid.UUID, Session) error { return nil }
+
+func (l *localSessions) Remove(_ context.Context, webinarID, userID uuid.UUID, sessions ...Session) error {
+	key := sessionKey(webinarID, userID)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	list := l.sessions[key][:0]
+	for _, s := range l.sessions[key] {
+		if !containsSession(sessions, s.ClientID) {
+			list = append(list, s)
+		}
+	}
+	if len(list) == 0 {
+		delete(l.sessions, key)
+	} else {
+		l.sessions[key] = list
+	}
+	return nil
+}
+
+func (l *localSessions) List(_ context.Context, webinarID, userID uuid.UUID) ([]Session, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Session(nil), l.sessions[sessionKey(webinarID, userID)]...), nil
+}
+
+// RedisSessions implements SessionTracker with one sorted set per webinar and user: members are
+// "<joined unix nanos>:<client id>", scored by when the session was last refreshed.
+type RedisSessions struct {
+	client *redis.Client
+}
+
+// NewRedisSessions creates a session tracker shared by all API instances.
+func NewRedisSessions(client *redis.Client) *RedisSessions {
+	return &RedisSessions{client: client}
+}
+
+// Add implements SessionTracker.
+func (r *RedisSessions) Add(ctx context.Context, webinarID, userID uuid.UUID, s Session) ([]Session, error) {
+	key := sessionKeyPrefix + sessionKey(webinarID, userID)
+	now := time.Now()
+	var members *redis.StringSliceCmd
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Add(-sessionStaleAfter).Unix(), 10))
+		p.ZAdd(ctx, key, redis.Z{Score: float64(now.Unix()), Member: sessionMember(s)})
+		p.Expire(ctx, key, 2*sessionStaleAfter)
+		members = p.ZRange(ctx, key, 0, -1)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("track session: %w", err)
+	}
+	return parseSessions(members.Val()), nil
+}
+
+// Touch implements SessionTracker. Sessions removed meanwhile (revoked) are not re-added.
+func (r *RedisSessions) Touch(ctx context.Context, webinarID, userID uuid.UUID, s Session) error {
+	key := sessionKeyPrefix + sessionKey(webinarID, userID)
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZAddXX(ctx, key, redis.Z{Score: float64(time.Now().Unix()), Member: sessionMember(s)})
+		p.Expire(ctx, key, 2*sessionStaleAfter)
+		return nil
+	})
+	return err
+}
+
+// Remove implements SessionTracker.
+func (r *RedisSessions) Remove(ctx context.Context, webinarID, userID uuid.UUID, sessions ...Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(sessions))
+	for i, s := range sessions {
+		members[i] = sessionMember(s)
+	}
+	return r.client.ZRem(ctx, sessionKeyPrefix+sessionKey(webinarID, userID), members...).Err()
+}
+
+// List implements SessionTracker.
+func (r *RedisSessions) List(ctx context.Context, webinarID, userID uuid.UUID) ([]Session, error) {
+	key := sessionKeyPrefix + sessionKey(webinarID, userID)
+	min := strconv.FormatInt(time.Now().Add(-sessionStaleAfter).Unix(), 10)
+	members, err := r.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: min, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return parseSessions(members), nil
+}
+
+// SetSessionLimit sets the join policy lookup. Without it, sessions are tracked but not limited.
+func (h *Hub) SetSessionLimit(fn SessionLimitFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessionLimit = fn
+}
+
+// SetSessionTracker replaces the in-memory session tracker (use NewRedisSessions with several instances).
+func (h *Hub) SetSessionTracker(t SessionTracker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions = t
+}
+
+// Admit tracks an audience member's new connection and applies the webinar's join policy before the
+// client is registered: over the limit, the oldest sessions are disconnected (newest wins) or
+// ErrSessionLimit is returned. Speakers and admins are not limited. Tracking errors let the client in.
+func (h *Hub) Admit(ctx context.Context, c *Client) error {
+	if c.Role != "audience" || c.UserID == uuid.Nil {
+		return nil
+	}
+	h.mu.RLock()
+	tracker, limitFn := h.sessions, h.sessionLimit
+	h.mu.RUnlock()
+	sessions, err := tracker.Add(ctx, c.WebinarID, c.UserID, c.session())
+	if err != nil {
+		h.logger.Warn("track session failed", zap.Error(err), zap.String("webinar_id", c.WebinarID.String()))
+		return nil
+	}
+	c.tracked.Store(true)
+	if limitFn == nil {
+		return nil
+	}
+	limit := limitFn(ctx, c.WebinarID)
+	if limit.Max <= 0 || len(sessions) <= limit.Max {
+		return nil
+	}
+	if !limit.NewestWins {
+		h.releaseSession(c)
+		return ErrSessionLimit
+	}
+	older := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		if s.ClientID != c.ID {
+			older = append(older, s)
+		}
+	}
+	evict := older[:len(older)-(limit.Max-1)]
+	h.revokeSessions(ctx, c.WebinarID, c.UserID, evict, RevokeReasonSessionLimit)
+	h.logger.Info("session limit: disconnected older sessions", zap.String("webinar_id", c.WebinarID.String()),
+		zap.String("user_id", c.UserID.String()), zap.Int("evicted", len(evict)))
+	return nil
+}
+
+// ActiveSessions returns how many live connections a user has in a webinar.
+func (h *Hub) ActiveSessions(ctx context.Context, webinarID, userID uuid.UUID) (int, error) {
+	h.mu.RLock()
+	tracker := h.sessions
+	h.mu.RUnlock()
+	sessions, err := tracker.List(ctx, webinarID, userID)
+	return len(sessions), err
+}
+
+// ResetSessions disconnects all of a user's connections to a webinar, on any instance. Returns how many
+// were disconnected.
+func (h *Hub) ResetSessions(ctx context.Context, webinarID, userID uuid.UUID) (int, error) {
+	h.mu.RLock()
+	tracker := h.sessions
+	h.mu.RUnlock()
+	sessions, err := tracker.List(ctx, webinarID, userID)
+	if err != nil {
+		return 0, err
+	}
+	h.revokeSessions(ctx, webinarID, userID, sessions, RevokeReasonReset)
+	return len(sessions), nil
+}
+
+// revokeSessions stops tracking sessions and disconnects them: local clients directly, others through the
+// webinar's Redis channel.
+func (h *Hub) revokeSessions(ctx context.Context, webinarID, userID uuid.UUID, sessions []Session, reason string) {
+	if len(sessions) == 0 {
+		return
+	}
+	h.mu.RLock()
+	tracker := h.sessions
+	h.mu.RUnlock()
+	if err := tracker.Remove(ctx, webinarID, userID, sessions...); err != nil {
+		h.logger.Warn("untrack sessions failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
+	}
+	p := revokePayload{ClientIDs: make([]string, len(sessions)), Reason: reason}
+	for i, s := range sessions {
+		p.ClientIDs[i] = s.ClientID
+	}
+	h.revokeLocal(webinarID, p)
+	if h.redis != nil {
+		data, _ := json.Marshal(p)
+		if err := h.redis.PublishWebinarEvent(webinarID, eventSessionRevoked, data); err != nil {
+			h.logger.Warn("publish session revocation failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
+		}
+	}
+}
+
+// revokeLocal disconnects the listed clients connected to this instance.
+func (h *Hub) revokeLocal(webinarID uuid.UUID, p revokePayload) {
+	h.mu.RLock()
+	clients := h.webinars[webinarID]
+	targets := make([]*Client, 0, len(p.ClientIDs))
+	for _, id := range p.ClientIDs {
+		if c, ok := clients[id]; ok {
+			targets = append(targets, c)
+		}
+	}
+	h.mu.RUnlock()
+	for _, c := range targets {
+		c.revoke(p.Reason)
+	}
+}
+
+// touchSession refreshes a tracked client (called on every ping).
+func (h *Hub) touchSession(c *Client) {
+	if !c.tracked.Load() {
+		return
+	}
+	h.mu.RLock()
+	tracker := h.sessions
+	h.mu.RUnlock()
+	ctx, cancel := context.WithTimeout(context.Background(), eventTTL)
+	defer cancel()
+	if err := tracker.Touch(ctx, c.WebinarID, c.UserID, c.session()); err != nil {
+		h.logger.Debug("refresh session failed", zap.Error(err), zap.String("client_id", c.ID))
+	}
+}
+
+// releaseSession stops tracking a client that disconnected or was refused.
+func (h *Hub) releaseSession(c *Client) {
+	if !c.tracked.Swap(false) {
+		return
+	}
+	h.mu.RLock()
+	tracker := h.sessions
+	h.mu.RUnlock()
+	ctx, cancel := context.WithTimeout(context.Background(), eventTTL)
+	defer cancel()
+	if err := tracker.Remove(ctx, c.WebinarID, c.UserID, c.session()); err != nil {
+		h.logger.Warn("untrack session failed", zap.Error(err), zap.String("client_id", c.ID))
+	}
+}
+
+func sessionKey(webinarID, userID uuid.UUID) string {
+	return webinarID.String() + ":" + userID.String()
+}
+
+func sessionMember(s Session) string {
+	return fmt.Sprintf("%019d:%s", s.JoinedAt.UnixNano(), s.ClientID)
+}
+
+func parseSessions(members []string) []Session {
+	out := make([]Session, 0, len(members))
+	for _, m := range members {
+		nanos, id, ok := strings.Cut(m, ":")
+		n, err := strconv.ParseInt(nanos, 10, 64)
+		if !ok || err != nil {
+			continue
+		}
+		out = append(out, Session{ClientID: id, JoinedAt: time.Unix(0, n)})
+	}
+	sortSessions(out)
+	return out
+}
+
+func sortSessions(list []Session) {
+	sort.SliceStable(list, func(i, j int) bool { return list[i].JoinedAt.Before(list[j].JoinedAt) })
+}
+
+func containsSession(sessions []Session, clientID string) bool {
+	for _, s := range sessions {
+		if s.ClientID == clientID {
+			return true
+		}
+	}
+	return false
+}
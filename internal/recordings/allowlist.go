@@ -0,0 +1,36 @@
+package recordings
+
+import (
+	"net/url"
+	"strings"
+)
+
+// HostAllowed reports whether rawURL is an https URL on an allowed host. An entry starting with "."
+// (e.g. ".s3.amazonaws.com") matches any subdomain; other entries must match the host exactly.
+// An empty allowlist allows nothing.
+func HostAllowed(rawURL string, allowed []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false
+	}
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" {
+			continue
+		}
+		if strings.HasPrefix(a, ".") {
+			if strings.HasSuffix(host, a) && len(host) > len(a) {
+				return true
+			}
+			continue
+		}
+		if host == a {
+			return true
+		}
+	}
+	return false
+}
@@ -2,12 +2,14 @@ package recordings
 
 import (
 	"context"
+	"errors"
 	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/aura-webinar/backend/internal/audit"
 	"github.com/aura-webinar/backend/internal/middleware"
 	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/webinars"
@@ -148,6 +150,7 @@ func (h *Handler) StartRecording(c *gin.Context) {
 		response.BadRequest(c, err.Error())
 		return
 	}
+	audit.Annotate(c, audit.Change{Action: "recording.start", TargetType: "recording", TargetID: rec.ID.String(), WebinarID: &webinarID})
 	response.OK(c, gin.H{"recording_id": rec.ID, "status": models.RecordingStatusRecording})
 }
 
@@ -171,31 +174,59 @@ func (h *Handler) StopRecording(c *gin.Context) {
 			return
 		}
 	}
-	path, err := h.recorder.StopRecording(webinarID)
+	rec, s3URL, err := h.stopAndUpload(c.Request.Context(), webinarID)
 	if err != nil {
-		response.NotFound(c, err.Error())
+		var notRecording *notRecordingError
+		if errors.As(err, &notRecording) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.Internal(c, err.Error())
 		return
 	}
+	audit.Annotate(c, audit.Change{Action: "recording.stop", TargetType: "recording", TargetID: rec.ID.String(), WebinarID: &webinarID})
+	response.OK(c, gin.H{"recording_id": rec.ID, "status": models.RecordingStatusCompleted, "s3_url": s3URL})
+}
+
+// FinishRecording stops the webinar's in-app recording, if one is running, and uploads it to S3 (the
+// recording follow-up when a webinar ends). Returns nil when nothing was recording.
+func (h *Handler) FinishRecording(ctx context.Context, webinarID uuid.UUID) (*models.Recording, error) {
+	if h.recorder == nil || !h.recorder.HasActiveRecording(webinarID) {
+		return nil, nil
+	}
+	rec, _, err := h.stopAndUpload(ctx, webinarID)
+	return rec, err
+}
+
+// notRecordingError is returned by stopAndUpload when the recorder has nothing to stop.
+type notRecordingError struct{ err error }
+
+func (e *notRecordingError) Error() string { return e.err.Error() }
+
+// stopAndUpload stops the in-app recording and stores the file in the recordings bucket. Error messages are
+// shown to the caller.
+func (h *Handler) stopAndUpload(ctx context.Context, webinarID uuid.UUID) (*models.Recording, string, error) {
+	path, err := h.recorder.StopRecording(webinarID)
+	if err != nil {
+		return nil, "", &notRecordingError{err: err}
+	}
 	defer func() { _ = os.Remove(path) }()
 
-	rec, err := h.repo.FindByWebinarStatus(c.Request.Context(), webinarID, models.RecordingStatusRecording)
+	rec, err := h.repo.FindByWebinarStatus(ctx, webinarID, models.RecordingStatusRecording)
 	if err != nil || rec == nil {
 		h.logger.Error("find recording in progress failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
-		response.Internal(c, "recording not found")
-		return
+		return nil, "", errors.New("recording not found")
 	}
 
 	if h.s3 == nil {
-		_ = h.repo.UpdateStatus(c.Request.Context(), rec.ID, models.RecordingStatusFailed)
-		response.Internal(c, "S3 not configured")
-		return
+		_ = h.repo.UpdateStatus(ctx, rec.ID, models.RecordingStatusFailed)
+		return nil, "", errors.New("S3 not configured")
 	}
 	f, err := os.Open(path)
 	if err != nil {
-		_ = h.repo.UpdateStatus(c.Request.Context(), rec.ID, models.RecordingStatusFailed)
+		_ = h.repo.UpdateStatus(ctx, rec.ID, models.RecordingStatusFailed)
 		h.logger.Error("open recording file failed", zap.Error(err), zap.String("path", path))
-		response.Internal(c, "failed to upload recording")
-		return
+		return nil, "", errors.New("failed to upload recording")
 	}
 	defer f.Close()
 	info, _ := f.Stat()
@@ -203,15 +234,14 @@ func (h *Handler) StopRecording(c *gin.Context) {
 	key := storage.RecordingKey(rec.WebinarID.String(), rec.ID.String())
 	bucket := h.s3.UploadRecordingsBucket()
 	h.logger.Info("S3 upload starting (AWS credentials from .env)", zap.String("bucket", bucket), zap.String("key", key), zap.String("recording_id", rec.ID.String()), zap.Int64("size", info.Size()))
-	s3URL, err := h.s3.Upload(c.Request.Context(), bucket, key, "video/mp4", f, info.Size(), false)
+	s3URL, err := h.s3.Upload(ctx, bucket, key, "video/mp4", f, info.Size(), false)
 	if err != nil {
-		_ = h.repo.UpdateStatus(c.Request.Context(), rec.ID, models.RecordingStatusFailed)
+		_ = h.repo.UpdateStatus(ctx, rec.ID, models.RecordingStatusFailed)
 		h.logger.Error("upload recording to S3 failed", zap.Error(err), zap.String("recording_id", rec.ID.String()))
-		response.Internal(c, "failed to upload recording")
-		return
+		return nil, "", errors.New("failed to upload recording")
 	}
-	if err := h.repo.UpdateS3Result(c.Request.Context(), rec.ID, s3URL, key, info.Size(), 0); err != nil {
+	if err := h.repo.UpdateS3Result(ctx, rec.ID, s3URL, key, info.Size(), 0); err != nil {
 		h.logger.Error("update recording S3 result failed", zap.Error(err))
 	}
-	response.OK(c, gin.H{"recording_id": rec.ID, "status": models.RecordingStatusCompleted, "s3_url": s3URL})
+	return rec, s3URL, nil
 }
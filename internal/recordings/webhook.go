@@ -2,15 +2,28 @@ package recordings
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
 	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/pkg/queue"
 	"github.com/aura-webinar/backend/pkg/response"
+	"github.com/aura-webinar/backend/pkg/webhook"
+)
+
+const (
+	// maxWebhookBody bounds the recording_ready payload.
+	maxWebhookBody = 1 << 20
+	// deliveryKeyPrefix marks processed delivery IDs in Redis.
+	deliveryKeyPrefix = "webhook:recording_ready:"
+	// deliveryTTL is how long a delivery ID is remembered; longer than any provider retry schedule.
+	deliveryTTL = 72 * time.Hour
 )
 
 // RecordingReadyPayload is the expected body from provider recording_ready webhook.
@@ -25,9 +38,13 @@ type RecordingReadyPayload struct {
 
 // WebhookHandler handles recording webhooks from the video provider (e.g. 100ms/Agora).
 type WebhookHandler struct {
-	repo   *Repository
-	queue  *queue.Queue
-	logger *zap.Logger
+	repo         *Repository
+	queue        *queue.Queue
+	secret       string
+	tolerance    time.Duration
+	allowedHosts []string
+	rdb          *redis.Client
+	logger       *zap.Logger
 }
 
 // NewWebhookHandler creates a webhook handler.
@@ -35,13 +52,49 @@ func NewWebhookHandler(repo *Repository, q *queue.Queue, logger *zap.Logger) *We
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &WebhookHandler{repo: repo, queue: q, logger: logger}
+	return &WebhookHandler{repo: repo, queue: q, tolerance: 5 * time.Minute, logger: logger}
+}
+
+// SetSignature sets the HMAC-SHA256 secret and timestamp tolerance. Without a secret every delivery is rejected.
+func (h *WebhookHandler) SetSignature(secret string, tolerance time.Duration) {
+	h.secret = secret
+	if tolerance > 0 {
+		h.tolerance = tolerance
+	}
+}
+
+// SetAllowedHosts restricts file_url to these hosts (see HostAllowed).
+func (h *WebhookHandler) SetAllowedHosts(hosts []string) {
+	h.allowedHosts = hosts
 }
 
-// RecordingReady handles POST /webhooks/recording-ready. Validates signature (if configured), updates DB, enqueues S3 upload job.
+// SetDeliveryStore enables delivery-ID idempotency so provider retries don't enqueue duplicate uploads.
+func (h *WebhookHandler) SetDeliveryStore(rdb *redis.Client) {
+	h.rdb = rdb
+}
+
+// RecordingReady handles POST /webhooks/recording-ready. Verifies the signature, drops repeated deliveries,
+// updates DB and enqueues the S3 upload job.
 func (h *WebhookHandler) RecordingReady(c *gin.Context) {
+	if h.secret == "" {
+		h.logger.Error("recording webhook rejected: RECORDING_WEBHOOK_SECRET not set")
+		response.ServiceUnavailable(c, "webhook not configured")
+		return
+	}
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody+1))
+	if err != nil || len(raw) > maxWebhookBody {
+		response.BadRequest(c, "invalid request body")
+		return
+	}
+	signature := c.GetHeader(webhook.HeaderSignature)
+	if err := webhook.Verify(h.secret, signature, c.GetHeader(webhook.HeaderTimestamp), raw, h.tolerance, time.Now()); err != nil {
+		h.logger.Warn("recording webhook signature rejected", zap.String("client_ip", c.ClientIP()), zap.Error(err))
+		response.Unauthorized(c, "invalid signature")
+		return
+	}
+
 	var body RecordingReadyPayload
-	if err := c.ShouldBindJSON(&body); err != nil {
+	if err := json.Unmarshal(raw, &body); err != nil {
 		response.BadRequest(c, "invalid request: "+err.Error())
 		return
 	}
@@ -49,9 +102,36 @@ func (h *WebhookHandler) RecordingReady(c *gin.Context) {
 		response.BadRequest(c, "file_url required")
 		return
 	}
+	if !HostAllowed(body.FileURL, h.allowedHosts) {
+		h.logger.Warn("recording webhook file_url host not allowed", zap.String("file_url", body.FileURL))
+		response.BadRequest(c, "file_url host not allowed")
+		return
+	}
 
-	// TODO: Validate webhook signature (e.g. X-Webhook-Signature) when provider supports it.
-	// if !validateSignature(c.GetHeader("X-Webhook-Signature"), body) { response.Unauthorized(c, "invalid signature"); return }
+	// Without a delivery ID the signature identifies the delivery (same body and timestamp = same delivery).
+	deliveryID := c.GetHeader(webhook.HeaderDeliveryID)
+	if deliveryID == "" {
+		deliveryID = signature
+	}
+	if h.rdb != nil {
+		first, err := h.rdb.SetNX(c.Request.Context(), deliveryKeyPrefix+deliveryID, time.Now().Unix(), deliveryTTL).Result()
+		if err != nil {
+			h.logger.Error("recording webhook idempotency check failed", zap.Error(err))
+			response.Internal(c, "failed to process webhook")
+			return
+		}
+		if !first {
+			h.logger.Info("recording webhook duplicate delivery ignored", zap.String("delivery_id", deliveryID))
+			c.JSON(http.StatusOK, gin.H{"success": true, "duplicate": true})
+			return
+		}
+		// Forget the delivery if processing fails so the provider's retry is handled.
+		defer func() {
+			if c.Writer.Status() >= http.StatusInternalServerError {
+				_ = h.rdb.Del(c.Request.Context(), deliveryKeyPrefix+deliveryID).Err()
+			}
+		}()
+	}
 
 	var recordingID uuid.UUID
 	var webinarID uuid.UUID
@@ -122,6 +202,3 @@ func (h *WebhookHandler) RecordingReady(c *gin.Context) {
 	h.logger.Info("recording_ready webhook processed", zap.String("recording_id", rec.ID.String()), zap.String("original_url", body.FileURL))
 	c.JSON(http.StatusOK, gin.H{"success": true, "recording_id": rec.ID, "status": "processing"})
 }
-
-// Ensure JSON binding works (compile-time check).
-var _ = func() ([]byte, error) { return json.Marshal(RecordingReadyPayload{}) }
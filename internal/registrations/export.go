@@ -0,0 +1,204 @@
+package registrations
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/forms"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/response"
+	"github.com/aura-webinar/backend/pkg/xlsx"
+)
+
+const (
+	// Attendance values of the export's Attendance column.
+	AttendanceAttended   = "attended"
+	AttendanceNoShow     = "no_show"
+	AttendanceRegistered = "registered"
+
+	// exportFlushEvery is how many rows are buffered before the export is flushed to the client.
+	exportFlushEvery = 500
+)
+
+// exportWriter is the row sink of one export format.
+type exportWriter interface {
+	header(cols []string) error
+	row(r *ExportRow, values []string) error
+	flush() error
+	close() error
+}
+
+// Export handles GET /webinars/:id/registrations/export?format=csv|xlsx (webinar org access). One row per
+// registration with the registration form's fields as columns (by label), then attendance, live watch time,
+// poll answers and feedback rating. Rows are streamed as they are read.
+func (h *Handler) Export(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	webinarID := w.ID
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format != "csv" && format != "xlsx" {
+		response.BadRequest(c, "format must be csv or xlsx")
+		return
+	}
+	fields, err := forms.ParseConfig(w.AudienceFormConfig)
+	if err != nil {
+		h.logger.Error("parse registration form failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
+		response.Internal(c, "failed to export registrations")
+		return
+	}
+
+	cols := []string{"Registration ID", "Email", "Full name", "Registered at"}
+	for _, f := range fields {
+		if f.Label != "" {
+			cols = append(cols, f.Label)
+		} else {
+			cols = append(cols, f.ID)
+		}
+	}
+	cols = append(cols, "Attendance", "Watch seconds", "Poll answers", "Feedback rating")
+
+	filename := "registrations-" + webinarID.String() + "." + format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	var out exportWriter
+	if format == "xlsx" {
+		c.Header("Content-Type", xlsx.ContentType)
+		c.Status(http.StatusOK)
+		xw, err := xlsx.NewWriter(c.Writer, "Registrations")
+		if err != nil {
+			h.logger.Error("start registration export failed", zap.Error(err))
+			return
+		}
+		out = &xlsxExport{w: xw}
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		out = &csvExport{w: csv.NewWriter(c.Writer)}
+	}
+
+	started := time.Now().After(w.StartsAt)
+	n := 0
+	err = out.header(cols)
+	if err == nil {
+		err = h.repo.StreamExport(c.Request.Context(), webinarID, func(r *ExportRow) error {
+			if err := out.row(r, exportValues(r, fields, started)); err != nil {
+				return err
+			}
+			if n++; n%exportFlushEvery == 0 {
+				if err := out.flush(); err != nil {
+					return err
+				}
+				c.Writer.Flush()
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = out.close()
+	}
+	if err != nil {
+		// Headers are sent; the client gets a truncated file.
+		h.logger.Error("registration export failed", zap.Error(err), zap.String("webinar_id", webinarID.String()), zap.Int("rows", n))
+		return
+	}
+	c.Writer.Flush()
+}
+
+// exportValues flattens a registration into export cells (all columns as text).
+func exportValues(r *ExportRow, fields []models.FormFieldConfig, started bool) []string {
+	values := []string{r.ID.String(), r.Email, r.FullName, r.CreatedAt.UTC().Format(time.RFC3339)}
+	var answers map[string]string
+	if len(r.ExtraData) > 0 {
+		_ = json.Unmarshal(r.ExtraData, &answers)
+	}
+	for _, f := range fields {
+		values = append(values, formatAnswer(f, answers[f.ID]))
+	}
+	attendance := AttendanceRegistered
+	switch {
+	case r.AttendedAt != nil || r.Sessions > 0:
+		attendance = AttendanceAttended
+	case started:
+		attendance = AttendanceNoShow
+	}
+	rating := ""
+	if r.FeedbackScore != nil {
+		rating = fmt.Sprint(*r.FeedbackScore)
+	}
+	return append(values, attendance, fmt.Sprint(r.WatchSeconds), fmt.Sprint(r.PollAnswers), rating)
+}
+
+// formatAnswer renders a stored answer for a spreadsheet cell: multiple-choice checkbox answers (JSON arrays)
+// become "a; b".
+func formatAnswer(f models.FormFieldConfig, v string) string {
+	if f.Type == forms.TypeCheckbox && strings.HasPrefix(v, "[") {
+		var choices []string
+		if json.Unmarshal([]byte(v), &choices) == nil {
+			return strings.Join(choices, "; ")
+		}
+	}
+	return v
+}
+
+type csvExport struct {
+	w *csv.Writer
+}
+
+func (e *csvExport) header(cols []string) error { return e.w.Write(cols) }
+
+func (e *csvExport) row(_ *ExportRow, values []string) error {
+	for i, v := range values {
+		values[i] = csvSafe(v)
+	}
+	return e.w.Write(values)
+}
+
+func (e *csvExport) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExport) close() error { return e.flush() }
+
+// csvSafe neutralizes values a spreadsheet would evaluate as a formula (attendee answers are untrusted).
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+type xlsxExport struct {
+	w *xlsx.Writer
+}
+
+func (e *xlsxExport) header(cols []string) error { return e.w.WriteStrings(cols) }
+
+// row writes the engagement columns as numbers; inline strings are never evaluated, so no formula guard.
+func (e *xlsxExport) row(r *ExportRow, values []string) error {
+	n := len(values)
+	cells := make([]xlsx.Cell, 0, n)
+	for _, v := range values[:n-3] {
+		cells = append(cells, xlsx.String(v))
+	}
+	cells = append(cells, xlsx.Number(float64(r.WatchSeconds)), xlsx.Number(float64(r.PollAnswers)))
+	if r.FeedbackScore != nil {
+		cells = append(cells, xlsx.Number(float64(*r.FeedbackScore)))
+	} else {
+		cells = append(cells, xlsx.String(""))
+	}
+	return e.w.WriteRow(cells)
+}
+
+func (e *xlsxExport) flush() error { return e.w.Flush() }
+
+func (e *xlsxExport) close() error { return e.w.Close() }
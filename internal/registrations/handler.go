@@ -1,10 +1,12 @@
 package registrations
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
+	"path"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,10 +14,12 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aura-webinar/backend/internal/auth"
+	"github.com/aura-webinar/backend/internal/forms"
 	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/waitlist"
 	"github.com/aura-webinar/backend/internal/webinars"
 	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/ical"
 	"github.com/aura-webinar/backend/pkg/queue"
 	"github.com/aura-webinar/backend/pkg/response"
 	"github.com/aura-webinar/backend/pkg/storage"
@@ -26,7 +30,8 @@ import (
 type RegisterRequest struct {
 	Email          string            `json:"email" binding:"required,email"`
 	FullName       string            `json:"full_name" binding:"required"`
-	FormResponses  map[string]string `json:"form_responses,omitempty"` // dynamic fields from audience_form_config
+	FormResponses  map[string]string `json:"form_responses,omitempty"` // dynamic fields from audience_form_config (validated by forms.ValidateResponses)
+	Locale         string            `json:"locale,omitempty"`         // email language; defaults to Accept-Language
 }
 
 // Handler handles registration HTTP endpoints.
@@ -39,6 +44,9 @@ type Handler struct {
 	jobQueue     *queue.Queue
 	s3Client     *storage.S3
 	frontendURL  string
+	sessions     SessionController
+	joinLimit    int    // default concurrent sessions per attendee; 0 = unlimited
+	joinOnLimit  string // default models.JoinLimit* policy
 	logger       *zap.Logger
 }
 
@@ -72,7 +80,8 @@ func (h *Handler) SetEmailQueue(q *queue.Queue, frontendURL string) {
 	h.frontendURL = frontendURL
 }
 
-// Register handles POST /webinars/:id/register. Creates registration and unique join token.
+// Register handles POST /webinars/:id/register. Creates registration and unique join token, which is
+// emailed to the registrant.
 func (h *Handler) Register(c *gin.Context) {
 	webinarID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -80,10 +89,15 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 	w, err := h.webinarRepo.GetByID(c.Request.Context(), webinarID)
-	if err != nil || w == nil {
+	if err != nil || w == nil || w.Visibility == models.WebinarVisibilityPrivate {
+		// Private webinars take no self-registration; organizers add their attendees.
 		response.NotFound(c, "webinar not found")
 		return
 	}
+	if !w.Published() {
+		response.Conflict(c, "registration is closed for this webinar")
+		return
+	}
 
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -91,10 +105,21 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
+	fields, err := forms.ParseConfig(w.AudienceFormConfig)
+	if err != nil {
+		h.logger.Error("parse registration form failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
+		response.Internal(c, "failed to register")
+		return
+	}
+	answers, fieldErrs := forms.ValidateResponses(fields, req.FormResponses, forms.Options{FileURLPrefix: h.fileURLPrefix(webinarID)})
+	if len(fieldErrs) > 0 {
+		response.BadRequestDetails(c, "invalid form_responses", gin.H{"fields": fieldErrs})
+		return
+	}
+
 	var extraData json.RawMessage
-	if len(req.FormResponses) > 0 {
-		var err error
-		extraData, err = json.Marshal(req.FormResponses)
+	if len(answers) > 0 {
+		extraData, err = json.Marshal(answers)
 		if err != nil {
 			response.BadRequest(c, "invalid form_responses")
 			return
@@ -131,11 +156,16 @@ func (h *Handler) Register(c *gin.Context) {
 		}
 	}
 
+	locale := email.NormalizeLocale(req.Locale)
+	if locale == "" {
+		locale = email.LocaleFromAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
 	reg := &models.Registration{
 		WebinarID: webinarID,
 		Email:     req.Email,
 		FullName:  req.FullName,
 		ExtraData: extraData,
+		Locale:    locale,
 	}
 	if err := h.repo.CreateRegistration(c.Request.Context(), reg); err != nil {
 		h.logger.Error("create registration failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
@@ -143,52 +173,26 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	tokenStr, err := generateToken()
+	tok, err := h.repo.IssueToken(c.Request.Context(), reg.ID)
 	if err != nil {
-		h.logger.Error("generate token failed", zap.Error(err))
-		response.Internal(c, "failed to generate join link")
-		return
-	}
-	expiresAt := time.Now().Add(30 * 24 * time.Hour) // 30 days
-	tok := &models.RegistrationToken{
-		RegistrationID: reg.ID,
-		Token:          tokenStr,
-		ExpiresAt:      expiresAt,
-	}
-	if err := h.repo.CreateToken(c.Request.Context(), tok); err != nil {
 		h.logger.Error("create token failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
 		response.Internal(c, "failed to create join link")
 		return
 	}
-
-	joinURL := "/audience?webinar_id=" + webinarID.String() + "&token=" + tokenStr
-	if h.jobQueue != nil && h.frontendURL != "" {
-		fullJoinURL := email.BuildJoinURL(h.frontendURL, webinarID.String(), tokenStr)
-		startsAt := w.StartsAt.Format(time.RFC3339)
-		payload := queue.EmailPayload{
-			EmailType:       models.EmailTypeRegistrationConfirmation,
-			WebinarID:       webinarID,
-			RegistrationID:  reg.ID,
-			RecipientEmail:  reg.Email,
-			RecipientName:   reg.FullName,
-			WebinarTitle:    w.Title,
-			WebinarStartsAt: startsAt,
-			JoinURL:         fullJoinURL,
-			Subject:         fmt.Sprintf("You're registered: %s", w.Title),
-		}
-		if err := h.jobQueue.EnqueueEmail(c.Request.Context(), payload); err != nil {
-			h.logger.Warn("enqueue confirmation email failed", zap.Error(err))
-		}
+	// The join link goes to the registered address only: anyone may register with any email.
+	if err := h.sendConfirmation(c.Request.Context(), w, reg, tok.Token); err != nil {
+		h.logger.Warn("enqueue confirmation email failed", zap.Error(err))
 	}
 	response.OK(c, gin.H{
+		"status":          "registered",
 		"registration_id": reg.ID,
-		"join_token":      tokenStr,
-		"join_url":        joinURL,
-		"expires_at":      expiresAt,
+		"message":         "You're registered. Your join link has been sent to your email.",
 	})
 }
 
-// ExchangeToken handles POST /auth/exchange-token. Exchanges registration join_token for JWT so audience can join live webinar.
+// ExchangeToken handles POST /auth/exchange-token. Exchanges registration join_token for an audience-only
+// guest JWT so the attendee can join the live webinar.
+// Join links are reusable; how many devices may be live at once is the webinar's join policy (see SessionLimit).
 func (h *Handler) ExchangeToken(c *gin.Context) {
 	if h.authRepo == nil || h.jwtService == nil {
 		response.Internal(c, "auth service not configured")
@@ -213,10 +217,6 @@ func (h *Handler) ExchangeToken(c *gin.Context) {
 		response.Unauthorized(c, "invalid or expired token")
 		return
 	}
-	if tok.UsedAt != nil {
-		response.BadRequest(c, "token already used")
-		return
-	}
 	if time.Now().After(tok.ExpiresAt) {
 		response.BadRequest(c, "token expired")
 		return
@@ -231,26 +231,100 @@ func (h *Handler) ExchangeToken(c *gin.Context) {
 		response.BadRequest(c, "token not valid for this webinar")
 		return
 	}
+	w, err := h.webinarRepo.GetByID(c.Request.Context(), webinarID)
+	if err != nil || w == nil {
+		response.NotFound(c, "webinar not found")
+		return
+	}
+	if !w.Published() {
+		response.Conflict(c, "this webinar is "+w.Status)
+		return
+	}
+
+	// The attendee joins as the registration's guest user (audience needs user_id for WebSocket, questions,
+	// polls), never as the account with the registration's email: that address is unverified.
+	user, ok := h.guestUser(c, reg)
+	if !ok {
+		return
+	}
+	token, err := h.jwtService.GenerateGuest(user.ID, reg.ID)
+	if err != nil {
+		response.Internal(c, "failed to generate token")
+		return
+	}
+	h.logTokenUse(c, tok, user.ID)
+
+	response.OK(c, gin.H{"token": token, "user": user.ToPublic()})
+}
 
-	// Find or create user for this registration (audience needs user_id for WebSocket, questions, polls)
-	user, err := h.authRepo.GetByEmail(c.Request.Context(), reg.Email)
+// guestEmail is the placeholder address of a registration's guest user (.invalid never receives mail).
+func guestEmail(registrationID uuid.UUID) string {
+	return "guest-" + registrationID.String() + "@guests.invalid"
+}
+
+// guestUser finds or creates the audience user the registration's attendee joins under. Writes the error
+// response on failure.
+func (h *Handler) guestUser(c *gin.Context, reg *models.Registration) (*models.User, bool) {
+	addr := guestEmail(reg.ID)
+	user, err := h.authRepo.GetByEmail(c.Request.Context(), addr)
 	if err != nil || user == nil {
-		randomPass, _ := utils.HashPassword(uuid.New().String() + reg.Email)
-		user, err = h.authRepo.Create(c.Request.Context(), reg.Email, randomPass, reg.FullName, models.RoleAudience, nil, true)
+		randomPass, _ := utils.HashPassword(uuid.New().String() + addr)
+		user, err = h.authRepo.Create(c.Request.Context(), addr, randomPass, reg.FullName, models.RoleAudience, nil, false)
 		if err != nil {
-			h.logger.Error("create guest user failed", zap.Error(err), zap.String("email", reg.Email))
+			h.logger.Error("create guest user failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
 			response.Internal(c, "failed to create session")
-			return
+			return nil, false
 		}
 	}
+	if user.Role != models.RoleAudience {
+		response.Forbidden(c, "this join link cannot be used")
+		return nil, false
+	}
+	return user, true
+}
 
-	token, err := h.jwtService.Generate(user.ID, user.Email, string(user.Role))
+// ListByWebinar handles GET /webinars/:id/registrations (webinar org access), newest first.
+// Query: search (email or name), attended (true/false), limit (max 200), offset.
+func (h *Handler) ListByWebinar(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	f := Filter{Search: c.Query("search"), Limit: defaultPageSize}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			response.BadRequest(c, "limit must be 1–200")
+			return
+		}
+		f.Limit = n
+	}
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(c, "invalid offset")
+			return
+		}
+		f.Offset = n
+	}
+	if v := c.Query("attended"); v != "" {
+		attended, err := strconv.ParseBool(v)
+		if err != nil {
+			response.BadRequest(c, "attended must be true or false")
+			return
+		}
+		f.Attended = &attended
+	}
+	list, total, err := h.repo.Search(c.Request.Context(), w.ID, f)
 	if err != nil {
-		response.Internal(c, "failed to generate token")
+		h.logger.Error("list registrations failed", zap.Error(err))
+		response.Internal(c, "failed to list registrations")
 		return
 	}
-
-	response.OK(c, gin.H{"token": token, "user": user.ToPublic()})
+	if list == nil {
+		list = []models.Registration{}
+	}
+	response.OK(c, gin.H{"items": list, "total": total, "offset": f.Offset, "limit": f.Limit})
 }
 
 // ValidateToken handles GET /registrations/:token/validate. Returns registration + webinar info if token valid.
@@ -266,10 +340,6 @@ func (h *Handler) ValidateToken(c *gin.Context) {
 		response.NotFound(c, "invalid or expired token")
 		return
 	}
-	if tok.UsedAt != nil {
-		response.BadRequest(c, "token already used")
-		return
-	}
 	if time.Now().After(tok.ExpiresAt) {
 		response.BadRequest(c, "token expired")
 		return
@@ -288,14 +358,43 @@ func (h *Handler) ValidateToken(c *gin.Context) {
 	}
 
 	response.OK(c, gin.H{
-		"valid":             true,
+		"valid":             w.Published(), // false once the webinar ended or was cancelled
 		"registration":      reg,
 		"webinar_id":        w.ID,
 		"webinar_title":     w.Title,
 		"webinar_starts_at": w.StartsAt,
+		"webinar_status":    w.Status,
 	})
 }
 
+// sendConfirmation enqueues the registration confirmation email with the join link for token. It is a no-op
+// without an email queue.
+func (h *Handler) sendConfirmation(ctx context.Context, w *models.Webinar, reg *models.Registration, token string) error {
+	if h.jobQueue == nil || h.frontendURL == "" {
+		return nil
+	}
+	return h.jobQueue.EnqueueEmail(ctx, queue.EmailPayload{
+		EmailType:       models.EmailTypeRegistrationConfirmation,
+		WebinarID:       w.ID,
+		RegistrationID:  reg.ID,
+		RecipientEmail:  reg.Email,
+		RecipientName:   reg.FullName,
+		WebinarTitle:    w.Title,
+		WebinarStartsAt: w.StartsAt.Format(time.RFC3339),
+		JoinURL:         email.BuildJoinURL(h.frontendURL, w.ID.String(), token),
+		Locale:          reg.Locale,
+		CalendarMethod:  ical.MethodRequest,
+	})
+}
+
+// fileURLPrefix is the URL prefix of files UploadFile stores for the webinar ("" without S3).
+func (h *Handler) fileURLPrefix(webinarID uuid.UUID) string {
+	if h.s3Client == nil {
+		return ""
+	}
+	return h.s3Client.PublicObjectURL(h.s3Client.UploadAdPresignedBucket(), path.Join(storage.FolderRegistration, webinarID.String())+"/")
+}
+
 func generateToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -316,6 +415,10 @@ func (h *Handler) UploadFile(c *gin.Context) {
 		response.NotFound(c, "webinar not found")
 		return
 	}
+	if !w.Published() {
+		response.Conflict(c, "registration is closed for this webinar")
+		return
+	}
 	if h.s3Client == nil {
 		response.Internal(c, "file upload not configured")
 		return
@@ -0,0 +1,246 @@
+package registrations
+
+import (
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/forms"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+	// maxFullNameLength matches registrations.full_name.
+	maxFullNameLength = 255
+)
+
+// UpdateRequest is the body for PATCH /webinars/:id/registrations/:registrationId. Omitted fields are
+// unchanged; form_responses replaces all answers and is validated like a registration.
+type UpdateRequest struct {
+	Email         *string           `json:"email"`
+	FullName      *string           `json:"full_name"`
+	Locale        *string           `json:"locale"`
+	FormResponses map[string]string `json:"form_responses"`
+}
+
+// ResendRequest is the body for POST /webinars/:id/registrations/:registrationId/resend.
+type ResendRequest struct {
+	RevokePrevious bool `json:"revoke_previous"` // revoke the registration's earlier join links
+}
+
+// Update handles PATCH /webinars/:id/registrations/:registrationId: corrects an attendee's email, name,
+// language or form answers. A new email revokes the registration's join links and sends a new one there.
+func (h *Handler) Update(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	reg, ok := h.loadRegistration(c, w)
+	if !ok {
+		return
+	}
+	var req UpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid body")
+		return
+	}
+	before := *reg
+	if req.Email != nil {
+		addr, err := mail.ParseAddress(strings.TrimSpace(*req.Email))
+		if err != nil || addr.Name != "" {
+			response.BadRequest(c, "invalid email")
+			return
+		}
+		if !strings.EqualFold(addr.Address, reg.Email) && !h.emailAssignable(c, addr.Address) {
+			return
+		}
+		reg.Email = addr.Address
+	}
+	if req.FullName != nil {
+		name := strings.TrimSpace(*req.FullName)
+		if name == "" || len([]rune(name)) > maxFullNameLength {
+			response.BadRequest(c, "full_name must be 1–255 characters")
+			return
+		}
+		reg.FullName = name
+	}
+	if req.Locale != nil {
+		reg.Locale = email.NormalizeLocale(*req.Locale)
+		if reg.Locale == "" && strings.TrimSpace(*req.Locale) != "" {
+			response.BadRequest(c, "invalid locale")
+			return
+		}
+	}
+	if req.FormResponses != nil {
+		fields, err := forms.ParseConfig(w.AudienceFormConfig)
+		if err != nil {
+			h.logger.Error("parse registration form failed", zap.Error(err), zap.String("webinar_id", w.ID.String()))
+			response.Internal(c, "failed to update registration")
+			return
+		}
+		answers, fieldErrs := forms.ValidateResponses(fields, req.FormResponses, forms.Options{FileURLPrefix: h.fileURLPrefix(w.ID)})
+		if len(fieldErrs) > 0 {
+			response.BadRequestDetails(c, "invalid form_responses", gin.H{"fields": fieldErrs})
+			return
+		}
+		reg.ExtraData = nil
+		if len(answers) > 0 {
+			if reg.ExtraData, err = json.Marshal(answers); err != nil {
+				response.BadRequest(c, "invalid form_responses")
+				return
+			}
+		}
+	}
+	if err := h.repo.Update(c.Request.Context(), reg); err != nil {
+		if errors.Is(err, ErrEmailTaken) {
+			response.Conflict(c, "email is already registered for this webinar")
+			return
+		}
+		h.logger.Error("update registration failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		response.Internal(c, "failed to update registration")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "registration.update", TargetType: "registration", TargetID: reg.ID.String(), Before: before, After: reg})
+	if !strings.EqualFold(before.Email, reg.Email) && !h.reissueLink(c, w, reg) {
+		return
+	}
+	response.OK(c, reg)
+}
+
+// emailAssignable reports whether a registration's email may be changed to addr: not the address of an
+// existing non-audience account, whose owner would otherwise receive another person's registration.
+// Writes the error response on failure.
+func (h *Handler) emailAssignable(c *gin.Context, addr string) bool {
+	if h.authRepo == nil {
+		return true
+	}
+	if u, err := h.authRepo.GetByEmail(c.Request.Context(), addr); err == nil && u != nil && u.Role != models.RoleAudience {
+		response.Conflict(c, "email belongs to an organizer account")
+		return false
+	}
+	return true
+}
+
+// reissueLink revokes the registration's join links after its email changed and emails a new one to the
+// new address only. Writes the error response if the old links could not be revoked; a missing new link
+// is only logged (the organizer can resend it).
+func (h *Handler) reissueLink(c *gin.Context, w *models.Webinar, reg *models.Registration) bool {
+	ctx := c.Request.Context()
+	if _, err := h.repo.RevokeTokens(ctx, reg.ID, uuid.Nil); err != nil {
+		h.logger.Error("revoke tokens failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		response.Internal(c, "email updated, but the previous join links could not be revoked")
+		return false
+	}
+	tok, err := h.repo.IssueToken(ctx, reg.ID)
+	if err != nil {
+		h.logger.Error("create token failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		return true
+	}
+	if err := h.sendConfirmation(ctx, w, reg, tok.Token); err != nil {
+		h.logger.Warn("enqueue confirmation email failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+	}
+	return true
+}
+
+// ResendLink handles POST /webinars/:id/registrations/:registrationId/resend: issues a fresh join token and
+// emails the confirmation with it. Earlier links stay valid unless revoke_previous is set.
+func (h *Handler) ResendLink(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	reg, ok := h.loadRegistration(c, w)
+	if !ok {
+		return
+	}
+	var req ResendRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "invalid body")
+			return
+		}
+	}
+	tok, err := h.repo.IssueToken(c.Request.Context(), reg.ID)
+	if err != nil {
+		h.logger.Error("create token failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		response.Internal(c, "failed to create join link")
+		return
+	}
+	var revoked int64
+	if req.RevokePrevious {
+		if revoked, err = h.repo.RevokeTokens(c.Request.Context(), reg.ID, tok.ID); err != nil {
+			h.logger.Error("revoke tokens failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+			response.Internal(c, "failed to revoke previous join links")
+			return
+		}
+	}
+	emailed := h.jobQueue != nil && h.frontendURL != ""
+	if err := h.sendConfirmation(c.Request.Context(), w, reg, tok.Token); err != nil {
+		h.logger.Warn("enqueue confirmation email failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		emailed = false
+	}
+	audit.Annotate(c, audit.Change{Action: "registration.resend_link", TargetType: "registration", TargetID: reg.ID.String(),
+		After: gin.H{"token_id": tok.ID, "revoked_previous": revoked, "emailed": emailed}})
+	response.OK(c, gin.H{
+		"registration_id": reg.ID,
+		"join_token":      tok.Token,
+		"join_url":        "/audience?webinar_id=" + w.ID.String() + "&token=" + tok.Token,
+		"expires_at":      tok.ExpiresAt,
+		"emailed":         emailed,
+		"revoked":         revoked,
+	})
+}
+
+// Revoke handles POST /webinars/:id/registrations/:registrationId/revoke: revokes all of the registration's
+// join links, so they no longer validate, exchange for a session or receive reminders. Sessions already
+// exchanged last until their JWT expires; resending the link restores access.
+func (h *Handler) Revoke(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	reg, ok := h.loadRegistration(c, w)
+	if !ok {
+		return
+	}
+	revoked, err := h.repo.RevokeTokens(c.Request.Context(), reg.ID, uuid.Nil)
+	if err != nil {
+		h.logger.Error("revoke tokens failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		response.Internal(c, "failed to revoke join links")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "registration.revoke", TargetType: "registration", TargetID: reg.ID.String(),
+		After: gin.H{"revoked": revoked}})
+	response.OK(c, gin.H{"registration_id": reg.ID, "revoked": revoked})
+}
+
+// managedWebinar loads :id and requires webinars.CanManage. Writes the error response on failure.
+func (h *Handler) managedWebinar(c *gin.Context) (*models.Webinar, bool) {
+	return webinars.ManagedWebinar(c, h.webinarRepo)
+}
+
+// loadRegistration parses :registrationId and requires it to belong to the webinar.
+func (h *Handler) loadRegistration(c *gin.Context, w *models.Webinar) (*models.Registration, bool) {
+	id, err := uuid.Parse(c.Param("registrationId"))
+	if err != nil {
+		response.BadRequest(c, "invalid registration id")
+		return nil, false
+	}
+	reg, err := h.repo.GetRegistrationByID(c.Request.Context(), id)
+	if err != nil || reg.WebinarID != w.ID {
+		response.NotFound(c, "registration not found")
+		return nil, false
+	}
+	return reg, true
+}
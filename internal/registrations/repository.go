@@ -2,13 +2,30 @@ package registrations
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/aura-webinar/backend/internal/models"
 )
 
+// TokenTTL is how long a join token issued at registration stays valid.
+const TokenTTL = 30 * 24 * time.Hour
+
+// uniqueViolation is the Postgres error code for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// ErrEmailTaken is returned when a registration's new email is already registered for the webinar.
+var ErrEmailTaken = errors.New("email already registered for this webinar")
+
+// likeEscaper escapes LIKE wildcards in user search input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // Repository handles registration and token persistence.
 type Repository struct {
 	pool *pgxpool.Pool
@@ -21,19 +38,20 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 
 // CreateRegistration inserts a registration (unique per webinar+email).
 func (r *Repository) CreateRegistration(ctx context.Context, reg *models.Registration) error {
-	const q = `INSERT INTO registrations (id, webinar_id, email, full_name, extra_data)
-		VALUES (gen_random_uuid(), $1, $2, $3, $4)
-		ON CONFLICT (webinar_id, email) DO UPDATE SET full_name = EXCLUDED.full_name, extra_data = EXCLUDED.extra_data, updated_at = NOW()
+	const q = `INSERT INTO registrations (id, webinar_id, email, full_name, extra_data, locale)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (webinar_id, email) DO UPDATE SET full_name = EXCLUDED.full_name, extra_data = EXCLUDED.extra_data,
+			locale = COALESCE(EXCLUDED.locale, registrations.locale), updated_at = NOW()
 		RETURNING id, attended_at, created_at, updated_at`
-	return r.pool.QueryRow(ctx, q, reg.WebinarID, reg.Email, reg.FullName, reg.ExtraData).
+	return r.pool.QueryRow(ctx, q, reg.WebinarID, reg.Email, reg.FullName, reg.ExtraData, reg.Locale).
 		Scan(&reg.ID, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt)
 }
 
 // GetRegistrationByID returns a registration by ID.
 func (r *Repository) GetRegistrationByID(ctx context.Context, id uuid.UUID) (*models.Registration, error) {
-	const q = `SELECT id, webinar_id, email, full_name, extra_data, attended_at, created_at, updated_at FROM registrations WHERE id = $1`
+	const q = `SELECT id, webinar_id, email, full_name, extra_data, COALESCE(locale, ''), attended_at, created_at, updated_at FROM registrations WHERE id = $1`
 	var reg models.Registration
-	err := r.pool.QueryRow(ctx, q, id).Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt)
+	err := r.pool.QueryRow(ctx, q, id).Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.Locale, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -42,9 +60,24 @@ func (r *Repository) GetRegistrationByID(ctx context.Context, id uuid.UUID) (*mo
 
 // GetRegistrationByWebinarAndEmail returns the registration for webinar+email.
 func (r *Repository) GetRegistrationByWebinarAndEmail(ctx context.Context, webinarID uuid.UUID, email string) (*models.Registration, error) {
-	const q = `SELECT id, webinar_id, email, full_name, extra_data, attended_at, created_at, updated_at FROM registrations WHERE webinar_id = $1 AND email = $2`
+	const q = `SELECT id, webinar_id, email, full_name, extra_data, COALESCE(locale, ''), attended_at, created_at, updated_at FROM registrations WHERE webinar_id = $1 AND email = $2`
+	var reg models.Registration
+	err := r.pool.QueryRow(ctx, q, webinarID, email).Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.Locale, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+// FindByEmail returns the webinar's registration for email, compared case-insensitively, or nil, nil.
+func (r *Repository) FindByEmail(ctx context.Context, webinarID uuid.UUID, email string) (*models.Registration, error) {
+	const q = `SELECT id, webinar_id, email, full_name, extra_data, COALESCE(locale, ''), attended_at, created_at, updated_at
+		FROM registrations WHERE webinar_id = $1 AND lower(email) = lower($2) ORDER BY created_at LIMIT 1`
 	var reg models.Registration
-	err := r.pool.QueryRow(ctx, q, webinarID, email).Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt)
+	err := r.pool.QueryRow(ctx, q, webinarID, email).Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.Locale, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +86,7 @@ func (r *Repository) GetRegistrationByWebinarAndEmail(ctx context.Context, webin
 
 // ListByWebinar returns all registrations for a webinar.
 func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]models.Registration, error) {
-	rows, err := r.pool.Query(ctx, `SELECT id, webinar_id, email, full_name, extra_data, attended_at, created_at, updated_at FROM registrations WHERE webinar_id = $1 ORDER BY created_at DESC`, webinarID)
+	rows, err := r.pool.Query(ctx, `SELECT id, webinar_id, email, full_name, extra_data, COALESCE(locale, ''), attended_at, created_at, updated_at FROM registrations WHERE webinar_id = $1 ORDER BY created_at DESC`, webinarID)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +94,7 @@ func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]
 	var list []models.Registration
 	for rows.Next() {
 		var reg models.Registration
-		if err := rows.Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt); err != nil {
+		if err := rows.Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.Locale, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt); err != nil {
 			return nil, err
 		}
 		list = append(list, reg)
@@ -69,6 +102,74 @@ func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]
 	return list, rows.Err()
 }
 
+// Filter narrows a registration search.
+type Filter struct {
+	Search   string // substring of email or full name, case-insensitive
+	Attended *bool
+	Limit    int
+	Offset   int
+}
+
+// searchWhere selects registrations for Search: $1 webinar, $2 escaped search text, $3 attended (or NULL).
+const searchWhere = `WHERE webinar_id = $1
+	AND ($2 = '' OR email ILIKE '%' || $2 || '%' OR full_name ILIKE '%' || $2 || '%')
+	AND ($3::BOOLEAN IS NULL OR (attended_at IS NOT NULL) = $3)`
+
+// Search returns one page of the webinar's registrations matching f, newest first, and the number of matches.
+func (r *Repository) Search(ctx context.Context, webinarID uuid.UUID, f Filter) ([]models.Registration, int, error) {
+	search := likeEscaper.Replace(strings.TrimSpace(f.Search))
+	rows, err := r.pool.Query(ctx, `SELECT id, webinar_id, email, full_name, extra_data, COALESCE(locale, ''), attended_at, created_at, updated_at, COUNT(*) OVER ()
+		FROM registrations `+searchWhere+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5`, webinarID, search, f.Attended, f.Limit, f.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []models.Registration
+	total := 0
+	for rows.Next() {
+		var reg models.Registration
+		if err := rows.Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.Locale, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt, &total); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, reg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if len(list) == 0 && f.Offset > 0 {
+		// Past the last page, so no row carried the count.
+		err = r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM registrations `+searchWhere, webinarID, search, f.Attended).Scan(&total)
+	}
+	return list, total, err
+}
+
+// Update saves a registration's email, full name, locale and form responses. Returns ErrEmailTaken if
+// another registration of the webinar has the new email.
+func (r *Repository) Update(ctx context.Context, reg *models.Registration) error {
+	const q = `UPDATE registrations SET email = $2, full_name = $3, locale = NULLIF($4, ''), extra_data = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+	err := r.pool.QueryRow(ctx, q, reg.ID, reg.Email, reg.FullName, reg.Locale, reg.ExtraData).Scan(&reg.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// RevokeTokens revokes every join token of a registration that is not revoked yet, except keep (uuid.Nil
+// revokes all). Returns how many were revoked.
+func (r *Repository) RevokeTokens(ctx context.Context, registrationID, keep uuid.UUID) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE registration_tokens SET revoked_at = NOW()
+		WHERE registration_id = $1 AND id <> $2 AND revoked_at IS NULL`, registrationID, keep)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
 // CountByWebinar returns total registrations and attended count for a webinar.
 func (r *Repository) CountByWebinar(ctx context.Context, webinarID uuid.UUID) (total, attended int, err error) {
 	const q = `SELECT COUNT(*), COUNT(attended_at) FROM registrations WHERE webinar_id = $1`
@@ -92,9 +193,28 @@ func (r *Repository) CreateToken(ctx context.Context, t *models.RegistrationToke
 		Scan(&t.ID, &t.UsedAt, &t.CreatedAt)
 }
 
-// GetTokenByToken returns a token by its string (for validation).
+// IssueToken generates and stores a new join token for a registration, valid for TokenTTL.
+func (r *Repository) IssueToken(ctx context.Context, registrationID uuid.UUID) (*models.RegistrationToken, error) {
+	return r.IssueTokenUntil(ctx, registrationID, time.Now().Add(TokenTTL))
+}
+
+// IssueTokenUntil generates and stores a new join token for a registration, valid until expiresAt (for
+// webinars further ahead than TokenTTL).
+func (r *Repository) IssueTokenUntil(ctx context.Context, registrationID uuid.UUID, expiresAt time.Time) (*models.RegistrationToken, error) {
+	tokenStr, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	t := &models.RegistrationToken{RegistrationID: registrationID, Token: tokenStr, ExpiresAt: expiresAt}
+	if err := r.CreateToken(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetTokenByToken returns a token by its string (for validation). Revoked tokens are not found.
 func (r *Repository) GetTokenByToken(ctx context.Context, tokenStr string) (*models.RegistrationToken, error) {
-	const q = `SELECT id, registration_id, token, expires_at, used_at, created_at FROM registration_tokens WHERE token = $1`
+	const q = `SELECT id, registration_id, token, expires_at, used_at, created_at FROM registration_tokens WHERE token = $1 AND revoked_at IS NULL`
 	var t models.RegistrationToken
 	err := r.pool.QueryRow(ctx, q, tokenStr).Scan(&t.ID, &t.RegistrationID, &t.Token, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
 	if err != nil {
@@ -103,23 +223,93 @@ func (r *Repository) GetTokenByToken(ctx context.Context, tokenStr string) (*mod
 	return &t, nil
 }
 
-// MarkTokenUsed sets used_at for a token.
+// MarkTokenUsed sets used_at when a token is first exchanged (tokens stay reusable).
 func (r *Repository) MarkTokenUsed(ctx context.Context, tokenID uuid.UUID) error {
 	const q = `UPDATE registration_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`
 	_, err := r.pool.Exec(ctx, q, tokenID)
 	return err
 }
 
+// LogTokenUse records that a join link was exchanged for a session.
+func (r *Repository) LogTokenUse(ctx context.Context, u *models.RegistrationTokenUse) error {
+	const q = `INSERT INTO registration_token_uses (token_id, registration_id, user_id, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	return r.pool.QueryRow(ctx, q, u.TokenID, u.RegistrationID, u.UserID, u.IPAddress, u.UserAgent).Scan(&u.ID, &u.CreatedAt)
+}
+
+// ListTokenUses returns the latest join link exchanges of a registration, newest first.
+func (r *Repository) ListTokenUses(ctx context.Context, registrationID uuid.UUID, limit int) ([]models.RegistrationTokenUse, error) {
+	const q = `SELECT id, token_id, registration_id, user_id, ip_address, user_agent, created_at
+		FROM registration_token_uses WHERE registration_id = $1 ORDER BY created_at DESC LIMIT $2`
+	rows, err := r.pool.Query(ctx, q, registrationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.RegistrationTokenUse
+	for rows.Next() {
+		var u models.RegistrationTokenUse
+		if err := rows.Scan(&u.ID, &u.TokenID, &u.RegistrationID, &u.UserID, &u.IPAddress, &u.UserAgent, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, u)
+	}
+	return list, rows.Err()
+}
+
+// TokenUserIDs returns the user accounts that exchanged the registration's join links.
+func (r *Repository) TokenUserIDs(ctx context.Context, registrationID uuid.UUID) ([]uuid.UUID, error) {
+	const q = `SELECT DISTINCT user_id FROM registration_token_uses WHERE registration_id = $1 AND user_id IS NOT NULL`
+	rows, err := r.pool.Query(ctx, q, registrationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetJoinPolicy returns a webinar's join policy, or nil when it uses the server defaults.
+func (r *Repository) GetJoinPolicy(ctx context.Context, webinarID uuid.UUID) (*models.JoinPolicy, error) {
+	const q = `SELECT webinar_id, device_limit, on_limit, updated_at FROM webinar_join_policies WHERE webinar_id = $1`
+	var p models.JoinPolicy
+	err := r.pool.QueryRow(ctx, q, webinarID).Scan(&p.WebinarID, &p.DeviceLimit, &p.OnLimit, &p.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpsertJoinPolicy stores a webinar's join policy.
+func (r *Repository) UpsertJoinPolicy(ctx context.Context, p *models.JoinPolicy) error {
+	const q = `INSERT INTO webinar_join_policies (webinar_id, device_limit, on_limit)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (webinar_id) DO UPDATE SET device_limit = EXCLUDED.device_limit, on_limit = EXCLUDED.on_limit, updated_at = NOW()
+		RETURNING updated_at`
+	return r.pool.QueryRow(ctx, q, p.WebinarID, p.DeviceLimit, p.OnLimit).Scan(&p.UpdatedAt)
+}
+
 // GetByRegistrationID returns a registration by ID (alias for handlers).
 func (r *Repository) GetByRegistrationID(ctx context.Context, id uuid.UUID) (*models.Registration, error) {
 	return r.GetRegistrationByID(ctx, id)
 }
 
-// GetLatestTokenForRegistration returns the most recent valid (non-expired) token for a registration.
+// GetLatestTokenForRegistration returns the most recent valid (non-expired, non-revoked) token for a registration.
 func (r *Repository) GetLatestTokenForRegistration(ctx context.Context, registrationID uuid.UUID) (*models.RegistrationToken, error) {
 	const q = `SELECT id, registration_id, token, expires_at, used_at, created_at
 		FROM registration_tokens
-		WHERE registration_id = $1 AND expires_at > NOW()
+		WHERE registration_id = $1 AND expires_at > NOW() AND revoked_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT 1`
 	var t models.RegistrationToken
@@ -129,3 +319,53 @@ func (r *Repository) GetLatestTokenForRegistration(ctx context.Context, registra
 	}
 	return &t, nil
 }
+
+// ExportRow is one registration with its engagement, for exports.
+type ExportRow struct {
+	models.Registration
+	Sessions      int   // closed live sessions
+	WatchSeconds  int64 // live watch time
+	PollAnswers   int
+	FeedbackScore *int // webinar_feedback.rating
+}
+
+// StreamExport calls fn for each registration of the webinar, oldest first, joined with watch time and
+// session count (matched by registration_id or the registrant's user account, as in sessionlog), poll
+// answers and feedback rating. Rows are read one at a time; an error from fn stops the stream.
+func (r *Repository) StreamExport(ctx context.Context, webinarID uuid.UUID, fn func(*ExportRow) error) error {
+	const q = `SELECT reg.id, reg.webinar_id, reg.email, reg.full_name, reg.extra_data, COALESCE(reg.locale, ''), reg.attended_at, reg.created_at, reg.updated_at,
+			COALESCE(s.sessions, 0), COALESCE(s.watch_seconds, 0), COALESCE(pa.answers, 0), f.rating
+		FROM registrations reg
+		LEFT JOIN LATERAL (SELECT id FROM users WHERE lower(email) = lower(reg.email) LIMIT 1) u ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*)::INT AS sessions, SUM(l.watch_seconds)::BIGINT AS watch_seconds
+			FROM user_session_logs l
+			WHERE l.webinar_id = reg.webinar_id AND l.left_at IS NOT NULL
+				AND (l.registration_id = reg.id OR l.user_id = u.id)
+		) s ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*)::INT AS answers
+			FROM poll_answers a JOIN polls p ON p.id = a.poll_id
+			WHERE p.webinar_id = reg.webinar_id AND a.user_id = u.id
+		) pa ON TRUE
+		LEFT JOIN webinar_feedback f ON f.webinar_id = reg.webinar_id AND f.registration_id = reg.id
+		WHERE reg.webinar_id = $1
+		ORDER BY reg.created_at, reg.id`
+	rows, err := r.pool.Query(ctx, q, webinarID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row ExportRow
+		reg := &row.Registration
+		if err := rows.Scan(&reg.ID, &reg.WebinarID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.Locale, &reg.AttendedAt, &reg.CreatedAt, &reg.UpdatedAt,
+			&row.Sessions, &row.WatchSeconds, &row.PollAnswers, &row.FeedbackScore); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,253 @@
+package registrations
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/realtime"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+const (
+	// maxTokenUses bounds the join log returned with an attendee's sessions.
+	maxTokenUses = 50
+	// maxUserAgentLength bounds the user agent stored with a join.
+	maxUserAgentLength = 512
+)
+
+// SessionController reports and disconnects an attendee's live sessions (implemented by realtime.Hub).
+type SessionController interface {
+	ActiveSessions(ctx context.Context, webinarID, userID uuid.UUID) (int, error)
+	ResetSessions(ctx context.Context, webinarID, userID uuid.UUID) (int, error)
+}
+
+// JoinPolicyRequest is the body for PUT /webinars/:id/join-policy. Null fields fall back to the server
+// defaults.
+type JoinPolicyRequest struct {
+	DeviceLimit *int    `json:"device_limit"` // concurrent live sessions per attendee; 0 = unlimited
+	OnLimit     *string `json:"on_limit"`     // newest_wins or reject
+}
+
+// SetJoinPolicy sets the live session controls and the default join policy: deviceLimit concurrent
+// sessions per attendee (0 = unlimited), and what happens over the limit (models.JoinLimit*).
+func (h *Handler) SetJoinPolicy(sessions SessionController, deviceLimit int, onLimit string) {
+	h.sessions = sessions
+	h.joinLimit = deviceLimit
+	h.joinOnLimit = onLimit
+}
+
+// SessionLimit returns a webinar's effective join policy (realtime.SessionLimitFunc). Falls back to the
+// defaults when the policy cannot be read.
+func (h *Handler) SessionLimit(ctx context.Context, webinarID uuid.UUID) realtime.SessionLimit {
+	p, err := h.repo.GetJoinPolicy(ctx, webinarID)
+	if err != nil {
+		h.logger.Warn("load join policy failed", zap.Error(err), zap.String("webinar_id", webinarID.String()))
+	}
+	limit, onLimit := h.effectiveJoinPolicy(p)
+	return realtime.SessionLimit{Max: limit, NewestWins: onLimit == models.JoinLimitNewestWins}
+}
+
+// GetJoinPolicy handles GET /webinars/:id/join-policy: the webinar's overrides and the effective policy.
+func (h *Handler) GetJoinPolicy(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	p, err := h.repo.GetJoinPolicy(c.Request.Context(), w.ID)
+	if err != nil {
+		h.logger.Error("load join policy failed", zap.Error(err), zap.String("webinar_id", w.ID.String()))
+		response.Internal(c, "failed to load join policy")
+		return
+	}
+	if p == nil {
+		p = &models.JoinPolicy{WebinarID: w.ID}
+	}
+	response.OK(c, h.joinPolicyResponse(p))
+}
+
+// UpdateJoinPolicy handles PUT /webinars/:id/join-policy: how many devices an attendee may be live on at
+// once, and whether a new device disconnects the oldest (newest_wins) or is refused (reject).
+func (h *Handler) UpdateJoinPolicy(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	var req JoinPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid body")
+		return
+	}
+	if req.DeviceLimit != nil && *req.DeviceLimit < 0 {
+		response.BadRequest(c, "device_limit must be 0 (unlimited) or more")
+		return
+	}
+	if req.OnLimit != nil && *req.OnLimit != models.JoinLimitNewestWins && *req.OnLimit != models.JoinLimitReject {
+		response.BadRequest(c, "on_limit must be newest_wins or reject")
+		return
+	}
+	before, err := h.repo.GetJoinPolicy(c.Request.Context(), w.ID)
+	if err != nil {
+		h.logger.Error("load join policy failed", zap.Error(err), zap.String("webinar_id", w.ID.String()))
+		response.Internal(c, "failed to update join policy")
+		return
+	}
+	p := &models.JoinPolicy{WebinarID: w.ID, DeviceLimit: req.DeviceLimit, OnLimit: req.OnLimit}
+	if err := h.repo.UpsertJoinPolicy(c.Request.Context(), p); err != nil {
+		h.logger.Error("update join policy failed", zap.Error(err), zap.String("webinar_id", w.ID.String()))
+		response.Internal(c, "failed to update join policy")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "webinar.join_policy", TargetType: "webinar", TargetID: w.ID.String(), Before: before, After: p})
+	response.OK(c, h.joinPolicyResponse(p))
+}
+
+// Sessions handles GET /webinars/:id/registrations/:registrationId/sessions: the attendee's live sessions
+// and the latest uses of their join links (IP address and device).
+func (h *Handler) Sessions(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	reg, ok := h.loadRegistration(c, w)
+	if !ok {
+		return
+	}
+	uses, err := h.repo.ListTokenUses(c.Request.Context(), reg.ID, maxTokenUses)
+	if err != nil {
+		h.logger.Error("list join link uses failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		response.Internal(c, "failed to load sessions")
+		return
+	}
+	if uses == nil {
+		uses = []models.RegistrationTokenUse{}
+	}
+	active := 0
+	if h.sessions != nil {
+		userIDs, err := h.sessionUsers(c.Request.Context(), reg)
+		if err != nil {
+			h.logger.Error("load session users failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+			response.Internal(c, "failed to load sessions")
+			return
+		}
+		for _, userID := range userIDs {
+			n, err := h.sessions.ActiveSessions(c.Request.Context(), w.ID, userID)
+			if err != nil {
+				h.logger.Warn("count live sessions failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+				continue
+			}
+			active += n
+		}
+	}
+	response.OK(c, gin.H{"registration_id": reg.ID, "active_sessions": active, "uses": uses})
+}
+
+// ResetSessions handles POST /webinars/:id/registrations/:registrationId/sessions/reset: disconnects all of
+// the attendee's live sessions. The join link stays valid; revoke it to keep a forwarded link out.
+func (h *Handler) ResetSessions(c *gin.Context) {
+	w, ok := h.managedWebinar(c)
+	if !ok {
+		return
+	}
+	reg, ok := h.loadRegistration(c, w)
+	if !ok {
+		return
+	}
+	if h.sessions == nil {
+		response.Internal(c, "live sessions not configured")
+		return
+	}
+	userIDs, err := h.sessionUsers(c.Request.Context(), reg)
+	if err != nil {
+		h.logger.Error("load session users failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		response.Internal(c, "failed to reset sessions")
+		return
+	}
+	disconnected := 0
+	for _, userID := range userIDs {
+		n, err := h.sessions.ResetSessions(c.Request.Context(), w.ID, userID)
+		if err != nil {
+			h.logger.Error("reset sessions failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+			response.Internal(c, "failed to reset sessions")
+			return
+		}
+		disconnected += n
+	}
+	audit.Annotate(c, audit.Change{Action: "registration.reset_sessions", TargetType: "registration", TargetID: reg.ID.String(),
+		After: gin.H{"disconnected": disconnected}})
+	response.OK(c, gin.H{"registration_id": reg.ID, "disconnected": disconnected})
+}
+
+// logTokenUse marks the token used and records which device exchanged it. Failures are logged only; the
+// attendee still joins.
+func (h *Handler) logTokenUse(c *gin.Context, tok *models.RegistrationToken, userID uuid.UUID) {
+	ctx := c.Request.Context()
+	if tok.UsedAt == nil {
+		if err := h.repo.MarkTokenUsed(ctx, tok.ID); err != nil {
+			h.logger.Warn("mark token used failed", zap.Error(err), zap.String("token_id", tok.ID.String()))
+		}
+	}
+	use := &models.RegistrationTokenUse{
+		TokenID:        tok.ID,
+		RegistrationID: tok.RegistrationID,
+		UserID:         &userID,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      truncate(c.Request.UserAgent(), maxUserAgentLength),
+	}
+	if err := h.repo.LogTokenUse(ctx, use); err != nil {
+		h.logger.Warn("log join link use failed", zap.Error(err), zap.String("registration_id", tok.RegistrationID.String()))
+	}
+}
+
+// sessionUsers returns the accounts an attendee may be live under: those that exchanged the registration's
+// join links, and the account with the registration's email.
+func (h *Handler) sessionUsers(ctx context.Context, reg *models.Registration) ([]uuid.UUID, error) {
+	ids, err := h.repo.TokenUserIDs(ctx, reg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if h.authRepo == nil {
+		return ids, nil
+	}
+	if u, err := h.authRepo.GetByEmail(ctx, reg.Email); err == nil && u != nil {
+		for _, id := range ids {
+			if id == u.ID {
+				return ids, nil
+			}
+		}
+		ids = append(ids, u.ID)
+	}
+	return ids, nil
+}
+
+func (h *Handler) effectiveJoinPolicy(p *models.JoinPolicy) (limit int, onLimit string) {
+	limit, onLimit = h.joinLimit, h.joinOnLimit
+	if p != nil && p.DeviceLimit != nil {
+		limit = *p.DeviceLimit
+	}
+	if p != nil && p.OnLimit != nil {
+		onLimit = *p.OnLimit
+	}
+	return limit, onLimit
+}
+
+func (h *Handler) joinPolicyResponse(p *models.JoinPolicy) gin.H {
+	limit, onLimit := h.effectiveJoinPolicy(p)
+	return gin.H{
+		"webinar_id":   p.WebinarID,
+		"device_limit": p.DeviceLimit,
+		"on_limit":     p.OnLimit,
+		"effective":    gin.H{"device_limit": limit, "on_limit": onLimit},
+	}
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,172 @@
+package reminders
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// OrgLookup is the organization membership check (organizations.Repository).
+type OrgLookup interface {
+	GetUserRole(ctx context.Context, orgID, userID uuid.UUID) (string, error)
+}
+
+// Handler serves reminder schedules. Webinar routes run after webinars.RequireWebinarOrgAccess.
+type Handler struct {
+	repo        *Repository
+	planner     *Planner
+	webinarRepo *webinars.Repository
+	orgRepo     OrgLookup
+	logger      *zap.Logger
+}
+
+// NewHandler creates a reminders handler.
+func NewHandler(repo *Repository, planner *Planner, webinarRepo *webinars.Repository, orgRepo OrgLookup, logger *zap.Logger) *Handler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Handler{repo: repo, planner: planner, webinarRepo: webinarRepo, orgRepo: orgRepo, logger: logger}
+}
+
+// ScheduleRequest is the body for PUT /webinars/:id/reminders and PUT /organizations/:id/reminders:
+// offsets in minutes before start (e.g. [10080, 60]); null inherits, [] sends no reminders.
+type ScheduleRequest struct {
+	ReminderOffsets []int `json:"reminder_offsets"`
+}
+
+// GetWebinar handles GET /webinars/:id/reminders: the configured and effective offsets and the planned reminders.
+func (h *Handler) GetWebinar(c *gin.Context) {
+	webinarID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid webinar id")
+		return
+	}
+	schedule, ok := h.webinarSchedule(c, webinarID)
+	if !ok {
+		return
+	}
+	response.OK(c, schedule)
+}
+
+// UpdateWebinar handles PUT /webinars/:id/reminders and re-plans the webinar's pending reminders.
+func (h *Handler) UpdateWebinar(c *gin.Context) {
+	w, ok := webinars.ManagedWebinar(c, h.webinarRepo)
+	if !ok {
+		return
+	}
+	webinarID := w.ID
+	offsets, ok := bindOffsets(c)
+	if !ok {
+		return
+	}
+	before, ok := h.webinarSchedule(c, webinarID)
+	if !ok {
+		return
+	}
+	if err := h.repo.SetWebinarOffsets(c.Request.Context(), webinarID, offsets); err != nil {
+		response.Internal(c, "failed to update reminders")
+		return
+	}
+	if err := h.planner.Plan(c.Request.Context(), webinarID); err != nil {
+		// Saved; the worker's reminder sweep re-plans webinars whose plan is out of date.
+		h.logger.Warn("plan reminders failed", zap.String("webinar_id", webinarID.String()), zap.Error(err))
+	}
+	after, ok := h.webinarSchedule(c, webinarID)
+	if !ok {
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "webinar.reminders.update", TargetType: "webinar", TargetID: webinarID.String(),
+		Before: gin.H{"reminder_offsets": before.Offsets}, After: gin.H{"reminder_offsets": after.Offsets}})
+	response.OK(c, after)
+}
+
+// GetOrganization handles GET /organizations/:id/reminders: the default offsets for the organization's webinars.
+func (h *Handler) GetOrganization(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	offsets, err := h.repo.OrganizationOffsets(c.Request.Context(), orgID)
+	if err != nil {
+		response.Internal(c, "failed to load reminders")
+		return
+	}
+	response.OK(c, organizationSchedule(offsets))
+}
+
+// UpdateOrganization handles PUT /organizations/:id/reminders. Upcoming webinars that inherit the default
+// are re-planned by the worker's reminder sweep.
+func (h *Handler) UpdateOrganization(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	offsets, ok := bindOffsets(c)
+	if !ok {
+		return
+	}
+	before, err := h.repo.OrganizationOffsets(c.Request.Context(), orgID)
+	if err != nil {
+		response.Internal(c, "failed to load reminders")
+		return
+	}
+	if err := h.repo.SetOrganizationOffsets(c.Request.Context(), orgID, offsets); err != nil {
+		response.Internal(c, "failed to update reminders")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "organization.reminders.update", TargetType: "organization", TargetID: orgID.String(), OrganizationID: &orgID,
+		Before: gin.H{"reminder_offsets": before}, After: gin.H{"reminder_offsets": offsets}})
+	response.OK(c, organizationSchedule(offsets))
+}
+
+func (h *Handler) webinarSchedule(c *gin.Context, webinarID uuid.UUID) (*models.ReminderSchedule, bool) {
+	own, effective, source, err := h.repo.Offsets(c.Request.Context(), webinarID)
+	if err == pgx.ErrNoRows {
+		response.NotFound(c, "webinar not found")
+		return nil, false
+	}
+	if err != nil {
+		response.Internal(c, "failed to load reminders")
+		return nil, false
+	}
+	list, err := h.repo.ListByWebinar(c.Request.Context(), webinarID)
+	if err != nil {
+		response.Internal(c, "failed to load reminders")
+		return nil, false
+	}
+	if list == nil {
+		list = []*models.WebinarReminder{}
+	}
+	return &models.ReminderSchedule{Offsets: own, Effective: effective, Source: source, Reminders: list}, true
+}
+
+func organizationSchedule(offsets []int) *models.ReminderSchedule {
+	s := &models.ReminderSchedule{Offsets: offsets, Effective: offsets, Source: models.ReminderSourceOrganization}
+	if offsets == nil {
+		s.Effective, s.Source = models.DefaultReminderOffsets, models.ReminderSourceDefault
+	}
+	return s
+}
+
+func bindOffsets(c *gin.Context) ([]int, bool) {
+	var body ScheduleRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "reminder_offsets must be a list of minutes or null")
+		return nil, false
+	}
+	offsets, err := NormalizeOffsets(body.ReminderOffsets)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return nil, false
+	}
+	return offsets, true
+}
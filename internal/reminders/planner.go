@@ -0,0 +1,100 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/queue"
+)
+
+const (
+	// MaxOffsets bounds how many reminders one webinar can have.
+	MaxOffsets = 8
+	// MaxOffsetMinutes is the earliest reminder: 30 days before start.
+	MaxOffsetMinutes = 30 * 24 * 60
+)
+
+// Planner turns a webinar's start time and reminder offsets into webinar_reminders rows, each enqueued as a
+// delayed reminder job. Re-planning cancels the previous rows; their jobs still fire but are dropped.
+type Planner struct {
+	repo *Repository
+	q    *queue.Queue
+}
+
+// NewPlanner creates a reminder planner.
+func NewPlanner(repo *Repository, q *queue.Queue) *Planner {
+	return &Planner{repo: repo, q: q}
+}
+
+// Plan (re)plans the webinar's reminders from its current starts_at. Implements webinars.ReminderPlanner.
+func (p *Planner) Plan(ctx context.Context, webinarID uuid.UUID) error {
+	planned, err := p.repo.Replan(ctx, webinarID, time.Now())
+	if err != nil {
+		return fmt.Errorf("plan reminders: %w", err)
+	}
+	for _, m := range planned {
+		if err := p.Enqueue(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Enqueue enqueues the reminder's job to run at its send time. Enqueuing the same reminder twice is a no-op
+// while the first job can still run.
+func (p *Planner) Enqueue(ctx context.Context, m *models.WebinarReminder) error {
+	delay := time.Until(m.SendAt)
+	if delay < 0 {
+		delay = 0
+	}
+	return p.enqueue(ctx, m, delay, m.ID.String(), delay+time.Hour)
+}
+
+// Requeue enqueues an overdue reminder again to run now, for when its planned job was lost. It is not
+// blocked by the planned job's dedup key, only by an earlier Requeue within ttl; if both jobs run, the
+// second finds the reminder already sent.
+func (p *Planner) Requeue(ctx context.Context, m *models.WebinarReminder, ttl time.Duration) error {
+	return p.enqueue(ctx, m, 0, m.ID.String()+":requeue", ttl)
+}
+
+func (p *Planner) enqueue(ctx context.Context, m *models.WebinarReminder, delay time.Duration, dedupKey string, dedupTTL time.Duration) error {
+	_, err := p.q.Enqueue(ctx, queue.JobTypeReminder, queue.ReminderPayload{ReminderID: m.ID, WebinarID: m.WebinarID, SendAt: m.SendAt}, queue.Options{
+		Delay:    delay,
+		DedupKey: dedupKey,
+		DedupTTL: dedupTTL,
+	})
+	if err != nil && !errors.Is(err, queue.ErrDuplicate) {
+		return fmt.Errorf("enqueue reminder %s: %w", m.ID, err)
+	}
+	return nil
+}
+
+// NormalizeOffsets validates offsets (minutes before start) and returns them deduplicated, earliest reminder
+// first. nil stays nil (inherit); an empty list means no reminders.
+func NormalizeOffsets(offsets []int) ([]int, error) {
+	if offsets == nil {
+		return nil, nil
+	}
+	seen := make(map[int]bool, len(offsets))
+	out := make([]int, 0, len(offsets))
+	for _, o := range offsets {
+		if o < 1 || o > MaxOffsetMinutes {
+			return nil, fmt.Errorf("reminder offsets must be between 1 and %d minutes", MaxOffsetMinutes)
+		}
+		if !seen[o] {
+			seen[o] = true
+			out = append(out, o)
+		}
+	}
+	if len(out) > MaxOffsets {
+		return nil, fmt.Errorf("at most %d reminder offsets", MaxOffsets)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(out)))
+	return out, nil
+}
@@ -0,0 +1,78 @@
+package reminders
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/queue"
+)
+
+func newTestPlanner(t *testing.T) (*Planner, *queue.Queue) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	q := queue.NewQueue(client, nil)
+	return NewPlanner(nil, q), q
+}
+
+// dequeueReminder returns the next ready reminder job, or nil.
+func dequeueReminder(t *testing.T, q *queue.Queue) *queue.Job {
+	t.Helper()
+	job, err := q.Dequeue(context.Background(), queue.JobTypeReminder, 100*time.Millisecond, time.Minute)
+	if err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+	return job
+}
+
+func TestRequeueDeliversLostReminder(t *testing.T) {
+	p, q := newTestPlanner(t)
+	ctx := context.Background()
+	m := &models.WebinarReminder{ID: uuid.New(), WebinarID: uuid.New(), SendAt: time.Now().Add(-6 * time.Minute)}
+
+	if err := p.Enqueue(ctx, m); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	// The planned job is lost: taken off the queue and acked without running.
+	lost := dequeueReminder(t, q)
+	if lost == nil {
+		t.Fatal("planned job not enqueued")
+	}
+	if err := q.Ack(ctx, lost); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+
+	// Planning again is still deduplicated against the lost job...
+	if err := p.Enqueue(ctx, m); err != nil {
+		t.Fatalf("enqueue again: %v", err)
+	}
+	if job := dequeueReminder(t, q); job != nil {
+		t.Fatalf("planned enqueue was not deduplicated: %s", job.ID)
+	}
+	// ...but the sweep's requeue is not.
+	if err := p.Requeue(ctx, m, 10*time.Minute); err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	job := dequeueReminder(t, q)
+	if job == nil {
+		t.Fatal("requeue did not enqueue the reminder")
+	}
+	if err := q.Ack(ctx, job); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+
+	// A later sweep within the TTL does not enqueue it a third time.
+	if err := p.Requeue(ctx, m, 10*time.Minute); err != nil {
+		t.Fatalf("requeue again: %v", err)
+	}
+	if job := dequeueReminder(t, q); job != nil {
+		t.Fatalf("requeue was not deduplicated: %s", job.ID)
+	}
+}
@@ -0,0 +1,200 @@
+package reminders
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository handles reminder offsets (organizations, webinars) and webinar_reminders persistence.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a reminders repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const columns = `id, webinar_id, offset_minutes, email_type, send_at, status, recipient_count, sent_at, created_at`
+
+func scanReminder(row pgx.Row) (*models.WebinarReminder, error) {
+	var m models.WebinarReminder
+	if err := row.Scan(&m.ID, &m.WebinarID, &m.OffsetMinutes, &m.EmailType, &m.SendAt, &m.Status, &m.RecipientCount, &m.SentAt, &m.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *Repository) list(ctx context.Context, q string, args ...interface{}) ([]*models.WebinarReminder, error) {
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*models.WebinarReminder
+	for rows.Next() {
+		m, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// OrganizationOffsets returns the organization's default offsets (nil = platform default).
+func (r *Repository) OrganizationOffsets(ctx context.Context, orgID uuid.UUID) ([]int, error) {
+	var offsets []int
+	err := r.pool.QueryRow(ctx, `SELECT reminder_offsets FROM organizations WHERE id = $1`, orgID).Scan(&offsets)
+	return offsets, err
+}
+
+// SetOrganizationOffsets stores the organization default (nil resets it) and marks the organization's upcoming
+// webinars that inherit it for re-planning by the worker.
+func (r *Repository) SetOrganizationOffsets(ctx context.Context, orgID uuid.UUID, offsets []int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE organizations SET reminder_offsets = $2, updated_at = NOW() WHERE id = $1`, orgID, offsets); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE webinars SET reminders_planned_for = NULL
+		WHERE organization_id = $1 AND reminder_offsets IS NULL AND starts_at > NOW()`, orgID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Offsets returns the webinar's own offsets (nil = inherited) and the ones that apply with their source.
+func (r *Repository) Offsets(ctx context.Context, webinarID uuid.UUID) (own, effective []int, source string, err error) {
+	var org []int
+	err = r.pool.QueryRow(ctx, `SELECT w.reminder_offsets, o.reminder_offsets
+		FROM webinars w LEFT JOIN organizations o ON o.id = w.organization_id
+		WHERE w.id = $1`, webinarID).Scan(&own, &org)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	effective, source = resolve(own, org)
+	return own, effective, source, nil
+}
+
+// SetWebinarOffsets stores the webinar's offsets (nil inherits the organization default).
+func (r *Repository) SetWebinarOffsets(ctx context.Context, webinarID uuid.UUID, offsets []int) error {
+	_, err := r.pool.Exec(ctx, `UPDATE webinars SET reminder_offsets = $2, updated_at = NOW() WHERE id = $1`, webinarID, offsets)
+	return err
+}
+
+// resolve picks webinar, then organization, then platform default offsets.
+func resolve(webinar, org []int) ([]int, string) {
+	switch {
+	case webinar != nil:
+		return webinar, models.ReminderSourceWebinar
+	case org != nil:
+		return org, models.ReminderSourceOrganization
+	default:
+		return models.DefaultReminderOffsets, models.ReminderSourceDefault
+	}
+}
+
+// Replan cancels the webinar's scheduled reminders and plans one per effective offset from its current
+// starts_at, skipping offsets already past at now. Returns the new reminders (nil, nil if the webinar is gone).
+func (r *Repository) Replan(ctx context.Context, webinarID uuid.UUID, now time.Time) ([]*models.WebinarReminder, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	// Locking the webinar row serializes concurrent re-plans (API update and worker sweep).
+	var startsAt time.Time
+	var own, org []int
+	err = tx.QueryRow(ctx, `SELECT w.starts_at, w.reminder_offsets, o.reminder_offsets
+		FROM webinars w LEFT JOIN organizations o ON o.id = w.organization_id
+		WHERE w.id = $1 FOR UPDATE OF w`, webinarID).Scan(&startsAt, &own, &org)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	effective, _ := resolve(own, org)
+	if _, err := tx.Exec(ctx, `UPDATE webinar_reminders SET status = 'cancelled' WHERE webinar_id = $1 AND status = 'scheduled'`, webinarID); err != nil {
+		return nil, err
+	}
+	var planned []*models.WebinarReminder
+	for _, offset := range effective {
+		sendAt := startsAt.Add(-time.Duration(offset) * time.Minute)
+		if !sendAt.After(now) {
+			continue
+		}
+		m, err := scanReminder(tx.QueryRow(ctx, `INSERT INTO webinar_reminders (webinar_id, offset_minutes, email_type, send_at)
+			VALUES ($1, $2, $3, $4) RETURNING `+columns, webinarID, offset, models.ReminderEmailType(offset), sendAt))
+		if err != nil {
+			return nil, err
+		}
+		planned = append(planned, m)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE webinars SET reminders_planned_for = starts_at WHERE id = $1`, webinarID); err != nil {
+		return nil, err
+	}
+	return planned, tx.Commit(ctx)
+}
+
+// GetByID returns a reminder (nil, nil if not found).
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*models.WebinarReminder, error) {
+	m, err := scanReminder(r.pool.QueryRow(ctx, `SELECT `+columns+` FROM webinar_reminders WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return m, err
+}
+
+// ListByWebinar returns the webinar's scheduled and sent reminders (cancelled plans omitted), soonest first.
+func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]*models.WebinarReminder, error) {
+	return r.list(ctx, `SELECT `+columns+` FROM webinar_reminders
+		WHERE webinar_id = $1 AND status <> 'cancelled' ORDER BY send_at`, webinarID)
+}
+
+// ListUnplanned returns upcoming scheduled webinars whose reminders were not planned for their current starts_at.
+func (r *Repository) ListUnplanned(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id FROM webinars
+		WHERE status = 'scheduled' AND starts_at > NOW() AND reminders_planned_for IS DISTINCT FROM starts_at
+		ORDER BY starts_at LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListOverdue returns scheduled reminders due before the given time (their queue job was lost or dead-lettered).
+func (r *Repository) ListOverdue(ctx context.Context, before time.Time, limit int) ([]*models.WebinarReminder, error) {
+	return r.list(ctx, `SELECT `+columns+` FROM webinar_reminders
+		WHERE status = 'scheduled' AND send_at < $1 ORDER BY send_at LIMIT $2`, before, limit)
+}
+
+// Finish moves a scheduled reminder to status (sent or skipped). Returns false if it was no longer scheduled.
+func (r *Repository) Finish(ctx context.Context, id uuid.UUID, status string, recipientCount int) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE webinar_reminders SET status = $2, recipient_count = $3,
+		sent_at = CASE WHEN $2 = 'sent' THEN NOW() END
+		WHERE id = $1 AND status = 'scheduled'`, id, status, recipientCount)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,426 @@
+// Package series implements recurring webinars: a series' RRULE and time zone generate webinars
+// (occurrences) with shared settings, attendees may register for the whole series, and edits apply to one
+// occurrence or to all future ones.
+package series
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/internal/waitlist"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/ical"
+	"github.com/aura-webinar/backend/pkg/queue"
+	"github.com/aura-webinar/backend/pkg/rrule"
+)
+
+const (
+	// Horizon is how far ahead occurrences are generated; the worker's series sweep moves it forward.
+	Horizon = 90 * 24 * time.Hour
+	// SweepSchedule is when the worker extends series whose occurrences run out within Horizon - sweepLead.
+	SweepSchedule = "15 * * * *"
+	// sweepLead is how far the generated occurrences may fall behind Horizon before a series is extended.
+	sweepLead = 7 * 24 * time.Hour
+	// sweepBatch bounds the series one sweep extends.
+	sweepBatch = 100
+	// maxOccurrencesPerRun bounds the occurrences one pass creates for a series.
+	maxOccurrencesPerRun = 100
+	// tokenGrace keeps a series registrant's join link valid until a day after the occurrence ends.
+	tokenGrace = 24 * time.Hour
+)
+
+// Outcomes of registering a series registrant for one occurrence.
+const (
+	RegistrationRegistered = "registered"
+	RegistrationExisting   = "already_registered"
+	RegistrationWaitlisted = "waitlist"
+)
+
+// editableStatuses are the occurrence statuses series edits and registrations apply to.
+var editableStatuses = []string{models.WebinarStatusDraft, models.WebinarStatusScheduled}
+
+// OccurrenceRegistration is the outcome of registering a series registrant for one occurrence.
+type OccurrenceRegistration struct {
+	WebinarID      uuid.UUID  `json:"webinar_id"`
+	StartsAt       time.Time  `json:"starts_at"`
+	Status         string     `json:"status"` // Registration*
+	RegistrationID *uuid.UUID `json:"registration_id,omitempty"`
+}
+
+// RescheduleResult counts what a schedule change did to the future occurrences.
+type RescheduleResult struct {
+	Moved     int `json:"moved"`
+	Created   int `json:"created"`
+	Deleted   int `json:"deleted"`   // had no registrations
+	Cancelled int `json:"cancelled"` // registrants were notified
+}
+
+// Generator creates a series' occurrences from its rule and registers the series' registrants for them.
+type Generator struct {
+	repo         *Repository
+	webinarRepo  *webinars.Repository
+	regRepo      *registrations.Repository
+	waitlistRepo *waitlist.Repository
+	reminders    webinars.ReminderPlanner
+	lifecycle    webinars.LifecycleEffects
+	schedule     webinars.ScheduleNotifier
+	jobQueue     *queue.Queue
+	frontendURL  string
+	logger       *zap.Logger
+}
+
+// NewGenerator creates an occurrence generator.
+func NewGenerator(repo *Repository, webinarRepo *webinars.Repository, regRepo *registrations.Repository, waitlistRepo *waitlist.Repository, logger *zap.Logger) *Generator {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Generator{repo: repo, webinarRepo: webinarRepo, regRepo: regRepo, waitlistRepo: waitlistRepo, logger: logger}
+}
+
+// SetReminderPlanner plans reminders for new and moved occurrences. Without it, the worker's reminder sweep
+// plans them on its next run.
+func (g *Generator) SetReminderPlanner(p webinars.ReminderPlanner) {
+	g.reminders = p
+}
+
+// SetLifecycle notifies registrants of occurrences a schedule change removes. Without it they are only
+// marked cancelled.
+func (g *Generator) SetLifecycle(e webinars.LifecycleEffects) {
+	g.lifecycle = e
+}
+
+// SetScheduleNotifier sends registrants of moved occurrences their new time. Without it they are not told.
+func (g *Generator) SetScheduleNotifier(n webinars.ScheduleNotifier) {
+	g.schedule = n
+}
+
+// SetEmailQueue configures the job queue and frontend URL for confirmation emails.
+func (g *Generator) SetEmailQueue(q *queue.Queue, frontendURL string) {
+	g.jobQueue = q
+	g.frontendURL = frontendURL
+}
+
+// Schedule parses a series' rule and time zone and returns the rule with its DTSTART (the series' start in
+// its time zone).
+func Schedule(s *models.WebinarSeries) (*rrule.Rule, time.Time, error) {
+	rule, err := rrule.Parse(s.RRule)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unknown time zone %q", s.Timezone)
+	}
+	return rule, s.StartsAt.In(loc), nil
+}
+
+// Extend creates the occurrences due from where generation stopped (or now) up to now + Horizon, registers
+// the series' registrants for them and plans their reminders. Returns the new occurrences.
+func (g *Generator) Extend(ctx context.Context, s *models.WebinarSeries, now time.Time) ([]*models.Webinar, error) {
+	rule, dtstart, err := Schedule(s)
+	if err != nil {
+		return nil, err
+	}
+	from := now
+	if s.GeneratedUntil != nil && s.GeneratedUntil.After(from) {
+		from = *s.GeneratedUntil
+	}
+	to := now.Add(Horizon)
+	times := rule.Between(dtstart, from, to, maxOccurrencesPerRun)
+	until := to
+	if len(times) == maxOccurrencesPerRun {
+		until = times[len(times)-1].Add(time.Second)
+	}
+	created, err := g.createOccurrences(ctx, s, times)
+	if err != nil {
+		return created, err
+	}
+	if err := g.repo.SetGeneratedUntil(ctx, s.ID, until); err != nil {
+		return created, err
+	}
+	s.GeneratedUntil = &until
+	return created, nil
+}
+
+// Reschedule lines the occurrences from `from` on up with the series' current rule, time zone and start:
+// the k-th editable occurrence moves to the k-th new time (keeping its registrations), occurrences left
+// over are deleted or, when someone registered, cancelled, and missing ones are created.
+func (g *Generator) Reschedule(ctx context.Context, s *models.WebinarSeries, from, now time.Time) (RescheduleResult, error) {
+	var res RescheduleResult
+	rule, dtstart, err := Schedule(s)
+	if err != nil {
+		return res, err
+	}
+	if from.Before(now) {
+		from = now
+	}
+	all, err := g.repo.ListOccurrences(ctx, s.ID, from, nil)
+	if err != nil {
+		return res, err
+	}
+	var existing []Occurrence
+	fixed := map[int64]bool{} // times held by occurrences that are live or over
+	for _, o := range all {
+		if o.Status == models.WebinarStatusDraft || o.Status == models.WebinarStatusScheduled {
+			existing = append(existing, o)
+		} else {
+			fixed[o.OccurrenceAt.Unix()] = true
+		}
+	}
+	to := now.Add(Horizon)
+	var times []time.Time
+	for _, t := range rule.Between(dtstart, from, to, maxOccurrencesPerRun) {
+		if !fixed[t.Unix()] {
+			times = append(times, t)
+		}
+	}
+	until := to
+	if len(times) == maxOccurrencesPerRun {
+		until = times[len(times)-1].Add(time.Second)
+	}
+
+	var moves []Move
+	for i := 0; i < len(existing) && i < len(times); i++ {
+		if !existing[i].OccurrenceAt.Equal(times[i]) || !existing[i].StartsAt.Equal(times[i]) {
+			moves = append(moves, Move{WebinarID: existing[i].WebinarID, OccurrenceAt: times[i]})
+		}
+	}
+	if err := g.repo.MoveOccurrences(ctx, moves); err != nil {
+		return res, fmt.Errorf("move occurrences: %w", err)
+	}
+	res.Moved = len(moves)
+	for _, m := range moves {
+		g.moved(ctx, m.WebinarID)
+	}
+	for i := len(times); i < len(existing); i++ {
+		if err := g.drop(ctx, existing[i]); err != nil {
+			return res, err
+		}
+		if existing[i].Registered == 0 {
+			res.Deleted++
+		} else {
+			res.Cancelled++
+		}
+	}
+	if len(times) > len(existing) {
+		created, err := g.createOccurrences(ctx, s, times[len(existing):])
+		res.Created = len(created)
+		if err != nil {
+			return res, err
+		}
+	}
+	if err := g.repo.SetGeneratedUntil(ctx, s.ID, until); err != nil {
+		return res, err
+	}
+	s.GeneratedUntil = &until
+	return res, nil
+}
+
+// drop removes an occurrence a schedule change no longer has: deleted when nobody registered, otherwise
+// cancelled so its registrants are told.
+func (g *Generator) drop(ctx context.Context, o Occurrence) error {
+	if o.Registered == 0 {
+		if err := g.webinarRepo.Delete(ctx, o.WebinarID); err != nil {
+			return fmt.Errorf("delete occurrence: %w", err)
+		}
+		return nil
+	}
+	t := models.WebinarTransitions[models.WebinarActionCancel]
+	changed, err := g.webinarRepo.Transition(ctx, o.WebinarID, t.From, t.To)
+	if err != nil || !changed {
+		return err
+	}
+	if g.lifecycle == nil {
+		return nil
+	}
+	w, err := g.webinarRepo.GetByID(ctx, o.WebinarID)
+	if err != nil {
+		return err
+	}
+	if _, _, err := g.lifecycle.Cancelled(ctx, w); err != nil {
+		g.logger.Error("cancel occurrence effects failed", zap.Error(err), zap.String("webinar_id", o.WebinarID.String()))
+	}
+	return nil
+}
+
+// createOccurrences creates occurrences at times (existing ones are skipped), registers the series'
+// registrants and plans reminders.
+func (g *Generator) createOccurrences(ctx context.Context, s *models.WebinarSeries, times []time.Time) ([]*models.Webinar, error) {
+	if len(times) == 0 {
+		return nil, nil
+	}
+	regs, err := g.repo.ListRegistrations(ctx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	var created []*models.Webinar
+	for _, t := range times {
+		w, err := g.repo.CreateOccurrence(ctx, s, t)
+		if err != nil {
+			return created, fmt.Errorf("create occurrence: %w", err)
+		}
+		if w == nil {
+			continue
+		}
+		created = append(created, w)
+		o := occurrenceOf(w)
+		for i := range regs {
+			if _, err := g.register(ctx, &o, &regs[i], false); err != nil {
+				g.logger.Warn("register series attendee failed", zap.Error(err), zap.String("webinar_id", w.ID.String()),
+					zap.String("series_registration_id", regs[i].ID.String()))
+			}
+		}
+		if w.Status == models.WebinarStatusScheduled {
+			g.planReminders(ctx, w.ID)
+		}
+	}
+	return created, nil
+}
+
+// RegisterAll registers reg for every upcoming draft or scheduled occurrence. Only the next published
+// occurrence gets a confirmation email; the others' join links go out with their reminders.
+func (g *Generator) RegisterAll(ctx context.Context, s *models.WebinarSeries, reg *models.SeriesRegistration, now time.Time) ([]OccurrenceRegistration, error) {
+	occs, err := g.repo.ListOccurrences(ctx, s.ID, now, editableStatuses)
+	if err != nil {
+		return nil, err
+	}
+	out := []OccurrenceRegistration{}
+	confirmed := false
+	for i := range occs {
+		if !occs[i].StartsAt.After(now) {
+			continue
+		}
+		confirm := !confirmed && occs[i].Status == models.WebinarStatusScheduled
+		res, err := g.register(ctx, &occs[i], reg, confirm)
+		if err != nil {
+			return out, err
+		}
+		if confirm && res.Status == RegistrationRegistered {
+			confirmed = true
+		}
+		out = append(out, *res)
+	}
+	return out, nil
+}
+
+// register registers reg for one occurrence: an existing registration is kept, and a full occurrence puts
+// the attendee on its waitlist.
+func (g *Generator) register(ctx context.Context, o *Occurrence, reg *models.SeriesRegistration, confirm bool) (*OccurrenceRegistration, error) {
+	res := &OccurrenceRegistration{WebinarID: o.WebinarID, StartsAt: o.StartsAt}
+	existing, err := g.regRepo.FindByEmail(ctx, o.WebinarID, reg.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		res.Status, res.RegistrationID = RegistrationExisting, &existing.ID
+		return res, nil
+	}
+	if o.MaxAudience != nil && *o.MaxAudience > 0 && g.waitlistRepo != nil && o.Registered >= *o.MaxAudience {
+		entry := &waitlist.Entry{WebinarID: o.WebinarID, Email: reg.Email, FullName: reg.FullName, ExtraData: reg.ExtraData}
+		if err := g.waitlistRepo.Create(ctx, entry); err != nil {
+			return nil, err
+		}
+		res.Status = RegistrationWaitlisted
+		return res, nil
+	}
+	r := &models.Registration{WebinarID: o.WebinarID, Email: reg.Email, FullName: reg.FullName, ExtraData: reg.ExtraData, Locale: reg.Locale}
+	if err := g.regRepo.CreateRegistration(ctx, r); err != nil {
+		return nil, err
+	}
+	o.Registered++
+	expiresAt := o.EndsAt.Add(tokenGrace)
+	if floor := time.Now().Add(registrations.TokenTTL); expiresAt.Before(floor) {
+		expiresAt = floor
+	}
+	tok, err := g.regRepo.IssueTokenUntil(ctx, r.ID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	res.Status, res.RegistrationID = RegistrationRegistered, &r.ID
+	if confirm && g.jobQueue != nil && g.frontendURL != "" {
+		payload := queue.EmailPayload{
+			EmailType:       models.EmailTypeRegistrationConfirmation,
+			WebinarID:       o.WebinarID,
+			RegistrationID:  r.ID,
+			RecipientEmail:  r.Email,
+			RecipientName:   r.FullName,
+			WebinarTitle:    o.Title,
+			WebinarStartsAt: o.StartsAt.Format(time.RFC3339),
+			JoinURL:         email.BuildJoinURL(g.frontendURL, o.WebinarID.String(), tok.Token),
+			Locale:          r.Locale,
+			CalendarMethod:  ical.MethodRequest,
+		}
+		_, err := g.jobQueue.Enqueue(ctx, queue.JobTypeEmail, payload, queue.Options{
+			DedupKey: "series:" + r.ID.String(),
+			DedupTTL: 24 * time.Hour,
+		})
+		if err != nil && !errors.Is(err, queue.ErrDuplicate) {
+			// Registered; only the email is missing.
+			g.logger.Warn("enqueue confirmation email failed", zap.Error(err), zap.String("registration_id", r.ID.String()))
+		}
+	}
+	return res, nil
+}
+
+// Sweep extends series whose generated occurrences fall behind the horizon. Run it on SweepSchedule.
+func (g *Generator) Sweep(ctx context.Context) {
+	now := time.Now()
+	due, err := g.repo.ListDue(ctx, now.Add(Horizon-sweepLead), sweepBatch)
+	if err != nil {
+		g.logger.Error("list series to extend failed", zap.Error(err))
+		return
+	}
+	for _, s := range due {
+		created, err := g.Extend(ctx, s, now)
+		if err != nil {
+			g.logger.Warn("extend series failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+			continue
+		}
+		if len(created) > 0 {
+			g.logger.Info("series extended", zap.String("series_id", s.ID.String()), zap.Int("occurrences", len(created)))
+		}
+	}
+}
+
+func (g *Generator) planReminders(ctx context.Context, webinarID uuid.UUID) {
+	if g.reminders == nil {
+		return
+	}
+	if err := g.reminders.Plan(ctx, webinarID); err != nil {
+		g.logger.Warn("plan reminders failed", zap.String("webinar_id", webinarID.String()), zap.Error(err))
+	}
+}
+
+// moved re-plans a moved occurrence's reminders and, once it is published, tells its registrants.
+func (g *Generator) moved(ctx context.Context, webinarID uuid.UUID) {
+	g.planReminders(ctx, webinarID)
+	if g.schedule == nil {
+		return
+	}
+	w, err := g.webinarRepo.GetByID(ctx, webinarID)
+	if err != nil || !w.Published() {
+		return
+	}
+	if _, err := g.schedule.Rescheduled(ctx, w); err != nil {
+		g.logger.Warn("reschedule notification failed", zap.String("webinar_id", webinarID.String()), zap.Error(err))
+	}
+}
+
+func occurrenceOf(w *models.Webinar) Occurrence {
+	o := Occurrence{WebinarID: w.ID, Title: w.Title, StartsAt: w.StartsAt, EndsAt: w.StartsAt, Status: w.Status, MaxAudience: w.MaxAudience}
+	if w.EndsAt != nil {
+		o.EndsAt = *w.EndsAt
+	}
+	if w.OccurrenceAt != nil {
+		o.OccurrenceAt = *w.OccurrenceAt
+	}
+	return o
+}
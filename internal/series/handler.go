@@ -0,0 +1,689 @@
+package series
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/ads"
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/forms"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// Edit scopes for PATCH /series/:id/occurrences/:webinarId.
+const (
+	ScopeThis      = "this"
+	ScopeFollowing = "following"
+)
+
+// CreateRequest is the body for POST /series.
+type CreateRequest struct {
+	Title              string                   `json:"title" binding:"required"`
+	Description        string                   `json:"description"`
+	RRule              string                   `json:"rrule" binding:"required"`     // RFC 5545, e.g. "FREQ=WEEKLY;BYDAY=TU"
+	Timezone           string                   `json:"timezone" binding:"required"`  // IANA name the rule repeats in
+	StartsAt           string                   `json:"starts_at" binding:"required"` // RFC 3339; the first occurrence
+	DurationMinutes    int                      `json:"duration_minutes" binding:"required"`
+	Status             string                   `json:"status"` // occurrences' status: "draft" or "scheduled" (default)
+	IsPaid             bool                     `json:"is_paid"`
+	TicketPriceCents   int                      `json:"ticket_price_cents"`
+	TicketCurrency     string                   `json:"ticket_currency"`
+	MaxAudience        *int                     `json:"max_audience"`
+	Category           string                   `json:"category"`
+	BannerImageURL     string                   `json:"banner_image_url"`
+	AudienceFormConfig []models.FormFieldConfig `json:"audience_form_config"`
+	SpeakerIDs         []string                 `json:"speaker_ids"`
+}
+
+// UpdateRequest is the body for PATCH /series/:id and PATCH /series/:id/occurrences/:webinarId. Absent
+// fields are unchanged. RRule, Timezone and Status apply to the series and so only to scope "following".
+type UpdateRequest struct {
+	Scope              string                    `json:"scope"` // occurrence edits: "this" (default) or "following"
+	Title              *string                   `json:"title"`
+	Description        *string                   `json:"description"`
+	RRule              *string                   `json:"rrule"`
+	Timezone           *string                   `json:"timezone"`
+	StartsAt           *string                   `json:"starts_at"` // "this": the occurrence's start; otherwise the rule's new first occurrence
+	DurationMinutes    *int                      `json:"duration_minutes"`
+	Status             *string                   `json:"status"`
+	IsPaid             *bool                     `json:"is_paid"`
+	TicketPriceCents   *int                      `json:"ticket_price_cents"`
+	TicketCurrency     *string                   `json:"ticket_currency"`
+	MaxAudience        *int                      `json:"max_audience"` // negative = unlimited
+	Category           *string                   `json:"category"`
+	BannerImageURL     *string                   `json:"banner_image_url"`
+	AudienceFormConfig *[]models.FormFieldConfig `json:"audience_form_config"`
+	SpeakerIDs         *[]string                 `json:"speaker_ids"`
+}
+
+// AdsRequest is the body for PUT /series/:id/ads: uploaded ads whose creatives every future occurrence shows.
+type AdsRequest struct {
+	AdvertisementIDs []string `json:"advertisement_ids"`
+}
+
+// RegisterRequest is the body for POST /series/:id/register.
+type RegisterRequest struct {
+	Email         string            `json:"email" binding:"required,email"`
+	FullName      string            `json:"full_name" binding:"required"`
+	FormResponses map[string]string `json:"form_responses,omitempty"` // validated against the series' form
+	Locale        string            `json:"locale,omitempty"`
+}
+
+// Handler handles webinar series HTTP endpoints.
+type Handler struct {
+	repo        *Repository
+	gen         *Generator
+	webinarRepo *webinars.Repository
+	adRepo      *ads.AdvertisementRepository
+	orgRepo     middleware.OrgRoleLookup
+	logger      *zap.Logger
+}
+
+// NewHandler creates a series handler.
+func NewHandler(repo *Repository, gen *Generator, webinarRepo *webinars.Repository, adRepo *ads.AdvertisementRepository, orgRepo middleware.OrgRoleLookup, logger *zap.Logger) *Handler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Handler{repo: repo, gen: gen, webinarRepo: webinarRepo, adRepo: adRepo, orgRepo: orgRepo, logger: logger}
+}
+
+// Create handles POST /series (admin only): saves the series and generates its occurrences up to Horizon.
+func (h *Handler) Create(c *gin.Context) {
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	s := &models.WebinarSeries{
+		CreatedBy:        c.MustGet(middleware.ContextUserID).(uuid.UUID),
+		OccurrenceStatus: models.WebinarStatusScheduled,
+		TicketCurrency:   "USD",
+		SpeakerIDs:       []uuid.UUID{},
+	}
+	if orgID, ok := middleware.APIKeyOrganizationID(c); ok {
+		s.OrganizationID = &orgID
+	}
+	upd := UpdateRequest{
+		Title: &req.Title, Description: &req.Description, RRule: &req.RRule, Timezone: &req.Timezone, StartsAt: &req.StartsAt,
+		DurationMinutes: &req.DurationMinutes, IsPaid: &req.IsPaid, TicketPriceCents: &req.TicketPriceCents,
+		MaxAudience: req.MaxAudience, Category: &req.Category, BannerImageURL: &req.BannerImageURL,
+	}
+	if req.Status != "" {
+		upd.Status = &req.Status
+	}
+	if req.TicketCurrency != "" {
+		upd.TicketCurrency = &req.TicketCurrency
+	}
+	if req.AudienceFormConfig != nil {
+		upd.AudienceFormConfig = &req.AudienceFormConfig
+	}
+	if req.SpeakerIDs != nil {
+		upd.SpeakerIDs = &req.SpeakerIDs
+	}
+	if msg := applySettings(s, &upd); msg != "" {
+		response.BadRequest(c, msg)
+		return
+	}
+	if msg := applySchedule(s, &upd, nil); msg != "" {
+		response.BadRequest(c, msg)
+		return
+	}
+	if err := h.repo.Create(c.Request.Context(), s); err != nil {
+		h.logger.Error("create series failed", zap.Error(err))
+		response.Internal(c, "failed to create series")
+		return
+	}
+	created, err := h.gen.Extend(c.Request.Context(), s, time.Now())
+	if err != nil {
+		h.logger.Error("generate occurrences failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+		response.Internal(c, "failed to generate occurrences")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "series.create", TargetType: "series", TargetID: s.ID.String(), OrganizationID: s.OrganizationID, After: s})
+	if created == nil {
+		created = []*models.Webinar{}
+	}
+	response.Created(c, gin.H{"series": s, "occurrences": created})
+}
+
+// List handles GET /series: the caller's series, or an API key's organization's.
+func (h *Handler) List(c *gin.Context) {
+	var createdBy, orgID *uuid.UUID
+	if id, ok := middleware.APIKeyOrganizationID(c); ok {
+		orgID = &id
+	} else {
+		userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
+		createdBy = &userID
+	}
+	list, err := h.repo.List(c.Request.Context(), createdBy, orgID)
+	if err != nil {
+		h.logger.Error("list series failed", zap.Error(err))
+		response.Internal(c, "failed to list series")
+		return
+	}
+	if list == nil {
+		list = []*models.WebinarSeries{}
+	}
+	response.OK(c, list)
+}
+
+// Get handles GET /series/:id (no auth): the series and its published upcoming occurrences.
+func (h *Handler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid series id")
+		return
+	}
+	s, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("load series failed", zap.Error(err), zap.String("series_id", id.String()))
+		response.Internal(c, "failed to load series")
+		return
+	}
+	if s == nil {
+		response.NotFound(c, "series not found")
+		return
+	}
+	occs, err := h.repo.ListOccurrences(c.Request.Context(), id, time.Now().Add(-24*time.Hour),
+		[]string{models.WebinarStatusScheduled, models.WebinarStatusLive})
+	if err != nil {
+		h.logger.Error("list occurrences failed", zap.Error(err), zap.String("series_id", id.String()))
+		response.Internal(c, "failed to load series")
+		return
+	}
+	if occs == nil {
+		occs = []Occurrence{}
+	}
+	response.OK(c, gin.H{"series": s, "occurrences": occs})
+}
+
+// Occurrences handles GET /series/:id/occurrences: every occurrence of the series in any status, and the
+// series' shared ads.
+func (h *Handler) Occurrences(c *gin.Context) {
+	s, ok := h.managedSeries(c)
+	if !ok {
+		return
+	}
+	occs, err := h.repo.ListOccurrences(c.Request.Context(), s.ID, time.Time{}, nil)
+	if err != nil {
+		h.logger.Error("list occurrences failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+		response.Internal(c, "failed to list occurrences")
+		return
+	}
+	if occs == nil {
+		occs = []Occurrence{}
+	}
+	seriesAds, err := h.repo.ListAds(c.Request.Context(), s.ID)
+	if err != nil {
+		h.logger.Error("list series ads failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+		response.Internal(c, "failed to list occurrences")
+		return
+	}
+	response.OK(c, gin.H{"series": s, "occurrences": occs, "ads": seriesAds})
+}
+
+// Update handles PATCH /series/:id: edits the series and all of its future occurrences.
+func (h *Handler) Update(c *gin.Context) {
+	s, ok := h.managedSeries(c)
+	if !ok {
+		return
+	}
+	var req UpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request")
+		return
+	}
+	h.updateFollowing(c, s, nil, &req)
+}
+
+// UpdateOccurrence handles PATCH /series/:id/occurrences/:webinarId: scope "this" edits only that occurrence;
+// "following" edits the series from that occurrence on (earlier occurrences keep their settings).
+func (h *Handler) UpdateOccurrence(c *gin.Context) {
+	s, ok := h.managedSeries(c)
+	if !ok {
+		return
+	}
+	webinarID, err := uuid.Parse(c.Param("webinarId"))
+	if err != nil {
+		response.BadRequest(c, "invalid webinar id")
+		return
+	}
+	w, err := h.webinarRepo.GetByID(c.Request.Context(), webinarID)
+	if err != nil || w.SeriesID == nil || *w.SeriesID != s.ID {
+		response.NotFound(c, "occurrence not found")
+		return
+	}
+	if w.Status != models.WebinarStatusDraft && w.Status != models.WebinarStatusScheduled {
+		response.Conflict(c, "cannot edit a "+w.Status+" occurrence")
+		return
+	}
+	var req UpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request")
+		return
+	}
+	switch req.Scope {
+	case "", ScopeThis:
+		h.updateThis(c, s, w, &req)
+	case ScopeFollowing:
+		h.updateFollowing(c, s, w, &req)
+	default:
+		response.BadRequest(c, "scope must be this or following")
+	}
+}
+
+// updateThis applies req to the single occurrence w; its start may move without changing the series.
+func (h *Handler) updateThis(c *gin.Context, s *models.WebinarSeries, w *models.Webinar, req *UpdateRequest) {
+	if req.RRule != nil || req.Timezone != nil || req.Status != nil {
+		response.BadRequest(c, "rrule, timezone and status apply to the series; use scope following")
+		return
+	}
+	var startsAt *time.Time
+	if req.StartsAt != nil {
+		t, err := time.Parse(time.RFC3339, *req.StartsAt)
+		if err != nil {
+			response.BadRequest(c, "invalid starts_at")
+			return
+		}
+		startsAt = &t
+	}
+	// The occurrence's current settings, with the series' duration when it has no end.
+	o := *s
+	o.Title, o.Description, o.IsPaid, o.TicketPriceCents, o.TicketCurrency = w.Title, w.Description, w.IsPaid, w.TicketPriceCents, w.TicketCurrency
+	o.MaxAudience, o.Category, o.BannerImageURL, o.AudienceFormConfig = w.MaxAudience, w.Category, w.BannerImageURL, w.AudienceFormConfig
+	if w.EndsAt != nil {
+		o.DurationMinutes = int(w.EndsAt.Sub(w.StartsAt) / time.Minute)
+	}
+	if msg := applySettings(&o, req); msg != "" {
+		response.BadRequest(c, msg)
+		return
+	}
+	ctx := c.Request.Context()
+	if err := h.repo.UpdateOccurrences(ctx, &o, []uuid.UUID{w.ID}, req.SpeakerIDs != nil); err != nil {
+		h.logger.Error("update occurrence failed", zap.Error(err), zap.String("webinar_id", w.ID.String()))
+		response.Internal(c, "failed to update occurrence")
+		return
+	}
+	if startsAt != nil && !startsAt.Equal(w.StartsAt) {
+		if err := h.repo.MoveOccurrence(ctx, w.ID, *startsAt); err != nil {
+			h.logger.Error("move occurrence failed", zap.Error(err), zap.String("webinar_id", w.ID.String()))
+			response.Internal(c, "failed to update occurrence")
+			return
+		}
+		h.gen.moved(ctx, w.ID)
+	}
+	updated, err := h.webinarRepo.GetByID(ctx, w.ID)
+	if err != nil {
+		response.Internal(c, "failed to load occurrence")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "series.occurrence.update", TargetType: "webinar", TargetID: w.ID.String(),
+		OrganizationID: s.OrganizationID, Before: w, After: updated})
+	response.OK(c, updated)
+}
+
+// updateFollowing applies req to the series and to its future draft and scheduled occurrences: all of them,
+// or from occurrence `from` on. A changed rule, time zone or start re-plans those occurrences (Reschedule).
+func (h *Handler) updateFollowing(c *gin.Context, s *models.WebinarSeries, from *models.Webinar, req *UpdateRequest) {
+	before := *s
+	if msg := applySettings(s, req); msg != "" {
+		response.BadRequest(c, msg)
+		return
+	}
+	var anchor *time.Time
+	if from != nil {
+		anchor = from.OccurrenceAt
+	}
+	if msg := applySchedule(s, req, anchor); msg != "" {
+		response.BadRequest(c, msg)
+		return
+	}
+	ctx := c.Request.Context()
+	if err := h.repo.Update(ctx, s); err != nil {
+		h.logger.Error("update series failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+		response.Internal(c, "failed to update series")
+		return
+	}
+	now := time.Now()
+	start := now
+	if from != nil && from.OccurrenceAt != nil {
+		start = *from.OccurrenceAt
+	}
+	var res RescheduleResult
+	if s.RRule != before.RRule || s.Timezone != before.Timezone || !s.StartsAt.Equal(before.StartsAt) {
+		var err error
+		if res, err = h.gen.Reschedule(ctx, s, start, now); err != nil {
+			h.logger.Error("reschedule series failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+			response.Internal(c, "failed to reschedule occurrences")
+			return
+		}
+	}
+	occs, err := h.repo.ListOccurrences(ctx, s.ID, start, editableStatuses)
+	if err != nil {
+		h.logger.Error("list occurrences failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+		response.Internal(c, "failed to update occurrences")
+		return
+	}
+	ids := make([]uuid.UUID, len(occs))
+	for i, o := range occs {
+		ids[i] = o.WebinarID
+	}
+	if err := h.repo.UpdateOccurrences(ctx, s, ids, true); err != nil {
+		h.logger.Error("update occurrences failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+		response.Internal(c, "failed to update occurrences")
+		return
+	}
+	if before.OccurrenceStatus == models.WebinarStatusDraft && s.OccurrenceStatus == models.WebinarStatusScheduled {
+		t := models.WebinarTransitions[models.WebinarActionPublish]
+		for _, o := range occs {
+			if o.Status != models.WebinarStatusDraft {
+				continue
+			}
+			if _, err := h.webinarRepo.Transition(ctx, o.WebinarID, t.From, t.To); err != nil {
+				h.logger.Error("publish occurrence failed", zap.Error(err), zap.String("webinar_id", o.WebinarID.String()))
+				continue
+			}
+			h.gen.planReminders(ctx, o.WebinarID)
+		}
+	}
+	action := "series.update"
+	if from != nil {
+		action = "series.update_following"
+	}
+	audit.Annotate(c, audit.Change{Action: action, TargetType: "series", TargetID: s.ID.String(), OrganizationID: s.OrganizationID,
+		Before: before, After: s})
+	response.OK(c, gin.H{"series": s, "updated_occurrences": len(ids), "rescheduled": res})
+}
+
+// UpdateAds handles PUT /series/:id/ads: the given uploaded ads become the series' shared creatives, shown in
+// every future occurrence in place of the previous ones. Ads must belong to webinars of the series' owner.
+func (h *Handler) UpdateAds(c *gin.Context) {
+	s, ok := h.managedSeries(c)
+	if !ok {
+		return
+	}
+	var req AdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request")
+		return
+	}
+	ctx := c.Request.Context()
+	list := make([]models.Advertisement, 0, len(req.AdvertisementIDs))
+	for _, idStr := range req.AdvertisementIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			response.BadRequest(c, "invalid advertisement id "+idStr)
+			return
+		}
+		a, err := h.adRepo.GetAdvertisementByID(ctx, id)
+		if err != nil {
+			response.NotFound(c, "advertisement "+idStr+" not found")
+			return
+		}
+		w, err := h.webinarRepo.GetByID(ctx, a.WebinarID)
+		if err != nil || !sameOwner(s, w) {
+			response.Forbidden(c, "advertisement "+idStr+" belongs to another owner's webinar")
+			return
+		}
+		list = append(list, *a)
+	}
+	occs, err := h.repo.ListOccurrences(ctx, s.ID, time.Now(), editableStatuses)
+	if err != nil {
+		h.logger.Error("list occurrences failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+		response.Internal(c, "failed to update ads")
+		return
+	}
+	ids := make([]uuid.UUID, len(occs))
+	for i, o := range occs {
+		ids[i] = o.WebinarID
+	}
+	before, _ := h.repo.ListAds(ctx, s.ID)
+	if err := h.repo.ReplaceAds(ctx, s.ID, list, ids); err != nil {
+		h.logger.Error("replace series ads failed", zap.Error(err), zap.String("series_id", s.ID.String()))
+		response.Internal(c, "failed to update ads")
+		return
+	}
+	after, err := h.repo.ListAds(ctx, s.ID)
+	if err != nil {
+		response.Internal(c, "failed to load ads")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "series.ads", TargetType: "series", TargetID: s.ID.String(), OrganizationID: s.OrganizationID,
+		Before: before, After: after})
+	response.OK(c, gin.H{"ads": after, "updated_occurrences": len(ids)})
+}
+
+// Register handles POST /series/:id/register (no auth): registers the attendee for every upcoming occurrence
+// and for occurrences generated later. Single occurrences use POST /webinars/:id/register.
+func (h *Handler) Register(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid series id")
+		return
+	}
+	ctx := c.Request.Context()
+	s, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		h.logger.Error("load series failed", zap.Error(err), zap.String("series_id", id.String()))
+		response.Internal(c, "failed to register")
+		return
+	}
+	if s == nil {
+		response.NotFound(c, "series not found")
+		return
+	}
+	if s.OccurrenceStatus != models.WebinarStatusScheduled {
+		response.Conflict(c, "registration is closed for this series")
+		return
+	}
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	fields, err := forms.ParseConfig(s.AudienceFormConfig)
+	if err != nil {
+		h.logger.Error("parse registration form failed", zap.Error(err), zap.String("series_id", id.String()))
+		response.Internal(c, "failed to register")
+		return
+	}
+	answers, fieldErrs := forms.ValidateResponses(fields, req.FormResponses, forms.Options{})
+	if len(fieldErrs) > 0 {
+		response.BadRequestDetails(c, "invalid form_responses", gin.H{"fields": fieldErrs})
+		return
+	}
+	reg := &models.SeriesRegistration{SeriesID: s.ID, Email: req.Email, FullName: req.FullName}
+	if len(answers) > 0 {
+		if reg.ExtraData, err = json.Marshal(answers); err != nil {
+			response.BadRequest(c, "invalid form_responses")
+			return
+		}
+	}
+	reg.Locale = email.NormalizeLocale(req.Locale)
+	if reg.Locale == "" {
+		reg.Locale = email.LocaleFromAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
+	if err := h.repo.UpsertRegistration(ctx, reg); err != nil {
+		h.logger.Error("create series registration failed", zap.Error(err), zap.String("series_id", id.String()))
+		response.Internal(c, "failed to register")
+		return
+	}
+	occs, err := h.gen.RegisterAll(ctx, s, reg, time.Now())
+	if err != nil {
+		h.logger.Error("register for occurrences failed", zap.Error(err), zap.String("series_registration_id", reg.ID.String()))
+		response.Internal(c, "failed to register for every occurrence")
+		return
+	}
+	response.OK(c, gin.H{"series_registration_id": reg.ID, "occurrences": occs})
+}
+
+// managedSeries loads the :id series for an owner or event manager of its organization (as for its webinars),
+// or for its creator if it has none; otherwise it writes the error response.
+func (h *Handler) managedSeries(c *gin.Context) (*models.WebinarSeries, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid series id")
+		return nil, false
+	}
+	s, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("load series failed", zap.Error(err), zap.String("series_id", id.String()))
+		response.Internal(c, "failed to load series")
+		return nil, false
+	}
+	if s == nil {
+		response.NotFound(c, "series not found")
+		return nil, false
+	}
+	if s.OrganizationID != nil {
+		if !middleware.AuthorizeOrgRole(c, h.orgRepo, *s.OrganizationID, models.OrgManagerRoles...) {
+			return nil, false
+		}
+		return s, true
+	}
+	if _, ok := middleware.APIKeyOrganizationID(c); ok {
+		response.Forbidden(c, "not authorized for this organization")
+		return nil, false
+	}
+	if s.CreatedBy != c.MustGet(middleware.ContextUserID).(uuid.UUID) {
+		response.Forbidden(c, "only the creator can manage this series")
+		return nil, false
+	}
+	return s, true
+}
+
+// sameOwner reports whether w belongs to the series' creator or organization.
+func sameOwner(s *models.WebinarSeries, w *models.Webinar) bool {
+	if s.OrganizationID != nil {
+		return w.OrganizationID != nil && *w.OrganizationID == *s.OrganizationID
+	}
+	return w.CreatedBy == s.CreatedBy
+}
+
+// applySettings applies the shared settings in req to s; returns a validation message or "".
+func applySettings(s *models.WebinarSeries, req *UpdateRequest) string {
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			return "title is required"
+		}
+		s.Title = *req.Title
+	}
+	if req.Description != nil {
+		s.Description = *req.Description
+	}
+	if req.DurationMinutes != nil {
+		if *req.DurationMinutes < 1 {
+			return "duration_minutes must be at least 1"
+		}
+		s.DurationMinutes = *req.DurationMinutes
+	}
+	if req.IsPaid != nil {
+		s.IsPaid = *req.IsPaid
+	}
+	if req.TicketPriceCents != nil {
+		if *req.TicketPriceCents < 0 {
+			return "ticket_price_cents must not be negative"
+		}
+		s.TicketPriceCents = *req.TicketPriceCents
+	}
+	if req.TicketCurrency != nil {
+		if len(*req.TicketCurrency) != 3 {
+			return "ticket_currency must be a 3-letter code"
+		}
+		s.TicketCurrency = strings.ToUpper(*req.TicketCurrency)
+	}
+	if req.MaxAudience != nil {
+		s.MaxAudience = req.MaxAudience
+		if *req.MaxAudience < 0 {
+			s.MaxAudience = nil // treat negative as unlimited
+		}
+	}
+	if req.Category != nil {
+		s.Category = *req.Category
+	}
+	if req.BannerImageURL != nil {
+		s.BannerImageURL = *req.BannerImageURL
+	}
+	if req.AudienceFormConfig != nil {
+		if err := forms.ValidateConfig(*req.AudienceFormConfig); err != nil {
+			return "invalid audience_form_config: " + err.Error()
+		}
+		config, err := json.Marshal(*req.AudienceFormConfig)
+		if err != nil {
+			return "invalid audience_form_config"
+		}
+		s.AudienceFormConfig = config
+	}
+	if req.SpeakerIDs != nil {
+		ids := make([]uuid.UUID, 0, len(*req.SpeakerIDs))
+		for _, v := range *req.SpeakerIDs {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return "invalid speaker id " + v
+			}
+			ids = append(ids, id)
+		}
+		s.SpeakerIDs = ids
+	}
+	return ""
+}
+
+// applySchedule applies the rule, time zone, start and status in req to s; returns a validation message or
+// "". When the rule or time zone changes without a new start, the rule restarts at anchor (the occurrence a
+// "following" edit starts from) or keeps its start, at the same wall-clock time in the new time zone.
+func applySchedule(s *models.WebinarSeries, req *UpdateRequest, anchor *time.Time) string {
+	if req.Status != nil {
+		if *req.Status != models.WebinarStatusDraft && *req.Status != models.WebinarStatusScheduled {
+			return "status must be draft or scheduled"
+		}
+		s.OccurrenceStatus = *req.Status
+	}
+	if req.RRule == nil && req.Timezone == nil && req.StartsAt == nil {
+		return ""
+	}
+	oldTZ := s.Timezone
+	if req.RRule != nil {
+		s.RRule = strings.TrimPrefix(strings.TrimSpace(*req.RRule), "RRULE:")
+	}
+	if req.Timezone != nil {
+		s.Timezone = *req.Timezone
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil || s.Timezone == "" {
+		return "unknown timezone " + s.Timezone
+	}
+	switch {
+	case req.StartsAt != nil:
+		t, err := time.Parse(time.RFC3339, *req.StartsAt)
+		if err != nil {
+			return "invalid starts_at"
+		}
+		s.StartsAt = t
+	default:
+		start := s.StartsAt
+		if anchor != nil {
+			start = *anchor
+		}
+		if old, err := time.LoadLocation(oldTZ); err == nil {
+			start = start.In(old)
+		}
+		s.StartsAt = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+	}
+	rule, dtstart, err := Schedule(s)
+	if err != nil {
+		return "invalid rrule: " + err.Error()
+	}
+	if len(rule.Between(dtstart, time.Now(), time.Time{}, 1)) == 0 {
+		return "the rule has no upcoming occurrences"
+	}
+	return ""
+}
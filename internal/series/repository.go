@@ -0,0 +1,365 @@
+package series
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository handles webinar_series, their ads and registrations, and the series columns of their
+// occurrences (webinars).
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a series repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const columns = `id, title, description, rrule, timezone, starts_at, duration_minutes, occurrence_status, created_by,
+	organization_id, is_paid, ticket_price_cents, ticket_currency, max_audience, category, banner_image_url,
+	audience_form_config, speaker_ids, generated_until, created_at, updated_at`
+
+func scanSeries(row pgx.Row) (*models.WebinarSeries, error) {
+	var s models.WebinarSeries
+	if err := row.Scan(&s.ID, &s.Title, &s.Description, &s.RRule, &s.Timezone, &s.StartsAt, &s.DurationMinutes,
+		&s.OccurrenceStatus, &s.CreatedBy, &s.OrganizationID, &s.IsPaid, &s.TicketPriceCents, &s.TicketCurrency,
+		&s.MaxAudience, &s.Category, &s.BannerImageURL, &s.AudienceFormConfig, &s.SpeakerIDs, &s.GeneratedUntil,
+		&s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Create inserts a series.
+func (r *Repository) Create(ctx context.Context, s *models.WebinarSeries) error {
+	q := `INSERT INTO webinar_series (title, description, rrule, timezone, starts_at, duration_minutes, occurrence_status,
+			created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, max_audience, category, banner_image_url,
+			audience_form_config, speaker_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING ` + columns
+	saved, err := scanSeries(r.pool.QueryRow(ctx, q, s.Title, s.Description, s.RRule, s.Timezone, s.StartsAt, s.DurationMinutes,
+		s.OccurrenceStatus, s.CreatedBy, s.OrganizationID, s.IsPaid, s.TicketPriceCents, s.TicketCurrency, s.MaxAudience,
+		s.Category, s.BannerImageURL, s.AudienceFormConfig, s.SpeakerIDs))
+	if err != nil {
+		return err
+	}
+	*s = *saved
+	return nil
+}
+
+// GetByID returns a series, or nil, nil.
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*models.WebinarSeries, error) {
+	s, err := scanSeries(r.pool.QueryRow(ctx, `SELECT `+columns+` FROM webinar_series WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+// List returns series created by createdBy or, for an organization, all of its series; newest first.
+func (r *Repository) List(ctx context.Context, createdBy, organizationID *uuid.UUID) ([]*models.WebinarSeries, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+columns+` FROM webinar_series
+		WHERE ($1::uuid IS NULL OR created_by = $1) AND ($2::uuid IS NULL OR organization_id = $2)
+		ORDER BY created_at DESC`, createdBy, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*models.WebinarSeries
+	for rows.Next() {
+		s, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// Update saves a series' settings and schedule.
+func (r *Repository) Update(ctx context.Context, s *models.WebinarSeries) error {
+	const q = `UPDATE webinar_series SET title = $2, description = $3, rrule = $4, timezone = $5, starts_at = $6,
+			duration_minutes = $7, occurrence_status = $8, is_paid = $9, ticket_price_cents = $10, ticket_currency = $11,
+			max_audience = $12, category = $13, banner_image_url = $14, audience_form_config = $15, speaker_ids = $16,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+	return r.pool.QueryRow(ctx, q, s.ID, s.Title, s.Description, s.RRule, s.Timezone, s.StartsAt, s.DurationMinutes,
+		s.OccurrenceStatus, s.IsPaid, s.TicketPriceCents, s.TicketCurrency, s.MaxAudience, s.Category, s.BannerImageURL,
+		s.AudienceFormConfig, s.SpeakerIDs).Scan(&s.UpdatedAt)
+}
+
+// SetGeneratedUntil records that occurrences exist up to t.
+func (r *Repository) SetGeneratedUntil(ctx context.Context, id uuid.UUID, t time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE webinar_series SET generated_until = $2 WHERE id = $1`, id, t)
+	return err
+}
+
+// ListDue returns series whose occurrences were generated only up to before (or not at all), oldest first.
+func (r *Repository) ListDue(ctx context.Context, before time.Time, limit int) ([]*models.WebinarSeries, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+columns+` FROM webinar_series
+		WHERE generated_until IS NULL OR generated_until < $1
+		ORDER BY generated_until NULLS FIRST LIMIT $2`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*models.WebinarSeries
+	for rows.Next() {
+		s, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// Occurrence is an occurrence of a series as the series code sees it.
+type Occurrence struct {
+	WebinarID    uuid.UUID `json:"webinar_id"`
+	Title        string    `json:"title"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	OccurrenceAt time.Time `json:"occurrence_at"`
+	Status       string    `json:"status"`
+	MaxAudience  *int      `json:"max_audience,omitempty"`
+	Registered   int       `json:"registered"`
+}
+
+// ListOccurrences returns the series' occurrences whose rule time is from or later, in statuses (all when
+// empty), in order.
+func (r *Repository) ListOccurrences(ctx context.Context, seriesID uuid.UUID, from time.Time, statuses []string) ([]Occurrence, error) {
+	rows, err := r.pool.Query(ctx, `SELECT w.id, w.title, w.starts_at, COALESCE(w.ends_at, w.starts_at), w.occurrence_at, w.status, w.max_audience,
+			(SELECT COUNT(*) FROM registrations reg WHERE reg.webinar_id = w.id)
+		FROM webinars w
+		WHERE w.series_id = $1 AND w.occurrence_at >= $2 AND (cardinality($3::text[]) = 0 OR w.status = ANY($3))
+		ORDER BY w.occurrence_at`, seriesID, from, statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Occurrence
+	for rows.Next() {
+		var o Occurrence
+		if err := rows.Scan(&o.WebinarID, &o.Title, &o.StartsAt, &o.EndsAt, &o.OccurrenceAt, &o.Status, &o.MaxAudience, &o.Registered); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// CreateOccurrence inserts the series' occurrence at occurrenceAt with the series' settings, speakers and
+// ads. Returns nil, nil when the occurrence already exists.
+func (r *Repository) CreateOccurrence(ctx context.Context, s *models.WebinarSeries, occurrenceAt time.Time) (*models.Webinar, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	w := &models.Webinar{
+		Title:              s.Title,
+		Description:        s.Description,
+		StartsAt:           occurrenceAt,
+		CreatedBy:          s.CreatedBy,
+		OrganizationID:     s.OrganizationID,
+		IsPaid:             s.IsPaid,
+		TicketPriceCents:   s.TicketPriceCents,
+		TicketCurrency:     s.TicketCurrency,
+		MaxAudience:        s.MaxAudience,
+		Category:           s.Category,
+		BannerImageURL:     s.BannerImageURL,
+		AudienceFormConfig: s.AudienceFormConfig,
+		Status:             s.OccurrenceStatus,
+		Visibility:         models.WebinarVisibilityPublic,
+		SeriesID:           &s.ID,
+		OccurrenceAt:       &occurrenceAt,
+	}
+	endsAt := occurrenceAt.Add(time.Duration(s.DurationMinutes) * time.Minute)
+	w.EndsAt = &endsAt
+	const q = `INSERT INTO webinars (title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents,
+			ticket_currency, max_audience, category, banner_image_url, audience_form_config, status, series_id, occurrence_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (series_id, occurrence_at) DO NOTHING
+		RETURNING id, created_at, updated_at`
+	err = tx.QueryRow(ctx, q, w.Title, w.Description, w.StartsAt, w.EndsAt, w.CreatedBy, w.OrganizationID, w.IsPaid, w.TicketPriceCents,
+		w.TicketCurrency, w.MaxAudience, w.Category, w.BannerImageURL, w.AudienceFormConfig, w.Status, w.SeriesID, w.OccurrenceAt).
+		Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO webinar_speakers (webinar_id, user_id) SELECT $1, unnest($2::uuid[])
+		ON CONFLICT (webinar_id, user_id) DO NOTHING`, w.ID, s.SpeakerIDs); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO advertisements (webinar_id, file_url, file_type, file_size, duration, s3_key, is_active, series_ad_id)
+		SELECT $1, file_url, file_type, file_size, duration, s3_key, TRUE, id FROM webinar_series_ads WHERE series_id = $2 ORDER BY position`,
+		w.ID, s.ID); err != nil {
+		return nil, err
+	}
+	return w, tx.Commit(ctx)
+}
+
+// UpdateOccurrences applies the settings in s (title through form config, and the duration from each
+// occurrence's start) to the given occurrences. With syncSpeakers their speakers become s.SpeakerIDs.
+func (r *Repository) UpdateOccurrences(ctx context.Context, s *models.WebinarSeries, ids []uuid.UUID, syncSpeakers bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	const q = `UPDATE webinars SET title = $2, description = $3, ends_at = starts_at + make_interval(mins => $4), is_paid = $5,
+			ticket_price_cents = $6, ticket_currency = $7, max_audience = $8, category = $9, banner_image_url = $10,
+			audience_form_config = $11, updated_at = NOW()
+		WHERE id = ANY($1)`
+	if _, err := tx.Exec(ctx, q, ids, s.Title, s.Description, s.DurationMinutes, s.IsPaid, s.TicketPriceCents, s.TicketCurrency,
+		s.MaxAudience, s.Category, s.BannerImageURL, s.AudienceFormConfig); err != nil {
+		return err
+	}
+	if syncSpeakers {
+		if _, err := tx.Exec(ctx, `DELETE FROM webinar_speakers WHERE webinar_id = ANY($1) AND NOT (user_id = ANY($2::uuid[]))`,
+			ids, s.SpeakerIDs); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO webinar_speakers (webinar_id, user_id)
+			SELECT w, u FROM unnest($1::uuid[]) w, unnest($2::uuid[]) u
+			ON CONFLICT (webinar_id, user_id) DO NOTHING`, ids, s.SpeakerIDs); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Move is an occurrence's new time in the series rule; the occurrence starts then and keeps its duration.
+type Move struct {
+	WebinarID    uuid.UUID
+	OccurrenceAt time.Time
+}
+
+// MoveOccurrences reschedules occurrences in one transaction, so occurrences may take each other's times.
+func (r *Repository) MoveOccurrences(ctx context.Context, moves []Move) error {
+	if len(moves) == 0 {
+		return nil
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	ids := make([]uuid.UUID, len(moves))
+	for i, m := range moves {
+		ids[i] = m.WebinarID
+	}
+	if _, err := tx.Exec(ctx, `UPDATE webinars SET occurrence_at = NULL WHERE id = ANY($1)`, ids); err != nil {
+		return err
+	}
+	for _, m := range moves {
+		if _, err := tx.Exec(ctx, `UPDATE webinars SET occurrence_at = $2, starts_at = $2,
+				ends_at = $2 + (COALESCE(ends_at, starts_at) - starts_at), updated_at = NOW()
+			WHERE id = $1`, m.WebinarID, m.OccurrenceAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// MoveOccurrence moves one occurrence to startsAt, keeping its duration and its time in the series rule.
+func (r *Repository) MoveOccurrence(ctx context.Context, webinarID uuid.UUID, startsAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE webinars SET starts_at = $2, ends_at = $2 + (COALESCE(ends_at, starts_at) - starts_at),
+			updated_at = NOW()
+		WHERE id = $1`, webinarID, startsAt)
+	return err
+}
+
+// ListAds returns the ad creatives copied into the series' occurrences.
+func (r *Repository) ListAds(ctx context.Context, seriesID uuid.UUID) ([]models.SeriesAd, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, series_id, file_url, file_type, file_size, duration, COALESCE(s3_key, ''), created_at
+		FROM webinar_series_ads WHERE series_id = $1 ORDER BY position`, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []models.SeriesAd{}
+	for rows.Next() {
+		var a models.SeriesAd
+		if err := rows.Scan(&a.ID, &a.SeriesID, &a.FileURL, &a.FileType, &a.FileSize, &a.Duration, &a.S3Key, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// ReplaceAds makes ads the series' ad creatives: the copies of the previous ones are removed from the given
+// occurrences and copies of the new ones added.
+func (r *Repository) ReplaceAds(ctx context.Context, seriesID uuid.UUID, ads []models.Advertisement, occurrenceIDs []uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM advertisements WHERE webinar_id = ANY($1)
+		AND series_ad_id IN (SELECT id FROM webinar_series_ads WHERE series_id = $2)`, occurrenceIDs, seriesID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM webinar_series_ads WHERE series_id = $1`, seriesID); err != nil {
+		return err
+	}
+	for i, a := range ads {
+		if _, err := tx.Exec(ctx, `INSERT INTO webinar_series_ads (series_id, file_url, file_type, file_size, duration, s3_key, position)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)`, seriesID, a.FileURL, a.FileType, a.FileSize, a.Duration, a.S3Key, i); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO advertisements (webinar_id, file_url, file_type, file_size, duration, s3_key, is_active, series_ad_id)
+		SELECT w, a.file_url, a.file_type, a.file_size, a.duration, a.s3_key, TRUE, a.id
+		FROM unnest($1::uuid[]) w, webinar_series_ads a WHERE a.series_id = $2`, occurrenceIDs, seriesID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UpsertRegistration stores a series registration (unique per series+email; re-registering updates it).
+func (r *Repository) UpsertRegistration(ctx context.Context, reg *models.SeriesRegistration) error {
+	const q = `INSERT INTO webinar_series_registrations (series_id, email, full_name, extra_data, locale)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (series_id, email) DO UPDATE SET full_name = EXCLUDED.full_name, extra_data = EXCLUDED.extra_data,
+			locale = COALESCE(EXCLUDED.locale, webinar_series_registrations.locale), updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+	return r.pool.QueryRow(ctx, q, reg.SeriesID, reg.Email, reg.FullName, reg.ExtraData, reg.Locale).
+		Scan(&reg.ID, &reg.CreatedAt, &reg.UpdatedAt)
+}
+
+// ListRegistrations returns the series' registrations, oldest first.
+func (r *Repository) ListRegistrations(ctx context.Context, seriesID uuid.UUID) ([]models.SeriesRegistration, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, series_id, email, full_name, extra_data, COALESCE(locale, ''), created_at, updated_at
+		FROM webinar_series_registrations WHERE series_id = $1 ORDER BY created_at`, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.SeriesRegistration
+	for rows.Next() {
+		var reg models.SeriesRegistration
+		if err := rows.Scan(&reg.ID, &reg.SeriesID, &reg.Email, &reg.FullName, &reg.ExtraData, &reg.Locale, &reg.CreatedAt, &reg.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, reg)
+	}
+	return list, rows.Err()
+}
@@ -90,3 +90,30 @@ func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]
 	}
 	return list, rows.Err()
 }
+
+// WatchSecondsByEmail returns total live watch time per attendee email (lowercased) for a webinar.
+// Sessions are matched to registrations by registration_id or by the joining user's email.
+func (r *Repository) WatchSecondsByEmail(ctx context.Context, webinarID uuid.UUID) (map[string]int64, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT lower(COALESCE(reg.email, u.email)), SUM(l.watch_seconds)::BIGINT
+		 FROM user_session_logs l
+		 LEFT JOIN registrations reg ON reg.id = l.registration_id
+		 LEFT JOIN users u ON u.id = l.user_id
+		 WHERE l.webinar_id = $1 AND l.left_at IS NOT NULL AND COALESCE(reg.email, u.email) IS NOT NULL
+		 GROUP BY 1`,
+		webinarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]int64)
+	for rows.Next() {
+		var email string
+		var seconds int64
+		if err := rows.Scan(&email, &seconds); err != nil {
+			return nil, err
+		}
+		out[email] = seconds
+	}
+	return out, rows.Err()
+}
@@ -1,6 +1,7 @@
 package speakerinvites
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -92,30 +93,38 @@ func (h *Handler) Invite(c *gin.Context) {
 		}
 	}
 
-	inv, err := h.inviteRepo.Create(c.Request.Context(), webinarID, req.Email)
-	if err != nil {
+	if err := h.InviteByEmail(c.Request.Context(), w, req.Email); err != nil {
 		h.logger.Error("create invitation failed", zap.Error(err))
 		response.Internal(c, "failed to create invitation")
 		return
 	}
 
+	response.Created(c, gin.H{"message": "Invitation sent", "email": req.Email})
+}
+
+// InviteByEmail creates a pending invitation to speak at w and emails it, even when the address already has
+// an account (accepting adds the speaker). Email failures are logged, not returned.
+func (h *Handler) InviteByEmail(ctx context.Context, w *models.Webinar, email string) error {
+	inv, err := h.inviteRepo.Create(ctx, w.ID, email)
+	if err != nil {
+		return err
+	}
+
 	if h.jobQueue != nil && h.frontendURL != "" {
 		inviteURL := h.frontendURL + "/auth/speaker-invite?token=" + inv.Token
 		payload := queue.EmailPayload{
 			EmailType:      models.EmailTypeSpeakerInvitation,
-			WebinarID:      webinarID,
+			WebinarID:      w.ID,
 			RegistrationID: uuid.Nil,
-			RecipientEmail: req.Email,
+			RecipientEmail: email,
 			WebinarTitle:   w.Title,
 			InviteURL:      inviteURL,
-			Subject:        "You're invited to speak: " + w.Title,
 		}
-		if err := h.jobQueue.EnqueueEmail(c.Request.Context(), payload); err != nil {
+		if err := h.jobQueue.EnqueueEmail(ctx, payload); err != nil {
 			h.logger.Warn("enqueue invite email failed", zap.Error(err))
 		}
 	}
-
-	response.Created(c, gin.H{"message": "Invitation sent", "email": req.Email})
+	return nil
 }
 
 // GetInviteByToken handles GET /auth/speaker-invite/validate?token=X. Returns invite info for the signup page.
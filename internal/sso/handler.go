@@ -0,0 +1,370 @@
+// Package sso implements OpenID Connect single sign-on (authorization code + PKCE) for the organizer console.
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/config"
+	"github.com/aura-webinar/backend/internal/auth"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/organizations"
+	"github.com/aura-webinar/backend/pkg/response"
+	"github.com/aura-webinar/backend/pkg/utils"
+)
+
+// stateKeyPrefix is the Redis key prefix for pending authorization requests.
+const stateKeyPrefix = "sso:state:"
+
+// stateCookie holds the state of the browser's pending authorization request, so a callback is only accepted
+// from the browser that started it (not from a victim sent an attacker's IdP redirect).
+const stateCookie = "aura_sso_state"
+
+var (
+	// errAccountExists means the IdP account is not linked and its email belongs to an existing user, who must
+	// sign in and link the provider first (GET /auth/sso/:provider/link).
+	errAccountExists = errors.New("account exists")
+	// errEmailNotVerified means the IdP account is not linked and the IdP did not vouch for its email.
+	errEmailNotVerified = errors.New("email not verified")
+	// errLinkedElsewhere means the IdP account is already linked to another user.
+	errLinkedElsewhere = errors.New("identity linked to another user")
+)
+
+// authRequest is stored in Redis between /authorize and /callback (one-time use).
+type authRequest struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	Redirect     string `json:"redirect,omitempty"`
+	LinkUserID   string `json:"link_user_id,omitempty"` // set by /link: the callback links the IdP account to this user
+}
+
+// CallbackRequest is the body for POST /auth/sso/:provider/callback.
+type CallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// identityStore, userStore and domainStore are the parts of Repository, auth.Repository and
+// organizations.Repository the handler uses.
+type identityStore interface {
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	Link(ctx context.Context, userID uuid.UUID, provider, subject, email string) error
+}
+
+type userStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Create(ctx context.Context, email, passwordHash, fullName string, role models.Role, profile *auth.CreateUserParams, emailVerified bool) (*models.User, error)
+}
+
+type domainStore interface {
+	GetVerifiedDomain(ctx context.Context, domain string) (*models.OrganizationDomain, error)
+	EnsureMember(ctx context.Context, orgID, userID uuid.UUID, role string) error
+}
+
+// Handler handles SSO HTTP endpoints.
+type Handler struct {
+	providers map[string]*Provider
+	order     []string
+	repo      identityStore
+	authRepo  userStore
+	orgRepo   domainStore
+	jwt       *auth.JWTService
+	rdb       *redis.Client
+	stateTTL  time.Duration
+	logger    *zap.Logger
+}
+
+// NewHandler creates an SSO handler for the configured providers.
+func NewHandler(cfg config.SSOConfig, repo *Repository, authRepo *auth.Repository, orgRepo *organizations.Repository, jwt *auth.JWTService, rdb *redis.Client, logger *zap.Logger) *Handler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	ttl := time.Duration(cfg.StateTTL) * time.Minute
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	h := &Handler{
+		providers: make(map[string]*Provider, len(cfg.Providers)),
+		repo:      repo,
+		authRepo:  authRepo,
+		orgRepo:   orgRepo,
+		jwt:       jwt,
+		rdb:       rdb,
+		stateTTL:  ttl,
+		logger:    logger,
+	}
+	for _, pc := range cfg.Providers {
+		p := NewProvider(pc, nil)
+		if pc.TrustEmail && !p.TrustsEmail() {
+			logger.Warn("sso: TRUST_EMAIL ignored for a multi-tenant issuer without ALLOWED_TENANTS", zap.String("provider", pc.ID))
+		}
+		h.providers[pc.ID] = p
+		h.order = append(h.order, pc.ID)
+	}
+	return h
+}
+
+// ListProviders handles GET /auth/sso/providers. Returns configured providers for login buttons.
+func (h *Handler) ListProviders(c *gin.Context) {
+	list := make([]gin.H, 0, len(h.order))
+	for _, id := range h.order {
+		list = append(list, gin.H{"id": id, "name": h.providers[id].Name()})
+	}
+	response.OK(c, list)
+}
+
+// Authorize handles GET /auth/sso/:provider/authorize?redirect=/path. Returns the IdP URL to send the browser to,
+// and sets the state cookie the callback requires (so both requests must be made with credentials).
+func (h *Handler) Authorize(c *gin.Context) {
+	h.start(c, "")
+}
+
+// Link handles GET /auth/sso/:provider/link?redirect=/path (JWT). Like Authorize, but the callback links the
+// IdP account to the signed-in user instead of signing in; this is how existing accounts start using SSO.
+func (h *Handler) Link(c *gin.Context) {
+	userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
+	h.start(c, userID.String())
+}
+
+// start stores a pending authorization request and returns the IdP URL for it.
+func (h *Handler) start(c *gin.Context, linkUserID string) {
+	p, ok := h.providers[c.Param("provider")]
+	if !ok {
+		response.NotFound(c, "unknown sso provider")
+		return
+	}
+	state, err1 := randomString()
+	nonce, err2 := randomString()
+	verifier, err3 := randomString()
+	if err1 != nil || err2 != nil || err3 != nil {
+		response.Internal(c, "failed to start sign-in")
+		return
+	}
+	ar := authRequest{Provider: p.ID(), Nonce: nonce, CodeVerifier: verifier, Redirect: safeRedirect(c.Query("redirect")), LinkUserID: linkUserID}
+	raw, _ := json.Marshal(ar)
+	if err := h.rdb.Set(c.Request.Context(), stateKeyPrefix+state, raw, h.stateTTL).Err(); err != nil {
+		h.logger.Error("store sso state failed", zap.Error(err))
+		response.Internal(c, "failed to start sign-in")
+		return
+	}
+	authURL, err := p.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		h.logger.Error("build sso authorization url failed", zap.String("provider", p.ID()), zap.Error(err))
+		response.ServiceUnavailable(c, "identity provider unavailable")
+		return
+	}
+	setStateCookie(c, state, int(h.stateTTL/time.Second))
+	response.OK(c, gin.H{"authorization_url": authURL, "state": state})
+}
+
+// Callback handles POST /auth/sso/:provider/callback. Exchanges the code, verifies the ID token, and either
+// links the IdP account (requests from /link) or signs in its user, provisioning new ones, and returns the platform JWT.
+func (h *Handler) Callback(c *gin.Context) {
+	p, ok := h.providers[c.Param("provider")]
+	if !ok {
+		response.NotFound(c, "unknown sso provider")
+		return
+	}
+	var req CallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "code and state required")
+		return
+	}
+	ctx := c.Request.Context()
+
+	bound, _ := c.Cookie(stateCookie)
+	if subtle.ConstantTimeCompare([]byte(bound), []byte(req.State)) != 1 {
+		response.BadRequest(c, "invalid or expired sign-in state")
+		return
+	}
+	setStateCookie(c, "", -1)
+	raw, err := h.rdb.GetDel(ctx, stateKeyPrefix+req.State).Bytes()
+	if err != nil {
+		response.BadRequest(c, "invalid or expired sign-in state")
+		return
+	}
+	var ar authRequest
+	if err := json.Unmarshal(raw, &ar); err != nil || ar.Provider != p.ID() {
+		response.BadRequest(c, "invalid or expired sign-in state")
+		return
+	}
+
+	idToken, err := p.Exchange(ctx, req.Code, ar.CodeVerifier)
+	if err != nil {
+		h.logger.Warn("sso code exchange failed", zap.String("provider", p.ID()), zap.Error(err))
+		response.Unauthorized(c, "sign-in failed")
+		return
+	}
+	claims, err := p.VerifyIDToken(ctx, idToken, ar.Nonce)
+	if err != nil {
+		h.logger.Warn("sso id token rejected", zap.String("provider", p.ID()), zap.Error(err))
+		response.Unauthorized(c, "sign-in failed")
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	emailVerified := claims.EmailVerified()
+	if p.TrustsEmail() {
+		if email == "" && strings.Contains(claims.PreferredUsername, "@") {
+			email = strings.ToLower(claims.PreferredUsername)
+		}
+		emailVerified = email != ""
+	}
+
+	if ar.LinkUserID != "" {
+		h.link(c, p, ar, claims.Subject, email)
+		return
+	}
+
+	user, err := h.resolveUser(ctx, p.ID(), claims.Subject, email, emailVerified, claims.Name)
+	switch {
+	case errors.Is(err, errEmailNotVerified):
+		response.Forbidden(c, "identity provider did not return a verified email address")
+		return
+	case errors.Is(err, errAccountExists):
+		response.Conflict(c, "an account with this email already exists; sign in with your password and link "+p.Name()+" from your account")
+		return
+	case err != nil:
+		h.logger.Error("sso provisioning failed", zap.String("provider", p.ID()), zap.String("email", email), zap.Error(err))
+		response.Internal(c, "failed to sign in")
+		return
+	}
+
+	token, err := h.jwt.Generate(user.ID, user.Email, string(user.Role))
+	if err != nil {
+		response.Internal(c, "failed to generate token")
+		return
+	}
+	h.logger.Info("sso login", zap.String("provider", p.ID()), zap.String("user_id", user.ID.String()))
+	response.OK(c, gin.H{"token": token, "user": user.ToPublic(), "redirect": ar.Redirect})
+}
+
+// setStateCookie sets (or, with maxAge -1, clears) the HttpOnly state cookie.
+func setStateCookie(c *gin.Context, state string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookie, state, maxAge, "/", "", secure, true)
+}
+
+// link links the IdP account to the user who started the request with /link.
+func (h *Handler) link(c *gin.Context, p *Provider, ar authRequest, subject, email string) {
+	ctx := c.Request.Context()
+	userID, err := uuid.Parse(ar.LinkUserID)
+	if err != nil {
+		response.BadRequest(c, "invalid or expired sign-in state")
+		return
+	}
+	ident, err := h.repo.GetByProviderSubject(ctx, p.ID(), subject)
+	if err != nil {
+		h.logger.Error("sso link failed", zap.String("provider", p.ID()), zap.Error(err))
+		response.Internal(c, "failed to link account")
+		return
+	}
+	if ident != nil && ident.UserID != userID {
+		response.Conflict(c, errLinkedElsewhere.Error())
+		return
+	}
+	if err := h.repo.Link(ctx, userID, p.ID(), subject, email); err != nil {
+		h.logger.Error("sso link failed", zap.String("provider", p.ID()), zap.Error(err))
+		response.Internal(c, "failed to link account")
+		return
+	}
+	h.logger.Info("sso identity linked", zap.String("provider", p.ID()), zap.String("user_id", userID.String()))
+	response.OK(c, gin.H{"linked": true, "provider": p.ID(), "redirect": ar.Redirect})
+}
+
+// resolveUser finds the user linked to the IdP account or provisions a new user. An unlinked account whose
+// email belongs to an existing user is never linked here (errAccountExists): the user links it from /link.
+// A verified organization domain matching a verified email adds the user to that organization.
+func (h *Handler) resolveUser(ctx context.Context, provider, subject, email string, emailVerified bool, name string) (*models.User, error) {
+	var user *models.User
+	ident, err := h.repo.GetByProviderSubject(ctx, provider, subject)
+	if err != nil {
+		return nil, err
+	}
+	if ident != nil {
+		user, err = h.authRepo.GetByID(ctx, ident.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !emailVerified {
+		if user == nil {
+			return nil, errEmailNotVerified
+		}
+		return user, h.repo.Link(ctx, user.ID, provider, subject, email)
+	}
+
+	domain := email[strings.LastIndex(email, "@")+1:]
+	orgDomain, err := h.orgRepo.GetVerifiedDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		existing, err := h.authRepo.GetByEmail(ctx, email)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, errAccountExists
+		}
+		// Just-in-time provisioning. Staff of an organization with a verified domain get organizer access.
+		role := models.RoleAudience
+		if orgDomain != nil {
+			role = models.RoleAdmin
+		}
+		if name == "" {
+			name = email
+		}
+		randomPass, err := utils.HashPassword(uuid.New().String() + email)
+		if err != nil {
+			return nil, err
+		}
+		user, err = h.authRepo.Create(ctx, email, randomPass, name, role, nil, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := h.repo.Link(ctx, user.ID, provider, subject, email); err != nil {
+		return nil, err
+	}
+	if orgDomain != nil {
+		if err := h.orgRepo.EnsureMember(ctx, orgDomain.OrganizationID, user.ID, orgDomain.DefaultRole); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// safeRedirect only allows same-origin relative paths for the post-login redirect.
+func safeRedirect(r string) string {
+	if !strings.HasPrefix(r, "/") || strings.HasPrefix(r, "//") || strings.HasPrefix(r, "/\\") {
+		return ""
+	}
+	return r
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,398 @@
+package sso
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/aura-webinar/backend/config"
+	"github.com/aura-webinar/backend/internal/auth"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// fakeStore keeps users, linked identities, verified domains and memberships in memory.
+type fakeStore struct {
+	mu          sync.Mutex
+	users       map[uuid.UUID]*models.User
+	identities  map[string]uuid.UUID // provider + "|" + subject
+	domains     map[string]*models.OrganizationDomain
+	members     map[uuid.UUID]string // user -> role in the domain's organization
+	getEmailErr error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:      map[uuid.UUID]*models.User{},
+		identities: map[string]uuid.UUID{},
+		domains:    map[string]*models.OrganizationDomain{},
+		members:    map[uuid.UUID]string{},
+	}
+}
+
+func (s *fakeStore) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.identities[provider+"|"+subject]
+	if !ok {
+		return nil, nil
+	}
+	return &models.UserIdentity{UserID: id, Provider: provider, Subject: subject}, nil
+}
+
+func (s *fakeStore) Link(ctx context.Context, userID uuid.UUID, provider, subject, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.identities[provider+"|"+subject] = userID
+	return nil
+}
+
+func (s *fakeStore) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[id]; ok {
+		return u, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *fakeStore) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.getEmailErr != nil {
+		return nil, s.getEmailErr
+	}
+	for _, u := range s.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (s *fakeStore) Create(ctx context.Context, email, passwordHash, fullName string, role models.Role, profile *auth.CreateUserParams, emailVerified bool) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := &models.User{ID: uuid.New(), Email: email, FullName: fullName, Role: role, EmailVerified: emailVerified}
+	s.users[u.ID] = u
+	return u, nil
+}
+
+func (s *fakeStore) GetVerifiedDomain(ctx context.Context, domain string) (*models.OrganizationDomain, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.domains[domain], nil
+}
+
+func (s *fakeStore) EnsureMember(ctx context.Context, orgID, userID uuid.UUID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.members[userID]; !ok {
+		s.members[userID] = role
+	}
+	return nil
+}
+
+func (s *fakeStore) addUser(email string) *models.User {
+	u, _ := s.Create(context.Background(), email, "hash", "Existing", models.RoleAudience, nil, true)
+	return u
+}
+
+func (s *fakeStore) linkedTo(subject string) (uuid.UUID, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.identities["test|"+subject]
+	return id, ok
+}
+
+type ssoTest struct {
+	t       *testing.T
+	issuer  *testIssuer
+	store   *fakeStore
+	router  *gin.Engine
+	jwt     *auth.JWTService
+	userID  uuid.UUID         // signed-in user for /link
+	cookies map[string]string // the browser's cookies, as set by responses
+}
+
+func newSSOTest(t *testing.T, issuerPath string, mod func(*config.OIDCProviderConfig)) *ssoTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ti := newTestIssuer(t, issuerPath)
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	pc := config.OIDCProviderConfig{ID: "test", Name: "Test IdP", Issuer: ti.issuer, ClientID: testClientID, RedirectURL: "http://app.test/cb"}
+	if mod != nil {
+		mod(&pc)
+	}
+	st := &ssoTest{t: t, issuer: ti, store: newFakeStore(), jwt: auth.NewJWTService("test-secret", 1), cookies: map[string]string{}}
+	h := NewHandler(config.SSOConfig{Providers: []config.OIDCProviderConfig{pc}}, nil, nil, nil, st.jwt, rdb, nil)
+	h.repo, h.authRepo, h.orgRepo = st.store, st.store, st.store
+
+	st.router = gin.New()
+	st.router.GET("/auth/sso/:provider/authorize", h.Authorize)
+	st.router.POST("/auth/sso/:provider/callback", h.Callback)
+	st.router.GET("/auth/sso/:provider/link", func(c *gin.Context) {
+		c.Set(middleware.ContextUserID, st.userID)
+		h.Link(c)
+	})
+	return st
+}
+
+// start begins an authorization (or a link, from path) and returns its state and nonce; the test issuer
+// then expects the matching PKCE verifier.
+func (st *ssoTest) start(path string) (state, nonce string) {
+	st.t.Helper()
+	rec := st.do(http.MethodGet, path, nil)
+	if rec.Code != http.StatusOK {
+		st.t.Fatalf("%s: status %d: %s", path, rec.Code, rec.Body)
+	}
+	var body struct {
+		Data struct {
+			AuthorizationURL string `json:"authorization_url"`
+			State            string `json:"state"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	u, err := url.Parse(body.Data.AuthorizationURL)
+	if err != nil {
+		st.t.Fatal(err)
+	}
+	st.issuer.mu.Lock()
+	st.issuer.challenge = u.Query().Get("code_challenge")
+	st.issuer.mu.Unlock()
+	return body.Data.State, u.Query().Get("nonce")
+}
+
+// login runs authorize and callback, signing the ID token with claims changed by mod.
+func (st *ssoTest) login(mod func(jwt.MapClaims)) *httptest.ResponseRecorder {
+	st.t.Helper()
+	state, nonce := st.start("/auth/sso/test/authorize")
+	return st.callback(state, nonce, mod)
+}
+
+func (st *ssoTest) callback(state, nonce string, mod func(jwt.MapClaims)) *httptest.ResponseRecorder {
+	st.t.Helper()
+	claims := st.issuer.claims(nonce)
+	if mod != nil {
+		mod(claims)
+	}
+	idToken := st.issuer.sign("k1", claims)
+	st.issuer.mu.Lock()
+	st.issuer.idToken = idToken
+	st.issuer.mu.Unlock()
+	return st.do(http.MethodPost, "/auth/sso/test/callback", CallbackRequest{Code: "good-code", State: state})
+}
+
+func (st *ssoTest) do(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range st.cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	rec := httptest.NewRecorder()
+	st.router.ServeHTTP(rec, req)
+	for _, ck := range rec.Result().Cookies() {
+		if ck.MaxAge < 0 {
+			delete(st.cookies, ck.Name)
+		} else {
+			st.cookies[ck.Name] = ck.Value
+		}
+	}
+	return rec
+}
+
+// signedInUser returns the user ID of the platform JWT in a callback response.
+func (st *ssoTest) signedInUser(rec *httptest.ResponseRecorder) uuid.UUID {
+	st.t.Helper()
+	if rec.Code != http.StatusOK {
+		st.t.Fatalf("callback: status %d: %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	claims, err := st.jwt.Validate(body.Data.Token)
+	if err != nil {
+		st.t.Fatalf("platform token: %v", err)
+	}
+	return claims.UserID
+}
+
+func TestCallbackProvisionsNewUser(t *testing.T) {
+	st := newSSOTest(t, "", nil)
+	userID := st.signedInUser(st.login(nil))
+	u := st.store.users[userID]
+	if u == nil || u.Email != "ada@example.com" || u.Role != models.RoleAudience {
+		t.Fatalf("provisioned user = %+v", u)
+	}
+	if id, ok := st.store.linkedTo("subject-1"); !ok || id != userID {
+		t.Fatal("identity not linked to the new user")
+	}
+
+	// The next sign-in finds the user through the linked identity, even after an email change at the IdP.
+	again := st.signedInUser(st.login(func(c jwt.MapClaims) { c["email"] = "ada@new.example.com" }))
+	if again != userID {
+		t.Fatalf("second sign-in as %s, want %s", again, userID)
+	}
+}
+
+func TestCallbackJoinsVerifiedDomain(t *testing.T) {
+	st := newSSOTest(t, "", nil)
+	orgID := uuid.New()
+	now := time.Now()
+	st.store.domains["example.com"] = &models.OrganizationDomain{OrganizationID: orgID, Domain: "example.com", DefaultRole: models.OrgRoleModerator, VerifiedAt: &now}
+
+	userID := st.signedInUser(st.login(nil))
+	if st.store.users[userID].Role != models.RoleAdmin {
+		t.Fatal("domain staff not provisioned as organizers")
+	}
+	if st.store.members[userID] != models.OrgRoleModerator {
+		t.Fatalf("membership role = %q", st.store.members[userID])
+	}
+}
+
+func TestCallbackDoesNotLinkExistingAccountByEmail(t *testing.T) {
+	st := newSSOTest(t, "", nil)
+	st.store.addUser("ada@example.com")
+
+	rec := st.login(nil)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status %d, want 409: %s", rec.Code, rec.Body)
+	}
+	if _, ok := st.store.linkedTo("subject-1"); ok {
+		t.Fatal("IdP account linked to an existing account by email")
+	}
+}
+
+func TestCallbackLinkWhileSignedIn(t *testing.T) {
+	st := newSSOTest(t, "", nil)
+	existing := st.store.addUser("ada@example.com")
+	st.userID = existing.ID
+
+	state, nonce := st.start("/auth/sso/test/link")
+	if rec := st.callback(state, nonce, nil); rec.Code != http.StatusOK {
+		t.Fatalf("link: status %d: %s", rec.Code, rec.Body)
+	}
+	if id, _ := st.store.linkedTo("subject-1"); id != existing.ID {
+		t.Fatal("identity not linked to the signed-in user")
+	}
+	if got := st.signedInUser(st.login(nil)); got != existing.ID {
+		t.Fatalf("signed in as %s, want the linking user", got)
+	}
+
+	// Another user cannot take over the linked identity.
+	st.userID = st.store.addUser("mallory@example.com").ID
+	state, nonce = st.start("/auth/sso/test/link")
+	if rec := st.callback(state, nonce, nil); rec.Code != http.StatusConflict {
+		t.Fatalf("relink: status %d, want 409", rec.Code)
+	}
+	if id, _ := st.store.linkedTo("subject-1"); id != existing.ID {
+		t.Fatal("identity moved to another user")
+	}
+}
+
+func TestCallbackRequiresVerifiedEmail(t *testing.T) {
+	unverified := func(c jwt.MapClaims) { delete(c, "email_verified") }
+
+	t.Run("not verified", func(t *testing.T) {
+		st := newSSOTest(t, "", nil)
+		if rec := st.login(unverified); rec.Code != http.StatusForbidden {
+			t.Fatalf("status %d, want 403", rec.Code)
+		}
+	})
+
+	t.Run("trust email on a multi-tenant issuer", func(t *testing.T) {
+		st := newSSOTest(t, "/organizations/v2.0", func(c *config.OIDCProviderConfig) { c.TrustEmail = true })
+		if rec := st.login(unverified); rec.Code != http.StatusForbidden {
+			t.Fatalf("status %d, want 403", rec.Code)
+		}
+	})
+
+	t.Run("trust email for allowed tenants", func(t *testing.T) {
+		st := newSSOTest(t, "/organizations/v2.0", func(c *config.OIDCProviderConfig) {
+			c.TrustEmail = true
+			c.AllowedTenants = []string{"tenant-1"}
+		})
+		st.signedInUser(st.login(func(c jwt.MapClaims) {
+			unverified(c)
+			c["tid"] = "tenant-1"
+		}))
+	})
+}
+
+func TestCallbackRejectsBadState(t *testing.T) {
+	st := newSSOTest(t, "", nil)
+	state, nonce := st.start("/auth/sso/test/authorize")
+	st.signedInUser(st.callback(state, nonce, nil))
+
+	if rec := st.callback(state, nonce, nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("replayed state: status %d, want 400", rec.Code)
+	}
+	if rec := st.callback("unknown", nonce, nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown state: status %d, want 400", rec.Code)
+	}
+
+	state, _ = st.start("/auth/sso/test/authorize")
+	if rec := st.callback(state, "other-nonce", nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("nonce mismatch: status %d, want 401", rec.Code)
+	}
+}
+
+func TestCallbackRequiresStartingBrowser(t *testing.T) {
+	st := newSSOTest(t, "", nil)
+	state, nonce := st.start("/auth/sso/test/authorize")
+	if st.cookies[stateCookie] != state {
+		t.Fatalf("state cookie %q, want %q", st.cookies[stateCookie], state)
+	}
+
+	// A victim's browser, sent the attacker's IdP redirect, has no (or another) state cookie.
+	st.cookies = map[string]string{}
+	if rec := st.callback(state, nonce, nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("no state cookie: status %d, want 400", rec.Code)
+	}
+	st.cookies[stateCookie] = "other-state"
+	if rec := st.callback(state, nonce, nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("other state cookie: status %d, want 400", rec.Code)
+	}
+
+	// The rejected callbacks did not use up the state: the browser that started it still signs in.
+	st.cookies[stateCookie] = state
+	st.signedInUser(st.callback(state, nonce, nil))
+	if _, ok := st.cookies[stateCookie]; ok {
+		t.Fatal("state cookie not cleared after the callback")
+	}
+}
+
+func TestCallbackUserLookupError(t *testing.T) {
+	st := newSSOTest(t, "", nil)
+	st.store.getEmailErr = errors.New("connection reset")
+	if rec := st.login(nil); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", rec.Code)
+	}
+	if len(st.store.users) != 0 {
+		t.Fatal("user provisioned after a failed lookup")
+	}
+}
@@ -0,0 +1,381 @@
+package sso
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/aura-webinar/backend/config"
+)
+
+// jwksRefreshInterval limits how often an unknown key ID triggers a JWKS refetch.
+const jwksRefreshInterval = time.Minute
+
+var (
+	// ErrInvalidIDToken is returned when the ID token fails signature or claim checks.
+	ErrInvalidIDToken = errors.New("invalid id token")
+	// ErrUnknownSigningKey is returned when the ID token kid is not in the provider JWKS.
+	ErrUnknownSigningKey = errors.New("unknown signing key")
+)
+
+// discoveryDocument is the subset of /.well-known/openid-configuration we use.
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// tokenResponse is the token endpoint response for the authorization code grant.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+	Error       string `json:"error"`
+	ErrorDesc   string `json:"error_description"`
+}
+
+// IDTokenClaims holds the OIDC claims used for login and provisioning.
+type IDTokenClaims struct {
+	Email             string      `json:"email"`
+	EmailVerifiedRaw  interface{} `json:"email_verified"` // bool, or "true"/"false" for some IdPs
+	Name              string      `json:"name"`
+	PreferredUsername string      `json:"preferred_username"`
+	Nonce             string      `json:"nonce"`
+	TenantID          string      `json:"tid"` // Microsoft Entra ID tenant; substituted into a {tenantid} issuer
+	jwt.RegisteredClaims
+}
+
+// EmailVerified reports whether the IdP asserted email_verified.
+func (c *IDTokenClaims) EmailVerified() bool {
+	switch v := c.EmailVerifiedRaw.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Provider is an OpenID Connect relying-party client for one identity provider.
+// Discovery and JWKS are fetched lazily and cached.
+type Provider struct {
+	cfg        config.OIDCProviderConfig
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *discoveryDocument
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+// NewProvider creates a provider client. httpClient may be nil (uses a 10s-timeout client).
+func NewProvider(cfg config.OIDCProviderConfig, httpClient *http.Client) *Provider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{cfg: cfg, httpClient: httpClient}
+}
+
+// ID returns the provider route key.
+func (p *Provider) ID() string { return p.cfg.ID }
+
+// Name returns the provider display name.
+func (p *Provider) Name() string { return p.cfg.Name }
+
+// AuthCodeURL returns the authorization endpoint URL for the code flow with PKCE (S256).
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse authorization_endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallengeS256(codeVerifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange redeems an authorization code (with its PKCE verifier) and returns the raw ID token.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+	var tok tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("decode token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || tok.Error != "" {
+		return "", fmt.Errorf("token endpoint: status %d: %s %s", resp.StatusCode, tok.Error, tok.ErrorDesc)
+	}
+	if tok.IDToken == "" {
+		return "", fmt.Errorf("token endpoint returned no id_token")
+	}
+	return tok.IDToken, nil
+}
+
+// VerifyIDToken checks the ID token signature against the provider JWKS and validates iss, aud, exp, iat and nonce.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var claims IDTokenClaims
+	_, err = jwt.ParseWithClaims(rawIDToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.signingKey(ctx, doc.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	expectedIssuer := strings.ReplaceAll(doc.Issuer, "{tenantid}", claims.TenantID)
+	if claims.Issuer != expectedIssuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrInvalidIDToken, claims.Issuer, expectedIssuer)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if len(p.cfg.AllowedTenants) > 0 && !containsFold(p.cfg.AllowedTenants, claims.TenantID) {
+		return nil, fmt.Errorf("%w: tenant %q not allowed", ErrInvalidIDToken, claims.TenantID)
+	}
+	return &claims, nil
+}
+
+// TrustsEmail reports whether the email claim counts as verified when the IdP omits email_verified. Only
+// providers limited to known tenants qualify: on a multi-tenant issuer anyone can create a tenant and
+// put any address in their account's email.
+func (p *Provider) TrustsEmail() bool {
+	return p.cfg.TrustEmail && (len(p.cfg.AllowedTenants) > 0 || !multiTenantIssuer(p.cfg.Issuer))
+}
+
+// multiTenantIssuer reports whether issuer accepts accounts from any Microsoft Entra ID tenant.
+func multiTenantIssuer(issuer string) bool {
+	if strings.Contains(issuer, "{tenantid}") {
+		return true
+	}
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return true
+	}
+	tenant, _, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	switch strings.ToLower(tenant) {
+	case "common", "organizations", "consumers":
+		return true
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// discover fetches and caches the provider discovery document.
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	doc := p.discovery
+	p.mu.Unlock()
+	if doc != nil {
+		return doc, nil
+	}
+	var fetched discoveryDocument
+	if err := p.getJSON(ctx, p.cfg.Issuer+"/.well-known/openid-configuration", &fetched); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if fetched.AuthorizationEndpoint == "" || fetched.TokenEndpoint == "" || fetched.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc discovery: incomplete document from %s", p.cfg.Issuer)
+	}
+	// The document must describe the configured issuer (Microsoft multi-tenant uses a {tenantid} template).
+	if fetched.Issuer != p.cfg.Issuer && !strings.Contains(fetched.Issuer, "{tenantid}") {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match configured %q", fetched.Issuer, p.cfg.Issuer)
+	}
+	p.mu.Lock()
+	p.discovery = &fetched
+	p.mu.Unlock()
+	return &fetched, nil
+}
+
+// signingKey returns the JWKS public key for kid, refetching the key set once if kid is unknown.
+func (p *Provider) signingKey(ctx context.Context, jwksURI, kid string) (interface{}, error) {
+	p.mu.Lock()
+	key, ok := p.lookupKey(kid)
+	fresh := p.keys != nil && time.Since(p.keysFetchedAt) <= jwksRefreshInterval
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if fresh {
+		return nil, ErrUnknownSigningKey
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// lookupKey finds kid in the cached key set; a token without kid matches a single-key set. Caller holds p.mu.
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
+
+func (p *Provider) getJSON(ctx context.Context, rawURL string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", rawURL, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jsonWebKey is one key of a JWKS (RFC 7517); only RSA and EC signing keys are supported.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// codeChallengeS256 derives the PKCE code_challenge from a verifier (RFC 7636).
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
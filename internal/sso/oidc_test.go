@@ -0,0 +1,384 @@
+package sso
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/aura-webinar/backend/config"
+)
+
+const testClientID = "aura-client"
+
+// testIssuer is an OpenID provider on an httptest server: discovery under its issuer path, a rotatable
+// JWKS, and a token endpoint that checks the PKCE verifier and returns a prepared ID token.
+type testIssuer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu            sync.Mutex
+	issuer        string // configured issuer (server URL + path)
+	advertised    string // issuer in the discovery document
+	keys          map[string]*ecdsa.PrivateKey
+	jwksFetches   int
+	discoveries   int
+	challenge     string // code_challenge the token endpoint expects the verifier for
+	idToken       string
+	tokenRequests int
+}
+
+func newTestIssuer(t *testing.T, path string) *testIssuer {
+	t.Helper()
+	ti := &testIssuer{t: t, keys: map[string]*ecdsa.PrivateKey{}}
+	mux := http.NewServeMux()
+	ti.Server = httptest.NewServer(mux)
+	t.Cleanup(ti.Close)
+	ti.issuer = ti.URL + path
+	ti.advertised = ti.issuer
+	ti.addKey("k1")
+
+	mux.HandleFunc(path+"/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		ti.mu.Lock()
+		defer ti.mu.Unlock()
+		ti.discoveries++
+		json.NewEncoder(w).Encode(discoveryDocument{
+			Issuer:                ti.advertised,
+			AuthorizationEndpoint: ti.URL + "/authorize",
+			TokenEndpoint:         ti.URL + "/token",
+			JWKSURI:               ti.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		ti.mu.Lock()
+		defer ti.mu.Unlock()
+		ti.jwksFetches++
+		set := struct {
+			Keys []jsonWebKey `json:"keys"`
+		}{}
+		for kid, k := range ti.keys {
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "EC", Kid: kid, Use: "sig", Crv: "P-256",
+				X: base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, 32))),
+				Y: base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, 32))),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		ti.mu.Lock()
+		defer ti.mu.Unlock()
+		ti.tokenRequests++
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("client_id") != testClientID {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_request"})
+			return
+		}
+		if r.PostForm.Get("code") != "good-code" || codeChallengeS256(r.PostForm.Get("code_verifier")) != ti.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(tokenResponse{AccessToken: "at", TokenType: "Bearer", IDToken: ti.idToken})
+	})
+	return ti
+}
+
+func (ti *testIssuer) addKey(kid string) {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		ti.t.Fatal(err)
+	}
+	ti.mu.Lock()
+	ti.keys[kid] = k
+	ti.mu.Unlock()
+}
+
+func (ti *testIssuer) removeKey(kid string) {
+	ti.mu.Lock()
+	delete(ti.keys, kid)
+	ti.mu.Unlock()
+}
+
+func (ti *testIssuer) fetches() (discoveries, jwks int) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	return ti.discoveries, ti.jwksFetches
+}
+
+// claims returns valid ID token claims for nonce; tests change them before signing.
+func (ti *testIssuer) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            ti.issuer,
+		"aud":            testClientID,
+		"sub":            "subject-1",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          nonce,
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"name":           "Ada Lovelace",
+	}
+}
+
+// sign signs claims with the key kid; a kid without a key signs with a fresh, unpublished key.
+func (ti *testIssuer) sign(kid string, claims jwt.MapClaims) string {
+	ti.t.Helper()
+	ti.mu.Lock()
+	k := ti.keys[kid]
+	ti.mu.Unlock()
+	if k == nil {
+		var err error
+		if k, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			ti.t.Fatal(err)
+		}
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(k)
+	if err != nil {
+		ti.t.Fatal(err)
+	}
+	return s
+}
+
+func (ti *testIssuer) provider(mod func(*config.OIDCProviderConfig)) *Provider {
+	cfg := config.OIDCProviderConfig{
+		ID:          "test",
+		Name:        "Test IdP",
+		Issuer:      ti.issuer,
+		ClientID:    testClientID,
+		RedirectURL: "http://app.test/auth/sso/callback",
+	}
+	if mod != nil {
+		mod(&cfg)
+	}
+	return NewProvider(cfg, ti.Client())
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	ti := newTestIssuer(t, "")
+	p := ti.provider(nil)
+	ctx := context.Background()
+
+	claims, err := p.VerifyIDToken(ctx, ti.sign("k1", ti.claims("n1")), "n1")
+	if err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if claims.Subject != "subject-1" || claims.Email != "ada@example.com" || !claims.EmailVerified() {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, ti.claims("n1"))
+	hs.Header["kid"] = "k1"
+	hsToken, _ := hs.SignedString([]byte("secret"))
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"wrong nonce", func() string { return ti.sign("k1", ti.claims("other")) }},
+		{"wrong audience", func() string { c := ti.claims("n1"); c["aud"] = "someone-else"; return ti.sign("k1", c) }},
+		{"wrong issuer", func() string { c := ti.claims("n1"); c["iss"] = "https://evil.test"; return ti.sign("k1", c) }},
+		{"expired", func() string {
+			c := ti.claims("n1")
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+			return ti.sign("k1", c)
+		}},
+		{"no expiry", func() string { c := ti.claims("n1"); delete(c, "exp"); return ti.sign("k1", c) }},
+		{"missing subject", func() string { c := ti.claims("n1"); delete(c, "sub"); return ti.sign("k1", c) }},
+		{"unpublished key", func() string { return ti.sign("k1-forged", ti.claims("n1")) }},
+		{"hmac algorithm", func() string { return hsToken }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.VerifyIDToken(ctx, tt.token(), "n1"); !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("err = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cached", func(t *testing.T) {
+		ti := newTestIssuer(t, "/realm")
+		p := ti.provider(nil)
+		for i := 0; i < 2; i++ {
+			if _, err := p.AuthCodeURL(ctx, "s", "n", "v"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if d, _ := ti.fetches(); d != 1 {
+			t.Fatalf("discovery fetched %d times, want 1", d)
+		}
+	})
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		ti := newTestIssuer(t, "")
+		ti.advertised = "https://other.test"
+		if _, err := ti.provider(nil).AuthCodeURL(ctx, "s", "n", "v"); err == nil {
+			t.Fatal("discovery document for another issuer accepted")
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		ti := newTestIssuer(t, "")
+		p := ti.provider(func(c *config.OIDCProviderConfig) { c.Issuer = ti.URL + "/missing" })
+		if _, err := p.AuthCodeURL(ctx, "s", "n", "v"); err == nil {
+			t.Fatal("missing discovery document accepted")
+		}
+	})
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	ti := newTestIssuer(t, "")
+	raw, err := ti.provider(nil).AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"scope":                 "openid email profile",
+		"code_challenge":        codeChallengeS256("verifier-1"),
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+	if q.Has("code_verifier") {
+		t.Error("authorization URL leaks the PKCE verifier")
+	}
+}
+
+func TestJWKSRotation(t *testing.T) {
+	ti := newTestIssuer(t, "")
+	p := ti.provider(nil)
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, ti.sign("k1", ti.claims("n")), "n"); err != nil {
+		t.Fatal(err)
+	}
+	ti.addKey("k2")
+	ti.removeKey("k1")
+
+	// An unknown kid right after a fetch does not refetch (a stream of forged kids cannot hammer the IdP).
+	if _, err := p.VerifyIDToken(ctx, ti.sign("k2", ti.claims("n")), "n"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("err = %v, want rejection until the refresh interval passes", err)
+	}
+	if _, n := ti.fetches(); n != 1 {
+		t.Fatalf("jwks fetched %d times, want 1", n)
+	}
+
+	p.mu.Lock()
+	p.keysFetchedAt = time.Now().Add(-2 * jwksRefreshInterval)
+	p.mu.Unlock()
+	if _, err := p.VerifyIDToken(ctx, ti.sign("k2", ti.claims("n")), "n"); err != nil {
+		t.Fatalf("rotated key rejected: %v", err)
+	}
+	if _, n := ti.fetches(); n != 2 {
+		t.Fatalf("jwks fetched %d times, want 2", n)
+	}
+	if _, err := p.VerifyIDToken(ctx, ti.sign("k1", ti.claims("n")), "n"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("token signed with a retired key: err = %v", err)
+	}
+}
+
+func TestTenantIssuer(t *testing.T) {
+	ctx := context.Background()
+	const tenant = "11111111-2222-3333-4444-555555555555"
+
+	newMultiTenant := func(t *testing.T) *testIssuer {
+		ti := newTestIssuer(t, "/organizations/v2.0")
+		ti.advertised = ti.URL + "/{tenantid}/v2.0"
+		return ti
+	}
+	tenantClaims := func(ti *testIssuer, tid, issTenant string) jwt.MapClaims {
+		c := ti.claims("n")
+		c["tid"] = tid
+		c["iss"] = ti.URL + "/" + issTenant + "/v2.0"
+		return c
+	}
+
+	t.Run("tenant substituted", func(t *testing.T) {
+		ti := newMultiTenant(t)
+		p := ti.provider(nil)
+		if _, err := p.VerifyIDToken(ctx, ti.sign("k1", tenantClaims(ti, tenant, tenant)), "n"); err != nil {
+			t.Fatalf("token from its own tenant rejected: %v", err)
+		}
+		if _, err := p.VerifyIDToken(ctx, ti.sign("k1", tenantClaims(ti, tenant, "other-tenant")), "n"); !errors.Is(err, ErrInvalidIDToken) {
+			t.Fatalf("issuer of another tenant: err = %v", err)
+		}
+	})
+
+	t.Run("allowed tenants", func(t *testing.T) {
+		ti := newMultiTenant(t)
+		p := ti.provider(func(c *config.OIDCProviderConfig) { c.AllowedTenants = []string{strings.ToUpper(tenant)} })
+		if _, err := p.VerifyIDToken(ctx, ti.sign("k1", tenantClaims(ti, tenant, tenant)), "n"); err != nil {
+			t.Fatalf("allowed tenant rejected: %v", err)
+		}
+		if _, err := p.VerifyIDToken(ctx, ti.sign("k1", tenantClaims(ti, "attacker", "attacker")), "n"); !errors.Is(err, ErrInvalidIDToken) {
+			t.Fatalf("other tenant: err = %v", err)
+		}
+	})
+}
+
+func TestTrustsEmail(t *testing.T) {
+	tests := []struct {
+		issuer  string
+		tenants []string
+		want    bool
+	}{
+		{"https://login.microsoftonline.com/organizations/v2.0", nil, false},
+		{"https://login.microsoftonline.com/common/v2.0", nil, false},
+		{"https://login.microsoftonline.com/{tenantid}/v2.0", nil, false},
+		{"https://login.microsoftonline.com/organizations/v2.0", []string{"t1"}, true},
+		{"https://login.microsoftonline.com/11111111-2222-3333-4444-555555555555/v2.0", nil, true},
+		{"https://acme.okta.com/oauth2/default", nil, true},
+	}
+	for _, tt := range tests {
+		p := NewProvider(config.OIDCProviderConfig{Issuer: tt.issuer, TrustEmail: true, AllowedTenants: tt.tenants}, nil)
+		if got := p.TrustsEmail(); got != tt.want {
+			t.Errorf("TrustsEmail(%s, %v) = %v, want %v", tt.issuer, tt.tenants, got, tt.want)
+		}
+	}
+	p := NewProvider(config.OIDCProviderConfig{Issuer: "https://acme.okta.com"}, nil)
+	if p.TrustsEmail() {
+		t.Error("TrustsEmail without TRUST_EMAIL")
+	}
+}
+
+func TestJSONWebKeyRSA(t *testing.T) {
+	k := jsonWebKey{Kty: "RSA", N: base64.RawURLEncoding.EncodeToString(big.NewInt(0xC5).Bytes()), E: "AQAB"}
+	if _, err := k.publicKey(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (jsonWebKey{Kty: "oct"}).publicKey(); err == nil {
+		t.Fatal("symmetric key accepted")
+	}
+}
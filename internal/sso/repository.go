@@ -0,0 +1,66 @@
+package sso
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository handles user_identities persistence.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates an SSO identity repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// GetByProviderSubject returns the linked identity for an IdP account, or nil if not linked.
+func (r *Repository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	const q = `SELECT id, user_id, provider, subject, email, last_login_at, created_at
+		FROM user_identities WHERE provider = $1 AND subject = $2`
+	var ui models.UserIdentity
+	err := r.pool.QueryRow(ctx, q, provider, subject).
+		Scan(&ui.ID, &ui.UserID, &ui.Provider, &ui.Subject, &ui.Email, &ui.LastLoginAt, &ui.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ui, nil
+}
+
+// Link records (or refreshes) the link between a user and an IdP account and bumps last_login_at.
+func (r *Repository) Link(ctx context.Context, userID uuid.UUID, provider, subject, email string) error {
+	const q = `INSERT INTO user_identities (id, user_id, provider, subject, email)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4)
+		ON CONFLICT (provider, subject) DO UPDATE SET email = EXCLUDED.email, last_login_at = NOW()`
+	_, err := r.pool.Exec(ctx, q, userID, provider, subject, email)
+	return err
+}
+
+// ListByUser returns the IdP accounts linked to a user.
+func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.UserIdentity, error) {
+	const q = `SELECT id, user_id, provider, subject, email, last_login_at, created_at
+		FROM user_identities WHERE user_id = $1 ORDER BY created_at`
+	rows, err := r.pool.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.UserIdentity
+	for rows.Next() {
+		var ui models.UserIdentity
+		if err := rows.Scan(&ui.ID, &ui.UserID, &ui.Provider, &ui.Subject, &ui.Email, &ui.LastLoginAt, &ui.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, ui)
+	}
+	return list, rows.Err()
+}
@@ -33,6 +33,41 @@ func (r *Repository) Create(ctx context.Context, webinarID uuid.UUID) (*models.S
 	return &s, nil
 }
 
+// GetByID returns a stream session by ID (nil if not found).
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*models.StreamSession, error) {
+	const q = `SELECT id, webinar_id, started_at, ended_at, peak_viewers, total_viewers, total_watch_time, poll_participation_count, questions_count, created_at, updated_at
+		FROM stream_sessions WHERE id = $1`
+	var s models.StreamSession
+	err := r.pool.QueryRow(ctx, q, id).Scan(&s.ID, &s.WebinarID, &s.StartedAt, &s.EndedAt, &s.PeakViewers, &s.TotalViewers, &s.TotalWatchTime, &s.PollParticipationCount, &s.QuestionsCount, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListByWebinar returns a webinar's stream sessions, newest first.
+func (r *Repository) ListByWebinar(ctx context.Context, webinarID uuid.UUID) ([]models.StreamSession, error) {
+	const q = `SELECT id, webinar_id, started_at, ended_at, peak_viewers, total_viewers, total_watch_time, poll_participation_count, questions_count, created_at, updated_at
+		FROM stream_sessions WHERE webinar_id = $1 ORDER BY started_at DESC`
+	rows, err := r.pool.Query(ctx, q, webinarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.StreamSession
+	for rows.Next() {
+		var s models.StreamSession
+		if err := rows.Scan(&s.ID, &s.WebinarID, &s.StartedAt, &s.EndedAt, &s.PeakViewers, &s.TotalViewers, &s.TotalWatchTime, &s.PollParticipationCount, &s.QuestionsCount, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
 // GetActiveByWebinar returns the active (no ended_at) stream session for a webinar.
 func (r *Repository) GetActiveByWebinar(ctx context.Context, webinarID uuid.UUID) (*models.StreamSession, error) {
 	const q = `SELECT id, webinar_id, started_at, ended_at, peak_viewers, total_viewers, total_watch_time, poll_participation_count, questions_count, created_at, updated_at
@@ -71,6 +106,30 @@ func (r *Repository) End(ctx context.Context, sessionID uuid.UUID) error {
 	return err
 }
 
+// EndActive ends the webinar's active stream session and returns it (nil if none was active).
+func (r *Repository) EndActive(ctx context.Context, webinarID uuid.UUID) (*models.StreamSession, error) {
+	const q = `UPDATE stream_sessions SET ended_at = NOW(), updated_at = NOW()
+		WHERE webinar_id = $1 AND ended_at IS NULL
+		RETURNING id, webinar_id, started_at, ended_at, peak_viewers, total_viewers, total_watch_time, poll_participation_count, questions_count, created_at, updated_at`
+	var s models.StreamSession
+	err := r.pool.QueryRow(ctx, q, webinarID).Scan(&s.ID, &s.WebinarID, &s.StartedAt, &s.EndedAt, &s.PeakViewers, &s.TotalViewers, &s.TotalWatchTime, &s.PollParticipationCount, &s.QuestionsCount, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SetTotals overwrites a session's computed totals (written by the analytics job from session logs).
+func (r *Repository) SetTotals(ctx context.Context, sessionID uuid.UUID, totalViewers int, totalWatchTime int64, pollParticipation, questions int) error {
+	const q = `UPDATE stream_sessions SET total_viewers = $1, total_watch_time = $2, poll_participation_count = $3, questions_count = $4, updated_at = NOW()
+		WHERE id = $5`
+	_, err := r.pool.Exec(ctx, q, totalViewers, totalWatchTime, pollParticipation, questions, sessionID)
+	return err
+}
+
 // IncrementPollParticipation increments poll_participation_count.
 func (r *Repository) IncrementPollParticipation(ctx context.Context, sessionID uuid.UUID) error {
 	const q = `UPDATE stream_sessions SET poll_participation_count = poll_participation_count + 1, updated_at = NOW() WHERE id = $1`
@@ -0,0 +1,155 @@
+package suppressions
+
+import (
+	"context"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/emaillogs"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// OrgLookup loads organizations and members' roles (organizations.Repository).
+type OrgLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	GetUserRole(ctx context.Context, orgID, userID uuid.UUID) (string, error)
+}
+
+// Handler serves provider delivery webhooks, unsubscribe links and the organization suppression list.
+type Handler struct {
+	repo        *Repository
+	emailLogs   *emaillogs.Repository
+	orgRepo     OrgLookup
+	signer      *Signer
+	sendGridKey string
+	sesTopics   []string
+	sns         *email.SNSVerifier
+	logger      *zap.Logger
+}
+
+// NewHandler creates a suppressions handler.
+func NewHandler(repo *Repository, emailLogs *emaillogs.Repository, orgRepo OrgLookup, signer *Signer, logger *zap.Logger) *Handler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Handler{repo: repo, emailLogs: emailLogs, orgRepo: orgRepo, signer: signer, sns: email.NewSNSVerifier(), logger: logger}
+}
+
+// SetSendGridKey sets the signed event webhook verification key. Without it SendGrid deliveries are rejected.
+func (h *Handler) SetSendGridKey(publicKey string) {
+	h.sendGridKey = publicKey
+}
+
+// SetSESTopics sets the SNS topic ARNs accepted for SES notifications. Without any SES deliveries are rejected.
+func (h *Handler) SetSESTopics(arns []string) {
+	h.sesTopics = arns
+}
+
+// CreateRequest is the body for POST /organizations/:id/email-suppressions.
+type CreateRequest struct {
+	Email  string `json:"email" binding:"required"`
+	Detail string `json:"detail"`
+}
+
+// List handles GET /organizations/:id/email-suppressions (owner or event manager).
+// Query: search (address prefix), limit (max 200), offset.
+func (h *Handler) List(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	limit, offset := defaultPageSize, 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			response.BadRequest(c, "limit must be 1–200")
+			return
+		}
+		limit = n
+	}
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(c, "invalid offset")
+			return
+		}
+		offset = n
+	}
+	list, err := h.repo.ListByOrganization(c.Request.Context(), orgID, c.Query("search"), limit, offset)
+	if err != nil {
+		response.Internal(c, "failed to load suppressions")
+		return
+	}
+	if list == nil {
+		list = []*models.EmailSuppression{}
+	}
+	response.OK(c, list)
+}
+
+// Create handles POST /organizations/:id/email-suppressions: stops the organization's emails to an address.
+func (h *Handler) Create(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	var body CreateRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, "email required")
+		return
+	}
+	addr, err := mail.ParseAddress(strings.TrimSpace(body.Email))
+	if err != nil || addr.Name != "" {
+		response.BadRequest(c, "invalid email")
+		return
+	}
+	s, err := h.repo.Add(c.Request.Context(), &orgID, addr.Address, models.SuppressionReasonManual, strings.TrimSpace(body.Detail))
+	if err != nil {
+		response.Internal(c, "failed to add suppression")
+		return
+	}
+	if s == nil {
+		response.Conflict(c, "address is already suppressed")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "email_suppression.create", TargetType: "email_suppression", TargetID: s.ID.String(), OrganizationID: &orgID, After: s})
+	response.Created(c, s)
+}
+
+// Delete handles DELETE /organizations/:id/email-suppressions/:suppressionId: the organization's emails
+// reach the address again (global bounce entries are not affected).
+func (h *Handler) Delete(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("suppressionId"))
+	if err != nil {
+		response.BadRequest(c, "invalid suppression id")
+		return
+	}
+	s, err := h.repo.Delete(c.Request.Context(), orgID, id)
+	if err != nil {
+		response.Internal(c, "failed to delete suppression")
+		return
+	}
+	if s == nil {
+		response.NotFound(c, "Suppression not found")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "email_suppression.delete", TargetType: "email_suppression", TargetID: s.ID.String(), OrganizationID: &orgID, Before: s})
+	response.NoContent(c)
+}
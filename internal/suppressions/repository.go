@@ -0,0 +1,98 @@
+package suppressions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// Repository handles email_suppressions persistence. Addresses are compared case-insensitively.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a suppressions repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+const columns = `id, organization_id, email, reason, detail, created_at`
+
+func scanSuppression(row pgx.Row) (*models.EmailSuppression, error) {
+	var s models.EmailSuppression
+	if err := row.Scan(&s.ID, &s.OrganizationID, &s.Email, &s.Reason, &s.Detail, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Add suppresses email for the organization (nil: every organization). Returns nil, nil if the address
+// was already suppressed in that scope; the first reason is kept.
+func (r *Repository) Add(ctx context.Context, orgID *uuid.UUID, email, reason, detail string) (*models.EmailSuppression, error) {
+	q := `INSERT INTO email_suppressions (organization_id, email, reason, detail)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+		RETURNING ` + columns
+	s, err := scanSuppression(r.pool.QueryRow(ctx, q, orgID, strings.ToLower(strings.TrimSpace(email)), reason, detail))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+// Find returns the suppression that blocks email for the organization (nil for account emails, which only
+// global entries block), or nil, nil. Unsubscribes are ignored unless includeUnsubscribes is set, so
+// transactional mail still reaches people who opted out of webinar mail.
+func (r *Repository) Find(ctx context.Context, orgID *uuid.UUID, email string, includeUnsubscribes bool) (*models.EmailSuppression, error) {
+	q := `SELECT ` + columns + ` FROM email_suppressions
+		WHERE lower(email) = lower($1)
+			AND (organization_id IS NULL OR organization_id = $2)
+			AND ($3 OR reason <> 'unsubscribe')
+		ORDER BY organization_id NULLS FIRST
+		LIMIT 1`
+	s, err := scanSuppression(r.pool.QueryRow(ctx, q, strings.TrimSpace(email), orgID, includeUnsubscribes))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+// ListByOrganization returns the organization's suppressions, newest first. search filters by address prefix.
+func (r *Repository) ListByOrganization(ctx context.Context, orgID uuid.UUID, search string, limit, offset int) ([]*models.EmailSuppression, error) {
+	q := `SELECT ` + columns + ` FROM email_suppressions
+		WHERE organization_id = $1 AND ($2 = '' OR lower(email) LIKE lower($2) || '%')
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4`
+	rows, err := r.pool.Query(ctx, q, orgID, likeEscaper.Replace(strings.TrimSpace(search)), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*models.EmailSuppression
+	for rows.Next() {
+		s, err := scanSuppression(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// Delete removes one of the organization's suppressions (re-enabling mail to the address). Returns nil, nil
+// if there is none with that id.
+func (r *Repository) Delete(ctx context.Context, orgID, id uuid.UUID) (*models.EmailSuppression, error) {
+	s, err := scanSuppression(r.pool.QueryRow(ctx, `DELETE FROM email_suppressions WHERE id = $1 AND organization_id = $2
+		RETURNING `+columns, id, orgID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
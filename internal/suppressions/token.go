@@ -0,0 +1,78 @@
+package suppressions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenTTL is how long an unsubscribe link works after the email was sent.
+const TokenTTL = 90 * 24 * time.Hour
+
+// ErrInvalidToken means an unsubscribe token is malformed, expired or its signature does not match.
+var ErrInvalidToken = errors.New("invalid unsubscribe token")
+
+// Signer issues and checks unsubscribe tokens: "<base64url(org|issued|email)>.<base64url(HMAC-SHA256)>",
+// where issued is the Unix time the token was made. Tokens expire after TokenTTL.
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewSigner creates a token signer. It returns nil without a secret: emails then carry no unsubscribe link,
+// and every token is invalid.
+func NewSigner(secret string) *Signer {
+	if secret == "" {
+		return nil
+	}
+	return &Signer{secret: []byte(secret), now: time.Now}
+}
+
+// Token returns the unsubscribe token for email in the organization's scope.
+func (s *Signer) Token(orgID uuid.UUID, email string) string {
+	payload := []byte(orgID.String() + "|" + strconv.FormatInt(s.now().Unix(), 10) + "|" + strings.ToLower(strings.TrimSpace(email)))
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+// Parse verifies token and returns the organization and address it was issued for.
+func (s *Signer) Parse(token string) (uuid.UUID, string, error) {
+	if s == nil {
+		return uuid.Nil, "", ErrInvalidToken
+	}
+	p, m, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, "", ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(m)
+	if err != nil || !hmac.Equal(sig, s.mac(payload)) {
+		return uuid.Nil, "", ErrInvalidToken
+	}
+	org, rest, _ := strings.Cut(string(payload), "|")
+	issued, email, _ := strings.Cut(rest, "|")
+	orgID, err := uuid.Parse(org)
+	if err != nil || email == "" {
+		return uuid.Nil, "", ErrInvalidToken
+	}
+	unix, err := strconv.ParseInt(issued, 10, 64)
+	if err != nil || s.now().Sub(time.Unix(unix, 0)) > TokenTTL {
+		return uuid.Nil, "", ErrInvalidToken
+	}
+	return orgID, email, nil
+}
+
+func (s *Signer) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte("unsubscribe:"))
+	h.Write(payload)
+	return h.Sum(nil)
+}
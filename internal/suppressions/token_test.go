@@ -0,0 +1,52 @@
+package suppressions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSignerRoundTripAndExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewSigner("unsubscribe-secret")
+	s.now = func() time.Time { return now }
+	orgID := uuid.New()
+	token := s.Token(orgID, " Ada@Example.com ")
+
+	tests := []struct {
+		name    string
+		signer  *Signer
+		at      time.Time
+		token   string
+		wantErr bool
+	}{
+		{name: "fresh", signer: s, at: now, token: token},
+		{name: "just before expiry", signer: s, at: now.Add(TokenTTL), token: token},
+		{name: "expired", signer: s, at: now.Add(TokenTTL + time.Second), token: token, wantErr: true},
+		{name: "other secret", signer: NewSigner("other-secret"), at: now, token: token, wantErr: true},
+		{name: "tampered", signer: s, at: now, token: "x" + token, wantErr: true},
+		{name: "no secret", signer: NewSigner(""), at: now, token: token, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.signer != nil {
+				at := tt.at
+				tt.signer.now = func() time.Time { return at }
+			}
+			gotOrg, gotEmail, err := tt.signer.Parse(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if gotOrg != orgID || gotEmail != "ada@example.com" {
+				t.Fatalf("got %s %q, want %s ada@example.com", gotOrg, gotEmail, orgID)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package suppressions
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html><html><head><meta charset="UTF-8">
+<meta name="viewport" content="width=device-width,initial-scale=1"><meta name="robots" content="noindex"><title>Unsubscribe</title>
+<style>body{font-family:Arial,sans-serif;max-width:480px;margin:60px auto;padding:0 20px;color:#0f172a;}
+button{background:#0ea5e9;color:#fff;border:0;border-radius:6px;padding:12px 20px;font-size:1rem;cursor:pointer;}
+p{line-height:1.5;}</style></head><body>
+{{if .Error}}<h1>Link not valid</h1><p>This unsubscribe link is invalid or incomplete.</p>
+{{else if .Done}}<h1>You are unsubscribed</h1><p><strong>{{.Email}}</strong> will no longer receive webinar emails from {{.Organization}}.</p>
+{{else}}<h1>Unsubscribe</h1><p>Stop webinar emails from {{.Organization}} to <strong>{{.Email}}</strong>?</p>
+<form method="post"><input type="hidden" name="token" value="{{.Token}}"><button type="submit">Unsubscribe</button></form>
+{{end}}</body></html>`))
+
+type unsubscribeView struct {
+	Token, Email, Organization string
+	Done, Error                bool
+	orgExists                  bool
+}
+
+// UnsubscribePage handles GET /email/unsubscribe?token=: a confirmation page. It changes nothing, so link
+// scanners that prefetch URLs cannot unsubscribe anyone.
+func (h *Handler) UnsubscribePage(c *gin.Context) {
+	token := c.Query("token")
+	view, ok := h.unsubscribeView(c, token)
+	if !ok {
+		return
+	}
+	view.Token = token
+	renderUnsubscribe(c, http.StatusOK, view)
+}
+
+// Unsubscribe handles POST /email/unsubscribe?token=: the confirmation form and RFC 8058 one-click
+// requests (List-Unsubscribe-Post) from mail clients. Repeating it is harmless.
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+	view, ok := h.unsubscribeView(c, token)
+	if !ok {
+		return
+	}
+	orgID, addr, _ := h.signer.Parse(token)
+	source := "link"
+	if c.PostForm("List-Unsubscribe") == "One-Click" {
+		source = "one-click"
+	}
+	// A deleted organization sends nothing anymore
+	if !view.orgExists {
+		view.Done = true
+		renderUnsubscribe(c, http.StatusOK, view)
+		return
+	}
+	if _, err := h.repo.Add(c.Request.Context(), &orgID, addr, models.SuppressionReasonUnsubscribe, source); err != nil {
+		h.logger.Error("unsubscribe failed", zap.String("organization_id", orgID.String()), zap.Error(err))
+		c.String(http.StatusInternalServerError, "Something went wrong, please try again later.")
+		return
+	}
+	view.Done = true
+	renderUnsubscribe(c, http.StatusOK, view)
+}
+
+// unsubscribeView checks the token and loads the organization name. Writes the error page on failure.
+func (h *Handler) unsubscribeView(c *gin.Context, token string) (unsubscribeView, bool) {
+	orgID, addr, err := h.signer.Parse(token)
+	if err != nil {
+		renderUnsubscribe(c, http.StatusBadRequest, unsubscribeView{Error: true})
+		return unsubscribeView{}, false
+	}
+	view := unsubscribeView{Email: addr, Organization: "this organizer"}
+	org, err := h.orgRepo.GetByID(c.Request.Context(), orgID)
+	if err != nil && err != pgx.ErrNoRows {
+		h.logger.Error("unsubscribe: load organization failed", zap.String("organization_id", orgID.String()), zap.Error(err))
+		c.String(http.StatusInternalServerError, "Something went wrong, please try again later.")
+		return unsubscribeView{}, false
+	}
+	if org != nil {
+		view.Organization, view.orgExists = org.Name, true
+	}
+	return view, true
+}
+
+func renderUnsubscribe(c *gin.Context, status int, view unsubscribeView) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := unsubscribePage.Execute(c.Writer, view); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,163 @@
+package suppressions
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// maxEventBody bounds a provider event delivery (SendGrid batches up to a few thousand events).
+const maxEventBody = 4 << 20
+
+// SendGridEvents handles POST /webhooks/email/sendgrid (signed event webhook).
+func (h *Handler) SendGridEvents(c *gin.Context) {
+	if h.sendGridKey == "" {
+		h.logger.Error("sendgrid event webhook rejected: EMAIL_SENDGRID_WEBHOOK_KEY not set")
+		response.ServiceUnavailable(c, "webhook not configured")
+		return
+	}
+	raw, ok := readBody(c)
+	if !ok {
+		return
+	}
+	if err := email.VerifySendGridSignature(h.sendGridKey, c.GetHeader(email.SendGridSignatureHeader), c.GetHeader(email.SendGridTimestampHeader), raw); err != nil {
+		h.logger.Warn("sendgrid event webhook signature rejected", zap.String("client_ip", c.ClientIP()), zap.Error(err))
+		response.Unauthorized(c, "invalid signature")
+		return
+	}
+	events, err := email.ParseSendGridEvents(raw)
+	if err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	if err := h.apply(c.Request.Context(), "sendgrid", events); err != nil {
+		response.Internal(c, "failed to process events")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// SESEvents handles POST /webhooks/email/ses: SNS deliveries of SES bounce and complaint notifications.
+// Subscription confirmations for an accepted topic are confirmed automatically.
+func (h *Handler) SESEvents(c *gin.Context) {
+	if len(h.sesTopics) == 0 {
+		h.logger.Error("ses event webhook rejected: EMAIL_SES_TOPIC_ARNS not set")
+		response.ServiceUnavailable(c, "webhook not configured")
+		return
+	}
+	raw, ok := readBody(c)
+	if !ok {
+		return
+	}
+	var msg email.SNSMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	if !h.topicAllowed(msg.TopicArn) {
+		h.logger.Warn("ses event webhook topic not allowed", zap.String("topic_arn", msg.TopicArn))
+		response.Forbidden(c, "topic not allowed")
+		return
+	}
+	if err := h.sns.Verify(c.Request.Context(), &msg); err != nil {
+		h.logger.Warn("ses event webhook signature rejected", zap.String("client_ip", c.ClientIP()), zap.Error(err))
+		response.Unauthorized(c, "invalid signature")
+		return
+	}
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		if err := h.sns.ConfirmSubscription(c.Request.Context(), &msg); err != nil {
+			h.logger.Error("sns subscription confirmation failed", zap.String("topic_arn", msg.TopicArn), zap.Error(err))
+			response.Internal(c, "failed to confirm subscription")
+			return
+		}
+		h.logger.Info("sns subscription confirmed", zap.String("topic_arn", msg.TopicArn))
+	case "Notification":
+		events, err := email.ParseSESNotification(msg.Message)
+		if err != nil {
+			response.BadRequest(c, "invalid notification: "+err.Error())
+			return
+		}
+		if err := h.apply(c.Request.Context(), "ses", events); err != nil {
+			response.Internal(c, "failed to process events")
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// apply records each event on its email log and suppresses the address: hard bounces for every
+// organization, complaints and unsubscribes for the organization that sent the message (every organization
+// when the message is unknown, e.g. account email). Transient bounces only get logged. Safe to repeat.
+func (h *Handler) apply(ctx context.Context, provider string, events []email.Event) error {
+	for _, e := range events {
+		if e.Email == "" {
+			continue
+		}
+		var status, reason string
+		switch {
+		case e.Type == email.EventBounce && e.Permanent:
+			status, reason = models.EmailLogStatusBounced, models.SuppressionReasonBounce
+		case e.Type == email.EventComplaint:
+			status, reason = models.EmailLogStatusComplained, models.SuppressionReasonComplaint
+		case e.Type == email.EventUnsubscribe:
+			reason = models.SuppressionReasonUnsubscribe
+		case e.Type == email.EventDropped:
+			status = models.EmailLogStatusFailed
+		default:
+			h.logger.Info("transient bounce", zap.String("provider", provider), zap.String("message_id", e.MessageID), zap.String("reason", e.Reason))
+			continue
+		}
+		var orgID *uuid.UUID
+		var found bool
+		if e.MessageID != "" {
+			var err error
+			orgID, found, err = h.emailLogs.MarkDelivery(ctx, e.MessageID, status, e.Reason)
+			if err != nil {
+				h.logger.Error("record email delivery event failed", zap.String("message_id", e.MessageID), zap.Error(err))
+				return err
+			}
+		}
+		if reason == "" {
+			continue
+		}
+		if reason == models.SuppressionReasonBounce {
+			orgID = nil
+		}
+		if _, err := h.repo.Add(ctx, orgID, e.Email, reason, provider+": "+e.Reason); err != nil {
+			h.logger.Error("add email suppression failed", zap.String("reason", reason), zap.Error(err))
+			return err
+		}
+		h.logger.Info("email address suppressed", zap.String("provider", provider), zap.String("reason", reason),
+			zap.String("message_id", e.MessageID), zap.Bool("log_found", found))
+	}
+	return nil
+}
+
+func (h *Handler) topicAllowed(arn string) bool {
+	for _, t := range h.sesTopics {
+		if t == arn {
+			return true
+		}
+	}
+	return false
+}
+
+// readBody reads a bounded raw body (signatures cover the exact bytes). Writes the error response on failure.
+func readBody(c *gin.Context) ([]byte, bool) {
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEventBody+1))
+	if err != nil || len(raw) > maxEventBody {
+		response.BadRequest(c, "invalid request body")
+		return nil, false
+	}
+	return raw, true
+}
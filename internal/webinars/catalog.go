@@ -0,0 +1,231 @@
+package webinars
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+const (
+	catalogPageSize    = 20
+	catalogMaxPageSize = 100
+)
+
+// CatalogFilter narrows the public catalog. Zero values are ignored.
+type CatalogFilter struct {
+	Query            string // full-text search over title and description (web search syntax)
+	Category         string
+	OrganizationSlug string
+	From             *time.Time // starts_at range
+	To               *time.Time
+	Paid             *bool
+	// Past lists ended and archived webinars, newest first; otherwise scheduled and live ones, soonest first.
+	Past bool
+	// Cursor continues after the last entry of the previous page.
+	Cursor *CatalogCursor
+	Limit  int
+}
+
+// CatalogCursor is the keyset position of a catalog entry (starts_at, id).
+type CatalogCursor struct {
+	StartsAt time.Time
+	ID       uuid.UUID
+}
+
+// CatalogOrganization is the host organization shown with a catalog entry.
+type CatalogOrganization struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// CatalogEntry is a public webinar as listed in the catalog.
+type CatalogEntry struct {
+	ID               uuid.UUID            `json:"id"`
+	Title            string               `json:"title"`
+	Description      string               `json:"description"`
+	StartsAt         time.Time            `json:"starts_at"`
+	EndsAt           *time.Time           `json:"ends_at,omitempty"`
+	Status           string               `json:"status"`
+	IsPaid           bool                 `json:"is_paid"`
+	TicketPriceCents int                  `json:"ticket_price_cents"`
+	TicketCurrency   string               `json:"ticket_currency"`
+	Category         string               `json:"category,omitempty"`
+	BannerImageURL   string               `json:"banner_image_url,omitempty"`
+	SeriesID         *uuid.UUID           `json:"series_id,omitempty"`
+	Organization     *CatalogOrganization `json:"organization,omitempty"`
+	MaxAudience      *int                 `json:"max_audience,omitempty"`
+	SeatsRemaining   *int                 `json:"seats_remaining"` // nil = unlimited
+}
+
+// Catalog returns public webinars matching f, in starts_at order (see CatalogFilter.Past).
+func (r *Repository) Catalog(ctx context.Context, f CatalogFilter) ([]CatalogEntry, error) {
+	statuses := []string{models.WebinarStatusScheduled, models.WebinarStatusLive}
+	order, after := "ASC", ">"
+	if f.Past {
+		statuses = []string{models.WebinarStatusEnded, models.WebinarStatusArchived}
+		order, after = "DESC", "<"
+	}
+	conds := []string{"w.visibility = 'public'", "w.status = ANY($1)"}
+	args := []interface{}{statuses}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Query != "" {
+		add("w.search_vector @@ websearch_to_tsquery('english', $%d)", f.Query)
+	}
+	if f.Category != "" {
+		add("LOWER(w.category) = LOWER($%d)", f.Category)
+	}
+	if f.OrganizationSlug != "" {
+		add("o.slug = $%d", f.OrganizationSlug)
+	}
+	if f.From != nil {
+		add("w.starts_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("w.starts_at < $%d", *f.To)
+	}
+	if f.Paid != nil {
+		add("w.is_paid = $%d", *f.Paid)
+	}
+	if f.Cursor != nil {
+		args = append(args, f.Cursor.StartsAt, f.Cursor.ID)
+		conds = append(conds, fmt.Sprintf("(w.starts_at, w.id) %s ($%d, $%d)", after, len(args)-1, len(args)))
+	}
+	args = append(args, f.Limit)
+	q := `SELECT w.id, w.title, w.description, w.starts_at, w.ends_at, w.status, w.is_paid, w.ticket_price_cents, w.ticket_currency,
+			w.category, w.banner_image_url, w.series_id, o.name, o.slug, w.max_audience,
+			CASE WHEN w.max_audience IS NULL THEN NULL
+				ELSE GREATEST(w.max_audience - (SELECT COUNT(*) FROM registrations r WHERE r.webinar_id = w.id), 0) END
+		FROM webinars w
+		LEFT JOIN organizations o ON o.id = w.organization_id
+		WHERE ` + strings.Join(conds, " AND ") + fmt.Sprintf(` ORDER BY w.starts_at %s, w.id %s LIMIT $%d`, order, order, len(args))
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []CatalogEntry
+	for rows.Next() {
+		var e CatalogEntry
+		var orgName, orgSlug *string
+		if err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.StartsAt, &e.EndsAt, &e.Status, &e.IsPaid, &e.TicketPriceCents, &e.TicketCurrency,
+			&e.Category, &e.BannerImageURL, &e.SeriesID, &orgName, &orgSlug, &e.MaxAudience, &e.SeatsRemaining); err != nil {
+			return nil, err
+		}
+		if orgName != nil && orgSlug != nil {
+			e.Organization = &CatalogOrganization{Name: *orgName, Slug: *orgSlug}
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// Catalog handles GET /webinars/catalog (no auth): public webinars only; unlisted and private ones never appear.
+// Query: q (full-text search), category, organization (slug), from, to (RFC3339, on starts_at), price (free|paid),
+// when (upcoming, the default, or past), limit (max 100), cursor (from next_cursor).
+func (h *Handler) Catalog(c *gin.Context) {
+	f := CatalogFilter{
+		Query:            strings.TrimSpace(c.Query("q")),
+		Category:         strings.TrimSpace(c.Query("category")),
+		OrganizationSlug: strings.TrimSpace(c.Query("organization")),
+		Limit:            catalogPageSize,
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		if v := c.Query(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				response.BadRequest(c, "invalid "+p.name+" (want RFC3339)")
+				return
+			}
+			*p.dst = &t
+		}
+	}
+	switch c.Query("price") {
+	case "":
+	case "free":
+		paid := false
+		f.Paid = &paid
+	case "paid":
+		paid := true
+		f.Paid = &paid
+	default:
+		response.BadRequest(c, "price must be free or paid")
+		return
+	}
+	switch c.Query("when") {
+	case "", "upcoming":
+	case "past":
+		f.Past = true
+	default:
+		response.BadRequest(c, "when must be upcoming or past")
+		return
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > catalogMaxPageSize {
+			response.BadRequest(c, "limit must be 1–100")
+			return
+		}
+		f.Limit = n
+	}
+	if v := c.Query("cursor"); v != "" {
+		cur, err := decodeCatalogCursor(v)
+		if err != nil {
+			response.BadRequest(c, "invalid cursor")
+			return
+		}
+		f.Cursor = cur
+	}
+
+	list, err := h.repo.Catalog(c.Request.Context(), f)
+	if err != nil {
+		h.logger.Error("list webinar catalog failed", zap.Error(err))
+		response.Internal(c, "failed to list webinars")
+		return
+	}
+	if list == nil {
+		list = []CatalogEntry{}
+	}
+	var next string
+	if len(list) == f.Limit {
+		last := list[len(list)-1]
+		next = encodeCatalogCursor(CatalogCursor{StartsAt: last.StartsAt, ID: last.ID})
+	}
+	response.OK(c, gin.H{"items": list, "next_cursor": next})
+}
+
+func encodeCatalogCursor(cur CatalogCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cur.StartsAt.UTC().Format(time.RFC3339Nano) + "|" + cur.ID.String()))
+}
+
+func decodeCatalogCursor(s string) (*CatalogCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	ts, id, _ := strings.Cut(string(raw), "|")
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, err
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	return &CatalogCursor{StartsAt: t, ID: uid}, nil
+}
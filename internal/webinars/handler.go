@@ -1,6 +1,7 @@
 package webinars
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -8,6 +9,8 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/forms"
 	"github.com/aura-webinar/backend/internal/middleware"
 	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/realtime"
@@ -28,6 +31,8 @@ type CreateRequest struct {
 	MaxAudience     *int     `json:"max_audience"`     // optional; nil = unlimited
 	Category        string   `json:"category"`
 	BannerImageURL  string   `json:"banner_image_url"`
+	Status          string   `json:"status"`           // optional; "draft" or "scheduled" (default, published)
+	Visibility      string   `json:"visibility"`       // optional; "public" (default), "unlisted" or "private"
 }
 
 // AddSpeakerRequest is the body for POST /webinars/:id/speakers.
@@ -45,10 +50,23 @@ type InviteSpeakerRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
 
+// ReminderPlanner plans a webinar's reminder emails from its start time (reminders.Planner).
+type ReminderPlanner interface {
+	Plan(ctx context.Context, webinarID uuid.UUID) error
+}
+
+// ScheduleNotifier tells a webinar's registrants that it moved to a new time (calendar.Notifier).
+type ScheduleNotifier interface {
+	Rescheduled(ctx context.Context, w *models.Webinar) (notified int, err error)
+}
+
 // Handler handles webinar HTTP endpoints.
 type Handler struct {
-	repo   *Repository
-	logger *zap.Logger
+	repo      *Repository
+	reminders ReminderPlanner
+	lifecycle LifecycleEffects
+	schedule  ScheduleNotifier
+	logger    *zap.Logger
 }
 
 // NewHandler creates a webinar handler.
@@ -59,6 +77,18 @@ func NewHandler(repo *Repository, logger *zap.Logger) *Handler {
 	return &Handler{repo: repo, logger: logger}
 }
 
+// SetReminderPlanner plans reminders when a webinar is created or its start time moves. Without it, the
+// worker's reminder sweep plans them on its next run.
+func (h *Handler) SetReminderPlanner(p ReminderPlanner) {
+	h.reminders = p
+}
+
+// SetScheduleNotifier emails registrants an updated calendar invite when a published webinar's start time
+// changes. Without it they are not told.
+func (h *Handler) SetScheduleNotifier(n ScheduleNotifier) {
+	h.schedule = n
+}
+
 // Create handles POST /webinars (admin only).
 func (h *Handler) Create(c *gin.Context) {
 	var req CreateRequest
@@ -83,6 +113,22 @@ func (h *Handler) Create(c *gin.Context) {
 		}
 		endsAt = &t
 	}
+	status := req.Status
+	if status == "" {
+		status = models.WebinarStatusScheduled
+	}
+	if status != models.WebinarStatusDraft && status != models.WebinarStatusScheduled {
+		response.BadRequest(c, "status must be draft or scheduled")
+		return
+	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.WebinarVisibilityPublic
+	}
+	if !validVisibility(visibility) {
+		response.BadRequest(c, "visibility must be public, unlisted or private")
+		return
+	}
 
 	w := &models.Webinar{
 		Title:          req.Title,
@@ -93,6 +139,11 @@ func (h *Handler) Create(c *gin.Context) {
 		MaxAudience:    req.MaxAudience,
 		Category:       req.Category,
 		BannerImageURL: req.BannerImageURL,
+		Status:         status,
+		Visibility:     visibility,
+	}
+	if orgID, ok := middleware.APIKeyOrganizationID(c); ok {
+		w.OrganizationID = &orgID
 	}
 	if err := h.repo.Create(c.Request.Context(), w); err != nil {
 		response.Internal(c, "failed to create webinar")
@@ -105,10 +156,15 @@ func (h *Handler) Create(c *gin.Context) {
 		}
 		_ = h.repo.AddSpeaker(c.Request.Context(), w.ID, speakerID)
 	}
+	if w.Status == models.WebinarStatusScheduled {
+		h.planReminders(c.Request.Context(), w.ID)
+	}
+	audit.Annotate(c, audit.Change{Action: "webinar.create", TargetType: "webinar", TargetID: w.ID.String(), OrganizationID: w.OrganizationID, After: w})
 	response.Created(c, w)
 }
 
-// GetByID handles GET /webinars/:id.
+// GetByID handles GET /webinars/:id. Private webinars are only shown to their creator and speakers (the
+// route takes an optional JWT).
 func (h *Handler) GetByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -121,6 +177,17 @@ func (h *Handler) GetByID(c *gin.Context) {
 		response.NotFound(c, "webinar not found")
 		return
 	}
+	if w.Visibility == models.WebinarVisibilityPrivate {
+		userID, ok := c.Get(middleware.ContextUserID)
+		if !ok {
+			response.NotFound(c, "webinar not found")
+			return
+		}
+		if allowed, _ := h.repo.IsAdminOrSpeaker(c.Request.Context(), id, userID.(uuid.UUID)); !allowed {
+			response.NotFound(c, "webinar not found")
+			return
+		}
+	}
 	response.OK(c, w)
 }
 
@@ -159,7 +226,18 @@ func (h *Handler) AddSpeaker(c *gin.Context) {
 // List handles GET /webinars.
 // Query ?mine=1: only webinars created by the current user (admin dashboard).
 // Query ?as_speaker=1: only webinars where the current user is a speaker (speaker dashboard).
+// API key requests always get their organization's webinars.
 func (h *Handler) List(c *gin.Context) {
+	if orgID, ok := middleware.APIKeyOrganizationID(c); ok {
+		list, err := h.repo.List(c.Request.Context(), nil, &orgID)
+		if err != nil {
+			h.logger.Error("list webinars by organization failed", zap.Error(err))
+			response.Internal(c, "failed to list webinars")
+			return
+		}
+		response.OK(c, list)
+		return
+	}
 	userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
 	if c.Query("as_speaker") == "1" {
 		list, err := h.repo.ListBySpeakerID(c.Request.Context(), userID)
@@ -184,9 +262,10 @@ func (h *Handler) List(c *gin.Context) {
 	response.OK(c, list)
 }
 
-// ListPublic handles GET /webinars/list (no auth). Returns all webinars for the audience "Join webinar" page.
+// ListPublic handles GET /webinars/list (no auth). Returns the published (scheduled or live) webinars for the
+// audience "Join webinar" page.
 func (h *Handler) ListPublic(c *gin.Context) {
-	list, err := h.repo.List(c.Request.Context(), nil, nil)
+	list, err := h.repo.ListPublished(c.Request.Context())
 	if err != nil {
 		h.logger.Error("list webinars (public) failed", zap.Error(err))
 		response.Internal(c, "failed to list webinars")
@@ -208,7 +287,7 @@ func (h *Handler) Update(c *gin.Context) {
 		response.NotFound(c, "webinar not found")
 		return
 	}
-	if w.CreatedBy != userID {
+	if !canEdit(c, w, userID) {
 		response.Forbidden(c, "only the creator can update this webinar")
 		return
 	}
@@ -220,6 +299,7 @@ func (h *Handler) Update(c *gin.Context) {
 		MaxAudience     *int    `json:"max_audience"`
 		Category        *string `json:"category"`
 		BannerImageURL  *string `json:"banner_image_url"`
+		Visibility      *string `json:"visibility"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "invalid request")
@@ -260,11 +340,24 @@ func (h *Handler) Update(c *gin.Context) {
 	if req.BannerImageURL != nil {
 		bannerURL = *req.BannerImageURL
 	}
-	if err := h.repo.Update(c.Request.Context(), id, title, desc, startsAt, endsAt, maxAudience, category, bannerURL); err != nil {
+	visibility := w.Visibility
+	if req.Visibility != nil {
+		if !validVisibility(*req.Visibility) {
+			response.BadRequest(c, "visibility must be public, unlisted or private")
+			return
+		}
+		visibility = *req.Visibility
+	}
+	if err := h.repo.Update(c.Request.Context(), id, title, desc, startsAt, endsAt, maxAudience, category, bannerURL, visibility); err != nil {
 		response.Internal(c, "failed to update webinar")
 		return
 	}
 	updated, _ := h.repo.GetByID(c.Request.Context(), id)
+	if startsAt != nil && !startsAt.Equal(w.StartsAt) {
+		h.planReminders(c.Request.Context(), id)
+		h.notifyRescheduled(c.Request.Context(), updated)
+	}
+	audit.Annotate(c, audit.Change{Action: "webinar.update", OrganizationID: w.OrganizationID, Before: w, After: updated})
 	response.OK(c, updated)
 }
 
@@ -281,7 +374,7 @@ func (h *Handler) UpdateRegistrationForm(c *gin.Context) {
 		response.NotFound(c, "webinar not found")
 		return
 	}
-	if w.CreatedBy != userID {
+	if !canEdit(c, w, userID) {
 		response.Forbidden(c, "only the creator can update the registration form")
 		return
 	}
@@ -290,6 +383,10 @@ func (h *Handler) UpdateRegistrationForm(c *gin.Context) {
 		response.BadRequest(c, "invalid request: "+err.Error())
 		return
 	}
+	if err := forms.ValidateConfig(req.AudienceFormConfig); err != nil {
+		response.BadRequest(c, "invalid audience_form_config: "+err.Error())
+		return
+	}
 	config, err := json.Marshal(req.AudienceFormConfig)
 	if err != nil {
 		response.Internal(c, "failed to save form config")
@@ -300,9 +397,51 @@ func (h *Handler) UpdateRegistrationForm(c *gin.Context) {
 		return
 	}
 	updated, _ := h.repo.GetByID(c.Request.Context(), id)
+	audit.Annotate(c, audit.Change{
+		Action:         "webinar.registration_form.update",
+		OrganizationID: w.OrganizationID,
+		Before:         gin.H{"audience_form_config": w.AudienceFormConfig},
+		After:          gin.H{"audience_form_config": json.RawMessage(config)},
+	})
 	response.OK(c, updated)
 }
 
+// planReminders (re)plans the webinar's reminders. A failure is only logged: the webinar is saved and the
+// worker's reminder sweep re-plans webinars whose plan does not match their start time.
+func (h *Handler) planReminders(ctx context.Context, webinarID uuid.UUID) {
+	if h.reminders == nil {
+		return
+	}
+	if err := h.reminders.Plan(ctx, webinarID); err != nil {
+		h.logger.Warn("plan reminders failed", zap.String("webinar_id", webinarID.String()), zap.Error(err))
+	}
+}
+
+// notifyRescheduled sends registrants of a published webinar its new time. Failures are logged; the
+// update itself has already succeeded.
+func (h *Handler) notifyRescheduled(ctx context.Context, w *models.Webinar) {
+	if h.schedule == nil || w == nil || !w.Published() {
+		return
+	}
+	if _, err := h.schedule.Rescheduled(ctx, w); err != nil {
+		h.logger.Warn("reschedule notification failed", zap.String("webinar_id", w.ID.String()), zap.Error(err))
+	}
+}
+
+// validVisibility reports whether v is a known models.WebinarVisibility* value.
+func validVisibility(v string) bool {
+	return v == models.WebinarVisibilityPublic || v == models.WebinarVisibilityUnlisted || v == models.WebinarVisibilityPrivate
+}
+
+// canEdit reports whether the caller may modify w: its creator, or an API key of its organization
+// (RequireWebinarOrgAccess has already matched the key's organization).
+func canEdit(c *gin.Context, w *models.Webinar, userID uuid.UUID) bool {
+	if orgID, ok := middleware.APIKeyOrganizationID(c); ok {
+		return w.OrganizationID != nil && *w.OrganizationID == orgID
+	}
+	return w.CreatedBy == userID
+}
+
 // Delete handles DELETE /webinars/:id (admin or creator).
 func (h *Handler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -324,6 +463,7 @@ func (h *Handler) Delete(c *gin.Context) {
 		response.Internal(c, "failed to delete webinar")
 		return
 	}
+	audit.Annotate(c, audit.Change{Action: "webinar.delete", OrganizationID: w.OrganizationID, Before: w})
 	response.NoContent(c)
 }
 
@@ -0,0 +1,104 @@
+package webinars
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// LifecycleEffects carries out what a status change implies beyond the webinar row (lifecycle.Effects).
+// The change is kept when an effect fails; failures are logged.
+type LifecycleEffects interface {
+	WentLive(ctx context.Context, w *models.Webinar) error
+	Ended(ctx context.Context, w *models.Webinar) error
+	Cancelled(ctx context.Context, w *models.Webinar) (notified int, refunds int64, err error)
+}
+
+// SetLifecycle sets the effects of going live, ending and cancelling. Without it only the status changes.
+func (h *Handler) SetLifecycle(e LifecycleEffects) {
+	h.lifecycle = e
+}
+
+// Transition returns the handler for POST /webinars/:id/<action> (models.WebinarTransitions): publish,
+// go-live, end, archive or cancel. The creator or the organization may change the status; speakers may
+// also take the webinar live and end it. A webinar not in a state the action starts from gets 409.
+func (h *Handler) Transition(action string) gin.HandlerFunc {
+	t, ok := models.WebinarTransitions[action]
+	if !ok {
+		panic("webinars: unknown lifecycle action " + action)
+	}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			response.BadRequest(c, "invalid webinar id")
+			return
+		}
+		userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
+		w, err := h.repo.GetByID(c.Request.Context(), id)
+		if err != nil {
+			response.NotFound(c, "webinar not found")
+			return
+		}
+		allowed := canEdit(c, w, userID)
+		if !allowed && (action == models.WebinarActionGoLive || action == models.WebinarActionEnd) {
+			if _, isKey := middleware.APIKeyOrganizationID(c); !isKey {
+				allowed, _ = h.repo.IsAdminOrSpeaker(c.Request.Context(), id, userID)
+			}
+		}
+		if !allowed {
+			response.Forbidden(c, "not allowed to change this webinar's status")
+			return
+		}
+		changed, err := h.repo.Transition(c.Request.Context(), id, t.From, t.To)
+		if err != nil {
+			h.logger.Error("webinar status change failed", zap.Error(err), zap.String("webinar_id", id.String()), zap.String("action", action))
+			response.Internal(c, "failed to change webinar status")
+			return
+		}
+		if !changed {
+			response.Conflict(c, "cannot "+action+" a "+w.Status+" webinar")
+			return
+		}
+		updated, err := h.repo.GetByID(c.Request.Context(), id)
+		if err != nil {
+			response.Internal(c, "failed to load webinar")
+			return
+		}
+		audit.Annotate(c, audit.Change{Action: "webinar.status", TargetType: "webinar", TargetID: id.String(), OrganizationID: w.OrganizationID,
+			Before: gin.H{"status": w.Status}, After: gin.H{"status": updated.Status, "action": action}})
+
+		out := gin.H{"webinar": updated}
+		switch action {
+		case models.WebinarActionPublish:
+			h.planReminders(c.Request.Context(), id)
+		case models.WebinarActionGoLive:
+			if h.lifecycle != nil {
+				if err := h.lifecycle.WentLive(c.Request.Context(), updated); err != nil {
+					h.logger.Error("go-live effects failed", zap.Error(err), zap.String("webinar_id", id.String()))
+				}
+			}
+		case models.WebinarActionEnd:
+			if h.lifecycle != nil {
+				if err := h.lifecycle.Ended(c.Request.Context(), updated); err != nil {
+					h.logger.Error("end effects failed", zap.Error(err), zap.String("webinar_id", id.String()))
+				}
+			}
+		case models.WebinarActionCancel:
+			if h.lifecycle != nil {
+				notified, refunds, err := h.lifecycle.Cancelled(c.Request.Context(), updated)
+				if err != nil {
+					h.logger.Error("cancel effects failed", zap.Error(err), zap.String("webinar_id", id.String()))
+				}
+				out["notified"], out["refunds_requested"] = notified, refunds
+			}
+		}
+		response.OK(c, out)
+	}
+}
@@ -1,19 +1,23 @@
 package webinars
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/organizations"
 	"github.com/aura-webinar/backend/pkg/response"
 )
 
 // ContextOrganizationID is the context key for organization ID when org access is enforced.
-const ContextOrganizationID = "organization_id"
+const ContextOrganizationID = middleware.ContextOrganizationID
 
 // RequireWebinarOrgAccess validates that the user has access to the webinar's organization (if any).
 // Call after JWT. If webinar has no organization_id, allows. Otherwise requires org membership.
+// API key requests may only reach webinars of the key's own organization.
 func RequireWebinarOrgAccess(webinarRepo *Repository, orgRepo *organizations.Repository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		webinarIDStr := c.Param("id")
@@ -33,18 +37,54 @@ func RequireWebinarOrgAccess(webinarRepo *Repository, orgRepo *organizations.Rep
 			c.Abort()
 			return
 		}
-		userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
 		if w.OrganizationID == nil {
+			if _, ok := middleware.APIKeyOrganizationID(c); ok {
+				response.Forbidden(c, "not authorized for this organization")
+				c.Abort()
+				return
+			}
 			c.Next()
 			return
 		}
-		ok, _ := orgRepo.UserHasOrgAccess(c.Request.Context(), *w.OrganizationID, userID)
-		if !ok {
-			response.Forbidden(c, "not authorized for this organization")
+		if !middleware.AuthorizeOrgRole(c, orgRepo, *w.OrganizationID, models.OrgMemberRoles...) {
 			c.Abort()
 			return
 		}
-		c.Set(ContextOrganizationID, *w.OrganizationID)
 		c.Next()
 	}
 }
+
+// CanManage reports whether the caller may manage w's registrations, imports, reminders and campaigns:
+// an owner or event manager of its organization (resolved by RequireWebinarOrgAccess), otherwise only its
+// creator.
+func CanManage(c *gin.Context, w *models.Webinar) bool {
+	if v, ok := c.Get(ContextOrganizationID); ok {
+		return w.OrganizationID != nil && *w.OrganizationID == v.(uuid.UUID) &&
+			models.HasOrgRole(c.GetString(middleware.ContextOrganizationRole), models.OrgManagerRoles)
+	}
+	return w.CreatedBy == c.MustGet(middleware.ContextUserID).(uuid.UUID)
+}
+
+// Lookup loads a webinar by ID (Repository).
+type Lookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Webinar, error)
+}
+
+// ManagedWebinar loads the :id webinar and requires CanManage. Writes the error response on failure.
+func ManagedWebinar(c *gin.Context, webinarRepo Lookup) (*models.Webinar, bool) {
+	webinarID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid webinar id")
+		return nil, false
+	}
+	w, err := webinarRepo.GetByID(c.Request.Context(), webinarID)
+	if err != nil || w == nil {
+		response.NotFound(c, "webinar not found")
+		return nil, false
+	}
+	if !CanManage(c, w) {
+		response.Forbidden(c, "only the creator or organization managers can manage this webinar")
+		return nil, false
+	}
+	return w, true
+}
@@ -22,19 +22,25 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 
 // Create inserts a new webinar.
 func (r *Repository) Create(ctx context.Context, w *models.Webinar) error {
-	const q = `INSERT INTO webinars (id, title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, max_audience, category, banner_image_url)
-		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	if w.Status == "" {
+		w.Status = models.WebinarStatusScheduled
+	}
+	if w.Visibility == "" {
+		w.Visibility = models.WebinarVisibilityPublic
+	}
+	const q = `INSERT INTO webinars (id, title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, max_audience, category, banner_image_url, status, visibility)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id, created_at, updated_at`
-	return r.pool.QueryRow(ctx, q, w.Title, w.Description, w.StartsAt, w.EndsAt, w.CreatedBy, w.OrganizationID, w.IsPaid, w.TicketPriceCents, w.TicketCurrency, w.MaxAudience, w.Category, w.BannerImageURL).
+	return r.pool.QueryRow(ctx, q, w.Title, w.Description, w.StartsAt, w.EndsAt, w.CreatedBy, w.OrganizationID, w.IsPaid, w.TicketPriceCents, w.TicketCurrency, w.MaxAudience, w.Category, w.BannerImageURL, w.Status, w.Visibility).
 		Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
 }
 
 // GetByID returns a webinar by ID.
 func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*models.Webinar, error) {
-	const q = `SELECT id, title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, max_audience, category, banner_image_url, audience_form_config, created_at, updated_at
+	const q = `SELECT id, title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, max_audience, category, banner_image_url, audience_form_config, status, visibility, status_changed_at, series_id, occurrence_at, calendar_sequence, created_at, updated_at
 		FROM webinars WHERE id = $1`
 	var w models.Webinar
-	err := r.pool.QueryRow(ctx, q, id).Scan(&w.ID, &w.Title, &w.Description, &w.StartsAt, &w.EndsAt, &w.CreatedBy, &w.OrganizationID, &w.IsPaid, &w.TicketPriceCents, &w.TicketCurrency, &w.MaxAudience, &w.Category, &w.BannerImageURL, &w.AudienceFormConfig, &w.CreatedAt, &w.UpdatedAt)
+	err := r.pool.QueryRow(ctx, q, id).Scan(&w.ID, &w.Title, &w.Description, &w.StartsAt, &w.EndsAt, &w.CreatedBy, &w.OrganizationID, &w.IsPaid, &w.TicketPriceCents, &w.TicketCurrency, &w.MaxAudience, &w.Category, &w.BannerImageURL, &w.AudienceFormConfig, &w.Status, &w.Visibility, &w.StatusChangedAt, &w.SeriesID, &w.OccurrenceAt, &w.CalendarSequence, &w.CreatedAt, &w.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -50,9 +56,8 @@ func (r *Repository) AddSpeaker(ctx context.Context, webinarID, userID uuid.UUID
 }
 
 // List returns all webinars, optionally filtered by created_by or organization_id.
-// Uses only columns present in base schema (001+003) so it works before migrations 009/012.
 func (r *Repository) List(ctx context.Context, createdBy *uuid.UUID, organizationID *uuid.UUID) ([]models.Webinar, error) {
-	base := `SELECT id, title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, created_at, updated_at FROM webinars`
+	base := `SELECT id, title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, max_audience, category, banner_image_url, status, visibility, status_changed_at, series_id, occurrence_at, created_at, updated_at FROM webinars`
 	var args []interface{}
 	var cond string
 	if createdBy != nil {
@@ -67,7 +72,18 @@ func (r *Repository) List(ctx context.Context, createdBy *uuid.UUID, organizatio
 		}
 		args = append(args, *organizationID)
 	}
-	rows, err := r.pool.Query(ctx, base+cond+" ORDER BY starts_at DESC", args...)
+	return r.listWith(ctx, base+cond+" ORDER BY starts_at DESC", args...)
+}
+
+// ListPublished returns the public webinars open to attendees (scheduled or live), soonest first.
+func (r *Repository) ListPublished(ctx context.Context) ([]models.Webinar, error) {
+	const q = `SELECT id, title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, max_audience, category, banner_image_url, status, visibility, status_changed_at, series_id, occurrence_at, created_at, updated_at
+		FROM webinars WHERE status IN ('scheduled', 'live') AND visibility = 'public' ORDER BY starts_at`
+	return r.listWith(ctx, q)
+}
+
+func (r *Repository) listWith(ctx context.Context, q string, args ...interface{}) ([]models.Webinar, error) {
+	rows, err := r.pool.Query(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +92,7 @@ func (r *Repository) List(ctx context.Context, createdBy *uuid.UUID, organizatio
 	var list []models.Webinar
 	for rows.Next() {
 		var w models.Webinar
-		if err := rows.Scan(&w.ID, &w.Title, &w.Description, &w.StartsAt, &w.EndsAt, &w.CreatedBy, &w.OrganizationID, &w.IsPaid, &w.TicketPriceCents, &w.TicketCurrency, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		if err := rows.Scan(&w.ID, &w.Title, &w.Description, &w.StartsAt, &w.EndsAt, &w.CreatedBy, &w.OrganizationID, &w.IsPaid, &w.TicketPriceCents, &w.TicketCurrency, &w.MaxAudience, &w.Category, &w.BannerImageURL, &w.Status, &w.Visibility, &w.StatusChangedAt, &w.SeriesID, &w.OccurrenceAt, &w.CreatedAt, &w.UpdatedAt); err != nil {
 			return nil, err
 		}
 		list = append(list, w)
@@ -85,32 +101,18 @@ func (r *Repository) List(ctx context.Context, createdBy *uuid.UUID, organizatio
 }
 
 // ListBySpeakerID returns webinars where the user is added as a speaker (for speaker dashboard).
-// Uses only columns present in base schema (001+003) so it works before migrations 009/012.
 func (r *Repository) ListBySpeakerID(ctx context.Context, userID uuid.UUID) ([]models.Webinar, error) {
-	const q = `SELECT w.id, w.title, w.description, w.starts_at, w.ends_at, w.created_by, w.organization_id, w.is_paid, w.ticket_price_cents, w.ticket_currency, w.created_at, w.updated_at
+	const q = `SELECT w.id, w.title, w.description, w.starts_at, w.ends_at, w.created_by, w.organization_id, w.is_paid, w.ticket_price_cents, w.ticket_currency, w.max_audience, w.category, w.banner_image_url, w.status, w.visibility, w.status_changed_at, w.series_id, w.occurrence_at, w.created_at, w.updated_at
 		FROM webinars w
 		INNER JOIN webinar_speakers ws ON ws.webinar_id = w.id AND ws.user_id = $1
 		ORDER BY w.starts_at DESC`
-	rows, err := r.pool.Query(ctx, q, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var list []models.Webinar
-	for rows.Next() {
-		var w models.Webinar
-		if err := rows.Scan(&w.ID, &w.Title, &w.Description, &w.StartsAt, &w.EndsAt, &w.CreatedBy, &w.OrganizationID, &w.IsPaid, &w.TicketPriceCents, &w.TicketCurrency, &w.CreatedAt, &w.UpdatedAt); err != nil {
-			return nil, err
-		}
-		list = append(list, w)
-	}
-	return list, rows.Err()
+	return r.listWith(ctx, q, userID)
 }
 
-// Update updates webinar fields (title, description, starts_at, ends_at, max_audience, category, banner_image_url).
-func (r *Repository) Update(ctx context.Context, id uuid.UUID, title, description string, startsAt, endsAt *time.Time, maxAudience *int, category, bannerImageURL string) error {
-	const q = `UPDATE webinars SET title = $1, description = $2, starts_at = COALESCE($3, starts_at), ends_at = COALESCE($4, ends_at), max_audience = $5, category = $6, banner_image_url = $7, updated_at = NOW() WHERE id = $8`
-	_, err := r.pool.Exec(ctx, q, title, description, startsAt, endsAt, maxAudience, category, bannerImageURL, id)
+// Update updates webinar fields (title, description, starts_at, ends_at, max_audience, category, banner_image_url, visibility).
+func (r *Repository) Update(ctx context.Context, id uuid.UUID, title, description string, startsAt, endsAt *time.Time, maxAudience *int, category, bannerImageURL, visibility string) error {
+	const q = `UPDATE webinars SET title = $1, description = $2, starts_at = COALESCE($3, starts_at), ends_at = COALESCE($4, ends_at), max_audience = $5, category = $6, banner_image_url = $7, visibility = $8, updated_at = NOW() WHERE id = $9`
+	_, err := r.pool.Exec(ctx, q, title, description, startsAt, endsAt, maxAudience, category, bannerImageURL, visibility, id)
 	return err
 }
 
@@ -121,6 +123,17 @@ func (r *Repository) UpdateAudienceFormConfig(ctx context.Context, id uuid.UUID,
 	return err
 }
 
+// Transition moves the webinar to status if it is in one of from (a lifecycle step), and reports whether it
+// did; false means another request changed the status first.
+func (r *Repository) Transition(ctx context.Context, id uuid.UUID, from []string, status string) (bool, error) {
+	const q = `UPDATE webinars SET status = $1, status_changed_at = NOW(), updated_at = NOW() WHERE id = $2 AND status = ANY($3)`
+	tag, err := r.pool.Exec(ctx, q, status, id, from)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
 // Delete removes a webinar by ID.
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 	const q = `DELETE FROM webinars WHERE id = $1`
@@ -128,28 +141,6 @@ func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-// ListStartingInWindow returns webinars whose starts_at falls within the given time window (for reminder scheduling).
-func (r *Repository) ListStartingInWindow(ctx context.Context, windowStart, windowEnd time.Time) ([]models.Webinar, error) {
-	const q = `SELECT id, title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents, ticket_currency, created_at, updated_at
-		FROM webinars
-		WHERE starts_at >= $1 AND starts_at <= $2
-		ORDER BY starts_at ASC`
-	rows, err := r.pool.Query(ctx, q, windowStart, windowEnd)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var list []models.Webinar
-	for rows.Next() {
-		var w models.Webinar
-		if err := rows.Scan(&w.ID, &w.Title, &w.Description, &w.StartsAt, &w.EndsAt, &w.CreatedBy, &w.OrganizationID, &w.IsPaid, &w.TicketPriceCents, &w.TicketCurrency, &w.CreatedAt, &w.UpdatedAt); err != nil {
-			return nil, err
-		}
-		list = append(list, w)
-	}
-	return list, rows.Err()
-}
-
 // IsAdminOrSpeaker returns true if the user created the webinar or is a speaker.
 func (r *Repository) IsAdminOrSpeaker(ctx context.Context, webinarID, userID uuid.UUID) (bool, error) {
 	w, err := r.GetByID(ctx, webinarID)
@@ -0,0 +1,249 @@
+// Package webinartemplates sets up new webinars from existing ones: POST /webinars/:id/clone copies a webinar,
+// and organizations save webinars as templates to create later webinars from. Both take a snapshot of the
+// source (a models.WebinarTemplate) and create the new webinar from it.
+package webinartemplates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/storage"
+)
+
+// errNoStorage means an ad's S3 object cannot be copied because S3 is not configured.
+var errNoStorage = errors.New("S3 not configured")
+
+// CopyOptions selects what a clone or template takes from its source besides the webinar's settings
+// (title, description, duration, price, capacity, category, banner and visibility).
+type CopyOptions struct {
+	Form      bool `json:"form"`      // registration form config
+	Speakers  bool `json:"speakers"`  // speakers and pending invitations, invited again to each new webinar
+	Ads       bool `json:"ads"`       // ad creatives (S3 objects are copied) and the playlist rotation interval
+	Polls     bool `json:"polls"`     // polls, created unlaunched
+	Reminders bool `json:"reminders"` // the webinar's own reminder offsets
+}
+
+// AllCopyOptions copies everything; it applies when a request has no "copy" object.
+var AllCopyOptions = CopyOptions{Form: true, Speakers: true, Ads: true, Polls: true, Reminders: true}
+
+// SpeakerInviter invites a speaker to a webinar by email (speakerinvites.Handler).
+type SpeakerInviter interface {
+	InviteByEmail(ctx context.Context, w *models.Webinar, email string) error
+}
+
+// Result reports what creating a webinar from a clone or template copied.
+type Result struct {
+	Webinar         *models.Webinar `json:"webinar"`
+	PollsCopied     int             `json:"polls_copied"`
+	AdsCopied       int             `json:"ads_copied"`
+	AdsFailed       int             `json:"ads_failed"` // creatives whose S3 object could not be copied
+	SpeakersInvited int             `json:"speakers_invited"`
+}
+
+// Cloner snapshots webinars and creates webinars and templates from snapshots.
+type Cloner struct {
+	repo      *Repository
+	s3        *storage.S3
+	speakers  SpeakerInviter
+	reminders webinars.ReminderPlanner
+	logger    *zap.Logger
+}
+
+// NewCloner creates a cloner. Without s3, ads stored in S3 are not copied.
+func NewCloner(repo *Repository, s3 *storage.S3, logger *zap.Logger) *Cloner {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Cloner{repo: repo, s3: s3, logger: logger}
+}
+
+// SetSpeakerInviter invites the snapshot's speakers to new webinars. Without it speakers are not copied.
+func (c *Cloner) SetSpeakerInviter(i SpeakerInviter) {
+	c.speakers = i
+}
+
+// SetReminderPlanner plans reminders for new scheduled webinars. Without it, the worker's reminder sweep
+// plans them on its next run.
+func (c *Cloner) SetReminderPlanner(p webinars.ReminderPlanner) {
+	c.reminders = p
+}
+
+// Snapshot returns w's setup with the parts opts selects. Its ads still point at w's S3 objects.
+func (c *Cloner) Snapshot(ctx context.Context, w *models.Webinar, opts CopyOptions) (*models.WebinarTemplate, error) {
+	t := &models.WebinarTemplate{
+		Title:            w.Title,
+		Description:      w.Description,
+		IsPaid:           w.IsPaid,
+		TicketPriceCents: w.TicketPriceCents,
+		TicketCurrency:   w.TicketCurrency,
+		MaxAudience:      w.MaxAudience,
+		Category:         w.Category,
+		BannerImageURL:   w.BannerImageURL,
+		Visibility:       w.Visibility,
+		SpeakerEmails:    []string{},
+		Polls:            []models.TemplatePoll{},
+		Ads:              []models.TemplateAd{},
+	}
+	if w.EndsAt != nil && w.EndsAt.After(w.StartsAt) {
+		t.DurationMinutes = int(w.EndsAt.Sub(w.StartsAt) / time.Minute)
+	}
+	var err error
+	if opts.Form {
+		t.AudienceFormConfig = w.AudienceFormConfig
+	}
+	if opts.Speakers {
+		if t.SpeakerEmails, err = c.repo.SpeakerEmails(ctx, w.ID); err != nil {
+			return nil, fmt.Errorf("list speakers: %w", err)
+		}
+	}
+	if opts.Polls {
+		if t.Polls, err = c.repo.ListPolls(ctx, w.ID); err != nil {
+			return nil, fmt.Errorf("list polls: %w", err)
+		}
+	}
+	if opts.Reminders {
+		if t.ReminderOffsets, err = c.repo.ReminderOffsets(ctx, w.ID); err != nil {
+			return nil, fmt.Errorf("load reminder offsets: %w", err)
+		}
+	}
+	if opts.Ads {
+		if t.Ads, t.AdRotationInterval, err = c.repo.ListAds(ctx, w.ID); err != nil {
+			return nil, fmt.Errorf("list ads: %w", err)
+		}
+	}
+	if t.AdRotationInterval <= 0 {
+		t.AdRotationInterval = 30
+	}
+	return t, nil
+}
+
+// CreateWebinar creates w (its schedule, owner and status set by the caller) from t: settings, form, polls
+// and reminder offsets, then copies of t's ads and invitations to t's speakers. A failed ad copy or
+// invitation is logged and counted, not returned; the webinar exists by then.
+func (c *Cloner) CreateWebinar(ctx context.Context, t *models.WebinarTemplate, w *models.Webinar) (*Result, error) {
+	w.Title, w.Description = t.Title, t.Description
+	w.IsPaid, w.TicketPriceCents, w.TicketCurrency = t.IsPaid, t.TicketPriceCents, t.TicketCurrency
+	w.MaxAudience, w.Category, w.BannerImageURL = t.MaxAudience, t.Category, t.BannerImageURL
+	w.AudienceFormConfig = t.AudienceFormConfig
+	if w.Visibility == "" {
+		w.Visibility = t.Visibility
+	}
+	if w.EndsAt == nil && t.DurationMinutes > 0 {
+		endsAt := w.StartsAt.Add(time.Duration(t.DurationMinutes) * time.Minute)
+		w.EndsAt = &endsAt
+	}
+	if err := c.repo.CreateWebinar(ctx, t, w); err != nil {
+		return nil, fmt.Errorf("create webinar: %w", err)
+	}
+	res := &Result{Webinar: w, PollsCopied: len(t.Polls)}
+	names := adFilenames(t.Ads)
+	for i, a := range t.Ads {
+		ad, err := c.copyAd(ctx, a, storage.AdKey(w.ID.String(), names[i]))
+		if err == nil {
+			err = c.repo.AddAdvertisement(ctx, w.ID, &ad)
+		}
+		if err != nil {
+			c.logger.Warn("copy ad failed", zap.Error(err), zap.String("webinar_id", w.ID.String()), zap.String("s3_key", a.S3Key))
+			res.AdsFailed++
+			continue
+		}
+		res.AdsCopied++
+	}
+	if c.speakers != nil {
+		for _, email := range t.SpeakerEmails {
+			if err := c.speakers.InviteByEmail(ctx, w, email); err != nil {
+				c.logger.Warn("invite speaker failed", zap.Error(err), zap.String("webinar_id", w.ID.String()))
+				continue
+			}
+			res.SpeakersInvited++
+		}
+	}
+	if w.Status == models.WebinarStatusScheduled && c.reminders != nil {
+		if err := c.reminders.Plan(ctx, w.ID); err != nil {
+			c.logger.Warn("plan reminders failed", zap.String("webinar_id", w.ID.String()), zap.Error(err))
+		}
+	}
+	return res, nil
+}
+
+// SaveTemplate stores t (from Snapshot) as a template with its own copies of the ads. Returns how many ads
+// could not be copied, or ErrNameTaken.
+func (c *Cloner) SaveTemplate(ctx context.Context, t *models.WebinarTemplate) (adsFailed int, err error) {
+	ads := t.Ads
+	t.Ads = []models.TemplateAd{}
+	if err := c.repo.Create(ctx, t); err != nil {
+		return 0, err
+	}
+	names := adFilenames(ads)
+	for i, a := range ads {
+		ad, err := c.copyAd(ctx, a, storage.TemplateAdKey(t.ID.String(), names[i]))
+		if err == nil {
+			err = c.repo.AddAd(ctx, t.ID, &ad, len(t.Ads))
+		}
+		if err != nil {
+			c.logger.Warn("copy template ad failed", zap.Error(err), zap.String("template_id", t.ID.String()), zap.String("s3_key", a.S3Key))
+			adsFailed++
+			continue
+		}
+		t.Ads = append(t.Ads, ad)
+	}
+	return adsFailed, nil
+}
+
+// DeleteAdObjects deletes the S3 objects of a deleted template's ads.
+func (c *Cloner) DeleteAdObjects(ctx context.Context, ads []models.TemplateAd) {
+	if c.s3 == nil {
+		return
+	}
+	for _, a := range ads {
+		if a.S3Key == "" {
+			continue
+		}
+		if err := c.s3.DeleteAd(ctx, a.S3Key); err != nil {
+			c.logger.Warn("delete template ad failed", zap.Error(err), zap.String("s3_key", a.S3Key))
+		}
+	}
+}
+
+// copyAd returns a with a copy of its S3 object at dst; ads without an S3 object (external URLs) are
+// returned as they are.
+func (c *Cloner) copyAd(ctx context.Context, a models.TemplateAd, dst string) (models.TemplateAd, error) {
+	a.ID = uuid.Nil
+	if a.S3Key == "" {
+		return a, nil
+	}
+	if c.s3 == nil {
+		return a, errNoStorage
+	}
+	fileURL, err := c.s3.CopyAd(ctx, a.S3Key, dst)
+	if err != nil {
+		return a, err
+	}
+	a.S3Key, a.FileURL = dst, fileURL
+	return a, nil
+}
+
+// adFilenames returns the file names of the ads' copies: each object's name, prefixed with its position when
+// an earlier ad has the same name (ads of series occurrences come from different webinars' folders).
+func adFilenames(ads []models.TemplateAd) []string {
+	names := make([]string, len(ads))
+	seen := make(map[string]bool, len(ads))
+	for i, a := range ads {
+		name := path.Base(a.S3Key)
+		if seen[name] {
+			name = strconv.Itoa(i) + "-" + name
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
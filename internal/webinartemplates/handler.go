@@ -0,0 +1,418 @@
+package webinartemplates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/middleware"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/reminders"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/response"
+)
+
+// maxPolls bounds a template's prepared polls.
+const maxPolls = 50
+
+// OrgLookup is the organization membership check (organizations.Repository).
+type OrgLookup interface {
+	GetUserRole(ctx context.Context, orgID, userID uuid.UUID) (string, error)
+}
+
+// Handler serves webinar cloning and organization webinar templates. Clone runs after
+// webinars.RequireWebinarOrgAccess.
+type Handler struct {
+	repo        *Repository
+	cloner      *Cloner
+	webinarRepo *webinars.Repository
+	orgRepo     OrgLookup
+	logger      *zap.Logger
+}
+
+// NewHandler creates a webinar templates handler.
+func NewHandler(repo *Repository, cloner *Cloner, webinarRepo *webinars.Repository, orgRepo OrgLookup, logger *zap.Logger) *Handler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Handler{repo: repo, cloner: cloner, webinarRepo: webinarRepo, orgRepo: orgRepo, logger: logger}
+}
+
+// NewWebinarRequest schedules the webinar a clone or template creates.
+type NewWebinarRequest struct {
+	Title    string  `json:"title"` // default: the source's title
+	StartsAt string  `json:"starts_at" binding:"required"`
+	EndsAt   *string `json:"ends_at"` // default: starts_at plus the source's duration
+	Status   string  `json:"status"`  // draft (default) or scheduled
+}
+
+// CloneRequest is the body for POST /webinars/:id/clone; "copy" defaults to everything.
+type CloneRequest struct {
+	NewWebinarRequest
+	Copy *CopyOptions `json:"copy"`
+}
+
+// CreateTemplateRequest is the body for POST /organizations/:id/webinar-templates: saves one of the
+// organization's webinars as a template; "copy" defaults to everything.
+type CreateTemplateRequest struct {
+	Name      string       `json:"name" binding:"required"`
+	WebinarID uuid.UUID    `json:"webinar_id" binding:"required"`
+	Copy      *CopyOptions `json:"copy"`
+}
+
+// UpdateTemplateRequest is the body for PATCH /organizations/:id/webinar-templates/:templateId; omitted
+// fields are unchanged and reminder_offsets null inherits the organization default. Ads and the registration
+// form are replaced by saving a webinar as a new template.
+type UpdateTemplateRequest struct {
+	Name            *string                `json:"name"`
+	Title           *string                `json:"title"`
+	Description     *string                `json:"description"`
+	DurationMinutes *int                   `json:"duration_minutes"`
+	Visibility      *string                `json:"visibility"`
+	SpeakerEmails   *[]string              `json:"speaker_emails"`
+	Polls           *[]models.TemplatePoll `json:"polls"`
+	ReminderOffsets json.RawMessage        `json:"reminder_offsets"`
+}
+
+// Clone handles POST /webinars/:id/clone: a new webinar with the source's settings and the parts "copy"
+// selects. Ads get their own S3 objects and speakers are invited again.
+func (h *Handler) Clone(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "invalid webinar id")
+		return
+	}
+	var req CloneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	ctx := c.Request.Context()
+	src, err := h.webinarRepo.GetByID(ctx, id)
+	if err != nil || src == nil {
+		response.NotFound(c, "webinar not found")
+		return
+	}
+	if _, ok := c.Get(middleware.ContextOrganizationID); !ok {
+		ok, err := h.webinarRepo.IsAdminOrSpeaker(ctx, id, c.MustGet(middleware.ContextUserID).(uuid.UUID))
+		if err != nil || !ok {
+			response.Forbidden(c, "only the webinar's creator or speakers can clone it")
+			return
+		}
+	}
+	w, msg := newWebinar(c, &req.NewWebinarRequest)
+	if msg != "" {
+		response.BadRequest(c, msg)
+		return
+	}
+	w.OrganizationID = src.OrganizationID
+	opts := AllCopyOptions
+	if req.Copy != nil {
+		opts = *req.Copy
+	}
+	t, err := h.cloner.Snapshot(ctx, src, opts)
+	if err != nil {
+		h.logger.Error("snapshot webinar failed", zap.Error(err), zap.String("webinar_id", id.String()))
+		response.Internal(c, "failed to clone webinar")
+		return
+	}
+	res, ok := h.createWebinar(c, t, w, &req.NewWebinarRequest)
+	if !ok {
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "webinar.clone", TargetType: "webinar", TargetID: w.ID.String(), OrganizationID: w.OrganizationID,
+		After: gin.H{"source_webinar_id": src.ID, "copy": opts, "webinar": w}})
+	response.Created(c, res)
+}
+
+// List handles GET /organizations/:id/webinar-templates.
+func (h *Handler) List(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	list, err := h.repo.List(c.Request.Context(), orgID)
+	if err != nil {
+		h.logger.Error("list webinar templates failed", zap.Error(err), zap.String("organization_id", orgID.String()))
+		response.Internal(c, "failed to list templates")
+		return
+	}
+	response.OK(c, list)
+}
+
+// Create handles POST /organizations/:id/webinar-templates. The template gets its own copies of the
+// webinar's ad objects.
+func (h *Handler) Create(c *gin.Context) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return
+	}
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > 255 {
+		response.BadRequest(c, "name must be 1–255 characters")
+		return
+	}
+	ctx := c.Request.Context()
+	src, err := h.webinarRepo.GetByID(ctx, req.WebinarID)
+	if err != nil || src == nil || src.OrganizationID == nil || *src.OrganizationID != orgID {
+		response.NotFound(c, "webinar not found in this organization")
+		return
+	}
+	opts := AllCopyOptions
+	if req.Copy != nil {
+		opts = *req.Copy
+	}
+	t, err := h.cloner.Snapshot(ctx, src, opts)
+	if err != nil {
+		h.logger.Error("snapshot webinar failed", zap.Error(err), zap.String("webinar_id", src.ID.String()))
+		response.Internal(c, "failed to create template")
+		return
+	}
+	userID := c.MustGet(middleware.ContextUserID).(uuid.UUID)
+	t.OrganizationID, t.Name, t.CreatedBy = orgID, name, &userID
+	adsFailed, err := h.cloner.SaveTemplate(ctx, t)
+	if errors.Is(err, ErrNameTaken) {
+		response.Conflict(c, "a template with this name already exists")
+		return
+	}
+	if err != nil {
+		h.logger.Error("create webinar template failed", zap.Error(err), zap.String("organization_id", orgID.String()))
+		response.Internal(c, "failed to create template")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "webinar_template.create", TargetType: "webinar_template", TargetID: t.ID.String(), OrganizationID: &orgID,
+		After: gin.H{"source_webinar_id": src.ID, "copy": opts, "template": t}})
+	response.Created(c, gin.H{"template": t, "ads_failed": adsFailed})
+}
+
+// Get handles GET /organizations/:id/webinar-templates/:templateId.
+func (h *Handler) Get(c *gin.Context) {
+	t, ok := h.template(c)
+	if !ok {
+		return
+	}
+	response.OK(c, t)
+}
+
+// Update handles PATCH /organizations/:id/webinar-templates/:templateId.
+func (h *Handler) Update(c *gin.Context) {
+	t, ok := h.template(c)
+	if !ok {
+		return
+	}
+	var req UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	before := *t
+	if msg := applyUpdate(t, &req); msg != "" {
+		response.BadRequest(c, msg)
+		return
+	}
+	err := h.repo.Update(c.Request.Context(), t)
+	if errors.Is(err, ErrNameTaken) {
+		response.Conflict(c, "a template with this name already exists")
+		return
+	}
+	if err != nil {
+		h.logger.Error("update webinar template failed", zap.Error(err), zap.String("template_id", t.ID.String()))
+		response.Internal(c, "failed to update template")
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "webinar_template.update", TargetType: "webinar_template", TargetID: t.ID.String(), OrganizationID: &t.OrganizationID,
+		Before: before, After: t})
+	response.OK(c, t)
+}
+
+// Delete handles DELETE /organizations/:id/webinar-templates/:templateId and its ad objects. Webinars
+// created from it keep their own copies.
+func (h *Handler) Delete(c *gin.Context) {
+	t, ok := h.template(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.Delete(c.Request.Context(), t.OrganizationID, t.ID); err != nil {
+		h.logger.Error("delete webinar template failed", zap.Error(err), zap.String("template_id", t.ID.String()))
+		response.Internal(c, "failed to delete template")
+		return
+	}
+	h.cloner.DeleteAdObjects(c.Request.Context(), t.Ads)
+	audit.Annotate(c, audit.Change{Action: "webinar_template.delete", TargetType: "webinar_template", TargetID: t.ID.String(), OrganizationID: &t.OrganizationID,
+		Before: t})
+	response.NoContent(c)
+}
+
+// CreateWebinar handles POST /organizations/:id/webinar-templates/:templateId/webinars: a new webinar of the
+// organization from the template.
+func (h *Handler) CreateWebinar(c *gin.Context) {
+	t, ok := h.template(c)
+	if !ok {
+		return
+	}
+	var req NewWebinarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	w, msg := newWebinar(c, &req)
+	if msg != "" {
+		response.BadRequest(c, msg)
+		return
+	}
+	orgID := t.OrganizationID
+	w.OrganizationID = &orgID
+	res, ok := h.createWebinar(c, t, w, &req)
+	if !ok {
+		return
+	}
+	audit.Annotate(c, audit.Change{Action: "webinar.create", TargetType: "webinar", TargetID: w.ID.String(), OrganizationID: w.OrganizationID,
+		After: gin.H{"template_id": t.ID, "webinar": w}})
+	response.Created(c, res)
+}
+
+// createWebinar creates w from t, with req's title when it has one. Writes the error response on failure.
+func (h *Handler) createWebinar(c *gin.Context, t *models.WebinarTemplate, w *models.Webinar, req *NewWebinarRequest) (*Result, bool) {
+	if title := strings.TrimSpace(req.Title); title != "" {
+		copied := *t
+		copied.Title = title
+		t = &copied
+	}
+	res, err := h.cloner.CreateWebinar(c.Request.Context(), t, w)
+	if err != nil {
+		h.logger.Error("create webinar from template failed", zap.Error(err))
+		response.Internal(c, "failed to create webinar")
+		return nil, false
+	}
+	return res, true
+}
+
+// newWebinar returns the webinar req schedules, created by the caller; returns a validation message or "".
+func newWebinar(c *gin.Context, req *NewWebinarRequest) (*models.Webinar, string) {
+	startsAt, err := time.Parse(time.RFC3339, req.StartsAt)
+	if err != nil {
+		return nil, "invalid starts_at"
+	}
+	w := &models.Webinar{StartsAt: startsAt, CreatedBy: c.MustGet(middleware.ContextUserID).(uuid.UUID), Status: req.Status}
+	if req.EndsAt != nil {
+		endsAt, err := time.Parse(time.RFC3339, *req.EndsAt)
+		if err != nil || !endsAt.After(startsAt) {
+			return nil, "ends_at must be a time after starts_at"
+		}
+		w.EndsAt = &endsAt
+	}
+	if w.Status == "" {
+		w.Status = models.WebinarStatusDraft
+	}
+	if w.Status != models.WebinarStatusDraft && w.Status != models.WebinarStatusScheduled {
+		return nil, "status must be draft or scheduled"
+	}
+	return w, ""
+}
+
+// applyUpdate applies req to t; returns a validation message or "".
+func applyUpdate(t *models.WebinarTemplate, req *UpdateTemplateRequest) string {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len([]rune(name)) > 255 {
+			return "name must be 1–255 characters"
+		}
+		t.Name = name
+	}
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			return "title is required"
+		}
+		t.Title = *req.Title
+	}
+	if req.Description != nil {
+		t.Description = *req.Description
+	}
+	if req.DurationMinutes != nil {
+		if *req.DurationMinutes < 0 {
+			return "duration_minutes must not be negative"
+		}
+		t.DurationMinutes = *req.DurationMinutes
+	}
+	if req.Visibility != nil {
+		v := *req.Visibility
+		if v != models.WebinarVisibilityPublic && v != models.WebinarVisibilityUnlisted && v != models.WebinarVisibilityPrivate {
+			return "visibility must be public, unlisted or private"
+		}
+		t.Visibility = v
+	}
+	if req.SpeakerEmails != nil {
+		emails := make([]string, 0, len(*req.SpeakerEmails))
+		for _, e := range *req.SpeakerEmails {
+			addr, err := mail.ParseAddress(strings.TrimSpace(e))
+			if err != nil || addr.Name != "" {
+				return "invalid speaker email: " + e
+			}
+			emails = append(emails, addr.Address)
+		}
+		t.SpeakerEmails = emails
+	}
+	if req.Polls != nil {
+		if len(*req.Polls) > maxPolls {
+			return "too many polls"
+		}
+		for _, p := range *req.Polls {
+			if p.Question == "" || p.OptionA == "" || p.OptionB == "" || p.OptionC == "" || p.OptionD == "" {
+				return "each poll needs a question and options a to d"
+			}
+		}
+		t.Polls = *req.Polls
+	}
+	if len(req.ReminderOffsets) > 0 {
+		var offsets []int
+		if !bytes.Equal(req.ReminderOffsets, []byte("null")) {
+			if err := json.Unmarshal(req.ReminderOffsets, &offsets); err != nil || offsets == nil {
+				return "reminder_offsets must be a list of minutes or null"
+			}
+		}
+		offsets, err := reminders.NormalizeOffsets(offsets)
+		if err != nil {
+			return err.Error()
+		}
+		t.ReminderOffsets = offsets
+	}
+	return ""
+}
+
+// template loads :templateId of organization :id after the access check. Writes the error response on failure.
+func (h *Handler) template(c *gin.Context) (*models.WebinarTemplate, bool) {
+	orgID, ok := middleware.RequireOrgRole(c, h.orgRepo, models.OrgManagerRoles...)
+	if !ok {
+		return nil, false
+	}
+	id, err := uuid.Parse(c.Param("templateId"))
+	if err != nil {
+		response.BadRequest(c, "invalid template id")
+		return nil, false
+	}
+	t, err := h.repo.GetByID(c.Request.Context(), orgID, id)
+	if err != nil {
+		h.logger.Error("load webinar template failed", zap.Error(err), zap.String("template_id", id.String()))
+		response.Internal(c, "failed to load template")
+		return nil, false
+	}
+	if t == nil {
+		response.NotFound(c, "template not found")
+		return nil, false
+	}
+	return t, true
+}
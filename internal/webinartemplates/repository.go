@@ -0,0 +1,278 @@
+package webinartemplates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aura-webinar/backend/internal/models"
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// ErrNameTaken is returned when the organization already has a template with the name.
+var ErrNameTaken = errors.New("template name already in use")
+
+// Repository handles webinar_templates and their ads, and reads and writes the parts of a webinar that
+// clones and templates copy.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a webinar templates repository.
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const columns = `id, organization_id, name, title, description, duration_minutes, is_paid, ticket_price_cents, ticket_currency,
+	max_audience, category, banner_image_url, visibility, audience_form_config, speaker_emails, polls, reminder_offsets,
+	ad_rotation_interval, created_by, created_at, updated_at`
+
+func scanTemplate(row pgx.Row) (*models.WebinarTemplate, error) {
+	var t models.WebinarTemplate
+	var polls []byte
+	err := row.Scan(&t.ID, &t.OrganizationID, &t.Name, &t.Title, &t.Description, &t.DurationMinutes, &t.IsPaid, &t.TicketPriceCents,
+		&t.TicketCurrency, &t.MaxAudience, &t.Category, &t.BannerImageURL, &t.Visibility, &t.AudienceFormConfig, &t.SpeakerEmails,
+		&polls, &t.ReminderOffsets, &t.AdRotationInterval, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(polls, &t.Polls); err != nil {
+		return nil, err
+	}
+	if t.Polls == nil {
+		t.Polls = []models.TemplatePoll{}
+	}
+	t.Ads = []models.TemplateAd{}
+	return &t, nil
+}
+
+// Create inserts a template without its ads (AddAd). Returns ErrNameTaken if the name is in use.
+func (r *Repository) Create(ctx context.Context, t *models.WebinarTemplate) error {
+	polls, err := json.Marshal(t.Polls)
+	if err != nil {
+		return err
+	}
+	const q = `INSERT INTO webinar_templates (organization_id, name, title, description, duration_minutes, is_paid, ticket_price_cents,
+			ticket_currency, max_audience, category, banner_image_url, visibility, audience_form_config, speaker_emails, polls,
+			reminder_offsets, ad_rotation_interval, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id, created_at, updated_at`
+	err = r.pool.QueryRow(ctx, q, t.OrganizationID, t.Name, t.Title, t.Description, t.DurationMinutes, t.IsPaid, t.TicketPriceCents,
+		t.TicketCurrency, t.MaxAudience, t.Category, t.BannerImageURL, t.Visibility, t.AudienceFormConfig, t.SpeakerEmails, polls,
+		t.ReminderOffsets, t.AdRotationInterval, t.CreatedBy).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	return nameTaken(err)
+}
+
+// AddAd appends an ad creative to a template.
+func (r *Repository) AddAd(ctx context.Context, templateID uuid.UUID, a *models.TemplateAd, position int) error {
+	return r.pool.QueryRow(ctx, `INSERT INTO webinar_template_ads (template_id, file_url, file_type, file_size, duration, s3_key, is_active, position)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8) RETURNING id`,
+		templateID, a.FileURL, a.FileType, a.FileSize, a.Duration, a.S3Key, a.IsActive, position).Scan(&a.ID)
+}
+
+// GetByID returns an organization's template with its ads (nil, nil if not found).
+func (r *Repository) GetByID(ctx context.Context, orgID, id uuid.UUID) (*models.WebinarTemplate, error) {
+	t, err := scanTemplate(r.pool.QueryRow(ctx, `SELECT `+columns+` FROM webinar_templates WHERE id = $1 AND organization_id = $2`, id, orgID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, r.loadAds(ctx, []*models.WebinarTemplate{t})
+}
+
+// List returns an organization's templates with their ads, by name.
+func (r *Repository) List(ctx context.Context, orgID uuid.UUID) ([]*models.WebinarTemplate, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+columns+` FROM webinar_templates WHERE organization_id = $1 ORDER BY name`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []*models.WebinarTemplate{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, r.loadAds(ctx, list)
+}
+
+func (r *Repository) loadAds(ctx context.Context, list []*models.WebinarTemplate) error {
+	if len(list) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*models.WebinarTemplate, len(list))
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, t := range list {
+		byID[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+	rows, err := r.pool.Query(ctx, `SELECT template_id, id, file_url, file_type, file_size, duration, COALESCE(s3_key, ''), is_active
+		FROM webinar_template_ads WHERE template_id = ANY($1) ORDER BY position`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var templateID uuid.UUID
+		var a models.TemplateAd
+		if err := rows.Scan(&templateID, &a.ID, &a.FileURL, &a.FileType, &a.FileSize, &a.Duration, &a.S3Key, &a.IsActive); err != nil {
+			return err
+		}
+		byID[templateID].Ads = append(byID[templateID].Ads, a)
+	}
+	return rows.Err()
+}
+
+// Update saves a template's name and settings (not its ads). Returns ErrNameTaken if the name is in use.
+func (r *Repository) Update(ctx context.Context, t *models.WebinarTemplate) error {
+	polls, err := json.Marshal(t.Polls)
+	if err != nil {
+		return err
+	}
+	const q = `UPDATE webinar_templates SET name = $3, title = $4, description = $5, duration_minutes = $6, visibility = $7,
+			speaker_emails = $8, polls = $9, reminder_offsets = $10, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2
+		RETURNING updated_at`
+	err = r.pool.QueryRow(ctx, q, t.ID, t.OrganizationID, t.Name, t.Title, t.Description, t.DurationMinutes, t.Visibility,
+		t.SpeakerEmails, polls, t.ReminderOffsets).Scan(&t.UpdatedAt)
+	return nameTaken(err)
+}
+
+// Delete removes an organization's template and its ad rows (their S3 objects are the caller's).
+func (r *Repository) Delete(ctx context.Context, orgID, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM webinar_templates WHERE id = $1 AND organization_id = $2`, id, orgID)
+	return err
+}
+
+func nameTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrNameTaken
+	}
+	return err
+}
+
+// SpeakerEmails returns the addresses of a webinar's speakers and of its pending speaker invitations.
+func (r *Repository) SpeakerEmails(ctx context.Context, webinarID uuid.UUID) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT u.email FROM webinar_speakers ws JOIN users u ON u.id = ws.user_id WHERE ws.webinar_id = $1
+		UNION
+		SELECT email FROM speaker_invitations WHERE webinar_id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		ORDER BY 1`, webinarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	emails := []string{}
+	for rows.Next() {
+		var e string
+		if err := rows.Scan(&e); err != nil {
+			return nil, err
+		}
+		emails = append(emails, e)
+	}
+	return emails, rows.Err()
+}
+
+// ListPolls returns a webinar's polls as prepared polls, oldest first.
+func (r *Repository) ListPolls(ctx context.Context, webinarID uuid.UUID) ([]models.TemplatePoll, error) {
+	rows, err := r.pool.Query(ctx, `SELECT question, option_a, option_b, option_c, option_d
+		FROM polls WHERE webinar_id = $1 ORDER BY created_at`, webinarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	polls := []models.TemplatePoll{}
+	for rows.Next() {
+		var p models.TemplatePoll
+		if err := rows.Scan(&p.Question, &p.OptionA, &p.OptionB, &p.OptionC, &p.OptionD); err != nil {
+			return nil, err
+		}
+		polls = append(polls, p)
+	}
+	return polls, rows.Err()
+}
+
+// ReminderOffsets returns a webinar's own reminder offsets (nil when it inherits).
+func (r *Repository) ReminderOffsets(ctx context.Context, webinarID uuid.UUID) ([]int, error) {
+	var offsets []int
+	err := r.pool.QueryRow(ctx, `SELECT reminder_offsets FROM webinars WHERE id = $1`, webinarID).Scan(&offsets)
+	return offsets, err
+}
+
+// ListAds returns a webinar's ad creatives and its playlist's rotation interval (0 without a playlist).
+func (r *Repository) ListAds(ctx context.Context, webinarID uuid.UUID) ([]models.TemplateAd, int, error) {
+	var interval int
+	err := r.pool.QueryRow(ctx, `SELECT rotation_interval FROM ad_playlists WHERE webinar_id = $1`, webinarID).Scan(&interval)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, 0, err
+	}
+	rows, err := r.pool.Query(ctx, `SELECT id, file_url, file_type, file_size, duration, COALESCE(s3_key, ''), is_active
+		FROM advertisements WHERE webinar_id = $1 ORDER BY created_at`, webinarID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	ads := []models.TemplateAd{}
+	for rows.Next() {
+		var a models.TemplateAd
+		if err := rows.Scan(&a.ID, &a.FileURL, &a.FileType, &a.FileSize, &a.Duration, &a.S3Key, &a.IsActive); err != nil {
+			return nil, 0, err
+		}
+		ads = append(ads, a)
+	}
+	return ads, interval, rows.Err()
+}
+
+// CreateWebinar inserts w with the template's registration form and reminder offsets, its prepared polls and,
+// when it has ads, an ad playlist with its rotation interval. Ads and speakers are added by the caller.
+func (r *Repository) CreateWebinar(ctx context.Context, t *models.WebinarTemplate, w *models.Webinar) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	const q = `INSERT INTO webinars (title, description, starts_at, ends_at, created_by, organization_id, is_paid, ticket_price_cents,
+			ticket_currency, max_audience, category, banner_image_url, audience_form_config, status, visibility, reminder_offsets)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, created_at, updated_at`
+	err = tx.QueryRow(ctx, q, w.Title, w.Description, w.StartsAt, w.EndsAt, w.CreatedBy, w.OrganizationID, w.IsPaid, w.TicketPriceCents,
+		w.TicketCurrency, w.MaxAudience, w.Category, w.BannerImageURL, w.AudienceFormConfig, w.Status, w.Visibility, t.ReminderOffsets).
+		Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	for _, p := range t.Polls {
+		if _, err := tx.Exec(ctx, `INSERT INTO polls (webinar_id, question, option_a, option_b, option_c, option_d, launched, closed)
+			VALUES ($1, $2, $3, $4, $5, $6, FALSE, FALSE)`, w.ID, p.Question, p.OptionA, p.OptionB, p.OptionC, p.OptionD); err != nil {
+			return err
+		}
+	}
+	if len(t.Ads) > 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO ad_playlists (webinar_id, rotation_interval, is_running) VALUES ($1, $2, FALSE)`,
+			w.ID, t.AdRotationInterval); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// AddAdvertisement adds an ad creative to a webinar.
+func (r *Repository) AddAdvertisement(ctx context.Context, webinarID uuid.UUID, a *models.TemplateAd) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO advertisements (webinar_id, file_url, file_type, file_size, duration, s3_key, is_active)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)`, webinarID, a.FileURL, a.FileType, a.FileSize, a.Duration, a.S3Key, a.IsActive)
+	return err
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/analytics"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/streams"
+	"github.com/aura-webinar/backend/pkg/queue"
+)
+
+// AnalyticsProcessor processes analytics jobs: computes a stream session's engagement metrics
+// and stores them as the session's engagement_metrics snapshot.
+type AnalyticsProcessor struct {
+	repo       *analytics.Repository
+	streamRepo *streams.Repository
+	logger     *zap.Logger
+}
+
+// NewAnalyticsProcessor creates an analytics processor.
+func NewAnalyticsProcessor(repo *analytics.Repository, streamRepo *streams.Repository, logger *zap.Logger) *AnalyticsProcessor {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &AnalyticsProcessor{repo: repo, streamRepo: streamRepo, logger: logger}
+}
+
+// Process executes one analytics job (queue.HandlerFunc for JobTypeAnalytics). Re-running it for the
+// same session recomputes and replaces the snapshot.
+func (p *AnalyticsProcessor) Process(ctx context.Context, job *queue.Job) error {
+	if job.Type != queue.JobTypeAnalytics {
+		return queue.Permanent(fmt.Errorf("unknown job type: %s", job.Type))
+	}
+	var payload queue.AnalyticsPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return queue.Permanent(fmt.Errorf("unmarshal payload: %w", err))
+	}
+
+	session, err := p.streamRepo.GetByID(ctx, payload.StreamSessionID)
+	if err != nil {
+		return fmt.Errorf("load stream session: %w", err)
+	}
+	if session == nil || session.WebinarID != payload.WebinarID {
+		return queue.Permanent(fmt.Errorf("stream session not found: %s", payload.StreamSessionID))
+	}
+
+	totals, err := p.repo.ComputeSessionTotals(ctx, session)
+	if err != nil {
+		return fmt.Errorf("compute session totals: %w", err)
+	}
+	m := sessionSnapshot(session, totals)
+	if err := p.repo.SaveSnapshot(ctx, m); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	// Keep the session row consistent with its snapshot (live updates only track peak viewers).
+	if err := p.streamRepo.SetTotals(ctx, session.ID, totals.Attended, totals.TotalWatchSeconds, totals.PollParticipants, totals.Questions); err != nil {
+		p.logger.Warn("update stream session totals failed", zap.Error(err), zap.String("stream_session_id", session.ID.String()))
+	}
+
+	p.logger.Info("engagement snapshot saved",
+		zap.String("webinar_id", session.WebinarID.String()),
+		zap.String("stream_session_id", session.ID.String()),
+		zap.Int("attended", m.TotalAttended))
+	return nil
+}
+
+// sessionSnapshot derives the engagement metrics of a session from its raw totals.
+func sessionSnapshot(session *models.StreamSession, t *analytics.SessionTotals) *models.EngagementMetrics {
+	sessionID := session.ID
+	m := &models.EngagementMetrics{
+		WebinarID:              session.WebinarID,
+		StreamSessionID:        &sessionID,
+		TotalRegistrations:     t.Registrations,
+		TotalAttended:          t.Attended,
+		PeakLiveViewers:        session.PeakViewers,
+		PollParticipationCount: t.PollParticipants,
+		QuestionsCount:         t.Questions,
+	}
+	if noShow := t.Registrations - t.Attended; noShow > 0 {
+		m.TotalNoShow = noShow
+	}
+	if t.Attended > 0 {
+		m.AvgWatchSeconds = t.TotalWatchSeconds / int64(t.Attended)
+		pct := float64(t.PollParticipants) / float64(t.Attended) * 100
+		if pct > 100 {
+			pct = 100
+		}
+		m.PollParticipationPercent = float64(int(pct*100+0.5)) / 100
+	}
+	return m
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/audit"
+)
+
+// AuditRetentionSchedule is when expired audit entries are purged (cron spec for Scheduler).
+const AuditRetentionSchedule = "0 3 * * *"
+
+// AuditRetention deletes audit log entries older than the configured retention.
+type AuditRetention struct {
+	repo      *audit.Repository
+	retention time.Duration
+	logger    *zap.Logger
+}
+
+// NewAuditRetention creates the retention job. retentionDays <= 0 keeps entries forever.
+func NewAuditRetention(repo *audit.Repository, retentionDays int, logger *zap.Logger) *AuditRetention {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &AuditRetention{repo: repo, retention: time.Duration(retentionDays) * 24 * time.Hour, logger: logger}
+}
+
+// Enabled reports whether a retention period is configured.
+func (r *AuditRetention) Enabled() bool {
+	return r.retention > 0
+}
+
+// Purge deletes entries older than the retention period. Run it on AuditRetentionSchedule.
+func (r *AuditRetention) Purge(ctx context.Context) {
+	if !r.Enabled() {
+		return
+	}
+	cutoff := time.Now().Add(-r.retention)
+	n, err := r.repo.DeleteBefore(ctx, cutoff)
+	if err != nil {
+		r.logger.Error("audit retention purge failed", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		r.logger.Info("audit entries purged", zap.Int64("count", n), zap.Time("before", cutoff))
+	}
+}
@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/campaigns"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/queue"
+)
+
+const (
+	// CampaignSchedule is how often CampaignSender.Run starts due campaigns and enqueues the next batch.
+	CampaignSchedule = "* * * * *"
+	// campaignDedupTTL keeps a recipient from being enqueued twice if a run is retried after a crash.
+	campaignDedupTTL = 7 * 24 * time.Hour
+)
+
+// CampaignSender fans campaigns out through the email queue, at most ratePerMinute emails per run
+// shared by all sending campaigns (oldest first), so providers' rate limits are respected.
+type CampaignSender struct {
+	repo          *campaigns.Repository
+	audience      *campaigns.Audience
+	webinarRepo   *webinars.Repository
+	regRepo       *registrations.Repository
+	jobQueue      *queue.Queue
+	frontendURL   string
+	ratePerMinute int
+	logger        *zap.Logger
+}
+
+// NewCampaignSender creates a campaign sender. Run it on CampaignSchedule.
+func NewCampaignSender(repo *campaigns.Repository, audience *campaigns.Audience, webinarRepo *webinars.Repository, regRepo *registrations.Repository, q *queue.Queue, frontendURL string, ratePerMinute int, logger *zap.Logger) *CampaignSender {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if ratePerMinute <= 0 {
+		ratePerMinute = 600
+	}
+	return &CampaignSender{repo: repo, audience: audience, webinarRepo: webinarRepo, regRepo: regRepo, jobQueue: q,
+		frontendURL: frontendURL, ratePerMinute: ratePerMinute, logger: logger}
+}
+
+// Run starts due campaigns (snapshotting their audience) and enqueues up to ratePerMinute emails.
+func (s *CampaignSender) Run(ctx context.Context) {
+	due, err := s.repo.ListDue(ctx, time.Now())
+	if err != nil {
+		s.logger.Error("list due campaigns failed", zap.Error(err))
+		return
+	}
+	for _, c := range due {
+		s.start(ctx, c)
+	}
+
+	sending, err := s.repo.ListSending(ctx)
+	if err != nil {
+		s.logger.Error("list sending campaigns failed", zap.Error(err))
+		return
+	}
+	budget := s.ratePerMinute
+	for _, c := range sending {
+		if budget == 0 || ctx.Err() != nil {
+			return
+		}
+		budget -= s.enqueueBatch(ctx, c, budget)
+	}
+}
+
+func (s *CampaignSender) start(ctx context.Context, c *models.EmailCampaign) {
+	regs, err := s.audience.Resolve(ctx, c.WebinarID, c.Segment)
+	if err != nil {
+		s.logger.Error("resolve campaign audience failed", zap.String("campaign_id", c.ID.String()), zap.Error(err))
+		return
+	}
+	ids := make([]uuid.UUID, len(regs))
+	for i, reg := range regs {
+		ids[i] = reg.ID
+	}
+	started, err := s.repo.Start(ctx, c.ID, ids)
+	if err != nil {
+		s.logger.Error("start campaign failed", zap.String("campaign_id", c.ID.String()), zap.Error(err))
+		return
+	}
+	if started {
+		s.logger.Info("campaign started", zap.String("campaign_id", c.ID.String()), zap.Int("recipients", len(ids)))
+	}
+}
+
+// enqueueBatch enqueues up to limit of the campaign's pending recipients and returns how many it used.
+// The campaign is marked sent once none are left.
+func (s *CampaignSender) enqueueBatch(ctx context.Context, c *models.EmailCampaign, limit int) int {
+	recipients, err := s.repo.PendingRecipients(ctx, c.ID, limit)
+	if err != nil {
+		s.logger.Error("list campaign recipients failed", zap.String("campaign_id", c.ID.String()), zap.Error(err))
+		return 0
+	}
+	if len(recipients) == 0 {
+		if err := s.repo.Complete(ctx, c.ID); err != nil {
+			s.logger.Error("complete campaign failed", zap.String("campaign_id", c.ID.String()), zap.Error(err))
+		} else {
+			s.logger.Info("campaign sent", zap.String("campaign_id", c.ID.String()), zap.Int("recipients", c.RecipientCount))
+		}
+		return 0
+	}
+	w, err := s.webinarRepo.GetByID(ctx, c.WebinarID)
+	if err != nil || w == nil {
+		s.logger.Error("load campaign webinar failed", zap.String("campaign_id", c.ID.String()), zap.Error(err))
+		return 0
+	}
+	used := 0
+	for _, rc := range recipients {
+		payload := queue.EmailPayload{
+			EmailType:       models.EmailTypeCampaign,
+			WebinarID:       w.ID,
+			RegistrationID:  rc.RegistrationID,
+			RecipientEmail:  rc.Email,
+			RecipientName:   rc.FullName,
+			WebinarTitle:    w.Title,
+			WebinarStartsAt: w.StartsAt.Format(time.RFC3339),
+			Locale:          rc.Locale,
+			CampaignID:      c.ID,
+		}
+		if tok, err := s.regRepo.GetLatestTokenForRegistration(ctx, rc.RegistrationID); err == nil && tok != nil {
+			payload.JoinURL = email.BuildJoinURL(s.frontendURL, w.ID.String(), tok.Token)
+		}
+		_, err := s.jobQueue.Enqueue(ctx, queue.JobTypeEmail, payload, queue.Options{
+			DedupKey: "campaign:" + c.ID.String() + ":" + rc.RegistrationID.String(),
+			DedupTTL: campaignDedupTTL,
+		})
+		if err != nil && !errors.Is(err, queue.ErrDuplicate) {
+			s.logger.Warn("enqueue campaign email failed", zap.String("campaign_id", c.ID.String()), zap.Error(err))
+			return used
+		}
+		if err := s.repo.MarkEnqueued(ctx, c.ID, rc.RegistrationID); err != nil {
+			s.logger.Error("mark campaign recipient enqueued failed", zap.String("campaign_id", c.ID.String()), zap.Error(err))
+			return used
+		}
+		used++
+	}
+	return used
+}
@@ -3,42 +3,159 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 
 	"github.com/aura-webinar/backend/config"
+	"github.com/aura-webinar/backend/internal/calendar"
+	"github.com/aura-webinar/backend/internal/campaigns"
 	"github.com/aura-webinar/backend/internal/emaillogs"
+	"github.com/aura-webinar/backend/internal/emailtemplates"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/suppressions"
+	"github.com/aura-webinar/backend/internal/webinars"
 	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/ical"
 	"github.com/aura-webinar/backend/pkg/queue"
 )
 
-// EmailProcessor processes email jobs: send via SMTP, update email_logs.
+// EmailProcessor processes email jobs: send through the configured transport, update email_logs.
 type EmailProcessor struct {
-	emailRepo *emaillogs.Repository
-	queue     *queue.Queue
-	cfg       config.EmailConfig
-	logger    *zap.Logger
+	emailRepo    *emaillogs.Repository
+	webinarRepo  *webinars.Repository
+	renderer     *emailtemplates.Renderer
+	transport    email.Transport
+	suppressions *suppressions.Repository // optional, see SetSuppressions
+	signer       *suppressions.Signer
+	campaigns    *campaigns.Repository // optional, see SetCampaigns
+	calendar     *calendar.Builder
+	cfg          config.EmailConfig
+	logger       *zap.Logger
 }
 
-// NewEmailProcessor creates an email processor.
-func NewEmailProcessor(emailRepo *emaillogs.Repository, q *queue.Queue, cfg config.EmailConfig, logger *zap.Logger) *EmailProcessor {
+// NewEmailProcessor creates an email processor. transport may be nil (see Enabled).
+func NewEmailProcessor(emailRepo *emaillogs.Repository, webinarRepo *webinars.Repository, renderer *emailtemplates.Renderer, transport email.Transport, cfg config.EmailConfig, logger *zap.Logger) *EmailProcessor {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &EmailProcessor{emailRepo: emailRepo, queue: q, cfg: cfg, logger: logger}
+	return &EmailProcessor{emailRepo: emailRepo, webinarRepo: webinarRepo, renderer: renderer, transport: transport,
+		calendar: calendar.NewBuilder(cfg.FromName, cfg.FromAddress), cfg: cfg, logger: logger}
 }
 
-// Process executes one email job.
+// SetSuppressions enables the suppression list check before sending and signed one-click unsubscribe
+// links in organization emails.
+func (p *EmailProcessor) SetSuppressions(repo *suppressions.Repository, signer *suppressions.Signer) {
+	p.suppressions = repo
+	p.signer = signer
+}
+
+// SetCampaigns enables campaign emails (payloads with a CampaignID render the campaign's content).
+func (p *EmailProcessor) SetCampaigns(repo *campaigns.Repository) {
+	p.campaigns = repo
+}
+
+// NewEmailTransport builds the transport selected by cfg.Email.Transport. Returns nil, nil when email
+// is not configured (no SMTP host and no API key).
+func NewEmailTransport(ctx context.Context, cfg *config.Config) (email.Transport, error) {
+	ec := cfg.Email
+	kind := ec.Transport
+	if kind == "" {
+		switch {
+		case ec.APIKey != "":
+			kind = "sendgrid"
+		case ec.SMTPHost != "":
+			kind = "smtp"
+		default:
+			return nil, nil
+		}
+	}
+	switch kind {
+	case "smtp":
+		return email.NewSMTPTransport(email.SMTPConfig{
+			Host:     ec.SMTPHost,
+			Port:     ec.SMTPPort,
+			User:     ec.SMTPUser,
+			Password: ec.SMTPPass,
+			PoolSize: ec.SMTPPoolSize,
+		})
+	case "sendgrid":
+		return email.NewSendGridTransport(ec.APIKey)
+	case "ses":
+		return email.NewSESTransport(ctx, email.SESConfig{
+			Region:          ec.SESRegion,
+			AccessKeyID:     cfg.AWS.AccessKeyID,
+			SecretAccessKey: cfg.AWS.SecretAccessKey,
+		})
+	case "file":
+		return email.NewFileSink(ec.SinkDir)
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_TRANSPORT %q (want smtp, sendgrid, ses or file)", kind)
+	}
+}
+
+// Enabled reports whether a transport is configured; without one email jobs stay queued.
+func (p *EmailProcessor) Enabled() bool {
+	return p.transport != nil
+}
+
+// Close releases the transport's connections.
+func (p *EmailProcessor) Close() error {
+	if p.transport == nil {
+		return nil
+	}
+	return p.transport.Close()
+}
+
+// Process executes one email job (queue.HandlerFunc for JobTypeEmail).
 func (p *EmailProcessor) Process(ctx context.Context, job *queue.Job) error {
 	if job.Type != queue.JobTypeEmail {
-		return fmt.Errorf("unknown job type: %s", job.Type)
+		return queue.Permanent(fmt.Errorf("unknown job type: %s", job.Type))
 	}
 	var payload queue.EmailPayload
 	if err := json.Unmarshal(job.Payload, &payload); err != nil {
-		return fmt.Errorf("unmarshal payload: %w", err)
+		return queue.Permanent(fmt.Errorf("unmarshal payload: %w", err))
+	}
+
+	orgID, err := p.organization(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("load webinar: %w", err)
+	}
+	if payload.UnsubscribeURL == "" && orgID != nil && p.signer != nil && !transactional(payload) {
+		payload.UnsubscribeURL = p.cfg.PublicAPIURL + "/email/unsubscribe?token=" + url.QueryEscape(p.signer.Token(*orgID, payload.RecipientEmail))
+	}
+	var campaign *models.EmailCampaign
+	var campaignID *uuid.UUID
+	if payload.CampaignID != uuid.Nil {
+		if p.campaigns == nil {
+			return queue.Permanent(fmt.Errorf("campaign email %s: campaigns not configured", payload.CampaignID))
+		}
+		if campaign, err = p.campaigns.GetByID(ctx, payload.CampaignID); err != nil {
+			return fmt.Errorf("load campaign: %w", err)
+		}
+		if campaign == nil || campaign.Status == models.CampaignStatusCancelled {
+			p.logger.Info("campaign email skipped: campaign cancelled or deleted", zap.String("campaign_id", payload.CampaignID.String()))
+			return nil
+		}
+		campaignID = &campaign.ID
+	}
+	var suppressed *models.EmailSuppression
+	if p.suppressions != nil {
+		suppressed, err = p.suppressions.Find(ctx, orgID, payload.RecipientEmail, !transactional(payload))
+		if err != nil {
+			return fmt.Errorf("check suppression: %w", err)
+		}
+	}
+
+	subject, body, err := p.render(ctx, orgID, campaign, payload)
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
 	}
 
 	// Create log entry (pending) before sending (skip for verification - no webinar/registration)
@@ -49,140 +166,155 @@ func (p *EmailProcessor) Process(ctx context.Context, job *queue.Job) error {
 	if payload.WebinarID != uuid.Nil {
 		webID = &payload.WebinarID
 	}
-	logEntry, err := p.emailRepo.Create(ctx, webID, regID, payload.EmailType, payload.RecipientEmail, payload.Subject)
+	logEntry, err := p.emailRepo.Create(ctx, webID, regID, campaignID, payload.EmailType, payload.RecipientEmail, subject)
 	if err != nil {
 		p.logger.Warn("create email log failed", zap.Error(err))
 		// Continue to send; log is best-effort
 	}
 
-	cfg := email.Config{
-		Host:     p.cfg.SMTPHost,
-		Port:     p.cfg.SMTPPort,
-		User:     p.cfg.SMTPUser,
-		Password: p.cfg.SMTPPass,
-		From:     p.cfg.FromAddress,
-		FromName:  p.cfg.FromName,
+	if suppressed != nil {
+		if logEntry != nil {
+			_ = p.emailRepo.MarkSuppressed(ctx, logEntry.ID, suppressed.Reason)
+		}
+		p.logger.Info("email suppressed", zap.String("type", payload.EmailType), zap.String("to", payload.RecipientEmail), zap.String("reason", suppressed.Reason))
+		return nil
+	}
+
+	msg := &email.Message{
+		From:    email.Address{Name: p.cfg.FromName, Email: p.cfg.FromAddress},
+		To:      email.Address{Name: payload.RecipientName, Email: payload.RecipientEmail},
+		Subject: subject,
+		HTML:    body,
 	}
-	body := payload.BodyHTML
-	if body == "" {
-		body = p.buildDefaultBody(payload)
+	if logEntry != nil {
+		msg.Headers = map[string]string{"X-Entity-Ref-ID": logEntry.ID.String()}
 	}
-	subject := payload.Subject
-	if subject == "" {
-		subject = p.buildDefaultSubject(payload)
+	p.addUnsubscribe(msg, payload)
+	if err := p.attachInvite(ctx, msg, payload); err != nil {
+		return fmt.Errorf("attach invite: %w", err)
 	}
 
-	if err := email.Send(cfg, payload.RecipientEmail, subject, body); err != nil {
+	messageID, err := p.transport.Send(ctx, msg)
+	if err != nil {
 		p.logger.Error("send email failed", zap.String("to", payload.RecipientEmail), zap.Error(err))
 		if logEntry != nil {
 			_ = p.emailRepo.MarkFailed(ctx, logEntry.ID, err.Error())
 		}
+		if errors.Is(err, email.ErrRejected) {
+			return queue.Permanent(err)
+		}
 		return fmt.Errorf("send: %w", err)
 	}
 
 	if logEntry != nil {
-		_ = p.emailRepo.MarkSent(ctx, logEntry.ID)
+		_ = p.emailRepo.MarkSent(ctx, logEntry.ID, messageID)
 	}
-	p.logger.Info("email sent", zap.String("type", payload.EmailType), zap.String("to", payload.RecipientEmail))
+	p.logger.Info("email sent", zap.String("type", payload.EmailType), zap.String("to", payload.RecipientEmail), zap.String("message_id", messageID))
 	return nil
 }
 
-func (p *EmailProcessor) buildDefaultSubject(payload queue.EmailPayload) string {
-	switch payload.EmailType {
-	case "email_verification":
-		return "Verify your email address"
-	case "speaker_invitation":
-		return fmt.Sprintf("You're invited to speak: %s", payload.WebinarTitle)
-	case "registration_confirmation":
-		return fmt.Sprintf("You're registered: %s", payload.WebinarTitle)
-	case "reminder_24h":
-		return fmt.Sprintf("Reminder: %s starts tomorrow", payload.WebinarTitle)
-	case "reminder_1h":
-		return fmt.Sprintf("Starting soon: %s", payload.WebinarTitle)
-	case "reminder_10m":
-		return fmt.Sprintf("Join now: %s", payload.WebinarTitle)
-	default:
-		return payload.WebinarTitle
+// organization returns the organization of the payload's webinar (nil for account emails).
+func (p *EmailProcessor) organization(ctx context.Context, payload queue.EmailPayload) (*uuid.UUID, error) {
+	if payload.WebinarID == uuid.Nil {
+		return nil, nil
 	}
+	w, err := p.webinarRepo.GetByID(ctx, payload.WebinarID)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+	if w == nil {
+		return nil, nil
+	}
+	return w.OrganizationID, nil
 }
 
-func (p *EmailProcessor) buildDefaultBody(payload queue.EmailPayload) string {
-	name := payload.RecipientName
-	if name == "" {
-		name = "there"
-	}
-	html := fmt.Sprintf(`<!DOCTYPE html><html><body style="font-family:sans-serif;max-width:600px;margin:0 auto;padding:20px;">
-<h2>%s</h2>
-<p>Hi %s,</p>`, p.buildDefaultSubject(payload), name)
-	switch payload.EmailType {
-	case "email_verification":
-		html += fmt.Sprintf(`<p>Please verify your email by clicking the link below:</p>
-<p><a href="%s" style="display:inline-block;padding:12px 24px;background:#0ea5e9;color:white;text-decoration:none;border-radius:8px;">Verify email</a></p>
-<p style="word-break:break-all;font-size:12px;color:#666;">%s</p>
-<p>This link expires in 24 hours.</p>`, payload.VerifyURL, payload.VerifyURL)
-	case "speaker_invitation":
-		html += fmt.Sprintf(`<p>You've been invited to speak at <strong>%s</strong>.</p>
-<p><a href="%s" style="display:inline-block;padding:12px 24px;background:#0ea5e9;color:white;text-decoration:none;border-radius:8px;">Accept invitation</a></p>
-<p style="word-break:break-all;font-size:12px;color:#666;">%s</p>
-<p>Create an account or sign in to join as a speaker.</p>`, payload.WebinarTitle, payload.InviteURL, payload.InviteURL)
-	case "registration_confirmation":
-		html += fmt.Sprintf(`<p>You're registered for <strong>%s</strong>.</p>
-<p><strong>When:</strong> %s</p>
-<p>Save your personal join link:</p>
-<p><a href="%s" style="display:inline-block;padding:12px 24px;background:#0ea5e9;color:white;text-decoration:none;border-radius:8px;">Join webinar</a></p>
-<p style="word-break:break-all;font-size:12px;color:#666;">%s</p>
-<p>We'll send you a reminder before the webinar starts.</p>`,
-			payload.WebinarTitle, payload.WebinarStartsAt, payload.JoinURL, payload.JoinURL)
-	case "reminder_24h", "reminder_1h", "reminder_10m":
-		html += fmt.Sprintf(`<p><strong>%s</strong> starts %s.</p>
-<p><a href="%s" style="display:inline-block;padding:12px 24px;background:#0ea5e9;color:white;text-decoration:none;border-radius:8px;">Join now</a></p>`,
-			payload.WebinarTitle, payload.WebinarStartsAt, payload.JoinURL)
-	default:
-		url := payload.JoinURL
-		if payload.InviteURL != "" {
-			url = payload.InviteURL
-		}
-		if url == "" {
-			url = payload.VerifyURL
+// render builds subject and HTML from the campaign's content or else the organization's (or default) template
+// in the recipient's locale. A payload Subject or BodyHTML replaces the rendered one.
+func (p *EmailProcessor) render(ctx context.Context, orgID *uuid.UUID, campaign *models.EmailCampaign, payload queue.EmailPayload) (string, string, error) {
+	data := emailtemplates.Data{
+		RecipientName:  payload.RecipientName,
+		WebinarTitle:   payload.WebinarTitle,
+		JoinURL:        payload.JoinURL,
+		VerifyURL:      payload.VerifyURL,
+		InviteURL:      payload.InviteURL,
+		UnsubscribeURL: payload.UnsubscribeURL,
+	}
+	if t, err := time.Parse(time.RFC3339, payload.WebinarStartsAt); err == nil {
+		data.StartsAt = emailtemplates.FormatTime(t, payload.Locale)
+	} else {
+		data.StartsAt = payload.WebinarStartsAt
+	}
+	var out *emailtemplates.Rendered
+	var err error
+	if campaign != nil {
+		locale := email.NormalizeLocale(payload.Locale)
+		if locale == "" {
+			locale = emailtemplates.DefaultLocale
 		}
-		html += fmt.Sprintf(`<p>%s</p><p><a href="%s">Continue</a></p>`, payload.WebinarTitle, url)
+		out, err = p.renderer.RenderDraft(ctx, orgID, payload.EmailType, locale, campaign.Subject, campaign.BodyHTML, data)
+	} else {
+		out, err = p.renderer.Render(ctx, orgID, payload.EmailType, payload.Locale, data)
+	}
+	if err != nil {
+		return "", "", err
 	}
-	html += `</body></html>`
-	return html
+	subject, body := out.Subject, out.HTML
+	if payload.Subject != "" {
+		subject = payload.Subject
+	}
+	if payload.BodyHTML != "" {
+		body = payload.BodyHTML
+	}
+	return subject, body, nil
 }
 
-// Run starts the worker loop.
-func (p *EmailProcessor) Run(ctx context.Context) {
-	if p.cfg.SMTPHost == "" || p.cfg.SMTPUser == "" || p.cfg.SMTPPass == "" {
-		p.logger.Warn("email worker disabled: SMTP not configured")
-		return
+// attachInvite attaches the webinar's .ics invite when the payload asks for one (CalendarMethod). The event
+// is built from the webinar as it is now, so a delayed email never carries a stale time.
+func (p *EmailProcessor) attachInvite(ctx context.Context, msg *email.Message, payload queue.EmailPayload) error {
+	if payload.CalendarMethod == "" || payload.WebinarID == uuid.Nil {
+		return nil
 	}
-	for {
-		select {
-		case <-ctx.Done():
-			p.logger.Info("email worker stopping")
-			return
-		default:
-		}
+	w, err := p.webinarRepo.GetByID(ctx, payload.WebinarID)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cal := p.calendar.Invite(w, payload.RegistrationID, payload.JoinURL, ical.Person{Name: payload.RecipientName, Email: payload.RecipientEmail}, payload.CalendarMethod)
+	msg.Attachments = append(msg.Attachments, email.Attachment{
+		Filename:    calendar.InviteFilename,
+		ContentType: ical.ContentType(cal.Method),
+		Data:        cal.Bytes(),
+	})
+	return nil
+}
 
-		job, _, err := p.queue.DequeueEmail(ctx)
-		if err != nil {
-			p.logger.Warn("dequeue email error", zap.Error(err))
-			time.Sleep(queue.RetryBackoff)
-			continue
-		}
-		if job == nil {
-			continue
-		}
+// transactionalTypes are emails the recipient asked for or needs about a webinar they are registered for.
+var transactionalTypes = map[string]bool{
+	models.EmailTypeEmailVerification:        true,
+	models.EmailTypeSpeakerInvitation:        true,
+	models.EmailTypeRegistrationConfirmation: true,
+	models.EmailTypeWebinarCancelled:         true,
+	models.EmailTypeWebinarRescheduled:       true,
+}
 
-		p.logger.Debug("processing email job", zap.String("job_id", job.ID), zap.String("type", string(job.Type)))
-		if err := p.Process(ctx, job); err != nil {
-			p.logger.Error("email job failed", zap.String("job_id", job.ID), zap.Error(err))
-			if reErr := p.queue.RetryEmail(ctx, job); reErr != nil {
-				p.logger.Error("retry enqueue failed", zap.Error(reErr))
-			}
-			time.Sleep(queue.RetryBackoff)
-			continue
-		}
+// transactional reports whether the email is sent regardless of unsubscribes (and carries no unsubscribe
+// link): a transactional type, or any mail carrying a calendar invite, update or cancellation.
+func transactional(payload queue.EmailPayload) bool {
+	return transactionalTypes[payload.EmailType] || payload.CalendarMethod != ""
+}
+
+// addUnsubscribe sets List-Unsubscribe on webinar mail; transactional mail has none.
+func (p *EmailProcessor) addUnsubscribe(msg *email.Message, payload queue.EmailPayload) {
+	if transactional(payload) {
+		return
+	}
+	if strings.HasPrefix(payload.UnsubscribeURL, "https://") {
+		msg.ListUnsubscribe = append(msg.ListUnsubscribe, payload.UnsubscribeURL)
+		msg.OneClickUnsubscribe = true
+	}
+	if p.cfg.UnsubscribeMailto != "" {
+		msg.ListUnsubscribe = append(msg.ListUnsubscribe, "mailto:"+p.cfg.UnsubscribeMailto+"?subject=unsubscribe")
 	}
 }
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"time"
+
+	"github.com/aura-webinar/backend/pkg/queue"
+)
+
+// HealthCheck probes one dependency (database, Redis); a non-nil error makes the worker not ready.
+type HealthCheck func(ctx context.Context) error
+
+// HealthHandler serves the worker's probes:
+//   - GET /healthz: 200 while the process is up (liveness).
+//   - GET /readyz: 200 while the consumer takes jobs and every check passes; 503 while draining or degraded.
+//   - GET /debug/vars: expvar gauges (queue_dlq_depth, ...).
+func HealthHandler(consumer *queue.Consumer, checks map[string]HealthCheck) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		status, code := "ready", http.StatusOK
+		switch {
+		case consumer.Draining():
+			status, code = "draining", http.StatusServiceUnavailable
+		case !consumer.Ready():
+			status, code = "starting", http.StatusServiceUnavailable
+		}
+		results := make(map[string]string, len(checks))
+		for name, check := range checks {
+			if err := check(ctx); err != nil {
+				results[name] = err.Error()
+				if code == http.StatusOK {
+					status, code = "degraded", http.StatusServiceUnavailable
+				}
+				continue
+			}
+			results[name] = "ok"
+		}
+		writeJSON(w, code, map[string]interface{}{
+			"status":    status,
+			"in_flight": consumer.InFlight(),
+			"checks":    results,
+		})
+	})
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
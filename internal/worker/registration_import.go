@@ -0,0 +1,284 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/internal/forms"
+	"github.com/aura-webinar/backend/internal/imports"
+	"github.com/aura-webinar/backend/internal/models"
+	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/internal/waitlist"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/email"
+	"github.com/aura-webinar/backend/pkg/ical"
+	"github.com/aura-webinar/backend/pkg/queue"
+)
+
+// maxImportNameLength matches registrations.full_name.
+const maxImportNameLength = 255
+
+// ImportProcessor registers the rows of a bulk registration import: rows are validated against the
+// registration form, de-duplicated against existing registrations and earlier rows, and sent to the waitlist
+// once the webinar is full. Each row's outcome is recorded as it is processed, so a retried job resumes
+// after the last recorded row.
+type ImportProcessor struct {
+	repo         *imports.Repository
+	webinarRepo  *webinars.Repository
+	regRepo      *registrations.Repository
+	waitlistRepo *waitlist.Repository
+	jobQueue     *queue.Queue
+	frontendURL  string
+	logger       *zap.Logger
+}
+
+// NewImportProcessor creates a registration import processor.
+func NewImportProcessor(
+	repo *imports.Repository,
+	webinarRepo *webinars.Repository,
+	regRepo *registrations.Repository,
+	waitlistRepo *waitlist.Repository,
+	q *queue.Queue,
+	frontendURL string,
+	logger *zap.Logger,
+) *ImportProcessor {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ImportProcessor{
+		repo:         repo,
+		webinarRepo:  webinarRepo,
+		regRepo:      regRepo,
+		waitlistRepo: waitlistRepo,
+		jobQueue:     q,
+		frontendURL:  frontendURL,
+		logger:       logger,
+	}
+}
+
+// importRun is the state of one pass over an import's file.
+type importRun struct {
+	imp       *models.RegistrationImport
+	webinar   *models.Webinar
+	fields    []models.FormFieldConfig
+	seen      map[string]bool // lowercased emails of earlier valid rows in this pass
+	total     int             // registrations, for the capacity check
+	lastRow   int             // rows up to this line were processed by an earlier attempt
+	processed int
+}
+
+// Process executes one import job (queue.HandlerFunc for JobTypeImport). An import that cannot run
+// (its webinar or form changed so the file no longer maps) fails permanently; other errors are retried,
+// and the import is marked failed when the job runs out of attempts.
+func (p *ImportProcessor) Process(ctx context.Context, job *queue.Job) error {
+	if job.Type != queue.JobTypeImport {
+		return queue.Permanent(fmt.Errorf("unknown job type: %s", job.Type))
+	}
+	var payload queue.ImportPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return queue.Permanent(fmt.Errorf("unmarshal payload: %w", err))
+	}
+	imp, err := p.repo.GetByID(ctx, payload.ImportID)
+	if err != nil {
+		return fmt.Errorf("load import: %w", err)
+	}
+	if imp == nil || imp.Status == models.ImportStatusCompleted {
+		return nil
+	}
+
+	err = p.run(ctx, imp)
+	if err == nil {
+		if err := p.repo.Complete(ctx, imp.ID); err != nil {
+			return fmt.Errorf("complete import: %w", err)
+		}
+		return nil
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+	maxAttempts := job.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = queue.MaxRetries
+	}
+	if queue.IsPermanent(err) || job.Attempt+1 >= maxAttempts {
+		if failErr := p.repo.Fail(context.Background(), imp.ID, err.Error()); failErr != nil {
+			p.logger.Error("mark import failed", zap.String("import_id", imp.ID.String()), zap.Error(failErr))
+		}
+	}
+	return err
+}
+
+func (p *ImportProcessor) run(ctx context.Context, imp *models.RegistrationImport) error {
+	lastRow, err := p.repo.Start(ctx, imp.ID)
+	if err != nil {
+		return fmt.Errorf("start import: %w", err)
+	}
+	w, err := p.webinarRepo.GetByID(ctx, imp.WebinarID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return queue.Permanent(errors.New("webinar not found"))
+	}
+	if err != nil {
+		return fmt.Errorf("load webinar: %w", err)
+	}
+	if w.Status != models.WebinarStatusDraft && !w.Published() {
+		return queue.Permanent(fmt.Errorf("the webinar is %s", w.Status))
+	}
+	fields, err := forms.ParseConfig(w.AudienceFormConfig)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("registration form: %w", err))
+	}
+	source, err := p.repo.Source(ctx, imp.ID)
+	if err != nil {
+		return fmt.Errorf("load file: %w", err)
+	}
+	header, err := imports.NewReader(source).Read()
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("read file: %w", err))
+	}
+	cols, err := imports.ResolveColumns(header, fields, imp.Mapping)
+	if err != nil {
+		// The registration form changed since upload.
+		return queue.Permanent(err)
+	}
+	total, _, err := p.regRepo.CountByWebinar(ctx, w.ID)
+	if err != nil {
+		return fmt.Errorf("count registrations: %w", err)
+	}
+
+	r := &importRun{imp: imp, webinar: w, fields: imports.ImportableFields(fields), seen: make(map[string]bool), total: total, lastRow: lastRow}
+	err = imports.Each(source, cols, func(row *imports.Row) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if row.Line <= r.lastRow {
+			return nil
+		}
+		res := p.importRow(ctx, r, row)
+		if res == nil {
+			return fmt.Errorf("row %d: import failed", row.Line)
+		}
+		if res.Status != models.ImportRowInvalid {
+			r.seen[strings.ToLower(row.Email)] = true
+		}
+		if err := p.repo.RecordRow(ctx, imp.ID, res); err != nil {
+			return fmt.Errorf("record row %d: %w", row.Line, err)
+		}
+		r.processed++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	p.logger.Info("registration import finished", zap.String("import_id", imp.ID.String()), zap.String("webinar", w.Title), zap.Int("rows", r.processed))
+	return nil
+}
+
+// importRow registers one row and returns its outcome, or nil after a storage error (the job is retried
+// from this row).
+func (p *ImportProcessor) importRow(ctx context.Context, r *importRun, row *imports.Row) *models.ImportRowResult {
+	res := &models.ImportRowResult{Row: row.Line, Email: row.Email}
+	invalid := func(msg string) *models.ImportRowResult {
+		res.Status, res.Message = models.ImportRowInvalid, msg
+		return res
+	}
+	if row.Email == "" {
+		return invalid("email is required")
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Name != "" || addr.Address != row.Email {
+		return invalid("email is not a valid address")
+	}
+	if row.FullName == "" {
+		return invalid("full name is required")
+	}
+	if len([]rune(row.FullName)) > maxImportNameLength {
+		return invalid(fmt.Sprintf("full name must be at most %d characters", maxImportNameLength))
+	}
+	answers, fieldErrs := forms.ValidateResponses(r.fields, row.Answers, forms.Options{})
+	if len(fieldErrs) > 0 {
+		msgs := make([]string, len(fieldErrs))
+		for i, fe := range fieldErrs {
+			msgs[i] = fe.Message
+		}
+		return invalid(strings.Join(msgs, "; "))
+	}
+	if r.seen[strings.ToLower(row.Email)] {
+		res.Status, res.Message = models.ImportRowDuplicate, "repeated earlier in the file"
+		return res
+	}
+	existing, err := p.regRepo.FindByEmail(ctx, r.webinar.ID, row.Email)
+	if err != nil {
+		p.logger.Error("find registration failed", zap.Error(err))
+		return nil
+	}
+	if existing != nil {
+		res.Status, res.Message, res.RegistrationID = models.ImportRowDuplicate, "already registered", &existing.ID
+		return res
+	}
+
+	var extraData json.RawMessage
+	if len(answers) > 0 {
+		if extraData, err = json.Marshal(answers); err != nil {
+			return invalid("invalid form answers")
+		}
+	}
+	if w := r.webinar; w.MaxAudience != nil && *w.MaxAudience > 0 && r.total >= *w.MaxAudience {
+		entry := &waitlist.Entry{WebinarID: w.ID, Email: row.Email, FullName: row.FullName, ExtraData: extraData}
+		if err := p.waitlistRepo.Create(ctx, entry); err != nil {
+			p.logger.Error("create waitlist entry failed", zap.Error(err))
+			return nil
+		}
+		res.Status, res.Message = models.ImportRowWaitlisted, "the webinar is full"
+		return res
+	}
+
+	reg := &models.Registration{
+		WebinarID: r.webinar.ID,
+		Email:     row.Email,
+		FullName:  row.FullName,
+		ExtraData: extraData,
+		Locale:    email.NormalizeLocale(row.Locale),
+	}
+	if err := p.regRepo.CreateRegistration(ctx, reg); err != nil {
+		p.logger.Error("create registration failed", zap.Error(err))
+		return nil
+	}
+	r.total++
+	res.Status, res.RegistrationID = models.ImportRowCreated, &reg.ID
+	tok, err := p.regRepo.IssueToken(ctx, reg.ID)
+	if err != nil {
+		p.logger.Error("create token failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+		return nil
+	}
+	if r.imp.SendConfirmations && p.frontendURL != "" {
+		payload := queue.EmailPayload{
+			EmailType:       models.EmailTypeRegistrationConfirmation,
+			WebinarID:       r.webinar.ID,
+			RegistrationID:  reg.ID,
+			RecipientEmail:  reg.Email,
+			RecipientName:   reg.FullName,
+			WebinarTitle:    r.webinar.Title,
+			WebinarStartsAt: r.webinar.StartsAt.Format(time.RFC3339),
+			JoinURL:         email.BuildJoinURL(p.frontendURL, r.webinar.ID.String(), tok.Token),
+			Locale:          reg.Locale,
+			CalendarMethod:  ical.MethodRequest,
+		}
+		_, err := p.jobQueue.Enqueue(ctx, queue.JobTypeEmail, payload, queue.Options{
+			DedupKey: "import:" + reg.ID.String(),
+			DedupTTL: 24 * time.Hour,
+		})
+		if err != nil && !errors.Is(err, queue.ErrDuplicate) {
+			// Registered; only the email is missing.
+			p.logger.Warn("enqueue confirmation email failed", zap.Error(err), zap.String("registration_id", reg.ID.String()))
+			res.Message = "confirmation email could not be queued"
+		}
+	}
+	return res
+}
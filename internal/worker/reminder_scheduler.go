@@ -2,151 +2,189 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 
-	"github.com/aura-webinar/backend/internal/emaillogs"
 	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/internal/reminders"
 	"github.com/aura-webinar/backend/internal/webinars"
 	"github.com/aura-webinar/backend/pkg/email"
 	"github.com/aura-webinar/backend/pkg/queue"
 )
 
 const (
-	// Scheduler runs every 5 minutes.
-	reminderCheckInterval = 5 * time.Minute
-	// Time window: ±5 minutes around target (e.g. 24h before = 23h55m to 24h5m).
-	reminderWindowMargin = 5 * time.Minute
+	// ReminderSchedule is how often the reminder sweep runs (cron spec for Scheduler).
+	ReminderSchedule = "*/5 * * * *"
+	// reminderSweepBatch bounds the webinars planned and the overdue reminders re-enqueued per sweep.
+	reminderSweepBatch = 200
+	// reminderOverdueGrace is how late a reminder job may be before the sweep re-enqueues it.
+	reminderOverdueGrace = 5 * time.Minute
+	// reminderRequeueTTL stops later sweeps from re-enqueueing the same overdue reminder while the previous
+	// re-enqueued job can still run.
+	reminderRequeueTTL = 10 * time.Minute
 )
 
-// ReminderScheduler enqueues reminder emails for webinars starting soon.
-type ReminderScheduler struct {
-	webinarRepo   *webinars.Repository
-	regRepo       *registrations.Repository
-	emailLogsRepo *emaillogs.Repository
-	jobQueue      *queue.Queue
-	frontendURL   string
-	logger        *zap.Logger
+// ReminderProcessor processes reminder jobs: when a planned reminder is due it enqueues one email per
+// registration. Reminders are planned by reminders.Planner when a webinar is created or rescheduled.
+type ReminderProcessor struct {
+	repo        *reminders.Repository
+	webinarRepo *webinars.Repository
+	regRepo     *registrations.Repository
+	jobQueue    *queue.Queue
+	frontendURL string
+	logger      *zap.Logger
 }
 
-// NewReminderScheduler creates a reminder scheduler.
-func NewReminderScheduler(
+// NewReminderProcessor creates a reminder processor.
+func NewReminderProcessor(
+	repo *reminders.Repository,
 	webinarRepo *webinars.Repository,
 	regRepo *registrations.Repository,
-	emailLogsRepo *emaillogs.Repository,
 	q *queue.Queue,
 	frontendURL string,
 	logger *zap.Logger,
-) *ReminderScheduler {
+) *ReminderProcessor {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &ReminderScheduler{
-		webinarRepo:   webinarRepo,
-		regRepo:       regRepo,
-		emailLogsRepo: emailLogsRepo,
-		jobQueue:      q,
-		frontendURL:   frontendURL,
-		logger:        logger,
+	return &ReminderProcessor{
+		repo:        repo,
+		webinarRepo: webinarRepo,
+		regRepo:     regRepo,
+		jobQueue:    q,
+		frontendURL: frontendURL,
+		logger:      logger,
 	}
 }
 
-// Run starts the scheduler loop.
-func (s *ReminderScheduler) Run(ctx context.Context) {
-	ticker := time.NewTicker(reminderCheckInterval)
-	defer ticker.Stop()
-
-	// Run once on start (after a short delay to let server settle)
-	time.Sleep(30 * time.Second)
-	s.checkAndEnqueue(ctx)
+// Process executes one reminder job (queue.HandlerFunc for JobTypeReminder). Jobs of cancelled plans and
+// reminders whose webinar moved since planning are dropped; a retry re-enqueues only missing emails.
+func (p *ReminderProcessor) Process(ctx context.Context, job *queue.Job) error {
+	if job.Type != queue.JobTypeReminder {
+		return queue.Permanent(fmt.Errorf("unknown job type: %s", job.Type))
+	}
+	var payload queue.ReminderPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return queue.Permanent(fmt.Errorf("unmarshal payload: %w", err))
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("reminder scheduler stopping")
-			return
-		case <-ticker.C:
-			s.checkAndEnqueue(ctx)
+	m, err := p.repo.GetByID(ctx, payload.ReminderID)
+	if err != nil {
+		return fmt.Errorf("load reminder: %w", err)
+	}
+	if m == nil || m.Status != models.ReminderStatusScheduled || !m.SendAt.Equal(payload.SendAt) {
+		p.logger.Debug("stale reminder job dropped", zap.String("reminder_id", payload.ReminderID.String()))
+		return nil
+	}
+	w, err := p.webinarRepo.GetByID(ctx, m.WebinarID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load webinar: %w", err)
+	}
+	// Moved since planning (a replacement is planned for the new start time), already started, or not
+	// scheduled (a draft is planned again when published; cancelled webinars send no reminders).
+	if !w.StartsAt.Add(-time.Duration(m.OffsetMinutes)*time.Minute).Equal(m.SendAt) || !time.Now().Before(w.StartsAt) ||
+		w.Status != models.WebinarStatusScheduled {
+		if _, err := p.repo.Finish(ctx, m.ID, models.ReminderStatusSkipped, 0); err != nil {
+			return fmt.Errorf("skip reminder: %w", err)
 		}
+		return nil
 	}
-}
-
-func (s *ReminderScheduler) checkAndEnqueue(ctx context.Context) {
-	now := time.Now()
-
-	// 24h reminder: webinars starting between 23h55m and 24h5m from now
-	s.enqueueForWindow(ctx, now, 24*time.Hour, models.EmailTypeReminder24h)
-
-	// 1h reminder: webinars starting between 55m and 1h5m from now
-	s.enqueueForWindow(ctx, now, 1*time.Hour, models.EmailTypeReminder1h)
-
-	// 10m reminder: webinars starting between 5m and 15m from now
-	s.enqueueForWindow(ctx, now, 10*time.Minute, models.EmailTypeReminder10m)
-}
 
-func (s *ReminderScheduler) enqueueForWindow(ctx context.Context, now time.Time, targetOffset time.Duration, emailType string) {
-	windowStart := now.Add(targetOffset - reminderWindowMargin)
-	windowEnd := now.Add(targetOffset + reminderWindowMargin)
-
-	webinars, err := s.webinarRepo.ListStartingInWindow(ctx, windowStart, windowEnd)
+	regs, err := p.regRepo.ListByWebinar(ctx, w.ID)
 	if err != nil {
-		s.logger.Error("list webinars for reminder failed", zap.Error(err), zap.String("type", emailType))
-		return
+		return fmt.Errorf("list registrations: %w", err)
 	}
-
-	for _, w := range webinars {
-		regs, err := s.regRepo.ListByWebinar(ctx, w.ID)
-		if err != nil {
-			s.logger.Warn("list registrations failed", zap.String("webinar_id", w.ID.String()), zap.Error(err))
+	enqueued := 0
+	for _, reg := range regs {
+		tok, err := p.regRepo.GetLatestTokenForRegistration(ctx, reg.ID)
+		if err != nil || tok == nil {
+			p.logger.Debug("no valid token for registration", zap.String("registration_id", reg.ID.String()))
+			continue
+		}
+		emailJob := queue.EmailPayload{
+			EmailType:       m.EmailType,
+			WebinarID:       w.ID,
+			RegistrationID:  reg.ID,
+			RecipientEmail:  reg.Email,
+			RecipientName:   reg.FullName,
+			WebinarTitle:    w.Title,
+			WebinarStartsAt: w.StartsAt.Format(time.RFC3339),
+			JoinURL:         email.BuildJoinURL(p.frontendURL, w.ID.String(), tok.Token),
+			Locale:          reg.Locale,
+		}
+		// One reminder of each type per registration and start time, however often this job is retried.
+		_, err = p.jobQueue.Enqueue(ctx, queue.JobTypeEmail, emailJob, queue.Options{
+			DedupKey: ReminderIdempotencyKey(reg.ID.String(), m.EmailType, w.StartsAt),
+			DedupTTL: time.Until(w.StartsAt) + time.Hour,
+		})
+		if errors.Is(err, queue.ErrDuplicate) {
 			continue
 		}
+		if err != nil {
+			return fmt.Errorf("enqueue reminder email: %w", err)
+		}
+		enqueued++
+	}
+	if _, err := p.repo.Finish(ctx, m.ID, models.ReminderStatusSent, len(regs)); err != nil {
+		return fmt.Errorf("finish reminder: %w", err)
+	}
+	p.logger.Info("enqueued reminders", zap.String("type", m.EmailType), zap.String("webinar", w.Title), zap.Int("emails", enqueued))
+	return nil
+}
 
-		for _, reg := range regs {
-			sent, err := s.emailLogsRepo.AlreadySent(ctx, reg.ID, emailType)
-			if err != nil || sent {
-				continue
-			}
+// ReminderIdempotencyKey is the enqueue dedup key for a reminder of emailType to a registration for a
+// webinar starting at startsAt (a rescheduled webinar sends its reminders again).
+func ReminderIdempotencyKey(registrationID, emailType string, startsAt time.Time) string {
+	return "reminder:" + registrationID + ":" + emailType + ":" + strconv.FormatInt(startsAt.Unix(), 10)
+}
 
-			tok, err := s.regRepo.GetLatestTokenForRegistration(ctx, reg.ID)
-			if err != nil || tok == nil {
-				s.logger.Debug("no valid token for registration", zap.String("registration_id", reg.ID.String()))
-				continue
-			}
+// ReminderScheduler is the safety net behind planned reminders: it plans upcoming webinars whose plan is
+// missing or stale (created before planning existed, a failed plan after an update, a new organization
+// default) and re-enqueues reminders whose delayed job never ran.
+type ReminderScheduler struct {
+	repo    *reminders.Repository
+	planner *reminders.Planner
+	logger  *zap.Logger
+}
 
-			joinURL := email.BuildJoinURL(s.frontendURL, w.ID.String(), tok.Token)
-			payload := queue.EmailPayload{
-				EmailType:       emailType,
-				WebinarID:       w.ID,
-				RegistrationID:  reg.ID,
-				RecipientEmail:  reg.Email,
-				RecipientName:   reg.FullName,
-				WebinarTitle:    w.Title,
-				WebinarStartsAt: w.StartsAt.Format(time.RFC3339),
-				JoinURL:         joinURL,
-				Subject:         s.subjectForType(emailType, w.Title),
-			}
+// NewReminderScheduler creates a reminder scheduler.
+func NewReminderScheduler(repo *reminders.Repository, planner *reminders.Planner, logger *zap.Logger) *ReminderScheduler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ReminderScheduler{repo: repo, planner: planner, logger: logger}
+}
 
-			if err := s.jobQueue.EnqueueEmail(ctx, payload); err != nil {
-				s.logger.Warn("enqueue reminder failed", zap.String("email", reg.Email), zap.Error(err))
-				continue
-			}
-			s.logger.Info("enqueued reminder", zap.String("type", emailType), zap.String("webinar", w.Title), zap.String("to", reg.Email))
+// Sweep plans unplanned webinars and re-enqueues overdue reminders. Run it on ReminderSchedule.
+func (s *ReminderScheduler) Sweep(ctx context.Context) {
+	ids, err := s.repo.ListUnplanned(ctx, reminderSweepBatch)
+	if err != nil {
+		s.logger.Error("list unplanned webinars failed", zap.Error(err))
+	}
+	for _, id := range ids {
+		if err := s.planner.Plan(ctx, id); err != nil {
+			s.logger.Warn("plan reminders failed", zap.String("webinar_id", id.String()), zap.Error(err))
 		}
 	}
-}
 
-func (s *ReminderScheduler) subjectForType(emailType, title string) string {
-	switch emailType {
-	case models.EmailTypeReminder24h:
-		return fmt.Sprintf("Reminder: %s starts tomorrow", title)
-	case models.EmailTypeReminder1h:
-		return fmt.Sprintf("Starting soon: %s", title)
-	case models.EmailTypeReminder10m:
-		return fmt.Sprintf("Join now: %s", title)
-	default:
-		return title
+	overdue, err := s.repo.ListOverdue(ctx, time.Now().Add(-reminderOverdueGrace), reminderSweepBatch)
+	if err != nil {
+		s.logger.Error("list overdue reminders failed", zap.Error(err))
+		return
+	}
+	for _, m := range overdue {
+		if err := s.planner.Requeue(ctx, m, reminderRequeueTTL); err != nil {
+			s.logger.Warn("re-enqueue reminder failed", zap.String("reminder_id", m.ID.String()), zap.Error(err))
+		}
 	}
 }
@@ -0,0 +1,173 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/aura-webinar/backend/config"
+	"github.com/aura-webinar/backend/internal/analytics"
+	"github.com/aura-webinar/backend/internal/audit"
+	"github.com/aura-webinar/backend/internal/calendar"
+	"github.com/aura-webinar/backend/internal/campaigns"
+	"github.com/aura-webinar/backend/internal/emaillogs"
+	"github.com/aura-webinar/backend/internal/emailtemplates"
+	"github.com/aura-webinar/backend/internal/imports"
+	"github.com/aura-webinar/backend/internal/lifecycle"
+	"github.com/aura-webinar/backend/internal/payments"
+	"github.com/aura-webinar/backend/internal/realtime"
+	"github.com/aura-webinar/backend/internal/recordings"
+	"github.com/aura-webinar/backend/internal/registrations"
+	"github.com/aura-webinar/backend/internal/reminders"
+	"github.com/aura-webinar/backend/internal/series"
+	"github.com/aura-webinar/backend/internal/sessionlog"
+	"github.com/aura-webinar/backend/internal/streams"
+	"github.com/aura-webinar/backend/internal/suppressions"
+	"github.com/aura-webinar/backend/internal/waitlist"
+	"github.com/aura-webinar/backend/internal/webinars"
+	"github.com/aura-webinar/backend/pkg/leader"
+	"github.com/aura-webinar/backend/pkg/queue"
+	"github.com/aura-webinar/backend/pkg/storage"
+)
+
+// Runner runs every background processor: queue job handlers on one consumer (recordings, emails,
+// analytics, reminders) and scheduled tasks (reminder sweep, campaigns, series occurrences, audit retention) that only the elected leader replica runs.
+// New processors are registered in NewRunner.
+type Runner struct {
+	consumer  *queue.Consumer
+	scheduler *Scheduler
+	elector   *leader.Elector
+	closers   []func() error
+	logger    *zap.Logger
+}
+
+// NewRunner wires all processors from config. Processors whose dependencies are missing are skipped
+// (recordings without S3, emails without a transport) so their jobs wait for a worker that has them.
+func NewRunner(cfg *config.Config, pool *pgxpool.Pool, rdb *redis.Client, q *queue.Queue, s3 *storage.S3, logger *zap.Logger) *Runner {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	elector := leader.NewElector(rdb, "scheduler", cfg.Worker.LeaderLeaseTTL, logger)
+	r := &Runner{
+		consumer:  queue.NewConsumer(q, logger),
+		scheduler: NewScheduler(elector.IsLeader, logger),
+		elector:   elector,
+		logger:    logger,
+	}
+	r.consumer.SetDrainTimeout(cfg.Worker.DrainTimeout)
+	r.consumer.SetVisibilityTimeout(cfg.Worker.VisibilityTimeout)
+	for t, n := range cfg.Worker.Concurrency {
+		r.consumer.SetConcurrency(queue.JobType(t), n)
+	}
+
+	webinarRepo := webinars.NewRepository(pool)
+	registrationRepo := registrations.NewRepository(pool)
+	emailLogsRepo := emaillogs.NewRepository(pool)
+
+	if s3 != nil {
+		recordingProcessor := NewRecordingProcessor(recordings.NewRepository(pool), s3, logger)
+		recordingProcessor.SetAllowedHosts(cfg.Recording.AllowedHosts)
+		r.Handle(queue.JobTypeRecordingUpload, recordingProcessor.Process)
+	} else {
+		logger.Warn("recording processor disabled: S3 not configured")
+	}
+	transport, err := NewEmailTransport(context.Background(), cfg)
+	if err != nil {
+		logger.Error("email transport setup failed", zap.Error(err))
+	}
+	renderer := emailtemplates.NewRenderer(emailtemplates.NewRepository(pool))
+	emailProcessor := NewEmailProcessor(emailLogsRepo, webinarRepo, renderer, transport, cfg.Email, logger)
+	if cfg.Email.UnsubscribeSecret == "" {
+		logger.Warn("unsubscribe links disabled until EMAIL_UNSUBSCRIBE_SECRET is set")
+	}
+	emailProcessor.SetSuppressions(suppressions.NewRepository(pool), suppressions.NewSigner(cfg.Email.UnsubscribeSecret))
+	campaignRepo := campaigns.NewRepository(pool)
+	emailProcessor.SetCampaigns(campaignRepo)
+	if emailProcessor.Enabled() {
+		r.Handle(queue.JobTypeEmail, emailProcessor.Process)
+		r.closers = append(r.closers, emailProcessor.Close)
+	} else {
+		logger.Warn("email processor disabled: no email transport configured")
+	}
+	r.Handle(queue.JobTypeAnalytics, NewAnalyticsProcessor(analytics.NewRepository(pool), streams.NewRepository(pool), logger).Process)
+	reminderRepo := reminders.NewRepository(pool)
+	r.Handle(queue.JobTypeReminder, NewReminderProcessor(reminderRepo, webinarRepo, registrationRepo, q, cfg.Email.FrontendURL, logger).Process)
+
+	r.Handle(queue.JobTypeImport, NewImportProcessor(imports.NewRepository(pool), webinarRepo, registrationRepo, waitlist.NewRepository(pool), q, cfg.Email.FrontendURL, logger).Process)
+
+	reminderScheduler := NewReminderScheduler(reminderRepo, reminders.NewPlanner(reminderRepo, q), logger)
+	r.mustSchedule("reminders", ReminderSchedule, reminderScheduler.Sweep)
+	campaignSender := NewCampaignSender(campaignRepo, campaigns.NewAudience(registrationRepo, sessionlog.NewRepository(pool)), webinarRepo, registrationRepo, q, cfg.Email.FrontendURL, cfg.Email.CampaignRate, logger)
+	r.mustSchedule("campaigns", CampaignSchedule, campaignSender.Run)
+	// Wired like the server's generator. The worker has no WebSocket clients: status changes are published
+	// to the servers' hubs over Redis, and in-app recordings (run by a server) are not finished here.
+	hub := realtime.NewHub(logger, realtime.NewRedisPubSub(rdb, logger), nil)
+	seriesGen := series.NewGenerator(series.NewRepository(pool), webinarRepo, registrationRepo, waitlist.NewRepository(pool), logger)
+	seriesGen.SetReminderPlanner(reminders.NewPlanner(reminderRepo, q))
+	seriesGen.SetLifecycle(lifecycle.NewEffects(streams.NewRepository(pool), registrationRepo, payments.NewRepository(pool), hub, q, logger))
+	seriesGen.SetEmailQueue(q, cfg.Email.FrontendURL)
+	seriesGen.SetScheduleNotifier(calendar.NewNotifier(registrationRepo, q, cfg.Email.FrontendURL))
+	r.mustSchedule("series", series.SweepSchedule, seriesGen.Sweep)
+	retention := NewAuditRetention(audit.NewRepository(pool), cfg.Audit.RetentionDays, logger)
+	if retention.Enabled() {
+		r.mustSchedule("audit_retention", AuditRetentionSchedule, retention.Purge)
+	} else {
+		logger.Info("audit retention disabled")
+	}
+	return r
+}
+
+// mustSchedule registers a task whose spec is a compile-time constant (an invalid spec is a bug).
+func (r *Runner) mustSchedule(name, spec string, run func(ctx context.Context)) {
+	if err := r.Schedule(name, spec, run); err != nil {
+		panic(err)
+	}
+}
+
+// Handle registers a queue job handler. Call before Run.
+func (r *Runner) Handle(t queue.JobType, h queue.HandlerFunc) {
+	r.consumer.Handle(t, h)
+}
+
+// Schedule registers a task on a cron-style spec (see ParseSchedule); it runs on the leader replica only.
+// Call before Run.
+func (r *Runner) Schedule(name, spec string, run func(ctx context.Context)) error {
+	return r.scheduler.Add(name, spec, run)
+}
+
+// Consumer returns the queue consumer (for readiness checks).
+func (r *Runner) Consumer() *queue.Consumer {
+	return r.consumer
+}
+
+// Run starts every processor and blocks until ctx is cancelled and in-flight jobs have drained.
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	if r.scheduler.Len() > 0 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.elector.Run(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			r.scheduler.Run(ctx)
+		}()
+	}
+	types := r.consumer.Types()
+	fields := make([]zap.Field, 0, len(types))
+	for _, t := range types {
+		fields = append(fields, zap.Int(string(t), r.consumer.Concurrency(t)))
+	}
+	r.logger.Info("background processors started", zap.Dict("concurrency", fields...), zap.Int("scheduled_tasks", r.scheduler.Len()))
+	r.consumer.Run(ctx)
+	wg.Wait()
+	for _, c := range r.closers {
+		if err := c(); err != nil {
+			r.logger.Warn("close processor failed", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Schedule yields the next activation time strictly after t.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses a cron-style spec:
+//   - five fields "minute hour day-of-month month day-of-week" with *, N, A-B, lists and /step
+//     (e.g. "*/5 * * * *", "0 3 * * *", "30 9 * * 1-5"); day-of-week 0 is Sunday;
+//   - "@every <duration>" (e.g. "@every 90s");
+//   - "@hourly", "@daily" (midnight), "@weekly" (Sunday midnight).
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	}
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("schedule %q: invalid interval", spec)
+		}
+		return everySchedule(d), nil
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q: want 5 fields (minute hour day month weekday)", spec)
+	}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	var c cronSchedule
+	sets := []*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, f := range fields {
+		bits, err := parseCronField(f, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		*sets[i] = bits
+	}
+	c.domAny, c.dowAny = fields[2] == "*", fields[4] == "*"
+	return c, nil
+}
+
+// parseCronField returns a bitmask of the values a field matches.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if r, s, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rng, step = r, n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			} else if step > 1 {
+				hi = max // "5/15" means from 5 every 15
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// Next finds the next matching minute (local time), searching up to five years ahead.
+func (c cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day-of-month and day-of-week are restricted, either may match.
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	d := time.Duration(e)
+	return t.Truncate(d).Add(d)
+}
+
+// LeaderCheck reports whether this replica should run scheduled tasks (see leader.Elector).
+type LeaderCheck func() bool
+
+type scheduledTask struct {
+	name     string
+	schedule Schedule
+	run      func(ctx context.Context)
+	next     time.Time
+	running  atomic.Bool
+}
+
+// Scheduler runs tasks on cron-style schedules. With a LeaderCheck only the leader replica runs them,
+// and a task never overlaps itself: an activation is skipped while the previous run is still going.
+type Scheduler struct {
+	tasks    []*scheduledTask
+	isLeader LeaderCheck
+	logger   *zap.Logger
+}
+
+// NewScheduler creates a scheduler. isLeader may be nil to run tasks on every replica.
+func NewScheduler(isLeader LeaderCheck, logger *zap.Logger) *Scheduler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Scheduler{isLeader: isLeader, logger: logger}
+}
+
+// Add registers a task under a cron-style spec (see ParseSchedule). Call before Run.
+func (s *Scheduler) Add(name, spec string, run func(ctx context.Context)) error {
+	sched, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	s.tasks = append(s.tasks, &scheduledTask{name: name, schedule: sched, run: run})
+	return nil
+}
+
+// Len returns the number of registered tasks.
+func (s *Scheduler) Len() int {
+	return len(s.tasks)
+}
+
+// Run fires tasks when due until ctx is cancelled, then waits for running tasks to return.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.tasks) == 0 {
+		return
+	}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	now := time.Now()
+	for _, t := range s.tasks {
+		t.next = t.schedule.Next(now)
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		wake := time.Time{}
+		for _, t := range s.tasks {
+			if !t.next.IsZero() && (wake.IsZero() || t.next.Before(wake)) {
+				wake = t.next
+			}
+		}
+		if wake.IsZero() {
+			<-ctx.Done()
+			return
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Until(wake))
+		select {
+		case <-ctx.Done():
+			s.logger.Info("scheduler stopping")
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		leader := s.isLeader == nil || s.isLeader()
+		for _, t := range s.tasks {
+			if t.next.IsZero() || t.next.After(now) {
+				continue
+			}
+			t.next = t.schedule.Next(now)
+			if !leader {
+				continue
+			}
+			if !t.running.CompareAndSwap(false, true) {
+				s.logger.Warn("scheduled task still running; skipping activation", zap.String("task", t.name))
+				continue
+			}
+			wg.Add(1)
+			go func(t *scheduledTask) {
+				defer wg.Done()
+				defer t.running.Store(false)
+				start := time.Now()
+				t.run(ctx)
+				s.logger.Debug("scheduled task finished", zap.String("task", t.name), zap.Duration("took", time.Since(start)))
+			}(t)
+		}
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/aura-webinar/backend/internal/models"
 	"github.com/aura-webinar/backend/internal/recordings"
 	"github.com/aura-webinar/backend/pkg/queue"
 	"github.com/aura-webinar/backend/pkg/storage"
@@ -16,28 +16,45 @@ import (
 
 // RecordingProcessor processes recording upload jobs: download from provider URL, upload to S3, update DB.
 type RecordingProcessor struct {
-	recRepo *recordings.Repository
-	s3      *storage.S3
-	queue   *queue.Queue
-	logger  *zap.Logger
+	recRepo      *recordings.Repository
+	s3           *storage.S3
+	allowedHosts []string
+	client       *http.Client
+	logger       *zap.Logger
 }
 
 // NewRecordingProcessor creates a recording upload processor.
-func NewRecordingProcessor(recRepo *recordings.Repository, s3 *storage.S3, q *queue.Queue, logger *zap.Logger) *RecordingProcessor {
+func NewRecordingProcessor(recRepo *recordings.Repository, s3 *storage.S3, logger *zap.Logger) *RecordingProcessor {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &RecordingProcessor{recRepo: recRepo, s3: s3, queue: q, logger: logger}
+	p := &RecordingProcessor{recRepo: recRepo, s3: s3, logger: logger}
+	// Redirects must stay on allowlisted hosts too.
+	p.client = &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return fmt.Errorf("too many redirects")
+		}
+		if !recordings.HostAllowed(req.URL.String(), p.allowedHosts) {
+			return fmt.Errorf("redirect to disallowed host %q", req.URL.Host)
+		}
+		return nil
+	}}
+	return p
+}
+
+// SetAllowedHosts restricts downloads to these provider hosts (see recordings.HostAllowed).
+func (p *RecordingProcessor) SetAllowedHosts(hosts []string) {
+	p.allowedHosts = hosts
 }
 
-// Process executes one recording upload job.
+// Process executes one recording upload job (queue.HandlerFunc for JobTypeRecordingUpload).
 func (p *RecordingProcessor) Process(ctx context.Context, job *queue.Job) error {
 	if job.Type != queue.JobTypeRecordingUpload {
-		return fmt.Errorf("unknown job type: %s", job.Type)
+		return queue.Permanent(fmt.Errorf("unknown job type: %s", job.Type))
 	}
 	var payload queue.RecordingUploadPayload
 	if err := json.Unmarshal(job.Payload, &payload); err != nil {
-		return fmt.Errorf("unmarshal payload: %w", err)
+		return queue.Permanent(fmt.Errorf("unmarshal payload: %w", err))
 	}
 
 	rec, err := p.recRepo.GetByID(ctx, payload.RecordingID)
@@ -49,12 +66,18 @@ func (p *RecordingProcessor) Process(ctx context.Context, job *queue.Job) error
 		return nil
 	}
 
+	// Jobs enqueued before the allowlist existed (or tampered with in Redis) are refused here as well.
+	if !recordings.HostAllowed(payload.OriginalURL, p.allowedHosts) {
+		_ = p.recRepo.UpdateStatus(ctx, payload.RecordingID, models.RecordingStatusFailed)
+		return queue.Permanent(fmt.Errorf("original_url host not allowed: %s", payload.OriginalURL))
+	}
+
 	// Download from provider (streaming)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, payload.OriginalURL, nil)
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := p.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("download: %w", err)
 	}
@@ -84,35 +107,3 @@ func (p *RecordingProcessor) Process(ctx context.Context, job *queue.Job) error
 	p.logger.Info("recording upload completed", zap.String("recording_id", payload.RecordingID.String()), zap.String("s3_key", key))
 	return nil
 }
-
-// Run starts the worker loop: dequeue, process, retry on error.
-func (p *RecordingProcessor) Run(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			p.logger.Info("recording worker stopping")
-			return
-		default:
-		}
-
-		job, _, err := p.queue.Dequeue(ctx)
-		if err != nil {
-			p.logger.Warn("dequeue error", zap.Error(err))
-			time.Sleep(queue.RetryBackoff)
-			continue
-		}
-		if job == nil {
-			continue
-		}
-
-		p.logger.Debug("processing job", zap.String("job_id", job.ID), zap.String("type", string(job.Type)))
-		if err := p.Process(ctx, job); err != nil {
-			p.logger.Error("job failed", zap.String("job_id", job.ID), zap.Error(err))
-			if reErr := p.queue.Retry(ctx, job); reErr != nil {
-				p.logger.Error("retry enqueue failed", zap.Error(reErr))
-			}
-			time.Sleep(queue.RetryBackoff)
-			continue
-		}
-	}
-}
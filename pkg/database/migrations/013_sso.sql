@@ -0,0 +1,30 @@
+-- Single sign-on (OIDC): linked IdP identities and organization email domains
+
+-- One row per (provider, subject); a user may link several IdPs
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    last_login_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(provider, subject)
+);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+
+-- Email domains claimed by an organization; SSO users with a verified email on a verified domain join the org
+CREATE TABLE IF NOT EXISTS organization_domains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    domain VARCHAR(255) NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    default_role VARCHAR(32) NOT NULL DEFAULT 'moderator' CHECK (default_role IN ('owner', 'event_manager', 'moderator')),
+    verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_organization_domains_org ON organization_domains(organization_id);
+-- Only a verified claim owns a domain. Several organizations may hold pending claims for the same domain
+-- (the first to publish the DNS TXT record wins), so an unverified claim cannot block the real owner.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_domains_verified ON organization_domains(domain) WHERE verified_at IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_domains_org_domain ON organization_domains(organization_id, domain);
@@ -0,0 +1,8 @@
+-- Engagement metrics: one snapshot per stream session (rewritten when the analytics job re-runs)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_engagement_metrics_session ON engagement_metrics(stream_session_id)
+    WHERE stream_session_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_engagement_metrics_webinar_recorded ON engagement_metrics(webinar_id, recorded_at DESC);
+
+-- Session window lookups for analytics
+CREATE INDEX IF NOT EXISTS idx_stream_sessions_webinar ON stream_sessions(webinar_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_questions_webinar_created ON questions(webinar_id, created_at);
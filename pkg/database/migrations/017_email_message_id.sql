@@ -0,0 +1,3 @@
+-- Email logs: provider message ID (SendGrid X-Message-Id, SES MessageId or our Message-ID) for delivery events
+ALTER TABLE email_logs ADD COLUMN IF NOT EXISTS message_id VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_email_logs_message_id ON email_logs(message_id) WHERE message_id IS NOT NULL;
@@ -0,0 +1,26 @@
+-- Organization email branding (logo, color, footer) applied to every email of the organization's webinars
+CREATE TABLE IF NOT EXISTS organization_email_branding (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    logo_url TEXT,
+    primary_color VARCHAR(7),
+    footer_text TEXT,
+    default_locale VARCHAR(16) NOT NULL DEFAULT 'en',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Organization overrides of the embedded default templates, per email type and locale
+CREATE TABLE IF NOT EXISTS email_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email_type VARCHAR(64) NOT NULL,
+    locale VARCHAR(16) NOT NULL,
+    subject TEXT NOT NULL,
+    body_html TEXT NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, email_type, locale)
+);
+
+-- Attendee locale (picks the template variant for registration and reminder emails)
+ALTER TABLE registrations ADD COLUMN IF NOT EXISTS locale VARCHAR(16);
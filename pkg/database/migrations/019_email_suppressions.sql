@@ -0,0 +1,19 @@
+-- Addresses that must not be emailed: hard bounces and complaints reported by the provider, one-click
+-- unsubscribes, and manual entries. organization_id NULL suppresses the address for every organization.
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    reason VARCHAR(32) NOT NULL CHECK (reason IN ('bounce', 'complaint', 'unsubscribe', 'manual')),
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_suppressions_org_email ON email_suppressions(organization_id, lower(email))
+    WHERE organization_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_suppressions_global_email ON email_suppressions(lower(email))
+    WHERE organization_id IS NULL;
+
+-- Email logs: skipped sends and provider outcomes after hand-off
+ALTER TABLE email_logs DROP CONSTRAINT IF EXISTS email_logs_status_check;
+ALTER TABLE email_logs ADD CONSTRAINT email_logs_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'suppressed', 'bounced', 'complained'));
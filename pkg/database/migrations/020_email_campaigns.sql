@@ -0,0 +1,32 @@
+-- Organizer email campaigns to a segment of a webinar's registrants
+CREATE TABLE IF NOT EXISTS email_campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webinar_id UUID NOT NULL REFERENCES webinars(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL,
+    body_html TEXT NOT NULL,
+    segment JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(32) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'scheduled', 'sending', 'sent', 'cancelled')),
+    scheduled_at TIMESTAMPTZ,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    recipient_count INT NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_email_campaigns_webinar ON email_campaigns(webinar_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_campaigns_due ON email_campaigns(scheduled_at) WHERE status = 'scheduled';
+
+-- Audience snapshot taken when sending starts; enqueued_at tracks the throttled fan-out
+CREATE TABLE IF NOT EXISTS email_campaign_recipients (
+    campaign_id UUID NOT NULL REFERENCES email_campaigns(id) ON DELETE CASCADE,
+    registration_id UUID NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+    enqueued_at TIMESTAMPTZ,
+    PRIMARY KEY (campaign_id, registration_id)
+);
+CREATE INDEX IF NOT EXISTS idx_email_campaign_recipients_pending ON email_campaign_recipients(campaign_id) WHERE enqueued_at IS NULL;
+
+-- Email logs: campaign the email belongs to (delivery stats)
+ALTER TABLE email_logs ADD COLUMN IF NOT EXISTS campaign_id UUID REFERENCES email_campaigns(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_email_logs_campaign ON email_logs(campaign_id) WHERE campaign_id IS NOT NULL;
@@ -0,0 +1,22 @@
+-- Reminder offsets in minutes before starts_at. NULL inherits: webinar -> organization -> platform default;
+-- an empty array means no reminders.
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS reminder_offsets INT[];
+ALTER TABLE webinars ADD COLUMN IF NOT EXISTS reminder_offsets INT[];
+-- starts_at the current reminder plan was computed for; the worker re-plans upcoming webinars where it differs
+ALTER TABLE webinars ADD COLUMN IF NOT EXISTS reminders_planned_for TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_webinars_reminders_unplanned ON webinars(starts_at) WHERE reminders_planned_for IS DISTINCT FROM starts_at;
+
+-- One row per planned reminder; each is a delayed queue job that fans out to the registrants when due
+CREATE TABLE IF NOT EXISTS webinar_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webinar_id UUID NOT NULL REFERENCES webinars(id) ON DELETE CASCADE,
+    offset_minutes INT NOT NULL CHECK (offset_minutes > 0),
+    email_type VARCHAR(32) NOT NULL,
+    send_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'sent', 'skipped', 'cancelled')),
+    recipient_count INT NOT NULL DEFAULT 0,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webinar_reminders_webinar ON webinar_reminders(webinar_id, send_at);
+CREATE INDEX IF NOT EXISTS idx_webinar_reminders_due ON webinar_reminders(send_at) WHERE status = 'scheduled';
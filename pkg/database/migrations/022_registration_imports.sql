@@ -0,0 +1,33 @@
+-- Bulk registration imports from CSV, processed by the worker
+CREATE TABLE IF NOT EXISTS registration_imports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webinar_id UUID NOT NULL REFERENCES webinars(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    source BYTEA NOT NULL,
+    mapping JSONB NOT NULL DEFAULT '{}',
+    send_confirmations BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(32) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_count INT NOT NULL DEFAULT 0,
+    waitlisted_count INT NOT NULL DEFAULT 0,
+    duplicate_count INT NOT NULL DEFAULT 0,
+    invalid_count INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_registration_imports_webinar ON registration_imports(webinar_id, created_at DESC);
+
+-- Per-row outcome of an import (the downloadable report)
+CREATE TABLE IF NOT EXISTS registration_import_rows (
+    import_id UUID NOT NULL REFERENCES registration_imports(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL CHECK (status IN ('created', 'waitlisted', 'duplicate', 'invalid')),
+    registration_id UUID REFERENCES registrations(id) ON DELETE SET NULL,
+    message TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (import_id, row_number)
+);
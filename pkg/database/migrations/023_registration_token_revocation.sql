@@ -0,0 +1,5 @@
+-- Organizers can revoke a registration's join links
+ALTER TABLE registration_tokens ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMPTZ;
+
+-- Registration search (newest first within a webinar)
+CREATE INDEX IF NOT EXISTS idx_registrations_webinar_created ON registrations(webinar_id, created_at DESC);
@@ -0,0 +1,19 @@
+-- Join links are reusable: each exchange is logged with the device that used it
+CREATE TABLE IF NOT EXISTS registration_token_uses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    token_id UUID NOT NULL REFERENCES registration_tokens(id) ON DELETE CASCADE,
+    registration_id UUID NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_registration_token_uses_registration ON registration_token_uses(registration_id, created_at DESC);
+
+-- Per-webinar limit on concurrent live sessions per attendee (NULL columns use the server defaults)
+CREATE TABLE IF NOT EXISTS webinar_join_policies (
+    webinar_id UUID PRIMARY KEY REFERENCES webinars(id) ON DELETE CASCADE,
+    device_limit INT CHECK (device_limit >= 0),
+    on_limit VARCHAR(20) CHECK (on_limit IN ('newest_wins', 'reject')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,25 @@
+-- Webinar lifecycle: draft -> scheduled -> live -> ended -> archived, or cancelled before going live
+ALTER TABLE webinars ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+    CHECK (status IN ('draft', 'scheduled', 'live', 'ended', 'archived', 'cancelled'));
+
+-- Existing webinars, once (when status_changed_at is added): live while a stream session is open, ended
+-- once past their end (or a day after start). Later transitions go through the lifecycle actions
+-- (POST /webinars/:id/<action>), which carry out their effects.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'webinars' AND column_name = 'status_changed_at') THEN
+        ALTER TABLE webinars ADD COLUMN status_changed_at TIMESTAMPTZ;
+        UPDATE webinars w SET status = 'live', status_changed_at = NOW()
+            WHERE status = 'scheduled' AND EXISTS (SELECT 1 FROM stream_sessions s WHERE s.webinar_id = w.id AND s.ended_at IS NULL);
+        UPDATE webinars SET status = 'ended', status_changed_at = NOW()
+            WHERE status = 'scheduled' AND COALESCE(ends_at, starts_at + INTERVAL '1 day') < NOW();
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_webinars_status_starts ON webinars(status, starts_at);
+
+-- Cancelling a paid webinar marks its completed payments refund_pending; organizers refund them with the
+-- provider and mark them refunded
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('pending', 'completed', 'failed', 'refund_pending', 'refunded', 'partially_refunded'));
@@ -0,0 +1,64 @@
+-- Recurring webinar series: an RFC 5545 RRULE in a time zone generates webinars (occurrences) that share
+-- the series' settings
+CREATE TABLE IF NOT EXISTS webinar_series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title VARCHAR(500) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    rrule TEXT NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    -- DTSTART: the first occurrence; later occurrences keep its wall-clock time in timezone
+    starts_at TIMESTAMPTZ NOT NULL,
+    duration_minutes INT NOT NULL CHECK (duration_minutes > 0),
+    -- status of newly generated occurrences
+    occurrence_status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (occurrence_status IN ('draft', 'scheduled')),
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL,
+    is_paid BOOLEAN NOT NULL DEFAULT FALSE,
+    ticket_price_cents INT NOT NULL DEFAULT 0,
+    ticket_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    max_audience INT,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    banner_image_url VARCHAR(512) NOT NULL DEFAULT '',
+    audience_form_config JSONB,
+    speaker_ids UUID[] NOT NULL DEFAULT '{}',
+    -- occurrences up to here exist; the worker extends the series as this falls inside the horizon
+    generated_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webinar_series_created_by ON webinar_series(created_by);
+CREATE INDEX IF NOT EXISTS idx_webinar_series_organization ON webinar_series(organization_id);
+CREATE INDEX IF NOT EXISTS idx_webinar_series_generated ON webinar_series(generated_until);
+
+-- occurrence_at is the occurrence's time in the rule; starts_at may differ once the occurrence is moved
+ALTER TABLE webinars ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES webinar_series(id) ON DELETE SET NULL;
+ALTER TABLE webinars ADD COLUMN IF NOT EXISTS occurrence_at TIMESTAMPTZ;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webinars_series_occurrence ON webinars(series_id, occurrence_at);
+
+-- Ad creatives copied into every generated occurrence
+CREATE TABLE IF NOT EXISTS webinar_series_ads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    series_id UUID NOT NULL REFERENCES webinar_series(id) ON DELETE CASCADE,
+    file_url VARCHAR(2048) NOT NULL,
+    file_type VARCHAR(32) NOT NULL,
+    file_size BIGINT NOT NULL DEFAULT 0,
+    duration INT NOT NULL DEFAULT 0,
+    s3_key VARCHAR(512),
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webinar_series_ads_series ON webinar_series_ads(series_id, position);
+ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS series_ad_id UUID REFERENCES webinar_series_ads(id) ON DELETE SET NULL;
+
+-- Attendees registered for the whole series; each is registered for every occurrence as it is generated
+CREATE TABLE IF NOT EXISTS webinar_series_registrations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    series_id UUID NOT NULL REFERENCES webinar_series(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    full_name VARCHAR(255) NOT NULL,
+    extra_data JSONB,
+    locale VARCHAR(16),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (series_id, email)
+);
@@ -0,0 +1,26 @@
+-- Public catalog: who may find a webinar, and full-text search over title and description
+-- public: listed in the catalog; unlisted: reachable by link only; private: organizers, speakers and invited
+-- (imported or pre-registered) attendees only
+ALTER TABLE webinars ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+ALTER TABLE webinars ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_webinars_search ON webinars USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_webinars_catalog ON webinars(visibility, status, starts_at, id);
+
+-- Listings scan category and banner as text; older rows left them NULL
+UPDATE webinars SET category = '' WHERE category IS NULL;
+UPDATE webinars SET banner_image_url = '' WHERE banner_image_url IS NULL;
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'webinars' AND column_name = 'category' AND is_nullable = 'YES') THEN
+        ALTER TABLE webinars ALTER COLUMN category SET DEFAULT '', ALTER COLUMN category SET NOT NULL;
+    END IF;
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'webinars' AND column_name = 'banner_image_url' AND is_nullable = 'YES') THEN
+        ALTER TABLE webinars ALTER COLUMN banner_image_url SET DEFAULT '', ALTER COLUMN banner_image_url SET NOT NULL;
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_webinars_category ON webinars(LOWER(category));
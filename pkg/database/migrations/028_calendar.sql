@@ -0,0 +1,18 @@
+-- Calendar invites: SEQUENCE of a webinar's iCalendar event, raised whenever attendees' calendars must update
+ALTER TABLE webinars ADD COLUMN IF NOT EXISTS calendar_sequence INT NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION webinars_calendar_sequence() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.starts_at IS DISTINCT FROM OLD.starts_at
+        OR NEW.ends_at IS DISTINCT FROM OLD.ends_at
+        OR NEW.title IS DISTINCT FROM OLD.title
+        OR (NEW.status = 'cancelled' AND OLD.status <> 'cancelled') THEN
+        NEW.calendar_sequence := OLD.calendar_sequence + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_webinars_calendar_sequence ON webinars;
+CREATE TRIGGER trg_webinars_calendar_sequence BEFORE UPDATE ON webinars
+    FOR EACH ROW EXECUTE FUNCTION webinars_calendar_sequence();
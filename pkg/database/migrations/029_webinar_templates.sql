@@ -0,0 +1,45 @@
+-- Organization webinar templates: a saved webinar setup (settings, registration form, speakers, polls,
+-- reminders and ads) that new webinars are created from
+CREATE TABLE IF NOT EXISTS webinar_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    -- 0 leaves new webinars without an end time
+    duration_minutes INT NOT NULL DEFAULT 0 CHECK (duration_minutes >= 0),
+    is_paid BOOLEAN NOT NULL DEFAULT FALSE,
+    ticket_price_cents INT NOT NULL DEFAULT 0,
+    ticket_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    max_audience INT,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    banner_image_url VARCHAR(512) NOT NULL DEFAULT '',
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'unlisted', 'private')),
+    audience_form_config JSONB,
+    -- speakers are invited by email to each webinar created from the template
+    speaker_emails TEXT[] NOT NULL DEFAULT '{}',
+    -- prepared polls: [{"question": ..., "option_a": ..., ...}]
+    polls JSONB NOT NULL DEFAULT '[]',
+    -- NULL inherits the organization default, like webinars.reminder_offsets
+    reminder_offsets INT[],
+    ad_rotation_interval INT NOT NULL DEFAULT 30,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, name)
+);
+
+-- The template's own copies of its ad creatives (S3 objects under ads/templates/{template_id}/)
+CREATE TABLE IF NOT EXISTS webinar_template_ads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id UUID NOT NULL REFERENCES webinar_templates(id) ON DELETE CASCADE,
+    file_url VARCHAR(2048) NOT NULL,
+    file_type VARCHAR(32) NOT NULL,
+    file_size BIGINT NOT NULL DEFAULT 0,
+    duration INT NOT NULL DEFAULT 0,
+    s3_key VARCHAR(512),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webinar_template_ads_template ON webinar_template_ads(template_id, position);
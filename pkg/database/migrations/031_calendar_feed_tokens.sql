@@ -0,0 +1,7 @@
+-- Attendee calendar feed links: one random token per email address. Resetting the link replaces the token,
+-- revoking the old subscription URL (it carries the attendee's join links).
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+    email VARCHAR(255) PRIMARY KEY, -- lowercased
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
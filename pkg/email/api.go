@@ -0,0 +1,231 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// SendGridEndpoint is the SendGrid v3 mail send API.
+const SendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+var apiHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// SendGridTransport sends through the SendGrid v3 API.
+type SendGridTransport struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewSendGridTransport creates a SendGrid transport authenticated with apiKey.
+func NewSendGridTransport(apiKey string) (*SendGridTransport, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("sendgrid api key not configured")
+	}
+	return &SendGridTransport{apiKey: apiKey, endpoint: SendGridEndpoint, client: apiHTTPClient}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"` // base64
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+}
+
+type sendGridRequest struct {
+	Personalizations []struct {
+		To []sendGridAddress `json:"to"`
+	} `json:"personalizations"`
+	From        sendGridAddress      `json:"from"`
+	ReplyTo     *sendGridAddress     `json:"reply_to,omitempty"`
+	Subject     string               `json:"subject"`
+	Content     []sendGridContent    `json:"content"`
+	Headers     map[string]string    `json:"headers,omitempty"`
+	CustomArgs  map[string]string    `json:"custom_args,omitempty"`
+	Attachments []sendGridAttachment `json:"attachments,omitempty"`
+}
+
+// Send implements Transport. Returns SendGrid's X-Message-Id, which its event webhook reports as
+// sg_message_id; our Message-ID travels as the message_id custom arg.
+func (t *SendGridTransport) Send(ctx context.Context, msg *Message) (string, error) {
+	if err := msg.Prepare(); err != nil {
+		return "", err
+	}
+	body := sendGridRequest{
+		From:       sendGridAddress{Email: msg.From.Email, Name: msg.From.Name},
+		Subject:    msg.Subject,
+		Headers:    map[string]string{},
+		CustomArgs: map[string]string{"message_id": msg.MessageID},
+	}
+	body.Personalizations = make([]struct {
+		To []sendGridAddress `json:"to"`
+	}, 1)
+	body.Personalizations[0].To = []sendGridAddress{{Email: msg.To.Email, Name: msg.To.Name}}
+	if msg.ReplyTo != "" {
+		body.ReplyTo = &sendGridAddress{Email: msg.ReplyTo}
+	}
+	body.Content = append(body.Content, sendGridContent{Type: "text/plain", Value: msg.Text})
+	if msg.HTML != "" {
+		body.Content = append(body.Content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+	for k, v := range msg.listHeaders() {
+		body.Headers[k] = v
+	}
+	for k, v := range msg.Headers {
+		body.Headers[k] = v
+	}
+	for _, a := range msg.Attachments {
+		body.Attachments = append(body.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(a.Data),
+			Type:        a.ContentType,
+			Filename:    a.Filename,
+			Disposition: "attachment",
+		})
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sendgrid: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := apiError("sendgrid", resp); err != nil {
+		return "", err
+	}
+	if id := resp.Header.Get("X-Message-Id"); id != "" {
+		return id, nil
+	}
+	return msg.MessageID, nil
+}
+
+// Close implements Transport.
+func (t *SendGridTransport) Close() error { return nil }
+
+// SESConfig holds Amazon SES settings. Empty keys use the default AWS credential chain.
+type SESConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// SESTransport sends raw MIME through the SES v2 API (SigV4-signed HTTPS, no SES SDK needed).
+type SESTransport struct {
+	region   string
+	endpoint string
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+	client   *http.Client
+}
+
+// NewSESTransport creates an SES transport.
+func NewSESTransport(ctx context.Context, cfg SESConfig) (*SESTransport, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("ses region not configured")
+	}
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.Region)}
+	if cfg.AccessKeyID != "" && cfg.SecretAccessKey != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, "")))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load aws config: %w", err)
+	}
+	return &SESTransport{
+		region:   cfg.Region,
+		endpoint: fmt.Sprintf("https://email.%s.amazonaws.com/v2/email/outbound-emails", cfg.Region),
+		creds:    awsCfg.Credentials,
+		signer:   v4.NewSigner(),
+		client:   apiHTTPClient,
+	}, nil
+}
+
+// Send implements Transport. Returns the SES MessageId, which SES event notifications reference.
+func (t *SESTransport) Send(ctx context.Context, msg *Message) (string, error) {
+	raw, err := msg.Bytes()
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"Content": map[string]interface{}{
+			"Raw": map[string]string{"Data": base64.StdEncoding.EncodeToString(raw)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	creds, err := t.creds.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("ses credentials: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	sum := sha256.Sum256(payload)
+	if err := t.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "ses", t.region, time.Now()); err != nil {
+		return "", fmt.Errorf("ses sign: %w", err)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ses: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := apiError("ses", resp); err != nil {
+		return "", err
+	}
+	var out struct {
+		MessageID string `json:"MessageId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || out.MessageID == "" {
+		return msg.MessageID, nil
+	}
+	return out.MessageID, nil
+}
+
+// Close implements Transport.
+func (t *SESTransport) Close() error { return nil }
+
+// apiError turns a non-2xx response into an error; 4xx other than 408/429 wrap ErrRejected.
+func apiError(provider string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+	err := fmt.Errorf("%s: status %d: %s", provider, resp.StatusCode, bytes.TrimSpace(detail))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	return err
+}
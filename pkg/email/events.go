@@ -0,0 +1,329 @@
+package email
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType is a delivery outcome reported by a provider after hand-off.
+type EventType string
+
+const (
+	EventBounce      EventType = "bounce"
+	EventComplaint   EventType = "complaint"
+	EventUnsubscribe EventType = "unsubscribe"
+	EventDropped     EventType = "dropped"
+)
+
+// Event is one provider delivery event for one recipient.
+type Event struct {
+	Type      EventType
+	Email     string
+	MessageID string // as returned by Transport.Send
+	Reason    string
+	Permanent bool // hard bounce; transient bounces are not suppressed
+}
+
+// Signed event webhook headers (SendGrid).
+const (
+	SendGridSignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	SendGridTimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+)
+
+// SendGridTimestampTolerance is how far a signed delivery's timestamp may be from now; older deliveries are
+// rejected so a captured request cannot be replayed later.
+const SendGridTimestampTolerance = 5 * time.Minute
+
+// ErrBadEventSignature means a provider event delivery failed verification.
+var ErrBadEventSignature = errors.New("email: event signature mismatch")
+
+// VerifySendGridSignature checks a signed event webhook delivery: an ECDSA signature over timestamp+body
+// by the key shown in SendGrid's Mail Settings (publicKey is that base64 DER value), with a timestamp
+// within SendGridTimestampTolerance of now.
+func VerifySendGridSignature(publicKey, signature, timestamp string, body []byte) error {
+	if signature == "" || timestamp == "" {
+		return ErrBadEventSignature
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrBadEventSignature
+	}
+	if d := time.Since(time.Unix(sec, 0)); d > SendGridTimestampTolerance || d < -SendGridTimestampTolerance {
+		return ErrBadEventSignature
+	}
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil {
+		return fmt.Errorf("sendgrid webhook key: %w", err)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return fmt.Errorf("sendgrid webhook key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("sendgrid webhook key: not an ECDSA key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrBadEventSignature
+	}
+	h := sha256.New()
+	h.Write([]byte(timestamp))
+	h.Write(body)
+	if !ecdsa.VerifyASN1(key, h.Sum(nil), sig) {
+		return ErrBadEventSignature
+	}
+	return nil
+}
+
+// ParseSendGridEvents maps an event webhook batch to Events; other event kinds (delivered, open, ...) are skipped.
+func ParseSendGridEvents(body []byte) ([]Event, error) {
+	var raw []struct {
+		Email       string `json:"email"`
+		Event       string `json:"event"`
+		Type        string `json:"type"`
+		Reason      string `json:"reason"`
+		SGMessageID string `json:"sg_message_id"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	var out []Event
+	for _, r := range raw {
+		// sg_message_id is "<X-Message-Id>.<filter suffix>"
+		e := Event{Email: r.Email, MessageID: strings.SplitN(r.SGMessageID, ".", 2)[0], Reason: r.Reason}
+		switch r.Event {
+		case "bounce":
+			e.Type, e.Permanent = EventBounce, r.Type != "blocked"
+		case "spamreport":
+			e.Type = EventComplaint
+		case "unsubscribe", "group_unsubscribe":
+			e.Type = EventUnsubscribe
+		case "dropped":
+			e.Type = EventDropped
+		default:
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// SNSMessage is an Amazon SNS HTTP(S) delivery (SES publishes bounce and complaint notifications via SNS).
+type SNSMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	Token            string `json:"Token"`
+}
+
+var snsHostRegex = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SNSVerifier verifies SNS message signatures, caching signing certificates by URL.
+type SNSVerifier struct {
+	client *http.Client
+	mu     sync.Mutex
+	certs  map[string]*x509.Certificate
+}
+
+// NewSNSVerifier creates a verifier.
+func NewSNSVerifier() *SNSVerifier {
+	return &SNSVerifier{client: apiHTTPClient, certs: make(map[string]*x509.Certificate)}
+}
+
+// Verify checks the message signature against its SNS signing certificate (fetched from an
+// sns.<region>.amazonaws.com HTTPS URL only).
+func (v *SNSVerifier) Verify(ctx context.Context, m *SNSMessage) error {
+	if !IsSNSURL(m.SigningCertURL) {
+		return fmt.Errorf("%w: signing certificate URL not from SNS", ErrBadEventSignature)
+	}
+	cert, err := v.cert(ctx, m.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: unexpected certificate key", ErrBadEventSignature)
+	}
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return ErrBadEventSignature
+	}
+	canonical := m.canonical()
+	switch m.SignatureVersion {
+	case "1":
+		sum := sha1.Sum([]byte(canonical))
+		err = rsa.VerifyPKCS1v15(pub, crypto.SHA1, sum[:], sig)
+	case "2":
+		sum := sha256.Sum256([]byte(canonical))
+		err = rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig)
+	default:
+		return fmt.Errorf("%w: unsupported signature version %q", ErrBadEventSignature, m.SignatureVersion)
+	}
+	if err != nil {
+		return ErrBadEventSignature
+	}
+	return nil
+}
+
+// canonical builds the string SNS signs: selected "Key\nValue\n" pairs in byte order of the key names.
+func (m *SNSMessage) canonical() string {
+	var b strings.Builder
+	add := func(k, v string) {
+		b.WriteString(k + "\n" + v + "\n")
+	}
+	add("Message", m.Message)
+	add("MessageId", m.MessageID)
+	if m.Type == "Notification" {
+		if m.Subject != "" {
+			add("Subject", m.Subject)
+		}
+		add("Timestamp", m.Timestamp)
+		add("TopicArn", m.TopicArn)
+		add("Type", m.Type)
+		return b.String()
+	}
+	add("SubscribeURL", m.SubscribeURL)
+	add("Timestamp", m.Timestamp)
+	add("Token", m.Token)
+	add("TopicArn", m.TopicArn)
+	add("Type", m.Type)
+	return b.String()
+}
+
+func (v *SNSVerifier) cert(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	v.mu.Lock()
+	cert := v.certs[certURL]
+	v.mu.Unlock()
+	if cert != nil {
+		return cert, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch sns certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch sns certificate: status %d", resp.StatusCode)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("sns certificate: not PEM")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("sns certificate: %w", err)
+	}
+	v.mu.Lock()
+	v.certs[certURL] = cert
+	v.mu.Unlock()
+	return cert, nil
+}
+
+// ConfirmSubscription visits SubscribeURL of a verified SubscriptionConfirmation.
+func (v *SNSVerifier) ConfirmSubscription(ctx context.Context, m *SNSMessage) error {
+	if !IsSNSURL(m.SubscribeURL) {
+		return fmt.Errorf("subscribe URL not from SNS")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.SubscribeURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("confirm subscription: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// IsSNSURL reports whether u is an HTTPS URL on an SNS endpoint.
+func IsSNSURL(u string) bool {
+	parsed, err := url.Parse(u)
+	return err == nil && parsed.Scheme == "https" && snsHostRegex.MatchString(parsed.Hostname())
+}
+
+// ParseSESNotification maps an SES bounce or complaint notification (SNS Message) to Events.
+// Both notification ("notificationType") and event publishing ("eventType") formats are accepted.
+func ParseSESNotification(message string) ([]Event, error) {
+	var n struct {
+		NotificationType string `json:"notificationType"`
+		EventType        string `json:"eventType"`
+		Mail             struct {
+			MessageID string `json:"messageId"`
+		} `json:"mail"`
+		Bounce struct {
+			BounceType        string `json:"bounceType"`
+			BounceSubType     string `json:"bounceSubType"`
+			BouncedRecipients []struct {
+				EmailAddress   string `json:"emailAddress"`
+				DiagnosticCode string `json:"diagnosticCode"`
+			} `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint struct {
+			FeedbackType         string `json:"complaintFeedbackType"`
+			ComplainedRecipients []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"complainedRecipients"`
+		} `json:"complaint"`
+	}
+	if err := json.Unmarshal([]byte(message), &n); err != nil {
+		return nil, err
+	}
+	kind := n.NotificationType
+	if kind == "" {
+		kind = n.EventType
+	}
+	var out []Event
+	switch kind {
+	case "Bounce":
+		for _, r := range n.Bounce.BouncedRecipients {
+			reason := r.DiagnosticCode
+			if reason == "" {
+				reason = n.Bounce.BounceType + "/" + n.Bounce.BounceSubType
+			}
+			out = append(out, Event{Type: EventBounce, Email: r.EmailAddress, MessageID: n.Mail.MessageID, Reason: reason, Permanent: n.Bounce.BounceType == "Permanent"})
+		}
+	case "Complaint":
+		for _, r := range n.Complaint.ComplainedRecipients {
+			out = append(out, Event{Type: EventComplaint, Email: r.EmailAddress, MessageID: n.Mail.MessageID, Reason: n.Complaint.FeedbackType})
+		}
+	}
+	return out, nil
+}
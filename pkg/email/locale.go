@@ -0,0 +1,28 @@
+package email
+
+import (
+	"regexp"
+	"strings"
+)
+
+var localeRegex = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// NormalizeLocale lowercases a BCP 47-style tag ("pt_BR" -> "pt-br"); "" if it is not one.
+func NormalizeLocale(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "_", "-"))
+	if !localeRegex.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+// LocaleFromAcceptLanguage returns the first usable tag of an Accept-Language header.
+func LocaleFromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if l := NormalizeLocale(tag); l != "" {
+			return l
+		}
+	}
+	return ""
+}
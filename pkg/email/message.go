@@ -0,0 +1,268 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Address is a mailbox with an optional display name (any UTF-8; encoded on the wire).
+type Address struct {
+	Name  string
+	Email string
+}
+
+// String formats the address for a header, RFC 2047-encoding the name when needed.
+func (a Address) String() string {
+	return (&mail.Address{Name: a.Name, Address: a.Email}).String()
+}
+
+// Attachment is a file sent with a message.
+type Attachment struct {
+	Filename    string
+	ContentType string // e.g. "text/calendar; charset=UTF-8; method=REQUEST"
+	Data        []byte
+}
+
+// Message is one email with text and HTML alternatives and optional attachments.
+type Message struct {
+	From    Address
+	To      Address
+	ReplyTo string
+	Subject string
+	Text    string // plain-text alternative; derived from HTML when empty
+	HTML    string
+	// MessageID is the Message-ID header without angle brackets; generated by Prepare when empty.
+	MessageID string
+	// ListUnsubscribe holds unsubscribe URIs (https: and/or mailto:) for the List-Unsubscribe header.
+	ListUnsubscribe []string
+	// OneClickUnsubscribe adds List-Unsubscribe-Post (RFC 8058); the https URI must accept a POST.
+	OneClickUnsubscribe bool
+	// Headers are extra headers (e.g. X-Entity-Ref-ID). Values must be ASCII.
+	Headers     map[string]string
+	Attachments []Attachment
+	Date        time.Time
+}
+
+// Prepare validates the message and fills MessageID, Date and Text when missing.
+func (m *Message) Prepare() error {
+	if _, err := mail.ParseAddress(m.From.Email); err != nil {
+		return fmt.Errorf("%w: invalid from address %q", ErrRejected, m.From.Email)
+	}
+	if _, err := mail.ParseAddress(m.To.Email); err != nil {
+		return fmt.Errorf("%w: invalid recipient %q", ErrRejected, m.To.Email)
+	}
+	if m.HTML == "" && m.Text == "" {
+		return fmt.Errorf("%w: empty body", ErrRejected)
+	}
+	values := []string{m.From.Name, m.To.Name, m.ReplyTo, m.Subject, m.MessageID}
+	values = append(values, m.ListUnsubscribe...)
+	for k, v := range m.Headers {
+		values = append(values, k, v)
+	}
+	for _, a := range m.Attachments {
+		values = append(values, a.Filename, a.ContentType)
+	}
+	for _, v := range values {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("%w: header contains a line break", ErrRejected)
+		}
+	}
+	if m.MessageID == "" {
+		m.MessageID = NewMessageID(m.From.Email)
+	}
+	if m.Date.IsZero() {
+		m.Date = time.Now()
+	}
+	if m.Text == "" {
+		m.Text = HTMLToText(m.HTML)
+	}
+	return nil
+}
+
+// Bytes renders the message as RFC 5322 / MIME: encoded headers and a multipart/alternative body
+// (quoted-printable text and HTML parts), wrapped in multipart/mixed with base64 attachments when there are
+// any. Calls Prepare.
+func (m *Message) Bytes() ([]byte, error) {
+	if err := m.Prepare(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	h := func(k, v string) {
+		buf.WriteString(k + ": " + v + "\r\n")
+	}
+	h("From", m.From.String())
+	h("To", m.To.String())
+	if m.ReplyTo != "" {
+		h("Reply-To", m.ReplyTo)
+	}
+	h("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	h("Date", m.Date.Format(time.RFC1123Z))
+	h("Message-ID", "<"+m.MessageID+">")
+	h("MIME-Version", "1.0")
+	if list := m.listHeaders(); list != nil {
+		h("List-Unsubscribe", list["List-Unsubscribe"])
+		if v, ok := list["List-Unsubscribe-Post"]; ok {
+			h("List-Unsubscribe-Post", v)
+		}
+	}
+	keys := make([]string, 0, len(m.Headers))
+	for k := range m.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		h(textproto.CanonicalMIMEHeaderKey(k), m.Headers[k])
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", m.Text},
+		{"text/html; charset=UTF-8", m.HTML},
+	} {
+		if part.content == "" {
+			continue
+		}
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	if len(m.Attachments) == 0 {
+		h("Content-Type", `multipart/alternative; boundary="`+mw.Boundary()+`"`)
+		buf.WriteString("\r\n")
+		buf.Write(body.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	var mixed bytes.Buffer
+	xw := multipart.NewWriter(&mixed)
+	w, err := xw.CreatePart(textproto.MIMEHeader{"Content-Type": {`multipart/alternative; boundary="` + mw.Boundary() + `"`}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(body.Bytes()); err != nil {
+		return nil, err
+	}
+	for _, a := range m.Attachments {
+		mediaType, params, err := mime.ParseMediaType(a.ContentType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: attachment %q: %v", ErrRejected, a.Filename, err)
+		}
+		params["name"] = a.Filename
+		w, err := xw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(mediaType, params)},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		enc := base64.StdEncoding.EncodeToString(a.Data)
+		for len(enc) > 76 {
+			if _, err := w.Write([]byte(enc[:76] + "\r\n")); err != nil {
+				return nil, err
+			}
+			enc = enc[76:]
+		}
+		if _, err := w.Write([]byte(enc + "\r\n")); err != nil {
+			return nil, err
+		}
+	}
+	if err := xw.Close(); err != nil {
+		return nil, err
+	}
+	h("Content-Type", `multipart/mixed; boundary="`+xw.Boundary()+`"`)
+	buf.WriteString("\r\n")
+	buf.Write(mixed.Bytes())
+	return buf.Bytes(), nil
+}
+
+// listHeaders returns List-Unsubscribe (and List-Unsubscribe-Post) when unsubscribe URIs are set.
+func (m *Message) listHeaders() map[string]string {
+	if len(m.ListUnsubscribe) == 0 {
+		return nil
+	}
+	uris := make([]string, len(m.ListUnsubscribe))
+	for i, u := range m.ListUnsubscribe {
+		uris[i] = "<" + u + ">"
+	}
+	out := map[string]string{"List-Unsubscribe": strings.Join(uris, ", ")}
+	if m.OneClickUnsubscribe {
+		out["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+	}
+	return out
+}
+
+// NewMessageID returns a unique Message-ID (without angle brackets) in the sender's domain.
+func NewMessageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		domain = from[i+1:]
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%d.%s@%s", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
+
+var (
+	reAnchor    = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	reBreak     = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr)>`)
+	reDrop      = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	reTag       = regexp.MustCompile(`(?s)<[^>]*>`)
+	reBlankRuns = regexp.MustCompile(`\n{3,}`)
+	reSpaces    = regexp.MustCompile(`[ \t]+`)
+)
+
+// HTMLToText makes a readable plain-text alternative: links become "label (url)", block ends become
+// line breaks, other tags are dropped and entities decoded.
+func HTMLToText(s string) string {
+	s = reDrop.ReplaceAllString(s, "")
+	s = reAnchor.ReplaceAllStringFunc(s, func(a string) string {
+		m := reAnchor.FindStringSubmatch(a)
+		label := strings.TrimSpace(reTag.ReplaceAllString(m[2], ""))
+		if label == "" || label == m[1] {
+			return m[1]
+		}
+		return label + " (" + m[1] + ")"
+	})
+	s = reBreak.ReplaceAllString(s, "\n")
+	s = reTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(reSpaces.ReplaceAllString(l, " "))
+	}
+	s = strings.Join(lines, "\n")
+	return strings.TrimSpace(reBlankRuns.ReplaceAllString(s, "\n\n"))
+}
+
+// ErrRejected marks a message the provider (or validation) refused outright; retrying will not help.
+var ErrRejected = errors.New("email rejected")
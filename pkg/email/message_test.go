@@ -0,0 +1,195 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nMETHOD:REQUEST\r\nBEGIN:VEVENT\r\nSUMMARY:Präsentation\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+func testMessage() *Message {
+	return &Message{
+		From:    Address{Name: "Zoë from Aura", Email: "events@aura.test"},
+		To:      Address{Name: "Jürgen Müller", Email: "juergen@example.com"},
+		Subject: "Ihre Anmeldung: Präsentation über Café-Kultur ☕",
+		HTML: `<p>Hallo Jürgen,</p><p>` + strings.Repeat("Wir freuen uns auf Sie. ", 6) +
+			`<a href="https://aura.test/join?token=a=b">Beitreten</a></p>`,
+		ListUnsubscribe:     []string{"https://aura.test/unsubscribe/t"},
+		OneClickUnsubscribe: true,
+		Headers:             map[string]string{"x-entity-ref-id": "reg-1"},
+		Attachments: []Attachment{{
+			Filename:    "invite.ics",
+			ContentType: "text/calendar; charset=UTF-8; method=REQUEST",
+			Data:        []byte(testICS),
+		}},
+		Date: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+	}
+}
+
+// readPart returns the decoded body of a MIME part.
+func readPart(t *testing.T, p *multipart.Part) string {
+	t.Helper()
+	var r io.Reader = p
+	switch p.Header.Get("Content-Transfer-Encoding") {
+	case "quoted-printable":
+		r = quotedprintable.NewReader(p)
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, p)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read part: %v", err)
+	}
+	return string(b)
+}
+
+func TestMemorySinkRendersMultipartWithCalendar(t *testing.T) {
+	sink := NewMemorySink()
+	msg := testMessage()
+	if _, err := sink.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got := sink.Messages(); len(got) != 1 || got[0] != msg {
+		t.Fatalf("captured %d messages", len(got))
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for i, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 998 {
+			t.Fatalf("line %d longer than RFC 5322 allows", i+1)
+		}
+		for _, r := range line {
+			if r > 127 {
+				t.Fatalf("line %d is not ASCII: %q", i+1, line)
+			}
+		}
+	}
+
+	if !bytes.Contains(raw, []byte("=\r\n")) || !bytes.Contains(raw, []byte("J=C3=BCrgen")) {
+		t.Error("body is not quoted-printable with soft line breaks")
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("subject %q (%v), want %q", subject, err, msg.Subject)
+	}
+	from, err := parsed.Header.AddressList("From")
+	if err != nil || len(from) != 1 || from[0].Name != msg.From.Name || from[0].Address != msg.From.Email {
+		t.Errorf("from %v (%v)", from, err)
+	}
+	to, err := parsed.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Name != msg.To.Name {
+		t.Errorf("to %v (%v)", to, err)
+	}
+	for k, want := range map[string]string{
+		"Message-ID":            "<" + msg.MessageID + ">",
+		"Date":                  "Sun, 01 Mar 2026 09:30:00 +0000",
+		"List-Unsubscribe":      "<https://aura.test/unsubscribe/t>",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		"X-Entity-Ref-Id":       "reg-1",
+	} {
+		if got := parsed.Header.Get(k); got != want {
+			t.Errorf("%s %q, want %q", k, got, want)
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("content type %q (%v), want multipart/mixed", mediaType, err)
+	}
+	mixed := multipart.NewReader(parsed.Body, params["boundary"])
+
+	alt, err := mixed.NextPart()
+	if err != nil {
+		t.Fatalf("alternative part: %v", err)
+	}
+	mediaType, params, _ = mime.ParseMediaType(alt.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("first part %q, want multipart/alternative", mediaType)
+	}
+	bodies := map[string]string{}
+	altReader := multipart.NewReader(alt, params["boundary"])
+	for {
+		p, err := altReader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("alternative: %v", err)
+		}
+		if enc := p.Header.Get("Content-Transfer-Encoding"); enc != "quoted-printable" {
+			t.Errorf("%s encoded as %q", p.Header.Get("Content-Type"), enc)
+		}
+		mediaType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		bodies[mediaType] = readPart(t, p)
+	}
+	if bodies["text/html"] != msg.HTML {
+		t.Errorf("html part %q, want %q", bodies["text/html"], msg.HTML)
+	}
+	if !strings.HasPrefix(bodies["text/plain"], "Hallo Jürgen,") ||
+		!strings.Contains(bodies["text/plain"], "Beitreten (https://aura.test/join?token=a=b)") {
+		t.Errorf("text part %q", bodies["text/plain"])
+	}
+
+	att, err := mixed.NextPart()
+	if err != nil {
+		t.Fatalf("attachment part: %v", err)
+	}
+	mediaType, params, _ = mime.ParseMediaType(att.Header.Get("Content-Type"))
+	if mediaType != "text/calendar" || params["method"] != "REQUEST" || params["charset"] != "UTF-8" || params["name"] != "invite.ics" {
+		t.Errorf("attachment content type %q", att.Header.Get("Content-Type"))
+	}
+	if att.FileName() != "invite.ics" {
+		t.Errorf("attachment filename %q", att.FileName())
+	}
+	if got := readPart(t, att); got != testICS {
+		t.Errorf("attachment %q, want %q", got, testICS)
+	}
+	if _, err := mixed.NextPart(); err != io.EOF {
+		t.Errorf("unexpected part after the attachment: %v", err)
+	}
+}
+
+func TestMemorySinkRejectsInvalidMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		mod  func(*Message)
+	}{
+		{name: "header injection in subject", mod: func(m *Message) { m.Subject = "Hi\r\nBcc: all@example.com" }},
+		{name: "header injection in name", mod: func(m *Message) { m.To.Name = "Ada\nBcc: all@example.com" }},
+		{name: "line break in attachment name", mod: func(m *Message) { m.Attachments[0].Filename = "a\r\n.ics" }},
+		{name: "bad attachment type", mod: func(m *Message) { m.Attachments[0].ContentType = "text/calendar; ;" }},
+		{name: "bad recipient", mod: func(m *Message) { m.To.Email = "not an address" }},
+		{name: "empty body", mod: func(m *Message) { m.HTML = "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := NewMemorySink()
+			msg := testMessage()
+			tt.mod(msg)
+			if _, err := sink.Send(context.Background(), msg); !errors.Is(err, ErrRejected) {
+				t.Fatalf("send = %v, want ErrRejected", err)
+			}
+			if n := len(sink.Messages()); n != 0 {
+				t.Fatalf("captured %d rejected messages", n)
+			}
+		})
+	}
+}
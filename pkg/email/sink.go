@@ -0,0 +1,78 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileSink writes each message as an .eml file instead of sending it (local development).
+type FileSink struct {
+	dir string
+}
+
+// NewFileSink creates a sink writing into dir (created if missing).
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("email sink dir: %w", err)
+	}
+	return &FileSink{dir: dir}, nil
+}
+
+// Send implements Transport.
+func (s *FileSink) Send(ctx context.Context, msg *Message) (string, error) {
+	raw, err := msg.Bytes()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.eml", msg.Date.UTC().Format("20060102T150405Z"), strings.SplitN(msg.MessageID, "@", 2)[0])
+	if err := os.WriteFile(filepath.Join(s.dir, name), raw, 0o644); err != nil {
+		return "", err
+	}
+	return msg.MessageID, nil
+}
+
+// Close implements Transport.
+func (s *FileSink) Close() error { return nil }
+
+// MemorySink keeps messages in memory (tests).
+type MemorySink struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+// NewMemorySink creates an empty in-memory sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Send implements Transport. The message is rendered so encoding errors still surface.
+func (s *MemorySink) Send(ctx context.Context, msg *Message) (string, error) {
+	if _, err := msg.Bytes(); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.messages = append(s.messages, msg)
+	s.mu.Unlock()
+	return msg.MessageID, nil
+}
+
+// Messages returns the messages captured so far.
+func (s *MemorySink) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message(nil), s.messages...)
+}
+
+// Reset drops captured messages.
+func (s *MemorySink) Reset() {
+	s.mu.Lock()
+	s.messages = nil
+	s.mu.Unlock()
+}
+
+// Close implements Transport.
+func (s *MemorySink) Close() error { return nil }
@@ -1,36 +1,204 @@
 package email
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/smtp"
+	"net/textproto"
 	"net/url"
 	"strings"
+	"time"
 )
 
-// Config holds SMTP settings.
-type Config struct {
+// SMTPConfig holds SMTP settings.
+type SMTPConfig struct {
 	Host     string
-	Port     int
+	Port     int // 465 uses implicit TLS; other ports upgrade with STARTTLS
 	User     string
 	Password string
-	From     string
-	FromName string
+	// PoolSize caps open connections (and concurrent sends); default 4.
+	PoolSize int
+	// IdleTimeout closes pooled connections unused for this long; default 1 minute.
+	IdleTimeout time.Duration
 }
 
-// Send sends an email via SMTP.
-func Send(cfg Config, to, subject, bodyHTML string) error {
-	if cfg.Host == "" || cfg.User == "" || cfg.Password == "" {
-		return fmt.Errorf("smtp not configured")
+const smtpSendTimeout = time.Minute
+
+// SMTPTransport sends over a pool of authenticated SMTP connections, reusing each for many messages.
+type SMTPTransport struct {
+	cfg  SMTPConfig
+	sem  chan struct{}
+	idle chan *smtpConn
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// NewSMTPTransport creates a pooled SMTP transport. Connections are opened lazily.
+func NewSMTPTransport(cfg SMTPConfig) (*SMTPTransport, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("smtp not configured")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 4
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = time.Minute
+	}
+	return &SMTPTransport{
+		cfg:  cfg,
+		sem:  make(chan struct{}, cfg.PoolSize),
+		idle: make(chan *smtpConn, cfg.PoolSize),
+	}, nil
+}
+
+// Send implements Transport. 5xx replies from the server wrap ErrRejected.
+func (t *SMTPTransport) Send(ctx context.Context, msg *Message) (string, error) {
+	raw, err := msg.Bytes()
+	if err != nil {
+		return "", err
+	}
+	select {
+	case t.sem <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-t.sem }()
+
+	c, err := t.conn(ctx)
+	if err != nil {
+		return "", err
+	}
+	deadline := time.Now().Add(smtpSendTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = c.conn.SetDeadline(deadline)
+	if err := t.deliver(c.client, msg, raw); err != nil {
+		c.close()
+		return "", smtpError(err)
+	}
+	c.lastUsed = time.Now()
+	select {
+	case t.idle <- c:
+	default:
+		c.close()
+	}
+	return msg.MessageID, nil
+}
+
+func (t *SMTPTransport) deliver(client *smtp.Client, msg *Message, raw []byte) error {
+	if err := client.Mail(msg.From.Email); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To.Email); err != nil {
+		return err
 	}
-	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	auth := smtp.PlainAuth("", cfg.User, cfg.Password, cfg.Host)
-	from := cfg.From
-	if cfg.FromName != "" {
-		from = fmt.Sprintf("%s <%s>", cfg.FromName, cfg.From)
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// conn returns a pooled connection that is still alive, or dials a new one.
+func (t *SMTPTransport) conn(ctx context.Context) (*smtpConn, error) {
+	for {
+		select {
+		case c := <-t.idle:
+			if time.Since(c.lastUsed) < t.cfg.IdleTimeout {
+				_ = c.conn.SetDeadline(time.Now().Add(10 * time.Second))
+				if c.client.Reset() == nil {
+					return c, nil
+				}
+			}
+			c.close()
+			continue
+		default:
+		}
+		return t.dial(ctx)
+	}
+}
+
+func (t *SMTPTransport) dial(ctx context.Context) (*smtpConn, error) {
+	addr := net.JoinHostPort(t.cfg.Host, fmt.Sprint(t.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: t.cfg.Host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: 15 * time.Second}
+	var conn net.Conn
+	var err error
+	if t.cfg.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("smtp dial: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	client, err := smtp.NewClient(conn, t.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake: %w", err)
+	}
+	c := &smtpConn{conn: conn, client: client}
+	if _, isTLS := conn.(*tls.Conn); !isTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				c.close()
+				return nil, fmt.Errorf("smtp starttls: %w", err)
+			}
+		} else if t.cfg.User != "" {
+			c.close()
+			return nil, fmt.Errorf("smtp: server does not offer STARTTLS; refusing to send credentials in clear text")
+		}
+	}
+	if t.cfg.User != "" {
+		if err := client.Auth(smtp.PlainAuth("", t.cfg.User, t.cfg.Password, t.cfg.Host)); err != nil {
+			c.close()
+			return nil, fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *smtpConn) close() {
+	_ = c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if c.client.Quit() != nil {
+		c.conn.Close()
+	}
+}
+
+// Close implements Transport: quits idle connections.
+func (t *SMTPTransport) Close() error {
+	for {
+		select {
+		case c := <-t.idle:
+			c.close()
+		default:
+			return nil
+		}
+	}
+}
+
+// smtpError marks permanent (5xx) server replies as rejected.
+func smtpError(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return fmt.Errorf("%w: smtp %d %s", ErrRejected, tpErr.Code, tpErr.Msg)
 	}
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s",
-		from, to, subject, bodyHTML)
-	return smtp.SendMail(addr, auth, cfg.From, []string{to}, []byte(msg))
+	return fmt.Errorf("smtp: %w", err)
 }
 
 // BuildJoinURL returns the full audience join URL.
@@ -0,0 +1,148 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// plainSMTPServer is an SMTP server without STARTTLS that records the commands and message data it receives.
+type plainSMTPServer struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	cmds []string
+	data []string
+}
+
+func newPlainSMTPServer(t *testing.T) *plainSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &plainSMTPServer{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *plainSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 mail.test ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		s.mu.Lock()
+		s.cmds = append(s.cmds, line)
+		s.mu.Unlock()
+		switch verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); verb {
+		case "EHLO":
+			reply("250-mail.test")
+			reply("250 AUTH PLAIN")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = append(s.data, data.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *plainSMTPServer) received() (cmds, data []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.cmds...), append([]string(nil), s.data...)
+}
+
+func (s *plainSMTPServer) transport(t *testing.T, user string) *SMTPTransport {
+	t.Helper()
+	port := s.ln.Addr().(*net.TCPAddr).Port
+	tr, err := NewSMTPTransport(SMTPConfig{Host: "127.0.0.1", Port: port, User: user, Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tr.Close() })
+	return tr
+}
+
+func TestSMTPRefusesCredentialsWithoutSTARTTLS(t *testing.T) {
+	s := newPlainSMTPServer(t)
+	tr := s.transport(t, "mailer")
+
+	_, err := tr.Send(context.Background(), testMessage())
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("send = %v, want a STARTTLS error", err)
+	}
+	cmds, data := s.received()
+	for _, c := range cmds {
+		if verb := strings.ToUpper(strings.SplitN(c, " ", 2)[0]); verb == "AUTH" || verb == "MAIL" {
+			t.Fatalf("server received %q", c)
+		}
+	}
+	if len(data) != 0 {
+		t.Fatalf("server received %d messages", len(data))
+	}
+}
+
+func TestSMTPSendsWithoutCredentialsInClear(t *testing.T) {
+	s := newPlainSMTPServer(t)
+	tr := s.transport(t, "")
+	msg := testMessage()
+
+	if _, err := tr.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	cmds, data := s.received()
+	for _, c := range cmds {
+		if strings.HasPrefix(strings.ToUpper(c), "AUTH") {
+			t.Fatalf("server received %q", c)
+		}
+	}
+	if len(data) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(data))
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(data[0]))
+	if err != nil {
+		t.Fatalf("parse delivered message: %v", err)
+	}
+	if got := parsed.Header.Get("Message-ID"); got != "<"+msg.MessageID+">" {
+		t.Fatalf("delivered Message-ID %q, want <%s>", got, msg.MessageID)
+	}
+	if !strings.HasPrefix(parsed.Header.Get("Content-Type"), "multipart/mixed;") {
+		t.Fatalf("delivered Content-Type %q", parsed.Header.Get("Content-Type"))
+	}
+}
@@ -0,0 +1,14 @@
+package email
+
+import "context"
+
+// Transport delivers messages: pooled SMTP, an HTTP API provider (SendGrid, SES) or a sink.
+// Implementations are safe for concurrent use.
+type Transport interface {
+	// Send delivers msg and returns the message ID to record on the email log: the provider's ID
+	// when it assigns one (used by its event webhooks), otherwise msg.MessageID.
+	// Errors wrapping ErrRejected are permanent.
+	Send(ctx context.Context, msg *Message) (string, error)
+	// Close releases connections. Send must not be called afterwards.
+	Close() error
+}
@@ -0,0 +1,185 @@
+// Package ical writes iCalendar (RFC 5545) data: invitations attached to emails (iTIP methods, RFC 5546)
+// and subscribable calendar feeds.
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Calendar methods (RFC 5546). An update is a REQUEST with the same UID and a higher SEQUENCE.
+const (
+	MethodPublish = "PUBLISH" // feeds: no scheduling semantics
+	MethodRequest = "REQUEST" // an invitation, or an update of one
+	MethodCancel  = "CANCEL"
+)
+
+// Event statuses.
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// ContentType is the MIME type of calendar data; method is added as a parameter when set.
+func ContentType(method string) string {
+	ct := "text/calendar; charset=UTF-8"
+	if method != "" {
+		ct += "; method=" + method
+	}
+	return ct
+}
+
+// Person is an organizer or attendee.
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Event is one VEVENT.
+type Event struct {
+	UID         string // stable across updates of the same event
+	Sequence    int    // incremented on every significant change (time, cancellation)
+	Start       time.Time
+	End         time.Time // defaults to Start + 1 hour
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Status      string // Status*; empty omits it
+	Organizer   *Person
+	Attendee    *Person
+	Modified    time.Time // LAST-MODIFIED; omitted when zero
+}
+
+// Calendar is a VCALENDAR with its events.
+type Calendar struct {
+	ProdID string // e.g. "-//Aura Webinar//EN"
+	Method string // Method*; empty omits it
+	Name   string // X-WR-CALNAME, shown for subscribed feeds
+	// Refresh is how often subscribers should re-fetch a feed (REFRESH-INTERVAL, X-PUBLISHED-TTL).
+	Refresh time.Duration
+	Events  []Event
+	// Stamp is DTSTAMP for every event; defaults to now.
+	Stamp time.Time
+}
+
+// Bytes renders the calendar with CRLF line endings and lines folded at 75 octets.
+func (c *Calendar) Bytes() []byte {
+	stamp := c.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	var b bytes.Buffer
+	line := func(name, value string) {
+		fold(&b, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", text(c.ProdID))
+	line("CALSCALE", "GREGORIAN")
+	if c.Method != "" {
+		line("METHOD", c.Method)
+	}
+	if c.Name != "" {
+		line("X-WR-CALNAME", text(c.Name))
+	}
+	if c.Refresh > 0 {
+		d := duration(c.Refresh)
+		fold(&b, "REFRESH-INTERVAL;VALUE=DURATION:"+d)
+		line("X-PUBLISHED-TTL", d)
+	}
+	for _, e := range c.Events {
+		end := e.End
+		if end.IsZero() || !end.After(e.Start) {
+			end = e.Start.Add(time.Hour)
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", text(e.UID))
+		line("DTSTAMP", utc(stamp))
+		line("SEQUENCE", strconv.Itoa(e.Sequence))
+		line("DTSTART", utc(e.Start))
+		line("DTEND", utc(end))
+		line("SUMMARY", text(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", text(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", text(e.Location))
+		}
+		if e.URL != "" {
+			fold(&b, "URL;VALUE=URI:"+e.URL)
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		if e.Organizer != nil {
+			fold(&b, "ORGANIZER"+cn(e.Organizer.Name)+":mailto:"+e.Organizer.Email)
+		}
+		if e.Attendee != nil {
+			fold(&b, "ATTENDEE"+cn(e.Attendee.Name)+";ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED;RSVP=FALSE:mailto:"+e.Attendee.Email)
+		}
+		if !e.Modified.IsZero() {
+			line("LAST-MODIFIED", utc(e.Modified))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.Bytes()
+}
+
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// text escapes a TEXT value: backslash, semicolon, comma and line breaks.
+func text(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// cn returns a ;CN= parameter for name (quoted; characters a quoted parameter cannot hold are dropped).
+func cn(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '"' || r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + name + `"`
+}
+
+// duration formats d as an RFC 5545 DURATION (whole minutes, at least one).
+func duration(d time.Duration) string {
+	m := int(d / time.Minute)
+	if m < 1 {
+		m = 1
+	}
+	if m%60 == 0 {
+		return "PT" + strconv.Itoa(m/60) + "H"
+	}
+	return "PT" + strconv.Itoa(m) + "M"
+}
+
+// fold writes a content line, breaking it into 75-octet lines continued with a leading space, never inside
+// a UTF-8 sequence.
+func fold(b *bytes.Buffer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,136 @@
+// Package leader elects a single leader among replicas with a Redis lease.
+package leader
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// KeyPrefix is the Redis key prefix for leases.
+const KeyPrefix = "leader:"
+
+// acquireScript takes the lease if it is free, or renews it if this candidate already holds it.
+// KEYS: lease. ARGV: candidate ID, TTL ms. Returns 1 while this candidate holds the lease.
+var acquireScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == ARGV[1] then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+  return 1
+end
+if not holder then
+  redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+  return 1
+end
+return 0
+`)
+
+// releaseScript deletes the lease only if this candidate holds it. KEYS: lease. ARGV: candidate ID.
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Elector campaigns for a named lease. The holder renews it every TTL/3; if it dies, another
+// candidate takes over once the lease expires (at most TTL later).
+type Elector struct {
+	client *redis.Client
+	key    string
+	id     string
+	ttl    time.Duration
+	logger *zap.Logger
+
+	mu         sync.Mutex
+	leaseUntil time.Time
+}
+
+// NewElector creates a candidate for the lease name (e.g. "scheduler") with the given TTL.
+func NewElector(client *redis.Client, name string, ttl time.Duration, logger *zap.Logger) *Elector {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	host, _ := os.Hostname()
+	return &Elector{
+		client: client,
+		key:    KeyPrefix + name,
+		id:     host + "/" + uuid.NewString(),
+		ttl:    ttl,
+		logger: logger.With(zap.String("lease", name)),
+	}
+}
+
+// IsLeader reports whether this candidate holds an unexpired lease. It turns false on its own if
+// renewals stop succeeding, before any other candidate can take over.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return time.Now().Before(e.leaseUntil)
+}
+
+// Run campaigns until ctx is cancelled, then releases the lease if held.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	e.campaign(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+func (e *Elector) campaign(ctx context.Context) {
+	// The local deadline is taken before the round trip so it never outlives the lease in Redis.
+	until := time.Now().Add(e.ttl)
+	held, err := acquireScript.Run(ctx, e.client, []string{e.key}, e.id, e.ttl.Milliseconds()).Int()
+	if err != nil {
+		if ctx.Err() == nil {
+			e.logger.Warn("leader lease renewal failed", zap.Error(err))
+		}
+		return
+	}
+	wasLeader := e.IsLeader()
+	e.mu.Lock()
+	if held == 1 {
+		e.leaseUntil = until
+	} else {
+		e.leaseUntil = time.Time{}
+	}
+	e.mu.Unlock()
+	switch {
+	case held == 1 && !wasLeader:
+		e.logger.Info("acquired leadership", zap.String("id", e.id))
+	case held == 0 && wasLeader:
+		e.logger.Warn("lost leadership", zap.String("id", e.id))
+	}
+}
+
+func (e *Elector) release() {
+	if !e.IsLeader() {
+		return
+	}
+	e.mu.Lock()
+	e.leaseUntil = time.Time{}
+	e.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := releaseScript.Run(ctx, e.client, []string{e.key}, e.id).Err(); err != nil {
+		e.logger.Warn("release leader lease failed", zap.Error(err))
+		return
+	}
+	e.logger.Info("released leadership", zap.String("id", e.id))
+}
@@ -0,0 +1,289 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultVisibilityTimeout is how long a job stays leased without a heartbeat before it is re-queued.
+	DefaultVisibilityTimeout = 5 * time.Minute
+	// DefaultDrainTimeout is how long in-flight jobs may finish after shutdown begins.
+	DefaultDrainTimeout = 30 * time.Second
+	// pollTimeout bounds each blocking dequeue so shutdown is noticed promptly.
+	pollTimeout = 2 * time.Second
+	// maintenanceInterval is how often delayed jobs are promoted and expired leases reclaimed.
+	maintenanceInterval = time.Second
+	// depthInterval is how often the DLQ depth gauge is refreshed.
+	depthInterval = 30 * time.Second
+)
+
+// HandlerFunc processes one job. Returning an error retries the job with backoff;
+// wrap it with Permanent to send the job straight to the DLQ.
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error as not worth retrying (bad payload, disallowed input).
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// Consumer runs registered handlers for their job types against a Queue.
+type Consumer struct {
+	q           *Queue
+	handlers    map[JobType]HandlerFunc
+	concurrency map[JobType]int
+	order       []JobType
+	visibility  time.Duration
+	drain       time.Duration
+	logger      *zap.Logger
+
+	running  atomic.Bool
+	draining atomic.Bool
+	inFlight atomic.Int64
+}
+
+// NewConsumer creates a consumer with DefaultVisibilityTimeout and DefaultDrainTimeout.
+func NewConsumer(q *Queue, logger *zap.Logger) *Consumer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Consumer{
+		q:           q,
+		handlers:    make(map[JobType]HandlerFunc),
+		concurrency: make(map[JobType]int),
+		visibility:  DefaultVisibilityTimeout,
+		drain:       DefaultDrainTimeout,
+		logger:      logger,
+	}
+}
+
+// SetVisibilityTimeout sets how long a job may go without a heartbeat before another consumer takes it over.
+func (c *Consumer) SetVisibilityTimeout(d time.Duration) {
+	if d > 0 {
+		c.visibility = d
+	}
+}
+
+// SetDrainTimeout sets how long in-flight jobs may keep running after Run's context is cancelled.
+// Jobs still running then are interrupted and handed back to the queue without using an attempt.
+func (c *Consumer) SetDrainTimeout(d time.Duration) {
+	if d > 0 {
+		c.drain = d
+	}
+}
+
+// SetConcurrency sets how many jobs of type t are processed at once (default 1). Call before Run.
+func (c *Consumer) SetConcurrency(t JobType, n int) {
+	if n > 0 {
+		c.concurrency[t] = n
+	}
+}
+
+// Handle registers the handler for a job type. Call before Run.
+func (c *Consumer) Handle(t JobType, h HandlerFunc) {
+	if _, ok := c.handlers[t]; !ok {
+		c.order = append(c.order, t)
+	}
+	c.handlers[t] = h
+}
+
+// Types returns the registered job types in registration order.
+func (c *Consumer) Types() []JobType {
+	return append([]JobType(nil), c.order...)
+}
+
+// Concurrency returns the number of workers for job type t.
+func (c *Consumer) Concurrency(t JobType) int {
+	if n := c.concurrency[t]; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// Ready reports whether the consumer is taking jobs (running and not draining).
+func (c *Consumer) Ready() bool {
+	return c.running.Load() && !c.draining.Load()
+}
+
+// Draining reports whether the consumer has stopped taking jobs and is finishing in-flight ones.
+func (c *Consumer) Draining() bool {
+	return c.draining.Load()
+}
+
+// InFlight returns the number of jobs currently being processed.
+func (c *Consumer) InFlight() int64 {
+	return c.inFlight.Load()
+}
+
+// Run consumes every registered job type until ctx is cancelled. It then stops taking jobs and waits
+// up to the drain timeout for in-flight jobs; jobs still running after that are interrupted and released.
+func (c *Consumer) Run(ctx context.Context) {
+	if len(c.order) == 0 {
+		c.logger.Warn("queue consumer has no handlers")
+		return
+	}
+	// Handlers get their own context so cancelling ctx stops intake without interrupting work.
+	jobCtx, interrupt := context.WithCancel(context.Background())
+	defer interrupt()
+
+	c.running.Store(true)
+	defer c.running.Store(false)
+
+	var wg sync.WaitGroup
+	for _, t := range c.order {
+		for i := 0; i < c.Concurrency(t); i++ {
+			wg.Add(1)
+			go func(t JobType) {
+				defer wg.Done()
+				c.consume(ctx, jobCtx, t)
+			}(t)
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.maintain(ctx)
+	}()
+
+	<-ctx.Done()
+	c.draining.Store(true)
+	c.logger.Info("queue consumer draining", zap.Int64("in_flight", c.InFlight()), zap.Duration("timeout", c.drain))
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(c.drain):
+		c.logger.Warn("drain timeout reached; interrupting in-flight jobs", zap.Int64("in_flight", c.InFlight()))
+		interrupt()
+		<-done
+	}
+	c.logger.Info("queue consumer stopped")
+}
+
+// consume takes jobs of type t until ctx is cancelled. Dequeue is not tied to ctx so a job is never
+// moved to processing by a request whose reply is lost to cancellation.
+func (c *Consumer) consume(ctx, jobCtx context.Context, t JobType) {
+	h := c.handlers[t]
+	for ctx.Err() == nil {
+		job, err := c.q.Dequeue(context.Background(), t, pollTimeout, c.visibility)
+		if err != nil {
+			c.logger.Warn("dequeue error", zap.String("type", string(t)), zap.Error(err))
+			sleepCtx(ctx, time.Second)
+			continue
+		}
+		if job == nil {
+			continue
+		}
+		c.process(jobCtx, h, job)
+	}
+}
+
+// process runs the handler while heartbeating the lease, then acks, schedules a retry, or (if the
+// handler was interrupted by shutdown) releases the job. Bookkeeping uses a fresh context so a job
+// finishing during shutdown is still acked.
+func (c *Consumer) process(ctx context.Context, h HandlerFunc, job *Job) {
+	c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	logger := c.logger.With(zap.String("job_id", job.ID), zap.String("type", string(job.Type)), zap.Int("attempt", job.Attempt))
+	logger.Debug("processing job")
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.visibility / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.q.Extend(context.Background(), job, c.visibility); err != nil {
+					logger.Warn("extend job lease failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+	err := h(ctx, job)
+	close(done)
+
+	bg, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err == nil {
+		if ackErr := c.q.Ack(bg, job); ackErr != nil {
+			logger.Error("ack job failed", zap.Error(ackErr))
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		logger.Warn("job interrupted by shutdown; releasing", zap.Error(err))
+		if _, relErr := c.q.Release(bg, job); relErr != nil {
+			logger.Error("release job failed; it will be reclaimed after the visibility timeout", zap.Error(relErr))
+		}
+		return
+	}
+	logger.Error("job failed", zap.Error(err))
+	retried, reErr := c.q.Retry(bg, job, err)
+	if reErr != nil {
+		logger.Error("schedule retry failed", zap.Error(reErr))
+	} else if !retried {
+		logger.Warn("job lease expired before it failed; it was reclaimed and is not retried again")
+	}
+}
+
+// maintain promotes due delayed jobs and reclaims expired leases for every registered type,
+// and keeps the DLQ depth gauge current.
+func (c *Consumer) maintain(ctx context.Context) {
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+	depthTicker := time.NewTicker(depthInterval)
+	defer depthTicker.Stop()
+	c.q.refreshDepth(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-depthTicker.C:
+			c.q.refreshDepth(ctx)
+		case <-ticker.C:
+			now := time.Now()
+			for _, t := range c.order {
+				if _, err := c.q.PromoteDue(ctx, t, now); err != nil && ctx.Err() == nil {
+					c.logger.Warn("promote delayed jobs failed", zap.String("type", string(t)), zap.Error(err))
+				}
+				if _, err := c.q.Reclaim(ctx, t, now, c.visibility); err != nil && ctx.Err() == nil {
+					c.logger.Warn("reclaim expired jobs failed", zap.String("type", string(t)), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,236 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// dlqDepth is the number of jobs in QueueDLQ as last observed (published at /debug/vars).
+var dlqDepth = expvar.NewInt("queue_dlq_depth")
+
+// errInvalidEncoding is reported for DLQ entries that are not a valid job envelope.
+const errInvalidEncoding = "invalid job encoding"
+
+// DLQFilter selects dead-lettered jobs. Zero fields match everything.
+type DLQFilter struct {
+	Type      JobType
+	WebinarID uuid.UUID
+}
+
+func (f DLQFilter) match(job *Job) bool {
+	if f.Type != "" && job.Type != f.Type {
+		return false
+	}
+	if f.WebinarID != uuid.Nil && WebinarIDOf(job) != f.WebinarID {
+		return false
+	}
+	return true
+}
+
+// WebinarIDOf returns the webinar_id of a job's payload (uuid.Nil if it has none).
+func WebinarIDOf(job *Job) uuid.UUID {
+	var p struct {
+		WebinarID uuid.UUID `json:"webinar_id"`
+	}
+	_ = json.Unmarshal(job.Payload, &p)
+	return p.WebinarID
+}
+
+// DLQStats is the dead-letter queue depth, total and per job type.
+type DLQStats struct {
+	Depth  int             `json:"depth"`
+	ByType map[JobType]int `json:"by_type"`
+}
+
+// DLQDepth returns the number of dead-lettered jobs and updates the queue_dlq_depth gauge.
+func (q *Queue) DLQDepth(ctx context.Context) (int64, error) {
+	n, err := q.client.LLen(ctx, QueueDLQ).Result()
+	if err != nil {
+		return 0, err
+	}
+	dlqDepth.Set(n)
+	return n, nil
+}
+
+// DLQStats counts dead-lettered jobs per type.
+func (q *Queue) DLQStats(ctx context.Context) (*DLQStats, error) {
+	jobs, err := q.dlqJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := &DLQStats{Depth: len(jobs), ByType: make(map[JobType]int)}
+	for _, job := range jobs {
+		stats.ByType[job.Type]++
+	}
+	dlqDepth.Set(int64(len(jobs)))
+	return stats, nil
+}
+
+// ListDLQ returns dead-lettered jobs matching f (oldest first), skipping offset and returning at most limit,
+// along with the total number of matches.
+func (q *Queue) ListDLQ(ctx context.Context, f DLQFilter, offset, limit int) ([]*Job, int, error) {
+	jobs, err := q.dlqJobs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	matched := make([]*Job, 0, len(jobs))
+	for _, job := range jobs {
+		if f.match(job) {
+			matched = append(matched, job)
+		}
+	}
+	total := len(matched)
+	if offset > total {
+		offset = total
+	}
+	matched = matched[offset:]
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+// GetDLQ returns the dead-lettered job with the given ID, or nil if it is not in the DLQ.
+func (q *Queue) GetDLQ(ctx context.Context, id string) (*Job, error) {
+	jobs, err := q.dlqJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.ID == id && id != "" {
+			return job, nil
+		}
+	}
+	return nil, nil
+}
+
+// replayScript moves one entry from the DLQ to the end of a ready list, if it is still in the DLQ.
+// KEYS: dlq, ready. ARGV: old job, new job.
+var replayScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+  return 0
+end
+redis.call('RPUSH', KEYS[2], ARGV[2])
+return 1
+`)
+
+// ReplayDLQ puts a dead-lettered job back on its ready list with a fresh set of attempts
+// (its failure history is kept). Returns false if the job is not in the DLQ.
+func (q *Queue) ReplayDLQ(ctx context.Context, id string) (bool, error) {
+	job, err := q.GetDLQ(ctx, id)
+	if err != nil || job == nil {
+		return false, err
+	}
+	return q.replay(ctx, job)
+}
+
+// ReplayAllDLQ replays every dead-lettered job matching f. Returns how many were replayed.
+func (q *Queue) ReplayAllDLQ(ctx context.Context, f DLQFilter) (int, error) {
+	jobs, err := q.dlqJobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, job := range jobs {
+		if job.Type == "" || !f.match(job) {
+			continue
+		}
+		ok, err := q.replay(ctx, job)
+		if err != nil {
+			return n, err
+		}
+		if ok {
+			n++
+		}
+	}
+	q.refreshDepth(ctx)
+	return n, nil
+}
+
+func (q *Queue) replay(ctx context.Context, job *Job) (bool, error) {
+	if job.Type == "" {
+		// Undecodable entries have nowhere to go; purge them instead.
+		return false, nil
+	}
+	old := job.raw
+	job.Attempt = 0
+	next, err := json.Marshal(job)
+	if err != nil {
+		return false, err
+	}
+	ok, err := replayScript.Run(ctx, q.client, []string{QueueDLQ, KeyFor(job.Type)}, old, string(next)).Int()
+	if err != nil {
+		return false, err
+	}
+	job.raw = string(next)
+	if ok == 1 {
+		q.logger.Info("replayed job from DLQ", zap.String("job_id", job.ID), zap.String("type", string(job.Type)))
+	}
+	return ok == 1, nil
+}
+
+// DeleteDLQ removes one dead-lettered job. Returns false if it is not in the DLQ.
+func (q *Queue) DeleteDLQ(ctx context.Context, id string) (bool, error) {
+	job, err := q.GetDLQ(ctx, id)
+	if err != nil || job == nil {
+		return false, err
+	}
+	n, err := q.client.LRem(ctx, QueueDLQ, 1, job.raw).Result()
+	if err != nil {
+		return false, err
+	}
+	q.refreshDepth(ctx)
+	return n > 0, nil
+}
+
+// PurgeDLQ deletes every dead-lettered job matching f (including undecodable entries when f is empty).
+// Returns how many were deleted.
+func (q *Queue) PurgeDLQ(ctx context.Context, f DLQFilter) (int, error) {
+	jobs, err := q.dlqJobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, job := range jobs {
+		if !f.match(job) {
+			continue
+		}
+		removed, err := q.client.LRem(ctx, QueueDLQ, 1, job.raw).Result()
+		if err != nil {
+			return n, err
+		}
+		n += int(removed)
+	}
+	q.refreshDepth(ctx)
+	return n, nil
+}
+
+// dlqJobs decodes the whole DLQ, oldest first. Entries that are not valid jobs are returned with an
+// empty Type and LastError set so they can still be listed and purged.
+func (q *Queue) dlqJobs(ctx context.Context) ([]*Job, error) {
+	raws, err := q.client.LRange(ctx, QueueDLQ, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(raws))
+	for _, raw := range raws {
+		job := &Job{}
+		if err := json.Unmarshal([]byte(raw), job); err != nil {
+			job = &Job{LastError: errInvalidEncoding}
+		}
+		job.raw = raw
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (q *Queue) refreshDepth(ctx context.Context) {
+	if _, err := q.DLQDepth(ctx); err != nil {
+		q.logger.Debug("refresh dlq depth failed", zap.Error(err))
+	}
+}
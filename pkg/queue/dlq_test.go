@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// deadLetter enqueues a job and fails it permanently so it lands in the DLQ.
+func deadLetter(t *testing.T, q *Queue, jt JobType, payload interface{}, cause string) string {
+	t.Helper()
+	id, err := q.Enqueue(context.Background(), jt, payload, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := mustDequeue(t, q, jt)
+	if _, err := q.Retry(context.Background(), job, Permanent(errors.New(cause))); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestDLQRecordsFailureHistory(t *testing.T) {
+	q, _ := newTestQueue(t)
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, JobTypeEmail, "x", Options{MaxAttempts: 2}); err != nil {
+		t.Fatal(err)
+	}
+	job := mustDequeue(t, q, JobTypeEmail)
+	if _, err := q.Retry(ctx, job, errors.New("smtp: connection refused")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.PromoteDue(ctx, JobTypeEmail, job.CreatedAt.Add(MaxRetryBackoff*2)); err != nil {
+		t.Fatal(err)
+	}
+	job = mustDequeue(t, q, JobTypeEmail)
+	if _, err := q.Retry(ctx, job, errors.New("smtp: 550 mailbox unavailable")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := q.GetDLQ(ctx, job.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetDLQ = %v, %v", got, err)
+	}
+	if got.LastError != "smtp: 550 mailbox unavailable" {
+		t.Fatalf("last error = %q", got.LastError)
+	}
+	if len(got.Failures) != 2 || got.Failures[0].Attempt != 0 || got.Failures[0].Error != "smtp: connection refused" {
+		t.Fatalf("failures = %+v", got.Failures)
+	}
+}
+
+func TestDLQListFilterReplayPurge(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx := context.Background()
+	webinar := uuid.New()
+
+	emailID := deadLetter(t, q, JobTypeEmail, EmailPayload{WebinarID: webinar}, "bad address")
+	deadLetter(t, q, JobTypeEmail, EmailPayload{WebinarID: uuid.New()}, "bad address")
+	deadLetter(t, q, JobTypeRecordingUpload, RecordingUploadPayload{WebinarID: webinar}, "host not allowed")
+	if err := q.client.RPush(ctx, QueueDLQ, "not json").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _ := q.DLQDepth(ctx); n != 4 {
+		t.Fatalf("depth = %d, want 4", n)
+	}
+	if dlqDepth.Value() != 4 {
+		t.Fatalf("gauge = %d, want 4", dlqDepth.Value())
+	}
+	jobs, total, err := q.ListDLQ(ctx, DLQFilter{Type: JobTypeEmail}, 0, 1)
+	if err != nil || total != 2 || len(jobs) != 1 {
+		t.Fatalf("list by type = %d jobs, total %d, %v", len(jobs), total, err)
+	}
+	jobs, total, _ = q.ListDLQ(ctx, DLQFilter{WebinarID: webinar}, 0, 0)
+	if total != 2 {
+		t.Fatalf("list by webinar total = %d, want 2", total)
+	}
+
+	ok, err := q.ReplayDLQ(ctx, emailID)
+	if err != nil || !ok {
+		t.Fatalf("replay = %v, %v", ok, err)
+	}
+	job := mustDequeue(t, q, JobTypeEmail)
+	if job.ID != emailID || job.Attempt != 0 || job.LastError != "bad address" {
+		t.Fatalf("replayed job = %+v", job)
+	}
+	if ok, _ := q.ReplayDLQ(ctx, emailID); ok {
+		t.Fatal("replayed a job that is no longer in the DLQ")
+	}
+
+	if n, err := q.ReplayAllDLQ(ctx, DLQFilter{Type: JobTypeRecordingUpload}); err != nil || n != 1 {
+		t.Fatalf("replay all = %d, %v", n, err)
+	}
+	if l := listLen(t, mr, QueueRecordings); l != 1 {
+		t.Fatalf("recordings ready len = %d, want 1", l)
+	}
+
+	if n, err := q.PurgeDLQ(ctx, DLQFilter{}); err != nil || n != 2 {
+		t.Fatalf("purge = %d, %v; want 2 (one email, one invalid)", n, err)
+	}
+	if n, _ := q.DLQDepth(ctx); n != 0 {
+		t.Fatalf("depth after purge = %d", n)
+	}
+}
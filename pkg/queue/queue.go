@@ -3,7 +3,9 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,12 +20,34 @@ const (
 	QueueEmails = "worker:emails"
 	// QueueAnalytics is the Redis list key for analytics processing jobs.
 	QueueAnalytics = "worker:analytics"
+	// QueueReminders is the Redis list key for webinar reminder fan-out jobs.
+	QueueReminders = "worker:reminders"
+	// QueueImports is the Redis list key for bulk registration import jobs.
+	QueueImports = "worker:imports"
 	// QueueDLQ is the dead-letter queue for failed jobs after retries.
 	QueueDLQ = "worker:dlq"
-	// MaxRetries is the number of times to retry a job before moving to DLQ.
+	// MaxRetries is the default number of attempts before a job moves to the DLQ.
 	MaxRetries = 3
-	// RetryBackoff is the delay between retries.
+	// RetryBackoff is the delay before the first retry; it doubles on each further attempt.
 	RetryBackoff = 10 * time.Second
+	// MaxRetryBackoff caps the exponential retry delay.
+	MaxRetryBackoff = 15 * time.Minute
+	// DefaultDedupTTL is how long a dedup key blocks identical enqueues when Options.DedupTTL is unset.
+	DefaultDedupTTL = 24 * time.Hour
+
+	// dedupKeyPrefix namespaces dedup keys per job type.
+	dedupKeyPrefix = "worker:dedup:"
+	// maxFailureHistory bounds how many failures a job envelope keeps (oldest dropped first).
+	maxFailureHistory = 10
+	// maxErrorLength truncates recorded error messages.
+	maxErrorLength = 1000
+)
+
+// Per-queue key suffixes: in-flight jobs, their visibility deadlines, and jobs waiting for a delay/backoff.
+const (
+	processingSuffix = ":processing"
+	leasesSuffix     = ":leases"
+	delayedSuffix    = ":delayed"
 )
 
 // JobType identifies the job kind.
@@ -33,13 +57,32 @@ const (
 	JobTypeRecordingUpload JobType = "recording_upload"
 	JobTypeEmail           JobType = "email"
 	JobTypeAnalytics       JobType = "analytics"
+	JobTypeReminder        JobType = "reminder"
+	JobTypeImport          JobType = "registration_import"
 )
 
+// queueKeys maps job types to their ready lists. Other types use "worker:<type>".
+var queueKeys = map[JobType]string{
+	JobTypeRecordingUpload: QueueRecordings,
+	JobTypeEmail:           QueueEmails,
+	JobTypeAnalytics:       QueueAnalytics,
+	JobTypeReminder:        QueueReminders,
+	JobTypeImport:          QueueImports,
+}
+
+// KeyFor returns the ready list key for a job type.
+func KeyFor(t JobType) string {
+	if k, ok := queueKeys[t]; ok {
+		return k
+	}
+	return "worker:" + string(t)
+}
+
 // RecordingUploadPayload is the payload for recording upload jobs.
 type RecordingUploadPayload struct {
 	RecordingID uuid.UUID `json:"recording_id"`
 	WebinarID   uuid.UUID `json:"webinar_id"`
-	OriginalURL string   `json:"original_url"`
+	OriginalURL string    `json:"original_url"`
 }
 
 // EmailPayload is the payload for email jobs.
@@ -54,26 +97,107 @@ type EmailPayload struct {
 	JoinURL         string    `json:"join_url"`
 	VerifyURL       string    `json:"verify_url"`
 	InviteURL       string    `json:"invite_url"` // for speaker invitation
-	Subject         string    `json:"subject"`
-	BodyHTML        string    `json:"body_html"`
+	Subject         string    `json:"subject"`    // overrides the template subject when set
+	BodyHTML        string    `json:"body_html"`  // sent as-is instead of the template when set
+	Locale          string    `json:"locale,omitempty"`
+	UnsubscribeURL  string    `json:"unsubscribe_url,omitempty"` // https one-click target for List-Unsubscribe; the worker signs one when empty
+	CampaignID      uuid.UUID `json:"campaign_id,omitempty"`     // campaign emails render the campaign's subject and body
+	CalendarMethod  string    `json:"calendar_method,omitempty"` // attach the webinar's .ics invite: "REQUEST" (new or updated) or "CANCEL"
 }
 
 // AnalyticsPayload is the payload for analytics processing jobs.
 type AnalyticsPayload struct {
-	WebinarID      uuid.UUID `json:"webinar_id"`
+	WebinarID       uuid.UUID `json:"webinar_id"`
 	StreamSessionID uuid.UUID `json:"stream_session_id"`
 }
 
+// ReminderPayload is the payload for a planned webinar reminder, enqueued with a delay until SendAt.
+// A job whose SendAt no longer matches the reminder (the webinar was rescheduled) is dropped.
+type ReminderPayload struct {
+	ReminderID uuid.UUID `json:"reminder_id"`
+	WebinarID  uuid.UUID `json:"webinar_id"`
+	SendAt     time.Time `json:"send_at"`
+}
+
+// ImportPayload is the payload for a bulk registration import; the CSV and its options are in the database.
+type ImportPayload struct {
+	ImportID  uuid.UUID `json:"import_id"`
+	WebinarID uuid.UUID `json:"webinar_id"`
+}
+
 // Job is a generic job envelope.
 type Job struct {
-	ID        string          `json:"id"`
-	Type      JobType         `json:"type"`
-	Payload   json.RawMessage `json:"payload"`
-	Attempt   int             `json:"attempt"`
-	CreatedAt time.Time       `json:"created_at"`
+	ID          string          `json:"id"`
+	Type        JobType         `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempt     int             `json:"attempt"`
+	MaxAttempts int             `json:"max_attempts,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	// LastError is the error of the most recent failed attempt; Failures keeps the last few attempts.
+	LastError string    `json:"last_error,omitempty"`
+	Failures  []Failure `json:"failures,omitempty"`
+
+	// raw is the exact encoding held in Redis; it identifies the job in the processing list.
+	raw string
+}
+
+// Failure records one failed attempt of a job.
+type Failure struct {
+	Attempt int       `json:"attempt"`
+	Error   string    `json:"error"`
+	At      time.Time `json:"at"`
+}
+
+// recordFailure appends a failure for the current attempt and updates LastError.
+func (j *Job) recordFailure(cause error, at time.Time) {
+	msg := "unknown error"
+	if cause != nil {
+		msg = cause.Error()
+	}
+	if len(msg) > maxErrorLength {
+		msg = msg[:maxErrorLength]
+	}
+	j.LastError = msg
+	j.Failures = append(j.Failures, Failure{Attempt: j.Attempt, Error: msg, At: at})
+	if len(j.Failures) > maxFailureHistory {
+		j.Failures = j.Failures[len(j.Failures)-maxFailureHistory:]
+	}
+}
+
+// Priority orders jobs within a queue.
+type Priority int
+
+const (
+	// PriorityNormal appends to the queue.
+	PriorityNormal Priority = iota
+	// PriorityHigh jumps ahead of every normal job already waiting.
+	PriorityHigh
+)
+
+// Options tune a single Enqueue.
+type Options struct {
+	// Delay postpones the job; it stays in the delayed set until due.
+	Delay time.Duration
+	// Priority of the job (ignored for delayed jobs, which are appended when due).
+	Priority Priority
+	// DedupKey drops the enqueue if a job with the same key and type was enqueued within DedupTTL.
+	DedupKey string
+	DedupTTL time.Duration
+	// MaxAttempts overrides MaxRetries for this job.
+	MaxAttempts int
 }
 
+// errLeaseExpired is recorded on jobs re-queued by Reclaim.
+var errLeaseExpired = errors.New("visibility timeout expired (worker stopped or hung)")
+
+// ErrDuplicate is returned by Enqueue when Options.DedupKey is already taken.
+var ErrDuplicate = errors.New("queue: duplicate job")
+
 // Queue enqueues and dequeues jobs via Redis.
+//
+// Each job type has a ready list. Consumers move a job atomically (BLMOVE) to the type's processing
+// list and lease it until a visibility deadline; a job whose consumer dies is re-queued once the lease
+// expires. Failed jobs wait in a delayed sorted set with exponential backoff, then land in QueueDLQ.
 type Queue struct {
 	client *redis.Client
 	logger *zap.Logger
@@ -87,158 +211,292 @@ func NewQueue(client *redis.Client, logger *zap.Logger) *Queue {
 	return &Queue{client: client, logger: logger}
 }
 
-// EnqueueRecordingUpload enqueues a recording upload job.
-func (q *Queue) EnqueueRecordingUpload(ctx context.Context, payload RecordingUploadPayload) error {
+// enqueueScript optionally claims a dedup key, then pushes the job (front/back of the ready list, or delayed).
+// KEYS: ready, delayed, dedup. ARGV: job, mode ("front"|"back"|"delay"), ready-at ms, dedup TTL ms, job ID.
+var enqueueScript = redis.NewScript(`
+if ARGV[4] ~= '0' then
+  if not redis.call('SET', KEYS[3], ARGV[5], 'NX', 'PX', ARGV[4]) then
+    return 0
+  end
+end
+if ARGV[2] == 'delay' then
+  redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+elseif ARGV[2] == 'front' then
+  redis.call('LPUSH', KEYS[1], ARGV[1])
+else
+  redis.call('RPUSH', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// Enqueue adds a job of type t. payload is JSON-encoded. Returns the job ID, or ErrDuplicate.
+func (q *Queue) Enqueue(ctx context.Context, t JobType, payload interface{}, opts Options) (string, error) {
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshal payload: %w", err)
+		return "", fmt.Errorf("marshal payload: %w", err)
 	}
 	job := Job{
-		ID:        uuid.New().String(),
-		Type:      JobTypeRecordingUpload,
-		Payload:   body,
-		Attempt:   0,
-		CreatedAt: time.Now(),
+		ID:          uuid.New().String(),
+		Type:        t,
+		Payload:     body,
+		MaxAttempts: opts.MaxAttempts,
+		CreatedAt:   time.Now(),
 	}
 	raw, err := json.Marshal(job)
 	if err != nil {
-		return fmt.Errorf("marshal job: %w", err)
-	}
-	if err := q.client.RPush(ctx, QueueRecordings, raw).Err(); err != nil {
-		return fmt.Errorf("rpush: %w", err)
+		return "", fmt.Errorf("marshal job: %w", err)
 	}
-	q.logger.Debug("enqueued recording upload job", zap.String("job_id", job.ID), zap.String("recording_id", payload.RecordingID.String()))
-	return nil
-}
 
-// EnqueueEmail enqueues an email job.
-func (q *Queue) EnqueueEmail(ctx context.Context, payload EmailPayload) error {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("marshal payload: %w", err)
+	mode, readyAt := "back", int64(0)
+	switch {
+	case opts.Delay > 0:
+		mode, readyAt = "delay", time.Now().Add(opts.Delay).UnixMilli()
+	case opts.Priority == PriorityHigh:
+		mode = "front"
 	}
-	job := Job{
-		ID:        uuid.New().String(),
-		Type:      JobTypeEmail,
-		Payload:   body,
-		Attempt:   0,
-		CreatedAt: time.Now(),
+	dedupKey, dedupTTL := "", int64(0)
+	if opts.DedupKey != "" {
+		dedupKey = dedupKeyPrefix + string(t) + ":" + opts.DedupKey
+		ttl := opts.DedupTTL
+		if ttl <= 0 {
+			ttl = DefaultDedupTTL
+		}
+		dedupTTL = ttl.Milliseconds()
 	}
-	raw, err := json.Marshal(job)
+	key := KeyFor(t)
+	ok, err := enqueueScript.Run(ctx, q.client, []string{key, key + delayedSuffix, dedupKey},
+		string(raw), mode, readyAt, dedupTTL, job.ID).Int()
 	if err != nil {
-		return fmt.Errorf("marshal job: %w", err)
+		return "", fmt.Errorf("enqueue: %w", err)
+	}
+	if ok == 0 {
+		return "", ErrDuplicate
 	}
-	if err := q.client.RPush(ctx, QueueEmails, raw).Err(); err != nil {
-		return fmt.Errorf("rpush: %w", err)
+	q.logger.Debug("enqueued job", zap.String("job_id", job.ID), zap.String("type", string(t)), zap.Duration("delay", opts.Delay))
+	return job.ID, nil
+}
+
+// EnqueueRecordingUpload enqueues a recording upload job; the same recording and source URL is enqueued once per hour.
+func (q *Queue) EnqueueRecordingUpload(ctx context.Context, payload RecordingUploadPayload) error {
+	_, err := q.Enqueue(ctx, JobTypeRecordingUpload, payload, Options{
+		DedupKey: payload.RecordingID.String() + ":" + payload.OriginalURL,
+		DedupTTL: time.Hour,
+	})
+	if errors.Is(err, ErrDuplicate) {
+		return nil
 	}
-	q.logger.Debug("enqueued email job", zap.String("job_id", job.ID), zap.String("email_type", payload.EmailType))
-	return nil
+	return err
+}
+
+// EnqueueEmail enqueues an email job.
+func (q *Queue) EnqueueEmail(ctx context.Context, payload EmailPayload) error {
+	_, err := q.Enqueue(ctx, JobTypeEmail, payload, Options{})
+	return err
 }
 
 // EnqueueAnalytics enqueues an analytics processing job.
 func (q *Queue) EnqueueAnalytics(ctx context.Context, payload AnalyticsPayload) error {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("marshal payload: %w", err)
-	}
-	job := Job{
-		ID:        uuid.New().String(),
-		Type:      JobTypeAnalytics,
-		Payload:   body,
-		Attempt:   0,
-		CreatedAt: time.Now(),
-	}
-	raw, err := json.Marshal(job)
-	if err != nil {
-		return fmt.Errorf("marshal job: %w", err)
-	}
-	if err := q.client.RPush(ctx, QueueAnalytics, raw).Err(); err != nil {
-		return fmt.Errorf("rpush: %w", err)
-	}
-	q.logger.Debug("enqueued analytics job", zap.String("job_id", job.ID), zap.String("webinar_id", payload.WebinarID.String()))
-	return nil
+	_, err := q.Enqueue(ctx, JobTypeAnalytics, payload, Options{})
+	return err
 }
 
-// Dequeue blocks until a job is available or ctx is done. Returns job and key (queue name).
-func (q *Queue) Dequeue(ctx context.Context) (*Job, string, error) {
-	result, err := q.client.BLPop(ctx, 0, QueueRecordings).Result()
+// Dequeue waits up to timeout for a job of type t, moves it to the processing list and leases it
+// until now+visibility. Returns nil, nil when the wait times out.
+func (q *Queue) Dequeue(ctx context.Context, t JobType, timeout, visibility time.Duration) (*Job, error) {
+	key := KeyFor(t)
+	raw, err := q.client.BLMove(ctx, key, key+processingSuffix, "LEFT", "RIGHT", timeout).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, "", nil
+			return nil, nil
 		}
-		return nil, "", err
+		return nil, err
 	}
-	if len(result) < 2 {
-		return nil, "", nil
+	if err := q.client.ZAdd(ctx, key+leasesSuffix, redis.Z{Score: float64(time.Now().Add(visibility).UnixMilli()), Member: raw}).Err(); err != nil {
+		// The reaper leases unleased processing entries, so the job is not lost.
+		q.logger.Warn("lease job failed", zap.Error(err))
 	}
 	var job Job
-	if err := json.Unmarshal([]byte(result[1]), &job); err != nil {
-		q.logger.Warn("invalid job payload", zap.String("raw", result[1]), zap.Error(err))
-		return nil, "", nil
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		q.logger.Warn("invalid job payload moved to DLQ", zap.String("raw", raw), zap.Error(err))
+		_, _ = moveScript.Run(ctx, q.client, []string{key + processingSuffix, key + leasesSuffix, QueueDLQ}, raw, raw, "list", 0).Result()
+		return nil, nil
 	}
-	return &job, result[0], nil
+	job.raw = raw
+	return &job, nil
+}
+
+// Extend pushes a leased job's visibility deadline to now+visibility (heartbeat for long jobs).
+func (q *Queue) Extend(ctx context.Context, job *Job, visibility time.Duration) error {
+	key := KeyFor(job.Type)
+	return q.client.ZAddXX(ctx, key+leasesSuffix, redis.Z{Score: float64(time.Now().Add(visibility).UnixMilli()), Member: job.raw}).Err()
+}
+
+// Ack removes a finished job from the processing list.
+func (q *Queue) Ack(ctx context.Context, job *Job) error {
+	key := KeyFor(job.Type)
+	_, err := q.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.LRem(ctx, key+processingSuffix, 1, job.raw)
+		p.ZRem(ctx, key+leasesSuffix, job.raw)
+		return nil
+	})
+	return err
 }
 
-// DequeueEmail blocks until an email job is available or ctx is done.
-func (q *Queue) DequeueEmail(ctx context.Context) (*Job, string, error) {
-	result, err := q.client.BLPop(ctx, 0, QueueEmails).Result()
+// moveScript takes a job out of processing (if it is still there) and pushes its new encoding to a list
+// (back, or front) or sorted set. KEYS: processing, leases, destination. ARGV: old job, new job,
+// "list"|"front"|"zset", score. Returns 0 when the job had already left processing (acked or reclaimed elsewhere).
+var moveScript = redis.NewScript(`
+redis.call('ZREM', KEYS[2], ARGV[1])
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+  return 0
+end
+if ARGV[3] == 'zset' then
+  redis.call('ZADD', KEYS[3], ARGV[4], ARGV[2])
+elseif ARGV[3] == 'front' then
+  redis.call('LPUSH', KEYS[3], ARGV[2])
+else
+  redis.call('RPUSH', KEYS[3], ARGV[2])
+end
+return 1
+`)
+
+// Release hands an unfinished job back to the front of its ready list without counting an attempt
+// (e.g. its worker is shutting down). Returns false if the job had already left processing.
+func (q *Queue) Release(ctx context.Context, job *Job) (bool, error) {
+	key := KeyFor(job.Type)
+	moved, err := moveScript.Run(ctx, q.client, []string{key + processingSuffix, key + leasesSuffix, key}, job.raw, job.raw, "front", 0).Int()
 	if err != nil {
-		if err == redis.Nil {
-			return nil, "", nil
-		}
-		return nil, "", err
-	}
-	if len(result) < 2 {
-		return nil, "", nil
-	}
-	var job Job
-	if err := json.Unmarshal([]byte(result[1]), &job); err != nil {
-		q.logger.Warn("invalid email job payload", zap.String("raw", result[1]), zap.Error(err))
-		return nil, "", nil
+		return false, err
 	}
-	return &job, result[0], nil
+	return moved == 1, nil
 }
 
-// Retry re-enqueues a job with incremented attempt. If attempt >= MaxRetries, pushes to DLQ instead.
-func (q *Queue) Retry(ctx context.Context, job *Job) error {
+// Retry records a failed attempt and its cause: the job waits in the delayed set for an exponential
+// backoff, or moves to QueueDLQ once it has used all attempts (or cause is Permanent). Returns false if
+// the job had already left processing (its lease expired and it was reclaimed); nothing is scheduled then.
+func (q *Queue) Retry(ctx context.Context, job *Job, cause error) (bool, error) {
+	key := KeyFor(job.Type)
+	old := job.raw
+	permanent := IsPermanent(cause)
+	job.recordFailure(cause, time.Now())
 	job.Attempt++
 	raw, err := json.Marshal(job)
 	if err != nil {
-		return err
+		return false, err
+	}
+	max := job.MaxAttempts
+	if max <= 0 {
+		max = MaxRetries
 	}
-	if job.Attempt >= MaxRetries {
-		if err := q.client.RPush(ctx, QueueDLQ, raw).Err(); err != nil {
+	keys := []string{key + processingSuffix, key + leasesSuffix, key + delayedSuffix}
+	if permanent || job.Attempt >= max {
+		keys[2] = QueueDLQ
+		moved, err := moveScript.Run(ctx, q.client, keys, old, string(raw), "list", 0).Int()
+		if err != nil {
 			q.logger.Error("dlq push failed", zap.Error(err), zap.String("job_id", job.ID))
-			return err
+			return false, err
 		}
-		q.logger.Warn("job moved to DLQ", zap.String("job_id", job.ID), zap.Int("attempt", job.Attempt))
-		return nil
+		if moved == 0 {
+			return false, nil
+		}
+		job.raw = string(raw)
+		q.logger.Warn("job moved to DLQ", zap.String("job_id", job.ID), zap.String("type", string(job.Type)), zap.Int("attempt", job.Attempt), zap.String("error", job.LastError))
+		return true, nil
+	}
+	delay := Backoff(job.Attempt)
+	moved, err := moveScript.Run(ctx, q.client, keys, old, string(raw), "zset", time.Now().Add(delay).UnixMilli()).Int()
+	if err != nil {
+		return false, err
 	}
-	if err := q.client.RPush(ctx, QueueRecordings, raw).Err(); err != nil {
-		return err
+	if moved == 0 {
+		return false, nil
 	}
-	q.logger.Info("job retried", zap.String("job_id", job.ID), zap.Int("attempt", job.Attempt))
-	return nil
+	job.raw = string(raw)
+	q.logger.Info("job retry scheduled", zap.String("job_id", job.ID), zap.String("type", string(job.Type)), zap.Int("attempt", job.Attempt), zap.Duration("backoff", delay))
+	return true, nil
 }
 
-// RetryEmail re-enqueues an email job with incremented attempt.
-func (q *Queue) RetryEmail(ctx context.Context, job *Job) error {
-	job.Attempt++
-	raw, err := json.Marshal(job)
+// Backoff is the delay before retrying after the given attempt: RetryBackoff doubled per attempt,
+// capped at MaxRetryBackoff, with up to 20% jitter so failed jobs don't retry in lockstep.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := RetryBackoff
+	for i := 1; i < attempt && d < MaxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > MaxRetryBackoff {
+		d = MaxRetryBackoff
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+// promoteScript moves due jobs from the delayed set to the end of the ready list.
+// KEYS: delayed, ready. ARGV: now ms, batch size. Returns the number moved.
+var promoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, raw in ipairs(due) do
+  redis.call('ZREM', KEYS[1], raw)
+  redis.call('RPUSH', KEYS[2], raw)
+end
+return #due
+`)
+
+// PromoteDue moves delayed jobs of type t that are due at now onto the ready list.
+func (q *Queue) PromoteDue(ctx context.Context, t JobType, now time.Time) (int, error) {
+	key := KeyFor(t)
+	return promoteScript.Run(ctx, q.client, []string{key + delayedSuffix, key}, now.UnixMilli(), 100).Int()
+}
+
+// Reclaim re-queues jobs of type t whose lease expired before now (their consumer died or hung).
+// Each reclaim counts as an attempt, so a job that keeps crashing its worker ends in the DLQ.
+// Processing entries without a lease (consumer died right after BLMOVE) are leased for visibility first.
+func (q *Queue) Reclaim(ctx context.Context, t JobType, now time.Time, visibility time.Duration) (int, error) {
+	key := KeyFor(t)
+	inflight, err := q.client.LRange(ctx, key+processingSuffix, 0, -1).Result()
 	if err != nil {
-		return err
+		return 0, err
 	}
-	if job.Attempt >= MaxRetries {
-		if err := q.client.RPush(ctx, QueueDLQ, raw).Err(); err != nil {
-			q.logger.Error("dlq push failed", zap.Error(err), zap.String("job_id", job.ID))
-			return err
+	deadline := float64(now.Add(visibility).UnixMilli())
+	for _, raw := range inflight {
+		if err := q.client.ZAddNX(ctx, key+leasesSuffix, redis.Z{Score: deadline, Member: raw}).Err(); err != nil {
+			return 0, err
 		}
-		q.logger.Warn("email job moved to DLQ", zap.String("job_id", job.ID), zap.Int("attempt", job.Attempt))
-		return nil
 	}
-	if err := q.client.RPush(ctx, QueueEmails, raw).Err(); err != nil {
-		return err
+	expired, err := q.client.ZRangeByScore(ctx, key+leasesSuffix, &redis.ZRangeBy{Min: "-inf", Max: fmt.Sprint(now.UnixMilli())}).Result()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, raw := range expired {
+		var job Job
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			_, _ = moveScript.Run(ctx, q.client, []string{key + processingSuffix, key + leasesSuffix, QueueDLQ}, raw, raw, "list", 0).Result()
+			continue
+		}
+		job.raw = raw
+		job.recordFailure(errLeaseExpired, now)
+		job.Attempt++
+		next, err := json.Marshal(&job)
+		if err != nil {
+			return n, err
+		}
+		dest, max := key, job.MaxAttempts
+		if max <= 0 {
+			max = MaxRetries
+		}
+		if job.Attempt >= max {
+			dest = QueueDLQ
+		}
+		moved, err := moveScript.Run(ctx, q.client, []string{key + processingSuffix, key + leasesSuffix, dest}, raw, string(next), "list", 0).Int()
+		if err != nil {
+			return n, err
+		}
+		if moved == 1 {
+			n++
+			q.logger.Warn("reclaimed job after visibility timeout", zap.String("job_id", job.ID), zap.String("type", string(t)), zap.Int("attempt", job.Attempt), zap.Bool("dlq", dest == QueueDLQ))
+		}
 	}
-	q.logger.Info("email job retried", zap.String("job_id", job.ID), zap.Int("attempt", job.Attempt))
-	return nil
+	return n, nil
 }
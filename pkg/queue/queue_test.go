@@ -0,0 +1,401 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestQueue(t *testing.T) (*Queue, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewQueue(client, nil), mr
+}
+
+func mustDequeue(t *testing.T, q *Queue, jt JobType) *Job {
+	t.Helper()
+	job, err := q.Dequeue(context.Background(), jt, time.Second, time.Minute)
+	if err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+	if job == nil {
+		t.Fatal("dequeue: no job")
+	}
+	return job
+}
+
+func listLen(t *testing.T, mr *miniredis.Miniredis, key string) int {
+	t.Helper()
+	if !mr.Exists(key) {
+		return 0
+	}
+	l, err := mr.List(key)
+	if err != nil {
+		t.Fatalf("list %s: %v", key, err)
+	}
+	return len(l)
+}
+
+func TestEnqueueDequeueAck(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx := context.Background()
+
+	id, err := q.Enqueue(ctx, JobTypeEmail, EmailPayload{RecipientEmail: "a@example.com"}, Options{})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	job := mustDequeue(t, q, JobTypeEmail)
+	if job.ID != id || job.Type != JobTypeEmail {
+		t.Fatalf("got job %s/%s, want %s/email", job.ID, job.Type, id)
+	}
+	var p EmailPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil || p.RecipientEmail != "a@example.com" {
+		t.Fatalf("payload = %s (%v)", job.Payload, err)
+	}
+	if n := listLen(t, mr, QueueEmails+processingSuffix); n != 1 {
+		t.Fatalf("processing len = %d, want 1", n)
+	}
+	if err := q.Ack(ctx, job); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if n := listLen(t, mr, QueueEmails+processingSuffix); n != 0 {
+		t.Fatalf("processing len after ack = %d, want 0", n)
+	}
+	if mr.Exists(QueueEmails + leasesSuffix) {
+		t.Fatal("lease left behind after ack")
+	}
+}
+
+func TestPriority(t *testing.T) {
+	q, _ := newTestQueue(t)
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, JobTypeEmail, "normal", Options{}); err != nil {
+		t.Fatal(err)
+	}
+	high, err := q.Enqueue(ctx, JobTypeEmail, "high", Options{Priority: PriorityHigh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job := mustDequeue(t, q, JobTypeEmail); job.ID != high {
+		t.Fatalf("first job = %s, want high-priority %s", job.ID, high)
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	q, _ := newTestQueue(t)
+	ctx := context.Background()
+
+	opts := Options{DedupKey: "rec-1"}
+	if _, err := q.Enqueue(ctx, JobTypeRecordingUpload, "x", opts); err != nil {
+		t.Fatalf("first enqueue: %v", err)
+	}
+	if _, err := q.Enqueue(ctx, JobTypeRecordingUpload, "x", opts); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("second enqueue err = %v, want ErrDuplicate", err)
+	}
+	// Same key on another type is independent.
+	if _, err := q.Enqueue(ctx, JobTypeEmail, "x", opts); err != nil {
+		t.Fatalf("other type: %v", err)
+	}
+}
+
+func TestDelayedJobPromotedWhenDue(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, JobTypeEmail, "later", Options{Delay: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := q.PromoteDue(ctx, JobTypeEmail, time.Now()); n != 0 {
+		t.Fatalf("promoted %d before due", n)
+	}
+	if n := listLen(t, mr, QueueEmails); n != 0 {
+		t.Fatalf("ready len = %d, want 0", n)
+	}
+	if n, err := q.PromoteDue(ctx, JobTypeEmail, time.Now().Add(2*time.Hour)); err != nil || n != 1 {
+		t.Fatalf("promote = %d, %v; want 1", n, err)
+	}
+	mustDequeue(t, q, JobTypeEmail)
+}
+
+func TestRetryBacksOffThenDeadLetters(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, JobTypeEmail, "flaky", Options{MaxAttempts: 2}); err != nil {
+		t.Fatal(err)
+	}
+	job := mustDequeue(t, q, JobTypeEmail)
+	if _, err := q.Retry(ctx, job, errors.New("smtp timeout")); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if n := listLen(t, mr, QueueEmails+processingSuffix); n != 0 {
+		t.Fatalf("processing len = %d, want 0", n)
+	}
+	delayed, err := mr.ZMembers(QueueEmails + delayedSuffix)
+	if err != nil || len(delayed) != 1 {
+		t.Fatalf("delayed = %v (%v), want 1 job", delayed, err)
+	}
+	score, _ := mr.ZScore(QueueEmails+delayedSuffix, delayed[0])
+	if wait := time.Until(time.UnixMilli(int64(score))); wait < RetryBackoff-time.Second {
+		t.Fatalf("backoff %s, want at least %s", wait, RetryBackoff)
+	}
+
+	if _, err := q.PromoteDue(ctx, JobTypeEmail, time.Now().Add(MaxRetryBackoff*2)); err != nil {
+		t.Fatal(err)
+	}
+	job = mustDequeue(t, q, JobTypeEmail)
+	if job.Attempt != 1 {
+		t.Fatalf("attempt = %d, want 1", job.Attempt)
+	}
+	if _, err := q.Retry(ctx, job, errors.New("smtp timeout")); err != nil {
+		t.Fatal(err)
+	}
+	if n := listLen(t, mr, QueueDLQ); n != 1 {
+		t.Fatalf("dlq len = %d, want 1", n)
+	}
+}
+
+func TestPermanentFailureSkipsRetries(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, JobTypeEmail, "bad", Options{}); err != nil {
+		t.Fatal(err)
+	}
+	job := mustDequeue(t, q, JobTypeEmail)
+	if _, err := q.Retry(ctx, job, Permanent(errors.New("bad payload"))); err != nil {
+		t.Fatal(err)
+	}
+	if n := listLen(t, mr, QueueDLQ); n != 1 {
+		t.Fatalf("dlq len = %d, want 1", n)
+	}
+	if mr.Exists(QueueEmails + delayedSuffix) {
+		t.Fatal("permanent failure was scheduled for retry")
+	}
+}
+
+func TestReclaimExpiredLease(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx := context.Background()
+
+	id, _ := q.Enqueue(ctx, JobTypeRecordingUpload, "upload", Options{})
+	mustDequeue(t, q, JobTypeRecordingUpload) // consumer "crashes": never acks
+
+	if n, _ := q.Reclaim(ctx, JobTypeRecordingUpload, time.Now(), time.Minute); n != 0 {
+		t.Fatalf("reclaimed %d before lease expiry", n)
+	}
+	n, err := q.Reclaim(ctx, JobTypeRecordingUpload, time.Now().Add(2*time.Minute), time.Minute)
+	if err != nil || n != 1 {
+		t.Fatalf("reclaim = %d, %v; want 1", n, err)
+	}
+	if l := listLen(t, mr, QueueRecordings+processingSuffix); l != 0 {
+		t.Fatalf("processing len = %d, want 0", l)
+	}
+	job := mustDequeue(t, q, JobTypeRecordingUpload)
+	if job.ID != id || job.Attempt != 1 {
+		t.Fatalf("reclaimed job %s attempt %d, want %s attempt 1", job.ID, job.Attempt, id)
+	}
+}
+
+func TestRetryAfterReclaimIsSkipped(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, JobTypeEmail, "slow", Options{}); err != nil {
+		t.Fatal(err)
+	}
+	job := mustDequeue(t, q, JobTypeEmail)
+	if n, err := q.Reclaim(ctx, JobTypeEmail, time.Now().Add(2*time.Minute), time.Minute); err != nil || n != 1 {
+		t.Fatalf("reclaim = %d, %v; want 1", n, err)
+	}
+
+	retried, err := q.Retry(ctx, job, errors.New("smtp timeout"))
+	if err != nil || retried {
+		t.Fatalf("retry = %v, %v; want false after the lease was reclaimed", retried, err)
+	}
+	if mr.Exists(QueueEmails + delayedSuffix) {
+		t.Fatal("reclaimed job was also scheduled for retry")
+	}
+	if n := listLen(t, mr, QueueEmails); n != 1 {
+		t.Fatalf("ready len = %d, want only the reclaimed copy", n)
+	}
+}
+
+func TestReclaimLeasesOrphanedProcessingEntry(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, JobTypeEmail, "orphan", Options{}); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a consumer that died between BLMOVE and writing its lease.
+	if err := q.client.LMove(ctx, QueueEmails, QueueEmails+processingSuffix, "LEFT", "RIGHT").Err(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if n, _ := q.Reclaim(ctx, JobTypeEmail, now, time.Minute); n != 0 {
+		t.Fatalf("orphan reclaimed immediately (%d); it should get a full visibility window", n)
+	}
+	if n, _ := q.Reclaim(ctx, JobTypeEmail, now.Add(2*time.Minute), time.Minute); n != 1 {
+		t.Fatalf("orphan not reclaimed after visibility window (%d)", n)
+	}
+	if l := listLen(t, mr, QueueEmails); l != 1 {
+		t.Fatalf("ready len = %d, want 1", l)
+	}
+}
+
+func TestConsumerDispatchesByType(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var emails, uploads atomic.Int32
+	c := NewConsumer(q, nil)
+	c.Handle(JobTypeEmail, func(ctx context.Context, job *Job) error {
+		emails.Add(1)
+		return nil
+	})
+	c.Handle(JobTypeRecordingUpload, func(ctx context.Context, job *Job) error {
+		uploads.Add(1)
+		return Permanent(errors.New("host not allowed"))
+	})
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		if _, err := q.Enqueue(context.Background(), JobTypeEmail, i, Options{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := q.Enqueue(context.Background(), JobTypeRecordingUpload, "x", Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for (emails.Load() < 3 || listLen(t, mr, QueueDLQ) < 1) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if emails.Load() != 3 || uploads.Load() != 1 {
+		t.Fatalf("handled emails=%d uploads=%d, want 3 and 1", emails.Load(), uploads.Load())
+	}
+	if n := listLen(t, mr, QueueEmails+processingSuffix); n != 0 {
+		t.Fatalf("emails left in processing: %d", n)
+	}
+	if n := listLen(t, mr, QueueDLQ); n != 1 {
+		t.Fatalf("dlq len = %d, want 1", n)
+	}
+}
+
+func TestBackoffGrowsAndCaps(t *testing.T) {
+	prev := time.Duration(0)
+	for attempt := 1; attempt <= 4; attempt++ {
+		d := Backoff(attempt)
+		if d < prev {
+			t.Fatalf("backoff(%d) = %s shrank from %s", attempt, d, prev)
+		}
+		prev = d
+	}
+	if d := Backoff(50); d > MaxRetryBackoff+MaxRetryBackoff/5 {
+		t.Fatalf("backoff(50) = %s exceeds cap", d)
+	}
+}
+
+func TestConsumerConcurrency(t *testing.T) {
+	q, _ := newTestQueue(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	c := NewConsumer(q, nil)
+	c.SetConcurrency(JobTypeEmail, 3)
+	c.Handle(JobTypeEmail, func(ctx context.Context, job *Job) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return nil
+	})
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+	for i := 0; i < 3; i++ {
+		if _, err := q.Enqueue(context.Background(), JobTypeEmail, i, Options{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for peak.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+	cancel()
+	<-done
+	if peak.Load() != 3 {
+		t.Fatalf("peak concurrency = %d, want 3", peak.Load())
+	}
+}
+
+func TestConsumerDrainReleasesInterruptedJobs(t *testing.T) {
+	q, mr := newTestQueue(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	c := NewConsumer(q, nil)
+	c.SetDrainTimeout(200 * time.Millisecond)
+	c.Handle(JobTypeRecordingUpload, func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done() // a long upload that only stops when interrupted
+		return ctx.Err()
+	})
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+	id, _ := q.Enqueue(context.Background(), JobTypeRecordingUpload, "big", Options{})
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job not started")
+	}
+	if !c.Ready() {
+		t.Fatal("consumer not ready while running")
+	}
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	if c.Ready() || !c.Draining() {
+		t.Fatal("consumer still ready after shutdown began")
+	}
+	<-done
+
+	if n := listLen(t, mr, QueueRecordings+processingSuffix); n != 0 {
+		t.Fatalf("processing len = %d, want 0", n)
+	}
+	job := mustDequeue(t, q, JobTypeRecordingUpload)
+	if job.ID != id || job.Attempt != 0 {
+		t.Fatalf("released job %s attempt %d, want %s attempt 0", job.ID, job.Attempt, id)
+	}
+}
@@ -0,0 +1,125 @@
+// Package ratelimit implements a Redis-backed sliding-window rate limiter.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyPrefix is the Redis key prefix for rate limit windows.
+const KeyPrefix = "ratelimit:"
+
+// slidingWindowScript keeps one sorted-set member per request (scored by time in ms) and
+// atomically trims expired entries, counts, and admits the request if under the limit.
+// Returns {allowed (0/1), count, ms until the oldest entry leaves the window}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', key, now, ARGV[4])
+  redis.call('PEXPIRE', key, window)
+  count = count + 1
+  allowed = 1
+end
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// Rule is a request budget: at most Limit requests per Window.
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRule parses "N/duration" (e.g. "10/1m", "5/1h"). An empty string or limit 0 disables the rule.
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Rule{}, nil
+	}
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return Rule{}, fmt.Errorf("rate limit %q: want N/duration", s)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || limit < 0 {
+		return Rule{}, fmt.Errorf("rate limit %q: invalid count", s)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || window <= 0 {
+		return Rule{}, fmt.Errorf("rate limit %q: invalid window", s)
+	}
+	return Rule{Limit: limit, Window: window}, nil
+}
+
+// Enabled reports whether the rule limits anything.
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Window > 0
+}
+
+// Result is the outcome of one Allow call.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until a slot frees up (the oldest request in the window expires).
+	Reset time.Duration
+}
+
+// Limiter checks request budgets against Redis.
+type Limiter struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter.
+func NewLimiter(client *redis.Client) *Limiter {
+	return &Limiter{client: client, now: time.Now}
+}
+
+// Allow records a request for key under rule and reports whether it is within the budget.
+// Rejected requests are not recorded, so a client that backs off regains capacity as the window slides.
+func (l *Limiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	if !rule.Enabled() {
+		return Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit}, nil
+	}
+	now := l.now().UnixMilli()
+	vals, err := slidingWindowScript.Run(ctx, l.client, []string{KeyPrefix + key},
+		now, rule.Window.Milliseconds(), rule.Limit, strconv.FormatInt(now, 10)+"-"+uuid.NewString()).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit script: %w", err)
+	}
+	if len(vals) != 3 {
+		return Result{}, fmt.Errorf("rate limit script: unexpected reply")
+	}
+	remaining := rule.Limit - int(vals[1])
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   vals[0] == 1,
+		Limit:     rule.Limit,
+		Remaining: remaining,
+		Reset:     time.Duration(vals[2]) * time.Millisecond,
+	}, nil
+}
+
+// Reset clears the window for key (e.g. after a successful login).
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.client.Del(ctx, KeyPrefix+key).Err()
+}
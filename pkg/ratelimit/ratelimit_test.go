@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLimiter(t *testing.T) (*Limiter, *time.Time) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	now := time.UnixMilli(1_760_000_000_000)
+	l := NewLimiter(client)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestAllowSlidingWindowBoundary(t *testing.T) {
+	l, now := newTestLimiter(t)
+	ctx := context.Background()
+	rule := Rule{Limit: 2, Window: time.Second}
+	start := *now
+
+	steps := []struct {
+		at        time.Duration // since start
+		allowed   bool
+		remaining int
+		reset     time.Duration
+	}{
+		{at: 0, allowed: true, remaining: 1, reset: time.Second},
+		{at: 500 * time.Millisecond, allowed: true, remaining: 0, reset: 500 * time.Millisecond},
+		// Full until the first request leaves the window.
+		{at: 999 * time.Millisecond, allowed: false, remaining: 0, reset: time.Millisecond},
+		// Exactly one window later the first request no longer counts.
+		{at: time.Second, allowed: true, remaining: 0, reset: 500 * time.Millisecond},
+		{at: 1001 * time.Millisecond, allowed: false, remaining: 0, reset: 499 * time.Millisecond},
+		{at: 1500 * time.Millisecond, allowed: true, remaining: 0, reset: 500 * time.Millisecond},
+	}
+	for _, st := range steps {
+		*now = start.Add(st.at)
+		res, err := l.Allow(ctx, "login:ada", rule)
+		if err != nil {
+			t.Fatalf("at %s: %v", st.at, err)
+		}
+		if res.Allowed != st.allowed || res.Remaining != st.remaining || res.Reset != st.reset {
+			t.Fatalf("at %s: got allowed=%v remaining=%d reset=%s, want allowed=%v remaining=%d reset=%s",
+				st.at, res.Allowed, res.Remaining, res.Reset, st.allowed, st.remaining, st.reset)
+		}
+	}
+}
+
+func TestAllowKeysAndReset(t *testing.T) {
+	l, _ := newTestLimiter(t)
+	ctx := context.Background()
+	rule := Rule{Limit: 1, Window: time.Minute}
+
+	if res, _ := l.Allow(ctx, "a", rule); !res.Allowed {
+		t.Fatal("first request for a rejected")
+	}
+	if res, _ := l.Allow(ctx, "a", rule); res.Allowed {
+		t.Fatal("second request for a allowed")
+	}
+	if res, _ := l.Allow(ctx, "b", rule); !res.Allowed {
+		t.Fatal("other key shares a's window")
+	}
+	if err := l.Reset(ctx, "a"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if res, _ := l.Allow(ctx, "a", rule); !res.Allowed {
+		t.Fatal("request after reset rejected")
+	}
+	if res, _ := l.Allow(ctx, "a", Rule{}); !res.Allowed {
+		t.Fatal("disabled rule rejected a request")
+	}
+}
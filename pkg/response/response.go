@@ -33,6 +33,11 @@ func BadRequest(c *gin.Context, err string) {
 	c.JSON(http.StatusBadRequest, Body{Success: false, Error: err})
 }
 
+// BadRequestDetails sends 400 with an error message and details (e.g. field-level errors) as data.
+func BadRequestDetails(c *gin.Context, err string, details interface{}) {
+	c.JSON(http.StatusBadRequest, Body{Success: false, Error: err, Data: details})
+}
+
 // Unauthorized sends 401.
 func Unauthorized(c *gin.Context, err string) {
 	c.JSON(http.StatusUnauthorized, Body{Success: false, Error: err})
@@ -53,6 +58,11 @@ func Conflict(c *gin.Context, err string) {
 	c.JSON(http.StatusConflict, Body{Success: false, Error: err})
 }
 
+// TooManyRequests sends 429.
+func TooManyRequests(c *gin.Context, err string) {
+	c.JSON(http.StatusTooManyRequests, Body{Success: false, Error: err})
+}
+
 // ServiceUnavailable sends 503.
 func ServiceUnavailable(c *gin.Context, err string) {
 	c.JSON(http.StatusServiceUnavailable, Body{Success: false, Error: err})
@@ -0,0 +1,448 @@
+// Package rrule parses RFC 5545 recurrence rules and expands them into occurrence times.
+//
+// Supported: FREQ=DAILY|WEEKLY|MONTHLY|YEARLY with INTERVAL, COUNT, UNTIL, BYDAY (ordinals such as 2TU or
+// -1FR in monthly and yearly rules), BYMONTHDAY, BYMONTH and WKST. Occurrences keep DTSTART's wall-clock
+// time in its location, so a 10:00 weekly session stays at 10:00 across daylight saving changes.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is a rule's FREQ.
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var freqNames = map[string]Frequency{"DAILY": Daily, "WEEKLY": Weekly, "MONTHLY": Monthly, "YEARLY": Yearly}
+
+var dayNames = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// maxEmptyPeriods stops expansion of a rule that can never match again (e.g. BYMONTHDAY=30;BYMONTH=2). It
+// covers the longest gap of a valid rule: a daily rule for February 29th across a skipped leap year.
+const maxEmptyPeriods = 3000
+
+// Weekday is a BYDAY entry: a day, optionally the Nth (negative: from the end) in the month or year.
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+// Rule is a parsed recurrence rule.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int       // 0 = unbounded
+	Until      time.Time // zero = unbounded; see UntilLocal
+	UntilLocal bool      // Until has no zone (floating or date-only): it is read in DTSTART's location
+	ByDay      []Weekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;BYDAY=TU;COUNT=10". An "RRULE:" prefix is accepted.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "RRULE:")
+	if s == "" {
+		return nil, errors.New("rrule: empty rule")
+	}
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	hasFreq := false
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("rrule: invalid part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			f, ok := freqNames[strings.ToUpper(value)]
+			if !ok {
+				return nil, fmt.Errorf("rrule: unsupported FREQ %q", value)
+			}
+			r.Freq, hasFreq = f, true
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(value); err != nil || r.Interval < 1 {
+				return nil, fmt.Errorf("rrule: invalid INTERVAL %q", value)
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(value); err != nil || r.Count < 1 {
+				return nil, fmt.Errorf("rrule: invalid COUNT %q", value)
+			}
+		case "UNTIL":
+			if r.Until, r.UntilLocal, err = parseUntil(value); err != nil {
+				return nil, err
+			}
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				wd, err := parseWeekday(v)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("rrule: invalid BYMONTHDAY %q", v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("rrule: invalid BYMONTH %q", v)
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			d, ok := dayNames[strings.ToUpper(value)]
+			if !ok {
+				return nil, fmt.Errorf("rrule: invalid WKST %q", value)
+			}
+			r.WeekStart = d
+		default:
+			return nil, fmt.Errorf("rrule: unsupported part %s", name)
+		}
+	}
+	if !hasFreq {
+		return nil, errors.New("rrule: FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, errors.New("rrule: COUNT and UNTIL are mutually exclusive")
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, errors.New("rrule: numbered BYDAY is only valid in MONTHLY and YEARLY rules")
+		}
+		if r.Freq == Yearly && len(r.ByMonth) == 0 {
+			return nil, errors.New("rrule: BYDAY in a YEARLY rule requires BYMONTH")
+		}
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return nil, errors.New("rrule: BYMONTHDAY is not valid in a WEEKLY rule")
+	}
+	return r, nil
+}
+
+func parseUntil(v string) (time.Time, bool, error) {
+	switch {
+	case len(v) == 8:
+		t, err := time.Parse("20060102", v)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("rrule: invalid UNTIL %q", v)
+		}
+		// A date includes the whole day.
+		return t.Add(24*time.Hour - time.Second), true, nil
+	case strings.HasSuffix(v, "Z"):
+		t, err := time.Parse("20060102T150405Z", v)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("rrule: invalid UNTIL %q", v)
+		}
+		return t, false, nil
+	default:
+		t, err := time.Parse("20060102T150405", v)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("rrule: invalid UNTIL %q", v)
+		}
+		return t, true, nil
+	}
+}
+
+func parseWeekday(v string) (Weekday, error) {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if len(v) < 2 {
+		return Weekday{}, fmt.Errorf("rrule: invalid BYDAY %q", v)
+	}
+	d, ok := dayNames[v[len(v)-2:]]
+	if !ok {
+		return Weekday{}, fmt.Errorf("rrule: invalid BYDAY %q", v)
+	}
+	wd := Weekday{Day: d}
+	if prefix := v[:len(v)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return Weekday{}, fmt.Errorf("rrule: invalid BYDAY %q", v)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+// String formats the rule as an RRULE value (without the "RRULE:" prefix).
+func (r *Rule) String() string {
+	names := map[Frequency]string{Daily: "DAILY", Weekly: "WEEKLY", Monthly: "MONTHLY", Yearly: "YEARLY"}
+	parts := []string{"FREQ=" + names[r.Freq]}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.UntilLocal {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = dayCode(wd.Day)
+			if wd.N != 0 {
+				days[i] = strconv.Itoa(wd.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = int(m)
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+dayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+func dayCode(d time.Weekday) string {
+	for code, wd := range dayNames {
+		if wd == d {
+			return code
+		}
+	}
+	return ""
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+// Between returns the occurrences of the rule starting at dtstart that fall in [from, to), at most limit of
+// them (0 = no limit). A zero to is unbounded; an unbounded rule without a limit returns nil. Occurrences
+// are in dtstart's location and keep its wall-clock time; a time skipped by a daylight saving change moves
+// forward by the gap and a repeated time takes its first instance (RFC 5545, section 3.3.5). COUNT counts from
+// dtstart, including occurrences before from.
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	loc := dtstart.Location()
+	until := r.Until
+	if !until.IsZero() && r.UntilLocal {
+		until = time.Date(until.Year(), until.Month(), until.Day(), until.Hour(), until.Minute(), until.Second(), 0, loc)
+	}
+	if to.IsZero() && limit <= 0 && r.Count == 0 && until.IsZero() {
+		return nil
+	}
+	clock := [3]int{dtstart.Hour(), dtstart.Minute(), dtstart.Second()}
+
+	var out []time.Time
+	count, empty := 0, 0
+	for period := 0; empty < maxEmptyPeriods; period++ {
+		days := r.periodDays(dtstart, period)
+		if len(days) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		for _, d := range days {
+			t := localTime(d.Year(), d.Month(), d.Day(), clock, loc)
+			if t.Before(dtstart) {
+				continue
+			}
+			if !until.IsZero() && t.After(until) {
+				return out
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return out
+			}
+			if !to.IsZero() && !t.Before(to) {
+				return out
+			}
+			if !t.Before(from) {
+				out = append(out, t)
+				if limit > 0 && len(out) >= limit {
+					return out
+				}
+			}
+		}
+	}
+	return out
+}
+
+// periodDays returns the sorted dates (midnight UTC, date part only) the rule selects in the n-th period
+// (day, week, month or year, stepped by INTERVAL) from dtstart's.
+func (r *Rule) periodDays(dtstart time.Time, n int) []time.Time {
+	start := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, time.UTC)
+	step := n * r.Interval
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		d := start.AddDate(0, 0, step)
+		if r.matchesMonth(d.Month()) && r.matchesMonthDay(d) && r.matchesWeekday(d) {
+			days = append(days, d)
+		}
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := start.AddDate(0, 0, -offset+7*step)
+		for i := 0; i < 7; i++ {
+			d := weekStart.AddDate(0, 0, i)
+			if !r.matchesMonth(d.Month()) {
+				continue
+			}
+			if len(r.ByDay) == 0 && d.Weekday() != start.Weekday() {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchesWeekday(d) {
+				continue
+			}
+			days = append(days, d)
+		}
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+		if r.matchesMonth(first.Month()) {
+			days = r.monthDays(first, start.Day())
+		}
+	case Yearly:
+		year := start.Year() + step
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, m := range months {
+			days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC), start.Day())...)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return dedupe(days)
+}
+
+// monthDays returns the days of the month starting at first selected by BYMONTHDAY and BYDAY (both must
+// match when both are set), or dtstart's day of the month when neither is set.
+func (r *Rule) monthDays(first time.Time, startDay int) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	var days []time.Time
+	for day := 1; day <= last; day++ {
+		d := first.AddDate(0, 0, day-1)
+		switch {
+		case len(r.ByMonthDay) == 0 && len(r.ByDay) == 0:
+			if day != startDay {
+				continue
+			}
+		case len(r.ByMonthDay) > 0 && !r.matchesMonthDay(d):
+			continue
+		case len(r.ByDay) > 0 && !r.matchesNthWeekday(d, last):
+			continue
+		}
+		days = append(days, d)
+	}
+	return days
+}
+
+func (r *Rule) matchesMonth(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.ByMonth {
+		if bm == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(d time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, md := range r.ByMonthDay {
+		if md == d.Day() || (md < 0 && last+md+1 == d.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesWeekday(d time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == d.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNthWeekday matches BYDAY within a month of last days: MO is every Monday, 2MO the second and -1MO
+// the last.
+func (r *Rule) matchesNthWeekday(d time.Time, last int) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day != d.Weekday() {
+			continue
+		}
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && (d.Day()-1)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && (last-d.Day())/7+1 == -wd.N:
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(days []time.Time) []time.Time {
+	out := days[:0]
+	for i, d := range days {
+		if i == 0 || !d.Equal(days[i-1]) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// localTime returns the wall-clock time on the given date in loc. A time skipped by a daylight saving
+// change uses the offset before the change (02:30 becomes 03:30); a repeated time takes the earlier instant.
+func localTime(year int, month time.Month, day int, clock [3]int, loc *time.Location) time.Time {
+	naive := time.Date(year, month, day, clock[0], clock[1], clock[2], 0, time.UTC)
+	_, before := naive.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := naive.Add(24 * time.Hour).In(loc).Zone()
+	for _, off := range []int{before, after} {
+		t := naive.Add(-time.Duration(off) * time.Second).In(loc)
+		if t.Hour() == clock[0] && t.Minute() == clock[1] && t.Second() == clock[2] {
+			return t
+		}
+	}
+	return naive.Add(-time.Duration(before) * time.Second).In(loc)
+}
@@ -0,0 +1,201 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func mustParse(t *testing.T, s string) *Rule {
+	t.Helper()
+	r, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return r
+}
+
+// assertWallClock checks every occurrence is at hh:mm local time and returns their UTC offsets in hours.
+func assertWallClock(t *testing.T, got []time.Time, hh, mm int) []float64 {
+	t.Helper()
+	offsets := make([]float64, len(got))
+	for i, o := range got {
+		if o.Hour() != hh || o.Minute() != mm {
+			t.Errorf("occurrence %d at %s, want %02d:%02d local", i, o, hh, mm)
+		}
+		_, off := o.Zone()
+		offsets[i] = float64(off) / 3600
+	}
+	return offsets
+}
+
+func TestWeeklyKeepsWallClockAcrossUSDaylightSaving(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	r := mustParse(t, "FREQ=WEEKLY;BYDAY=TU")
+	// US clocks go forward on Sunday 2026-03-08.
+	dtstart := time.Date(2026, 3, 3, 10, 0, 0, 0, ny)
+	got := r.Between(dtstart, time.Time{}, time.Time{}, 3)
+	wantDays := []int{3, 10, 17}
+	if len(got) != len(wantDays) {
+		t.Fatalf("got %d occurrences, want %d", len(got), len(wantDays))
+	}
+	for i, o := range got {
+		if o.Month() != time.March || o.Day() != wantDays[i] || o.Weekday() != time.Tuesday {
+			t.Errorf("occurrence %d on %s, want Tuesday March %d", i, o.Format(time.RFC3339), wantDays[i])
+		}
+	}
+	offsets := assertWallClock(t, got, 10, 0)
+	if offsets[0] != -5 || offsets[1] != -4 || offsets[2] != -4 {
+		t.Errorf("UTC offsets %v, want [-5 -4 -4]", offsets)
+	}
+	if d := got[1].Sub(got[0]); d != 7*24*time.Hour-time.Hour {
+		t.Errorf("gap over the change is %s, want 167h", d)
+	}
+
+	// Clocks go back on Sunday 2026-11-01.
+	dtstart = time.Date(2026, 10, 27, 10, 0, 0, 0, ny)
+	got = r.Between(dtstart, time.Time{}, time.Time{}, 2)
+	offsets = assertWallClock(t, got, 10, 0)
+	if offsets[0] != -4 || offsets[1] != -5 {
+		t.Errorf("UTC offsets %v, want [-4 -5]", offsets)
+	}
+}
+
+func TestWeeklyKeepsWallClockAcrossEuropeanDaylightSaving(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	r := mustParse(t, "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=6")
+	// European clocks go forward on Sunday 2026-03-29, a different week than in the US.
+	dtstart := time.Date(2026, 3, 24, 18, 30, 0, 0, berlin)
+	got := r.Between(dtstart, time.Time{}, time.Time{}, 0)
+	if len(got) != 6 {
+		t.Fatalf("got %d occurrences, want 6 (COUNT)", len(got))
+	}
+	offsets := assertWallClock(t, got, 18, 30)
+	want := []float64{1, 1, 2, 2, 2, 2}
+	for i := range want {
+		if offsets[i] != want[i] {
+			t.Fatalf("UTC offsets %v, want %v", offsets, want)
+		}
+	}
+}
+
+func TestSkippedAndRepeatedLocalTimes(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+
+	// 02:30 does not exist on 2026-03-08: it moves forward by the gap to 03:30 EDT.
+	r := mustParse(t, "FREQ=DAILY;COUNT=3")
+	got := r.Between(time.Date(2026, 3, 7, 2, 30, 0, 0, ny), time.Time{}, time.Time{}, 0)
+	if len(got) != 3 {
+		t.Fatalf("got %d occurrences, want 3", len(got))
+	}
+	if got[1].Day() != 8 || got[1].Hour() != 3 || got[1].Minute() != 30 {
+		t.Errorf("occurrence in the gap at %s, want 2026-03-08 03:30", got[1])
+	}
+	if got[2].Hour() != 2 || got[2].Minute() != 30 {
+		t.Errorf("occurrence after the gap at %s, want 02:30", got[2])
+	}
+
+	// 01:30 happens twice on 2026-11-01: the first (EDT) instance is used.
+	got = r.Between(time.Date(2026, 10, 31, 1, 30, 0, 0, ny), time.Time{}, time.Time{}, 0)
+	if len(got) != 3 {
+		t.Fatalf("got %d occurrences, want 3", len(got))
+	}
+	if _, off := got[1].Zone(); got[1].Hour() != 1 || off != -4*3600 {
+		t.Errorf("repeated time resolved to %s, want 01:30 EDT", got[1])
+	}
+	if d := got[2].Sub(got[1]); d != 25*time.Hour {
+		t.Errorf("day after the repeated hour is %s later, want 25h", d)
+	}
+}
+
+func TestMonthlyByDay(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	cases := []struct {
+		rule string
+		want []string
+	}{
+		{"FREQ=MONTHLY;BYDAY=2TU;COUNT=3", []string{"2026-01-13", "2026-02-10", "2026-03-10"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", []string{"2026-01-30", "2026-02-27", "2026-03-27"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3", []string{"2026-01-31", "2026-03-31", "2026-05-31"}},
+		{"FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=1,-1;COUNT=4", []string{"2026-01-01", "2026-01-31", "2026-03-01", "2026-03-31"}},
+		{"FREQ=YEARLY;BYMONTH=3;BYDAY=2SU;COUNT=2", []string{"2026-03-08", "2027-03-14"}},
+	}
+	dtstart := time.Date(2026, 1, 1, 9, 0, 0, 0, ny)
+	for _, tc := range cases {
+		got := mustParse(t, tc.rule).Between(dtstart, time.Time{}, time.Time{}, 0)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %d occurrences, want %d", tc.rule, len(got), len(tc.want))
+			continue
+		}
+		for i, o := range got {
+			if d := o.Format("2006-01-02"); d != tc.want[i] {
+				t.Errorf("%s: occurrence %d on %s, want %s", tc.rule, i, d, tc.want[i])
+			}
+		}
+	}
+}
+
+func TestBetweenWindowAndUntil(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	dtstart := time.Date(2026, 3, 3, 10, 0, 0, 0, ny)
+
+	// UNTIL as a date includes that whole day, read in the series time zone.
+	r := mustParse(t, "FREQ=WEEKLY;UNTIL=20260324")
+	if got := r.Between(dtstart, time.Time{}, time.Time{}, 0); len(got) != 4 {
+		t.Errorf("UNTIL date: got %d occurrences, want 4", len(got))
+	}
+	// UNTIL in UTC: 2026-03-17 14:00 UTC is 10:00 EDT, so the 17th is included.
+	r = mustParse(t, "FREQ=WEEKLY;UNTIL=20260317T140000Z")
+	if got := r.Between(dtstart, time.Time{}, time.Time{}, 0); len(got) != 3 {
+		t.Errorf("UNTIL UTC: got %d occurrences, want 3", len(got))
+	}
+
+	// COUNT counts from dtstart even when the window starts later.
+	r = mustParse(t, "FREQ=WEEKLY;COUNT=4")
+	got := r.Between(dtstart, time.Date(2026, 3, 15, 0, 0, 0, 0, ny), time.Time{}, 0)
+	if len(got) != 2 || got[0].Day() != 17 || got[1].Day() != 24 {
+		t.Errorf("window after dtstart: got %v, want March 17 and 24", got)
+	}
+	got = r.Between(dtstart, time.Time{}, time.Date(2026, 3, 17, 10, 0, 0, 0, ny), 0)
+	if len(got) != 2 {
+		t.Errorf("window end is exclusive: got %d occurrences, want 2", len(got))
+	}
+
+	if got := mustParse(t, "FREQ=DAILY").Between(dtstart, time.Time{}, time.Time{}, 0); got != nil {
+		t.Errorf("unbounded rule without a limit returned %d occurrences", len(got))
+	}
+}
+
+func TestParse(t *testing.T) {
+	r := mustParse(t, "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10;WKST=SU")
+	if r.Freq != Weekly || r.Interval != 2 || r.Count != 10 || len(r.ByDay) != 2 || r.WeekStart != time.Sunday {
+		t.Errorf("parsed %+v", r)
+	}
+	if s := r.String(); s != "FREQ=WEEKLY;INTERVAL=2;COUNT=10;BYDAY=MO,WE;WKST=SU" {
+		t.Errorf("String() = %q", s)
+	}
+	for _, bad := range []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;INTERVAL=0",
+		"FREQ=WEEKLY;COUNT=3;UNTIL=20260101",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYDAY=MO",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", bad)
+		}
+	}
+}
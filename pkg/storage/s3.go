@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path"
 	"strings"
@@ -138,6 +139,11 @@ func AdKey(webinarID, filename string) string {
 	return path.Join(FolderAds, webinarID, path.Base(filename))
 }
 
+// TemplateAdKey returns the S3 object key for a webinar template's ad: ads/templates/{template_id}/{filename}.
+func TemplateAdKey(templateID, filename string) string {
+	return path.Join(FolderAds, "templates", templateID, path.Base(filename))
+}
+
 // Allowed registration file types (PDF, DOC, DOCX, images).
 var (
 	AllowedRegistrationTypes = map[string]string{
@@ -284,6 +290,23 @@ func (s *S3) DeleteObject(ctx context.Context, bucket, key string) error {
 	return nil
 }
 
+// CopyAd copies an ad object within the ads bucket, publicly readable like uploaded ads, and returns the
+// copy's public URL.
+func (s *S3) CopyAd(ctx context.Context, srcKey, dstKey string) (string, error) {
+	bucket := s.cfg.AdsBucket
+	source := (&url.URL{Path: bucket + "/" + srcKey}).EscapedPath()
+	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		CopySource: aws.String(source),
+		Key:        aws.String(dstKey),
+		ACL:        types.ObjectCannedACLPublicRead,
+	})
+	if err != nil {
+		return "", fmt.Errorf("copy object: %w", err)
+	}
+	return s.PublicObjectURL(bucket, dstKey), nil
+}
+
 // DeleteAd removes an ad object from the ads bucket.
 func (s *S3) DeleteAd(ctx context.Context, key string) error {
 	return s.DeleteObject(ctx, s.cfg.AdsBucket, key)
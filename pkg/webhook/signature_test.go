@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec-current"
+	body := []byte(`{"event":"registration.created"}`)
+	signedAt := time.Unix(1_760_000_000, 0)
+	ts := strconv.FormatInt(signedAt.Unix(), 10)
+	sig := Sign(secret, signedAt, body)
+	oldSig := Sign("whsec-previous", signedAt, body)
+	tolerance := 5 * time.Minute
+
+	tests := []struct {
+		name      string
+		signature string
+		timestamp string
+		body      []byte
+		now       time.Time
+		want      error
+	}{
+		{name: "valid", signature: sig, timestamp: ts, body: body, now: signedAt},
+		{name: "at tolerance in the past", signature: sig, timestamp: ts, body: body, now: signedAt.Add(tolerance)},
+		{name: "at tolerance in the future", signature: sig, timestamp: ts, body: body, now: signedAt.Add(-tolerance)},
+		{name: "expired", signature: sig, timestamp: ts, body: body, now: signedAt.Add(tolerance + time.Second), want: ErrExpired},
+		{name: "too far in the future", signature: sig, timestamp: ts, body: body, now: signedAt.Add(-tolerance - time.Second), want: ErrExpired},
+		{name: "missing signature", timestamp: ts, body: body, now: signedAt, want: ErrMissingSignature},
+		{name: "missing timestamp", signature: sig, body: body, now: signedAt, want: ErrMissingSignature},
+		{name: "invalid timestamp", signature: sig, timestamp: "yesterday", body: body, now: signedAt, want: ErrInvalidTimestamp},
+		{name: "timestamp with spaces", signature: sig, timestamp: " " + ts + " ", body: body, now: signedAt},
+		{name: "other timestamp", signature: sig, timestamp: strconv.FormatInt(signedAt.Unix()+1, 10), body: body, now: signedAt, want: ErrBadSignature},
+		{name: "tampered body", signature: sig, timestamp: ts, body: []byte(`{"event":"registration.deleted"}`), now: signedAt, want: ErrBadSignature},
+		{name: "other secret only", signature: oldSig, timestamp: ts, body: body, now: signedAt, want: ErrBadSignature},
+		{name: "without scheme prefix", signature: sig[len(signaturePrefix):], timestamp: ts, body: body, now: signedAt},
+		{name: "rotated: current second", signature: oldSig + "," + sig, timestamp: ts, body: body, now: signedAt},
+		{name: "rotated: current first", signature: sig + ", " + oldSig, timestamp: ts, body: body, now: signedAt},
+		{name: "malformed entry skipped", signature: "sha256=not-hex," + sig, timestamp: ts, body: body, now: signedAt},
+		{name: "only malformed entries", signature: "sha256=zz,,sha256=", timestamp: ts, body: body, now: signedAt, want: ErrBadSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(secret, tt.signature, tt.timestamp, tt.body, tolerance, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Package xlsx writes single-sheet Office Open XML spreadsheets row by row, so exports of any size stream
+// straight to the client. Cells are inline strings or numbers; there is no styling.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the MIME type of .xlsx files.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+const (
+	contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	sheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	sheetFooter = `</sheetData></worksheet>`
+	// maxSheetName is Excel's limit on sheet name length.
+	maxSheetName = 31
+)
+
+// Writer streams rows into the single worksheet of a workbook.
+type Writer struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	err   error
+}
+
+// Cell is one cell value: a string, or a number when Number is set.
+type Cell struct {
+	Text   string
+	Number *float64
+}
+
+// String returns a text cell.
+func String(s string) Cell { return Cell{Text: s} }
+
+// Number returns a numeric cell.
+func Number(x float64) Cell { return Cell{Number: &x} }
+
+// NewWriter writes the workbook parts to w and opens the worksheet named sheetName for rows.
+// Call Close to finish the file.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	name := sanitizeSheetName(sheetName)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escape(name) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	if _, err := sheet.WriteString(sheetHeader); err != nil {
+		return nil, err
+	}
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteRow appends a row. Errors are sticky and also returned by Close.
+func (w *Writer) WriteRow(cells []Cell) error {
+	if w.err != nil {
+		return w.err
+	}
+	var b strings.Builder
+	b.WriteString("<row>")
+	for _, c := range cells {
+		if c.Number != nil {
+			b.WriteString("<c><v>" + strconv.FormatFloat(*c.Number, 'f', -1, 64) + "</v></c>")
+			continue
+		}
+		b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		b.WriteString(escape(c.Text))
+		b.WriteString("</t></is></c>")
+	}
+	b.WriteString("</row>")
+	_, w.err = w.sheet.WriteString(b.String())
+	return w.err
+}
+
+// WriteStrings appends a row of text cells.
+func (w *Writer) WriteStrings(values []string) error {
+	cells := make([]Cell, len(values))
+	for i, v := range values {
+		cells[i] = String(v)
+	}
+	return w.WriteRow(cells)
+}
+
+// Flush pushes buffered rows to the underlying writer.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.sheet.Flush()
+	if w.err == nil {
+		w.err = w.zw.Flush()
+	}
+	return w.err
+}
+
+// Close ends the worksheet and the archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if _, err := w.sheet.WriteString(sheetFooter); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// escape escapes XML text and drops characters XML 1.0 cannot carry.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '<':
+			b.WriteString("&lt;")
+		case r == '>':
+			b.WriteString("&gt;")
+		case r == '&':
+			b.WriteString("&amp;")
+		case r == '"':
+			b.WriteString("&quot;")
+		case r == '\t' || r == '\n' || r == '\r' || r >= 0x20 && r <= 0xD7FF || r >= 0xE000 && r <= 0xFFFD || r >= 0x10000 && r <= 0x10FFFF:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// sanitizeSheetName removes characters Excel forbids in sheet names and truncates to its length limit.
+func sanitizeSheetName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimSpace(strings.Trim(s, "'"))
+	if r := []rune(s); len(r) > maxSheetName {
+		s = string(r[:maxSheetName])
+	}
+	if s == "" {
+		return "Sheet1"
+	}
+	return s
+}